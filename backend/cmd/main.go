@@ -8,22 +8,39 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"backend/config"
 	"backend/internal/adapters/email"
 	"backend/internal/adapters/http"
+	"backend/internal/adapters/http/middleware"
 	mlservice "backend/internal/adapters/ml_service"
 	"backend/internal/adapters/storage"
+	"backend/internal/entities"
+	"backend/internal/services/achievement"
+	"backend/internal/services/activity"
 	"backend/internal/services/auth"
+	"backend/internal/services/authz"
+	"backend/internal/services/events"
+	"backend/internal/services/mail"
 	"backend/internal/services/scheduler"
 
+	"backend/internal/adapters/postgres/analytics"
+	"backend/internal/adapters/postgres/cohort"
 	"backend/internal/adapters/postgres/course"
+	"backend/internal/adapters/postgres/digest"
 	"backend/internal/adapters/postgres/gamification"
+	"backend/internal/adapters/postgres/jobs"
+	"backend/internal/adapters/postgres/outbox"
+	"backend/internal/adapters/postgres/ownership"
 	"backend/internal/adapters/postgres/profile"
 	"backend/internal/adapters/postgres/progress"
+	"backend/internal/adapters/postgres/ratelimit"
+	"backend/internal/adapters/postgres/session"
 	"backend/internal/adapters/postgres/subject"
 	"backend/internal/adapters/postgres/testing"
 	"backend/internal/adapters/postgres/user"
+	"backend/internal/adapters/postgres/xp"
 	courseService "backend/internal/services/course"
 	gamificationService "backend/internal/services/gamification"
 	"backend/internal/services/student"
@@ -52,6 +69,11 @@ func main() {
 		log.Fatal("JWT_SECRET must be set and should not use default value")
 	}
 
+	verificationMode, err := auth.ParseVerificationMode(cfg.EmailVerificationMode)
+	if err != nil {
+		log.Fatalf("Invalid EMAIL_VERIFICATION_MODE: %v", err)
+	}
+
 	ctx := context.Background()
 
 	connectionURL := fmt.Sprintf(
@@ -105,17 +127,83 @@ func main() {
 	}
 	defer gamificationRepo.Close()
 
+	analyticsRepo := analytics.NewAnalyticsRepository(connectionURL)
+	if err := analyticsRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed analytics repo: %v", err)
+	}
+	defer analyticsRepo.Close()
+
+	sessionRepo := session.NewSessionRepository(connectionURL)
+	if err := sessionRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed session repo: %v", err)
+	}
+	defer sessionRepo.Close()
+
+	ownershipRepo := ownership.NewOwnershipRepository(connectionURL)
+	if err := ownershipRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed ownership repo: %v", err)
+	}
+	defer ownershipRepo.Close()
+
+	xpRepo := xp.NewXPRepository(connectionURL)
+	if err := xpRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed xp repo: %v", err)
+	}
+	defer xpRepo.Close()
+
+	cohortRepo := cohort.NewCohortRepository(connectionURL)
+	if err := cohortRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed cohort repo: %v", err)
+	}
+	defer cohortRepo.Close()
+
+	jobRunRepo := jobs.NewJobRunRepository(connectionURL)
+	if err := jobRunRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed job run repo: %v", err)
+	}
+	defer jobRunRepo.Close()
+
+	digestRepo := digest.NewDigestRepository(connectionURL)
+	if err := digestRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed digest repo: %v", err)
+	}
+	defer digestRepo.Close()
+
+	outboxRepo := outbox.NewOutboxRepository(connectionURL)
+	if err := outboxRepo.Connect(ctx); err != nil {
+		log.Fatalf("Failed outbox repo: %v", err)
+	}
+	defer outboxRepo.Close()
+
+	var rateLimitStore middleware.RateLimitStore
+	switch cfg.RateLimitBackend {
+	case "postgres":
+		rateLimitRepo := ratelimit.NewRateLimitRepository(connectionURL)
+		if err := rateLimitRepo.Connect(ctx); err != nil {
+			log.Fatalf("Failed rate limit repo: %v", err)
+		}
+		defer rateLimitRepo.Close()
+		rateLimitStore = rateLimitRepo
+	default:
+		rateLimitStore = middleware.NewMemoryRateLimitStore()
+	}
+
 	log.Println("All repositories connected")
 
-	jwtManager := jwt.NewJWTManager(cfg.JWTSecret)
+	jwtManager := jwt.NewJWTManager(cfg.JWTSecret, cfg.JWTAccessTTL)
 
-	emailService := email.NewGomailService(
+	smtpSender := email.NewGomailService(
 		cfg.SMTPHost,
 		cfg.SMTPPort,
 		cfg.SMTPUser,
 		cfg.SMTPPassword,
 		cfg.SMTPFrom,
 	)
+	emailRenderer, err := email.NewRenderer()
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+	mailer := mail.NewMailer(emailRenderer, outboxRepo)
 	minioStorage, err := storage.NewMinioStorage(
 		cfg.MinioEndpoint,
 		cfg.MinioUser,
@@ -130,10 +218,30 @@ func main() {
 
 	mlClient := mlservice.NewClient(cfg.MLServiceURL)
 
-	authService := auth.NewAuthService(userRepo, jwtManager, minioStorage, emailService)
+	activityTracker := activity.NewTracker(analyticsRepo, 4096, 200, 2*time.Second)
+	activityTracker.Start()
+
+	mailDispatcher := mail.NewDispatcher(outboxRepo, smtpSender, 5*time.Second, 20)
+	mailDispatcher.Start()
+
+	eventBus := events.NewBus(outboxRepo, 30*time.Second)
+
+	authService := auth.NewAuthService(
+		userRepo,
+		sessionRepo,
+		jwtManager,
+		minioStorage,
+		mailer,
+		eventBus,
+		cfg.JWTRefreshTTL,
+		auth.VerificationConfig{Mode: verificationMode, LinkBaseURL: cfg.AppBaseURL},
+	)
 	subjService := subjectService.NewSubjectService(subjectRepo)
-	cService := courseService.NewCourseService(courseRepo, mlClient)
-	testService := testService.NewTestService(testRepo)
+	policy := authz.NewPolicy(ownershipRepo)
+	gService := gamificationService.NewGamificationService(gamificationRepo)
+	cService := courseService.NewCourseService(courseRepo, mlClient, policy)
+	testService := testService.NewTestService(testRepo, policy)
+	achievementEngine := achievement.NewEngine(gamificationRepo, xpRepo, gService)
 	studentService := student.NewStudentService(
 		profileRepo,
 		subjectRepo,
@@ -142,16 +250,90 @@ func main() {
 		gamificationRepo,
 		testRepo,
 		userRepo,
+		activityTracker,
+		xpRepo,
+		gService,
+		cohortRepo,
+		eventBus,
 	)
-	gService := gamificationService.NewGamificationService(gamificationRepo)
-
-	weeklyResetService := scheduler.NewWeeklyResetService(profileRepo, gamificationRepo)
-	schedulerCtx, cancelScheduler := context.WithCancel(context.Background())
 
-	go weeklyResetService.Start(schedulerCtx)
-	log.Println("Weekly reset scheduler started")
+	eventBus.Subscribe("course_progress", 4, 512, studentService.OnLessonCompleted,
+		entities.DomainLessonCompleted,
+	)
+	eventBus.Subscribe("achievements", 4, 512, achievementEngine.OnEvent,
+		entities.DomainLessonCompleted,
+		entities.DomainTestSubmitted,
+		entities.DomainCourseCompleted,
+		entities.DomainLeaguePromoted,
+	)
+	eventBus.Start()
+
+	eventRelay := events.NewRelay(outboxRepo, eventBus, time.Second, 50)
+	eventRelay.Start()
+
+	weeklyResetService := scheduler.NewWeeklyResetService(profileRepo, gamificationRepo, cohortRepo, eventBus)
+	streakFinalizer := scheduler.NewStreakFinalizer(profileRepo)
+
+	digestSender := scheduler.NewDigestSender(digestRepo, mailer)
+	courseStatsJob := scheduler.NewCourseStatsJob(courseRepo)
+
+	jobScheduler := scheduler.NewScheduler(jobRunRepo)
+	for _, job := range []scheduler.Job{
+		{
+			// Сброс идемпотентен: ежечасная проверка догоняет неделю,
+			// если запуск в начале понедельника не удался
+			Name:       "weekly_league_reset",
+			Schedule:   "0 * * * *",
+			Run:        weeklyResetService.CheckAndRunReset,
+			Timeout:    30 * time.Minute,
+			Retries:    3,
+			RetryDelay: time.Minute,
+			CatchUp:    true,
+		},
+		{
+			Name:     "streak_finalizer",
+			Schedule: "*/15 * * * *",
+			Run:      streakFinalizer.Run,
+			Retries:  2,
+			CatchUp:  true,
+		},
+		{
+			// Итоги прошедшей недели лиг; отметки в email_digests не дают
+			// догоняющему запуску и повторам отправить письмо дважды
+			Name:       "weekly_digest",
+			Schedule:   "0 6 * * 1",
+			Run:        digestSender.Run,
+			Timeout:    30 * time.Minute,
+			Retries:    3,
+			RetryDelay: 5 * time.Minute,
+			CatchUp:    true,
+		},
+		{
+			Name:     "course_stats",
+			Schedule: "20 * * * *",
+			Run:      courseStatsJob.Run,
+			Retries:  2,
+			CatchUp:  true,
+		},
+		{
+			Name:     "expired_tokens_cleanup",
+			Schedule: "30 3 * * *",
+			Run:      authService.CleanupExpiredTokens,
+			Retries:  2,
+		},
+		{
+			Name:     "job_runs_cleanup",
+			Schedule: "45 3 * * *",
+			Run:      jobScheduler.PruneRuns,
+		},
+	} {
+		if err := jobScheduler.Register(job); err != nil {
+			log.Fatalf("Failed to register job: %v", err)
+		}
+	}
+	jobScheduler.Start()
 
-	httpServer := http.NewServer(
+	httpServer, err := http.NewServer(
 		authService,
 		cService,
 		subjService,
@@ -159,8 +341,16 @@ func main() {
 		testService,
 		studentService,
 		gService,
-		cfg.JWTSecret,
+		activityTracker,
+		weeklyResetService,
+		jobScheduler,
+		jwtManager,
+		rateLimitStore,
+		cfg.TrustedProxies,
 	)
+	if err != nil {
+		log.Fatalf("Failed to create HTTP server: %v", err)
+	}
 
 	log.Println("Starting Education Platform API...")
 	log.Printf("Server running on http://localhost:%s", cfg.ServerPort)
@@ -179,17 +369,43 @@ func main() {
 	case sig := <-quit:
 		log.Printf("Received signal: %s", sig)
 
-		cancelScheduler()
+		// У каждого компонента свой срок: если один не успел остановиться,
+		// остальные всё равно получают время на свою работу
+		if err := stopWithin(10*time.Second, httpServer.Shutdown); err != nil {
+			log.Printf("Graceful shutdown failed, server forced to shutdown: %v", err)
+		}
+
+		// Задачи сами ограничены своими таймаутами; ждём текущую до конца
+		if err := stopWithin(30*time.Second, jobScheduler.Stop); err != nil {
+			log.Printf("Failed to stop job scheduler: %v", err)
+		}
+
+		if err := stopWithin(5*time.Second, activityTracker.Stop); err != nil {
+			log.Printf("Failed to flush activity logs: %v", err)
+		}
+
+		// Текущее письмо может ждать SMTP; не отправленное уйдёт после рестарта
+		if err := stopWithin(15*time.Second, mailDispatcher.Stop); err != nil {
+			log.Printf("Failed to stop mail dispatcher: %v", err)
+		}
 
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		// Сначала relay, чтобы он не передавал события в остановленную шину
+		if err := stopWithin(5*time.Second, eventRelay.Stop); err != nil {
+			log.Printf("Failed to stop event relay: %v", err)
+		}
 
-		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Graceful shutdown failed: %v", err)
-			log.Fatal("Server forced to shutdown")
+		if err := stopWithin(10*time.Second, eventBus.Stop); err != nil {
+			log.Printf("Failed to drain event bus: %v", err)
 		}
 	}
 
 	log.Println("Server stopped gracefully")
 	log.Println("All connections closed")
 }
+
+// stopWithin останавливает компонент, давая ему timeout.
+func stopWithin(timeout time.Duration, stop func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return stop(ctx)
+}
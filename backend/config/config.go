@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -16,6 +18,10 @@ type Config struct {
 	ServerPort string
 	JWTSecret  string
 
+	// Время жизни access-токена и сессии (refresh-токена)
+	JWTAccessTTL  time.Duration
+	JWTRefreshTTL time.Duration
+
 	// MINIO
 	MinioEndpoint   string
 	MinioUser       string
@@ -32,6 +38,20 @@ type Config struct {
 	SMTPFrom     string
 
 	MLServiceURL string
+
+	// memory — лимиты в памяти процесса, postgres — общие для всех реплик.
+	// postgres пишет в базу на каждый запрос, поэтому включайте его только
+	// при нескольких экземплярах API
+	RateLimitBackend string
+	// Адреса или подсети обратных прокси, которым можно верить в
+	// X-Forwarded-For. Пусто — API смотрит в сеть напрямую, и IP клиента
+	// берётся из соединения
+	TrustedProxies []string
+
+	// Что доступно до подтверждения email: allow, read_only или block
+	EmailVerificationMode string
+	// Адрес фронтенда для ссылок в письмах
+	AppBaseURL string
 }
 
 func LoadConfig() Config {
@@ -44,6 +64,9 @@ func LoadConfig() Config {
 		DBSSLMode:  GetEnv("DB_SSLMODE", "disable"),
 		ServerPort: GetEnv("SERVER_PORT", "8080"),
 		JWTSecret:  GetEnv("JWT_SECRET", "secret"),
+
+		JWTAccessTTL:  getEnvAsDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		JWTRefreshTTL: getEnvAsDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
 		// MINIO
 		MinioEndpoint:   GetEnv("MINIO_ENDPOINT", "localhost:9000"),
 		MinioUser:       GetEnv("MINIO_ROOT_USER", "admin"),
@@ -58,6 +81,12 @@ func LoadConfig() Config {
 		SMTPPassword: GetEnv("SMTP_PASSWORD", ""),
 		SMTPFrom:     GetEnv("SMTP_FROM", "School With AI <no-reply@school.com>"),
 		MLServiceURL: GetEnv("ML_SERVICE_URL", "http://0.0.0.0:5000"),
+
+		RateLimitBackend: GetEnv("RATE_LIMIT_BACKEND", "memory"),
+		TrustedProxies:   getEnvAsList("TRUSTED_PROXIES"),
+
+		EmailVerificationMode: GetEnv("EMAIL_VERIFICATION_MODE", "read_only"),
+		AppBaseURL:            GetEnv("APP_BASE_URL", "http://localhost:3000"),
 	}
 }
 
@@ -99,3 +128,27 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getEnvAsList читает список через запятую; пустой список — nil.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -17,11 +17,6 @@ const docTemplate = `{
     "paths": {
         "/teacher/courses": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "produces": [
                     "application/json"
                 ],
@@ -33,23 +28,55 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CourseListResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseListResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/auth/change-password": {
-            "post": {
+        "/v1/admin/gamification/policy": {
+            "get": {
+                "description": "Level curve and XP rules currently in effect",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get gamification policy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.GamificationPolicyPayload"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Change password for the currently authenticated user",
+                ]
+            },
+            "put": {
+                "description": "Replaces the level curve and XP rules. Students whose XP reaches a higher level on the new curve are raised to it; levels are never lowered.",
                 "consumes": [
                     "application/json"
                 ],
@@ -57,23 +84,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Change user password",
+                "summary": "Update gamification policy",
                 "parameters": [
                     {
-                        "description": "Password change payload",
+                        "description": "Policy",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ChangePasswordRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.GamificationPolicyPayload"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.GamificationPolicyPayload"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -81,154 +111,149 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
-                        }
+                        "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/auth/login": {
-            "post": {
-                "description": "Authenticate user and return JWT token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/jobs": {
+            "get": {
+                "description": "Registered periodic jobs with their cron schedule (UTC), next run and the most recent run on any instance",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
-                ],
-                "summary": "Login user",
-                "parameters": [
-                    {
-                        "description": "Login credentials",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.LoginRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "List scheduled jobs",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.LoginResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.JobsListResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
-                        }
+                        "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/auth/register": {
-            "post": {
-                "description": "Register a new user (student or teacher)",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/v1/admin/jobs/{name}/runs": {
+            "get": {
+                "description": "Most recent runs of the job, newest first. History is kept for 30 days.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "List runs of a job",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "User Avatar Image",
-                        "name": "avatar",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "User Email",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User Password (min 8 chars)",
-                        "name": "password",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User Role (student or teacher)",
-                        "name": "role",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "First Name",
-                        "name": "first_name",
-                        "in": "formData",
+                        "description": "Job name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Last Name",
-                        "name": "last_name",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Number of runs (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.RegisterResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.JobRunsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "User already exists",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/admin/leagues/weekly-reset/preview": {
+            "get": {
+                "description": "Dry run: reports league sizes, promotions and demotions the weekly reset would apply now. Nothing is written.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Preview weekly league reset",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.WeeklyResetPlanResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/auth/reset-password": {
-            "post": {
-                "description": "Change password using email and old password",
+        "/v1/admin/leagues/{id}/zones": {
+            "put": {
+                "description": "Percent of league members promoted and demoted at the weekly reset (0-50 each). Students without weekly XP rank last and are demoted first; demote_inactive demotes all of them. Applies from the next reset.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,23 +261,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Reset password (public)",
+                "summary": "Update league promotion and demotion zones",
                 "parameters": [
                     {
-                        "description": "Reset password payload",
+                        "type": "integer",
+                        "description": "League ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Zones",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ResetPasswordRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeagueZonesRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeagueResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -260,23 +295,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
-                }
-            }
-        },
-        "/v1/courses": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a course (Teacher/Admin only)",
+                ]
+            }
+        },
+        "/v1/auth/change-password": {
+            "post": {
+                "description": "Change password for the currently authenticated user. All sessions, including the current one, are revoked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -284,26 +322,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Create a new course",
+                "summary": "Change user password",
                 "parameters": [
                     {
-                        "description": "Course data",
+                        "description": "Password change payload",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateCourseRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ChangePasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateCourseResponse"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -318,19 +353,22 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/v1/courses/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get details of a specific course by ID",
+                ]
+            }
+        },
+        "/v1/auth/forgot-password": {
+            "post": {
+                "description": "Sends a 6-digit code to the user's email",
                 "consumes": [
                     "application/json"
                 ],
@@ -338,46 +376,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Get course details",
+                "summary": "Request password reset code",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CourseDetailResponse"
-                        }
+                        "description": "OK"
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update title, description, cover, etc. (Author only)",
+            }
+        },
+        "/v1/auth/login": {
+            "post": {
+                "description": "Authenticate user and return JWT token",
                 "consumes": [
                     "application/json"
                 ],
@@ -385,30 +419,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Update course details",
+                "summary": "Login user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update data",
+                        "description": "Login credentials",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.UpdateCourseRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LoginResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -417,29 +447,54 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Email is not verified",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/logout": {
+            "post": {
+                "description": "Revokes the current session: its refresh token and access tokens stop working immediately",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Delete course by id",
+                ]
+            }
+        },
+        "/v1/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new token pair. The old refresh token stops working; presenting it again revokes the whole session.",
                 "consumes": [
                     "application/json"
                 ],
@@ -447,21 +502,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Delete course",
+                "summary": "Refresh access token",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LoginResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -469,57 +529,89 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/courses/{id}/publish": {
+        "/v1/auth/register": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Change visibility of the course",
+                "description": "Register a new user (student or teacher)",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Publish or Unpublish course",
+                "summary": "Register a new user",
                 "parameters": [
+                    {
+                        "type": "file",
+                        "description": "User Avatar Image",
+                        "name": "avatar",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "User Email",
+                        "name": "email",
+                        "in": "formData",
                         "required": true
                     },
                     {
-                        "description": "Status",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.PublishStatusRequest"
-                        }
+                        "type": "string",
+                        "description": "User Password (min 8 chars)",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (student or teacher)",
+                        "name": "role",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First Name",
+                        "name": "first_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last Name",
+                        "name": "last_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred language (ru, kk or en), negotiated from Accept-Language by default",
+                        "name": "locale",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.RegisterResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -527,83 +619,91 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "409": {
+                        "description": "User already exists",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/courses/{id}/structure": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/v1/auth/resend-verification": {
+            "post": {
+                "description": "Sends a new verification link if the account exists and is not verified yet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Get modules and lessons for editor",
                 "tags": [
-                    "courses"
+                    "auth"
                 ],
-                "summary": "Get full course structure",
+                "summary": "Resend verification email",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ResendVerificationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.GetStructureResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error"
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/lessons": {
+        "/v1/auth/reset-password": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+                "description": "Change password using email and the code received via email. All sessions are revoked.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "lessons"
+                    "auth"
                 ],
-                "summary": "Add lesson to module",
+                "summary": "Reset password with code",
                 "parameters": [
                     {
-                        "description": "Lesson data",
+                        "description": "Reset payload",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateLessonRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ResetPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateLessonResponse"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -611,136 +711,184 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/lessons/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/v1/auth/verify-email": {
+            "post": {
+                "description": "Confirms the email with the token from the verification link. Write access opens immediately, even with tokens issued before; the email_verified claim updates on the next /v1/auth/refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Get content, video url and attachments for a specific lesson",
                 "tags": [
-                    "lessons"
+                    "auth"
                 ],
-                "summary": "Get full lesson details",
+                "summary": "Verify email",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Lesson ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Verification token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.VerifyEmailRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.LessonResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
-                "security": [
+            }
+        },
+        "/v1/catalog": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Get all published courses",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Search by title or description",
+                        "name": "q",
+                        "in": "query"
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/courses": {
+            "post": {
+                "description": "Create a course (Teacher/Admin only)",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "lessons"
+                    "courses"
                 ],
-                "summary": "Update lesson content",
+                "summary": "Create a new course",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Lesson ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data",
+                        "description": "Course data",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.UpdateLessonRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateCourseRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateCourseResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/v1/courses/favorites": {
+            "get": {
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "lessons"
-                ],
-                "summary": "Delete lesson content",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Lesson ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "courses"
                 ],
+                "summary": "Get user favorite courses",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseListResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/v1/modules": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/courses/{id}": {
+            "get": {
+                "description": "Get details of a specific course by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -748,68 +896,71 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "modules"
+                    "courses"
                 ],
-                "summary": "Add module to course",
+                "summary": "Get course details",
                 "parameters": [
                     {
-                        "description": "Module data",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateModuleRequest"
-                        }
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateModuleResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseDetailResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/v1/modules/{id}": {
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            },
+            "put": {
+                "description": "Update title, description, cover, etc. (Author only)",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "modules"
+                    "courses"
                 ],
-                "summary": "Update module",
+                "summary": "Update course details",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Module ID",
+                        "description": "Course ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Data",
+                        "description": "Update data",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.UpdateModuleRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.UpdateCourseRequest"
                         }
                     }
                 ],
@@ -820,28 +971,47 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            },
+            "delete": {
+                "description": "Delete course by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "modules"
+                    "courses"
                 ],
-                "summary": "Delete module",
+                "summary": "Delete course",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Module ID",
+                        "description": "Course ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -851,118 +1021,183 @@ const docTemplate = `{
                     "200": {
                         "description": "OK"
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            }
-        },
-        "/v1/modules/{id}/test": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get test structure with questions and answers",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/v1/courses/{id}/favorite": {
+            "post": {
+                "description": "Add or remove course from favorites",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tests"
+                    "courses"
                 ],
-                "summary": "Get test details",
+                "summary": "Toggle course favorite status",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Module ID",
+                        "description": "Course ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/subjects": {
-            "get": {
-                "description": "Get list of available subjects",
+        "/v1/courses/{id}/publish": {
+            "post": {
+                "description": "Change visibility of the course",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subjects"
+                    "courses"
+                ],
+                "summary": "Publish or Unpublish course",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.PublishStatusRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all subjects",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/internal_adapters_http_handlers.GetAllSubjectsResponse"
-                            }
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/tags": {
+        "/v1/courses/{id}/question-bank": {
             "get": {
-                "description": "Get list of available tags",
+                "description": "Questions that module tests draw from through pool rules (course author only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "tests"
+                ],
+                "summary": "Get course question bank",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all tags",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.GetAllTagsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_adapters_http_handlers.BankQuestionResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/v1/tests": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a test (Teacher/Admin only)",
+                ]
+            },
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -972,15 +1207,22 @@ const docTemplate = `{
                 "tags": [
                     "tests"
                 ],
-                "summary": "Create a new test",
+                "summary": "Add question to course bank",
                 "parameters": [
                     {
-                        "description": "Test data",
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestRequest"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.BankQuestionRequest"
                         }
                     }
                 ],
@@ -988,7 +1230,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers.BankQuestionResponse"
                         }
                     },
                     "400": {
@@ -997,187 +1239,3595 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/v1/tests/:id": {
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Update a test (Teacher/Admin only)",
-                "consumes": [
+                ]
+            }
+        },
+        "/v1/courses/{id}/stats": {
+            "get": {
+                "description": "Students, completions, activity and test results of the course. Recomputed hourly; computed_at tells when. Course author or admin only.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tests"
+                    "courses"
                 ],
-                "summary": "Update old test",
+                "summary": "Get course statistics",
                 "parameters": [
                     {
-                        "description": "Test data",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestRequest"
-                        }
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseStatsResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Course not found or statistics not computed yet",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Delete a test (Teacher/Admin only)",
+                ]
+            }
+        },
+        "/v1/courses/{id}/structure": {
+            "get": {
+                "description": "Get modules and lessons for editor",
                 "tags": [
-                    "tests"
+                    "courses"
+                ],
+                "summary": "Get full course structure",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete old test",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.GetStructureResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            }
-        },
-        "/v1/upload": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Upload file to MinIO (avatar, course_cover, lesson_material)",
+                ]
+            }
+        },
+        "/v1/courses/{id}/translations/{locale}": {
+            "put": {
+                "description": "Creates or replaces the course title and description in the given language. An empty description falls back to the original one",
                 "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "upload"
+                    "courses"
                 ],
-                "summary": "Upload a file",
+                "summary": "Save course translation",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "File type (avatar, cover, lesson)",
-                        "name": "type",
-                        "in": "formData",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Translation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseTranslationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.UploadResponse"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Delete course translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/gamification/leagues": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gamification"
+                ],
+                "summary": "Get all available leagues",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeaguesListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/leaderboard/global": {
+            "get": {
+                "description": "Get top players by total XP (all-time)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get global leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of entries (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeaderboardResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/leaderboard/history": {
+            "get": {
+                "description": "Past weeks of the current user, newest first: league, final rank, XP and whether the user was promoted, demoted or stayed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get my league history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of weeks (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeagueHistoryResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/leaderboard/history/{period}": {
+            "get": {
+                "description": "Final standings of the league cohort the current user was in during the given week",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get final table of a past week",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Any date of the week, YYYY-MM-DD",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeagueHistoryTableResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/leaderboard/weekly": {
+            "get": {
+                "description": "Get top players in current user's league cohort for this week. Until the weekly reset runs, shows last week's cohort. Read-only: cohorts are assigned by onboarding and the weekly reset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get weekly league leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of entries (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.LeaderboardResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/lessons": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Add lesson to module",
+                "parameters": [
+                    {
+                        "description": "Lesson data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateLessonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateLessonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/lessons/{id}": {
+            "get": {
+                "description": "Get content, video url and attachments for a specific lesson",
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Get full lesson details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.LessonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Update lesson content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.UpdateLessonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Delete lesson content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/lessons/{id}/translations/{locale}": {
+            "put": {
+                "description": "Creates or replaces the lesson title and text in the given language. Empty content_text falls back to the original text",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Save lesson translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.LessonTranslationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Delete lesson translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/locale": {
+            "put": {
+                "description": "Sets the language of emails and localized content. The preference overrides Accept-Language for access tokens issued after the next /v1/auth/refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Set preferred language",
+                "parameters": [
+                    {
+                        "description": "Locale",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.UpdateLocaleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/modules": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modules"
+                ],
+                "summary": "Add module to course",
+                "parameters": [
+                    {
+                        "description": "Module data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateModuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.CreateModuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/modules/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modules"
+                ],
+                "summary": "Update module",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.UpdateModuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "modules"
+                ],
+                "summary": "Delete module",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/modules/{id}/test": {
+            "get": {
+                "description": "Get test settings. Questions are returned to the course author and admins only; students get them from POST /v1/student/tests/{id}/start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Get test details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.TestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/modules/{id}/test-with-answers": {
+            "get": {
+                "description": "Get test structure with questions and answers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Get test details with correct answer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.TestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/modules/{id}/translations/{locale}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modules"
+                ],
+                "summary": "Save module translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ModuleTranslationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "modules"
+                ],
+                "summary": "Delete module translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Module ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (ru, kk, en)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/question-bank/{id}": {
+            "put": {
+                "description": "Answers are matched by id: known ids are updated, others are added, missing ones are removed",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Update question in course bank",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.BankQuestionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Delete question from course bank",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/achievements": {
+            "get": {
+                "description": "All achievements with the student's progress: earned ones first (newest first), then locked ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gamification"
+                ],
+                "summary": "Get student achievements",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.AchievementsListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/courses/{id}/progress": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get completed lesson IDs for a course",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/dashboard": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get student dashboard info",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/backend_internal_services_student.DashboardData"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/lessons/{id}/complete": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Mark lesson as completed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lesson ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.CompleteLessonResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get basic student info for header",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/backend_internal_services_student.StudentHeaderInfo"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/my-courses": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get all active courses for student",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/internal_adapters_http_handlers.ActiveCourseDTO"
+                                }
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/onboarding": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Create student profile and set interests",
+                "parameters": [
+                    {
+                        "description": "Onboarding data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.OnboardingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/tests/submit": {
+            "post": {
+                "description": "Grades the submission. answer_ids is used for multi-select and ordering questions, answer_id is kept for single choice, text for short answer and numeric, pairs for matching (left answer ID to the match option ID served in the attempt).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Submit test answers",
+                "parameters": [
+                    {
+                        "description": "Answers",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.SubmitTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.SubmitTestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/tests/{id}/attempts": {
+            "get": {
+                "description": "Lists the student's attempts, newest first, with the chosen answers and per-question correctness",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get my attempts for a test",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Test ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_adapters_http_handlers.AttemptResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/tests/{id}/start": {
+            "post": {
+                "description": "Issues an attempt token with a deadline and the questions served in this attempt. Returns the open attempt if there is one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Start a test attempt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Test ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.StartTestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Attempt limit reached",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Cooldown is active, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/timezone": {
+            "put": {
+                "description": "Day boundaries for the streak are computed in this IANA timezone",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Set streak timezone",
+                "parameters": [
+                    {
+                        "description": "Timezone",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.TimezoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/student/xp-history": {
+            "get": {
+                "description": "XP grants of the current student, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Get XP history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.XPHistoryResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/subjects": {
+            "get": {
+                "description": "Get list of available subjects. Names are localized by the Accept-Language header or the user's locale",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subjects"
+                ],
+                "summary": "Get all subjects",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_adapters_http_handlers.GetAllSubjectsResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/v1/tags": {
+            "get": {
+                "description": "Get list of available tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get all tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers_content.GetAllTagsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/v1/teacher/tests/{id}/attempts": {
+            "get": {
+                "description": "Lists attempts for a test in the teacher's own course. Filter by student with student_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Review students' attempts for a test",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Test ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Student ID",
+                        "name": "student_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_adapters_http_handlers.AttemptResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/tests": {
+            "post": {
+                "description": "Create a test (Teacher/Admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Create a new test",
+                "parameters": [
+                    {
+                        "description": "Test data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/tests/:id": {
+            "put": {
+                "description": "Update a test (Teacher/Admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Update old test",
+                "parameters": [
+                    {
+                        "description": "Test data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.CreateTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Delete a test (Teacher/Admin only)",
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Delete old test",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/upload": {
+            "post": {
+                "description": "Upload file to MinIO (avatar, course_cover, lesson_material)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Upload a file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File type (avatar, cover, lesson)",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "backend_internal_entities.LeaderboardHistory": {
+            "type": "object",
+            "properties": {
+                "avatarURL": {
+                    "type": "string"
+                },
+                "cohortID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "leagueID": {
+                    "type": "integer"
+                },
+                "leagueIconURL": {
+                    "type": "string"
+                },
+                "leagueName": {
+                    "description": "Заполняются только при чтении истории.",
+                    "type": "string"
+                },
+                "outcome": {
+                    "$ref": "#/definitions/backend_internal_entities.LeagueOutcome"
+                },
+                "periodEnd": {
+                    "type": "string"
+                },
+                "periodStart": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "toLeagueID": {
+                    "type": "integer"
+                },
+                "totalXP": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "backend_internal_entities.LeagueOutcome": {
+            "type": "string",
+            "enum": [
+                "promoted",
+                "demoted",
+                "stayed"
+            ],
+            "x-enum-varnames": [
+                "LeagueOutcomePromoted",
+                "LeagueOutcomeDemoted",
+                "LeagueOutcomeStayed"
+            ]
+        },
+        "backend_internal_entities.StudentProfile": {
+            "type": "object",
+            "properties": {
+                "avatarURL": {
+                    "type": "string"
+                },
+                "cohortID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currentLeagueID": {
+                    "type": "integer"
+                },
+                "currentStreak": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "grade": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastActivityDate": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "maxStreak": {
+                    "type": "integer"
+                },
+                "streakFreezes": {
+                    "type": "integer"
+                },
+                "timezone": {
+                    "description": "Серия считается по локальным дням в Timezone (IANA-имя).\nLastActivityDate — последний засчитанный в серию день: с активностью\nили покрытый заморозкой. StreakFreezes — накопленные заморозки,\nкаждая автоматически покрывает один пропущенный день.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                },
+                "weeklyXP": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "xp": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "backend_internal_entities.Subject": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "backend_internal_services_student.ActiveCourseData": {
+            "type": "object",
+            "properties": {
+                "completedLessons": {
+                    "type": "integer"
+                },
+                "courseID": {
+                    "type": "string"
+                },
+                "coverURL": {
+                    "type": "string"
+                },
+                "progressPercentage": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "totalLessons": {
+                    "type": "integer"
+                }
+            }
+        },
+        "backend_internal_services_student.DashboardData": {
+            "type": "object",
+            "properties": {
+                "activeCourses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backend_internal_services_student.ActiveCourseData"
+                    }
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backend_internal_entities.Subject"
+                    }
+                },
+                "lastWeek": {
+                    "description": "LastWeek — итог прошедшей недели в лиге; nil, если ученик в ней\nне участвовал.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/backend_internal_entities.LeaderboardHistory"
+                        }
+                    ]
+                },
+                "profile": {
+                    "$ref": "#/definitions/backend_internal_entities.StudentProfile"
+                }
+            }
+        },
+        "backend_internal_services_student.StudentHeaderInfo": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "current_streak": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.AchievementResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "earned": {
+                    "type": "boolean"
+                },
+                "earned_at": {
+                    "type": "string"
+                },
+                "icon_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "xp_reward": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.AchievementsListResponse": {
+            "type": "object",
+            "properties": {
+                "achievements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AchievementResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ActiveCourseDTO": {
+            "type": "object",
+            "properties": {
+                "completed_lessons": {
+                    "type": "integer"
+                },
+                "course_id": {
+                    "type": "string"
+                },
+                "cover_url": {
+                    "type": "string"
+                },
+                "progress_percentage": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "total_lessons": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.AnswerResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "match_text": {
+                    "type": "string"
+                },
+                "order_index": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.AttemptAnswerResponse": {
+            "type": "object",
+            "properties": {
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "pairs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "points": {
+                    "type": "number"
+                },
+                "question_id": {
+                    "type": "string"
+                },
+                "question_text": {
+                    "type": "string"
+                },
+                "question_type": {
+                    "type": "string"
+                },
+                "selected_answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AnswerResponse"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.AttemptResponse": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AttemptAnswerResponse"
+                    }
+                },
+                "attempt_date": {
+                    "type": "string"
+                },
+                "is_passed": {
+                    "type": "boolean"
+                },
+                "result_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.BankQuestionRequest": {
+            "type": "object",
+            "required": [
+                "question_type",
+                "text"
+            ],
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.CreateAnswerRequest"
+                    }
+                },
+                "difficulty": {
+                    "description": "0 — не задана",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0
+                },
+                "id": {
+                    "description": "при обновлении: ID существующего вопроса",
+                    "type": "string"
+                },
+                "numeric_tolerance": {
+                    "type": "number"
+                },
+                "numeric_value": {
+                    "type": "number"
+                },
+                "question_type": {
+                    "description": "single_choice, multiple, short_answer, numeric, ordering, matching",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.BankQuestionResponse": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AnswerResponse"
+                    }
+                },
+                "course_id": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "match_options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AnswerResponse"
+                    }
+                },
+                "numeric_tolerance": {
+                    "type": "number"
+                },
+                "numeric_value": {
+                    "type": "number"
+                },
+                "question_type": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.CompleteLessonResponse": {
+            "type": "object",
+            "properties": {
+                "level_up": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.LevelUpResponse"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "xp_gained": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.CreateAnswerRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "id": {
+                    "description": "при обновлении: ID существующего варианта",
+                    "type": "string"
+                },
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "match_text": {
+                    "description": "matching: правая часть пары",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.CreateQuestionRequest": {
+            "type": "object",
+            "required": [
+                "question_type",
+                "text"
+            ],
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.CreateAnswerRequest"
+                    }
+                },
+                "id": {
+                    "description": "при обновлении: ID существующего вопроса",
+                    "type": "string"
+                },
+                "numeric_tolerance": {
+                    "type": "number"
+                },
+                "numeric_value": {
+                    "type": "number"
+                },
+                "question_type": {
+                    "description": "single_choice, multiple, short_answer, numeric, ordering, matching",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.CreateTestRequest": {
+            "type": "object",
+            "required": [
+                "module_id",
+                "passing_score",
+                "title"
+            ],
+            "properties": {
+                "cooldown_seconds": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_attempts": {
+                    "description": "0 — без ограничения",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "module_id": {
+                    "type": "string"
+                },
+                "passing_score": {
+                    "type": "integer"
+                },
+                "pool_rules": {
+                    "description": "Случайные вопросы из банка курса в дополнение к questions",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.PoolRuleRequest"
+                    }
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.CreateQuestionRequest"
+                    }
+                },
+                "scoring_rules": {
+                    "description": "question_type -\u003e all_or_nothing | partial | negative",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "shuffle_answers": {
+                    "type": "boolean"
+                },
+                "time_limit_seconds": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.CreateTestResponse": {
+            "type": "object",
+            "properties": {
+                "test_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "something went wrong"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.GamificationPolicyPayload": {
+            "type": "object",
+            "required": [
+                "levels",
+                "xp"
+            ],
+            "properties": {
+                "levels": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.LevelCurvePayload"
+                },
+                "streaks": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.StreakRulesPayload"
+                },
+                "xp": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.XPRulesPayload"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.GetAllSubjectsResponse": {
+            "type": "object",
+            "properties": {
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.SubjectResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.JobRunResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "scheduled_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "succeeded",
+                        "failed",
+                        "skipped"
+                    ]
+                }
+            }
+        },
+        "internal_adapters_http_handlers.JobRunsResponse": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.JobRunResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.JobStatusResponse": {
+            "type": "object",
+            "properties": {
+                "last_run": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.JobRunResponse"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.JobsListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.JobStatusResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "league_id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "leaderboard": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeaderboardEntry"
+                    }
+                },
+                "user_rank": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueHistoryDTO": {
+            "type": "object",
+            "properties": {
+                "league_icon_url": {
+                    "type": "string"
+                },
+                "league_id": {
+                    "type": "integer"
+                },
+                "league_name": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "promoted",
+                        "demoted",
+                        "stayed"
+                    ]
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "to_league_id": {
+                    "type": "integer"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueHistoryDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueHistoryTableEntry": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "promoted",
+                        "demoted",
+                        "stayed"
+                    ]
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueHistoryTableResponse": {
+            "type": "object",
+            "properties": {
+                "leaderboard": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueHistoryTableEntry"
+                    }
+                },
+                "league_icon_url": {
+                    "type": "string"
+                },
+                "league_id": {
+                    "type": "integer"
+                },
+                "league_name": {
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueMoveResponse": {
+            "type": "object",
+            "properties": {
+                "cohort_id": {
+                    "type": "string"
+                },
+                "from_league_id": {
+                    "type": "integer"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "to_league_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "weekly_xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueResetPlanResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer"
+                },
+                "cohorts": {
+                    "type": "integer"
+                },
+                "demoted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueMoveResponse"
+                    }
+                },
+                "inactive": {
+                    "type": "integer"
+                },
+                "league_id": {
+                    "type": "integer"
+                },
+                "promoted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueMoveResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueResponse": {
+            "type": "object",
+            "properties": {
+                "demote_inactive": {
+                    "type": "boolean"
+                },
+                "demote_percent": {
+                    "type": "integer"
+                },
+                "icon_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order_index": {
+                    "type": "integer"
+                },
+                "promote_percent": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeagueZonesRequest": {
+            "type": "object",
+            "required": [
+                "demote_inactive",
+                "demote_percent",
+                "promote_percent"
+            ],
+            "properties": {
+                "demote_inactive": {
+                    "type": "boolean"
+                },
+                "demote_percent": {
+                    "type": "integer"
+                },
+                "promote_percent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LeaguesListResponse": {
+            "type": "object",
+            "properties": {
+                "leagues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueResponse"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LevelCurvePayload": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "linear",
+                        "quadratic",
+                        "table"
+                    ]
+                },
+                "thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "xp_per_level": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LevelUpResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "student@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "internal_adapters_http_handlers.OnboardingRequest": {
+            "type": "object",
+            "required": [
+                "grade",
+                "subject_ids"
+            ],
+            "properties": {
+                "grade": {
+                    "type": "integer",
+                    "maximum": 11,
+                    "minimum": 1
+                },
+                "subject_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timezone": {
+                    "description": "Timezone — IANA-имя, например \"Asia/Almaty\"; по умолчанию Asia/Almaty.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.PoolRuleRequest": {
+            "type": "object",
+            "required": [
+                "count"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "difficulty": {
+                    "description": "0 — любая",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.QuestionResponse": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AnswerResponse"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "match_options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.AnswerResponse"
+                    }
+                },
+                "numeric_tolerance": {
+                    "type": "number"
+                },
+                "numeric_value": {
+                    "type": "number"
+                },
+                "question_type": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.QuestionResultResponse": {
+            "type": "object",
+            "properties": {
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "points": {
+                    "type": "number"
+                },
+                "question_id": {
+                    "type": "string"
+                },
+                "selected_answer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_adapters_http_handlers.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ResendVerificationRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "email",
+                "new_password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "internal_adapters_http_handlers.StartTestResponse": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "string"
+                },
+                "attempts_used": {
+                    "type": "integer"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "test": {
+                    "description": "Вопросы этой попытки; для тестов с банком вопросов отличаются от попытки к попытке",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_adapters_http_handlers.TestResponse"
+                        }
+                    ]
+                },
+                "time_limit_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.StreakMultiplierPayload": {
+            "type": "object",
+            "properties": {
+                "min_days": {
+                    "type": "integer"
+                },
+                "multiplier": {
+                    "type": "number"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.StreakRulesPayload": {
+            "type": "object",
+            "properties": {
+                "freeze_every_days": {
+                    "type": "integer"
+                },
+                "max_freezes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.SubjectResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.SubmitAnswerRequest": {
+            "type": "object",
+            "properties": {
+                "answer_id": {
+                    "type": "string"
+                },
+                "answer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pairs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "question_id": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.SubmitTestRequest": {
+            "type": "object",
+            "required": [
+                "attempt_id",
+                "test_id"
+            ],
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.SubmitAnswerRequest"
+                    }
+                },
+                "attempt_id": {
+                    "description": "из /student/tests/{id}/start",
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.SubmitTestResponse": {
+            "type": "object",
+            "properties": {
+                "is_passed": {
+                    "type": "boolean"
+                },
+                "level_up": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers.LevelUpResponse"
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.QuestionResultResponse"
+                    }
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "xp_gained": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.TestResponse": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "type": "integer"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "module_id": {
+                    "type": "string"
+                },
+                "passing_score": {
+                    "type": "integer"
+                },
+                "pool_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.PoolRuleRequest"
+                    }
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.QuestionResponse"
+                    }
+                },
+                "scoring_rules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "shuffle_answers": {
+                    "type": "boolean"
+                },
+                "test_id": {
+                    "type": "string"
+                },
+                "time_limit_seconds": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.TimezoneRequest": {
+            "type": "object",
+            "required": [
+                "timezone"
+            ],
+            "properties": {
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.UpdateLocaleRequest": {
+            "type": "object",
+            "required": [
+                "locale"
+            ],
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "ru",
+                        "kk",
+                        "en"
+                    ]
+                }
+            }
+        },
+        "internal_adapters_http_handlers.UploadResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.WeeklyResetPlanResponse": {
+            "type": "object",
+            "properties": {
+                "due": {
+                    "type": "boolean"
+                },
+                "last_reset": {
+                    "type": "string"
+                },
+                "leagues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.LeagueResetPlanResponse"
+                    }
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "week_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.XPHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.XPTransactionResponse"
                     }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "internal_adapters_http_handlers.ChangePasswordRequest": {
+        },
+        "internal_adapters_http_handlers.XPRulesPayload": {
             "type": "object",
-            "required": [
-                "new_password",
-                "old_password"
-            ],
             "properties": {
-                "new_password": {
-                    "type": "string",
-                    "minLength": 8
+                "difficulty_multipliers": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
                 },
-                "old_password": {
+                "first_try_bonus_percent": {
+                    "type": "integer"
+                },
+                "lesson_default_xp": {
+                    "type": "integer"
+                },
+                "score_proportional": {
+                    "type": "boolean"
+                },
+                "streak_multipliers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_adapters_http_handlers.StreakMultiplierPayload"
+                    }
+                },
+                "test_max_xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers.XPTransactionResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "source_id": {
+                    "type": "string"
+                },
+                "source_title": {
+                    "type": "string"
+                },
+                "source_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_adapters_http_handlers_content.AuthorResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
                 }
             }
         },
-        "internal_adapters_http_handlers.CourseDetailResponse": {
+        "internal_adapters_http_handlers_content.CourseDetailResponse": {
             "type": "object",
             "properties": {
+                "author": {
+                    "$ref": "#/definitions/internal_adapters_http_handlers_content.AuthorResponse"
+                },
                 "author_id": {
                     "type": "string"
                 },
@@ -1193,6 +4843,9 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "is_favorite": {
+                    "type": "boolean"
+                },
                 "is_published": {
                     "type": "boolean"
                 },
@@ -1202,40 +4855,81 @@ const docTemplate = `{
                 "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.TagResponse"
+                        "$ref": "#/definitions/internal_adapters_http_handlers_content.TagResponse"
                     }
                 },
                 "title": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "internal_adapters_http_handlers.CourseListResponse": {
+        "internal_adapters_http_handlers_content.CourseListResponse": {
             "type": "object",
             "properties": {
                 "courses": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.CourseDetailResponse"
+                        "$ref": "#/definitions/internal_adapters_http_handlers_content.CourseDetailResponse"
                     }
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateAnswerRequest": {
+        "internal_adapters_http_handlers_content.CourseStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_students_7d": {
+                    "type": "integer"
+                },
+                "avg_progress": {
+                    "type": "integer"
+                },
+                "avg_test_score": {
+                    "type": "integer"
+                },
+                "completions": {
+                    "type": "integer"
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "course_id": {
+                    "type": "string"
+                },
+                "favorites": {
+                    "type": "integer"
+                },
+                "students": {
+                    "type": "integer"
+                },
+                "test_attempts": {
+                    "type": "integer"
+                },
+                "test_pass_rate": {
+                    "type": "integer"
+                },
+                "views_30d": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_adapters_http_handlers_content.CourseTranslationRequest": {
             "type": "object",
             "required": [
-                "text"
+                "title"
             ],
             "properties": {
-                "is_correct": {
-                    "type": "boolean"
+                "description": {
+                    "type": "string"
                 },
-                "text": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateCourseRequest": {
+        "internal_adapters_http_handlers_content.CreateCourseRequest": {
             "type": "object",
             "required": [
                 "difficulty_level",
@@ -1268,7 +4962,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateCourseResponse": {
+        "internal_adapters_http_handlers_content.CreateCourseResponse": {
             "type": "object",
             "properties": {
                 "id": {
@@ -1276,7 +4970,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateLessonRequest": {
+        "internal_adapters_http_handlers_content.CreateLessonRequest": {
             "type": "object",
             "required": [
                 "module_id",
@@ -1303,11 +4997,13 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "xp_reward": {
-                    "type": "integer"
+                    "description": "XPReward — награда за урок; без поля или null — награда по умолчанию.",
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateLessonResponse": {
+        "internal_adapters_http_handlers_content.CreateLessonResponse": {
             "type": "object",
             "required": [
                 "lesson_id"
@@ -1318,7 +5014,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateModuleRequest": {
+        "internal_adapters_http_handlers_content.CreateModuleRequest": {
             "type": "object",
             "required": [
                 "course_id",
@@ -1337,7 +5033,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateModuleResponse": {
+        "internal_adapters_http_handlers_content.CreateModuleResponse": {
             "type": "object",
             "required": [
                 "module_id"
@@ -1348,66 +5044,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.CreateQuestionRequest": {
-            "type": "object",
-            "required": [
-                "answers",
-                "question_type",
-                "text"
-            ],
-            "properties": {
-                "answers": {
-                    "type": "array",
-                    "minItems": 2,
-                    "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.CreateAnswerRequest"
-                    }
-                },
-                "question_type": {
-                    "description": "single_choice",
-                    "type": "string"
-                },
-                "text": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.CreateTestRequest": {
-            "type": "object",
-            "required": [
-                "module_id",
-                "passing_score",
-                "questions",
-                "title"
-            ],
-            "properties": {
-                "module_id": {
-                    "type": "string"
-                },
-                "passing_score": {
-                    "type": "integer"
-                },
-                "questions": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.CreateQuestionRequest"
-                    }
-                },
-                "title": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.CreateTestResponse": {
-            "type": "object",
-            "properties": {
-                "test_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.ErrorResponse": {
+        "internal_adapters_http_handlers_content.ErrorResponse": {
             "type": "object",
             "properties": {
                 "message": {
@@ -1416,40 +5053,29 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.GetAllSubjectsResponse": {
-            "type": "object",
-            "properties": {
-                "subjects": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.SubjectResponse"
-                    }
-                }
-            }
-        },
-        "internal_adapters_http_handlers.GetAllTagsResponse": {
+        "internal_adapters_http_handlers_content.GetAllTagsResponse": {
             "type": "object",
             "properties": {
                 "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.TagResponse"
+                        "$ref": "#/definitions/internal_adapters_http_handlers_content.TagResponse"
                     }
                 }
             }
         },
-        "internal_adapters_http_handlers.GetStructureResponse": {
+        "internal_adapters_http_handlers_content.GetStructureResponse": {
             "type": "object",
             "properties": {
                 "modules": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.ModuleResponse"
+                        "$ref": "#/definitions/internal_adapters_http_handlers_content.ModuleResponse"
                     }
                 }
             }
         },
-        "internal_adapters_http_handlers.LessonResponse": {
+        "internal_adapters_http_handlers_content.LessonResponse": {
             "type": "object",
             "properties": {
                 "content_text": {
@@ -1474,37 +5100,26 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "xp_reward": {
+                    "description": "XPReward — null, если у урока награда по умолчанию.",
                     "type": "integer"
                 }
             }
         },
-        "internal_adapters_http_handlers.LoginRequest": {
+        "internal_adapters_http_handlers_content.LessonTranslationRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "title"
             ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "student@example.com"
+                "content_text": {
+                    "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "secret123"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "internal_adapters_http_handlers.ModuleResponse": {
+        "internal_adapters_http_handlers_content.ModuleResponse": {
             "type": "object",
             "properties": {
                 "course_id": {
@@ -1516,7 +5131,7 @@ const docTemplate = `{
                 "lessons": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_adapters_http_handlers.LessonResponse"
+                        "$ref": "#/definitions/internal_adapters_http_handlers_content.LessonResponse"
                     }
                 },
                 "order_index": {
@@ -1527,72 +5142,26 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.PublishStatusRequest": {
-            "type": "object",
-            "properties": {
-                "is_published": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.RegisterResponse": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "full_name": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "role": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_adapters_http_handlers.ResetPasswordRequest": {
+        "internal_adapters_http_handlers_content.ModuleTranslationRequest": {
             "type": "object",
             "required": [
-                "email",
-                "new_password",
-                "old_password"
+                "title"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "new_password": {
-                    "type": "string",
-                    "minLength": 8
-                },
-                "old_password": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "internal_adapters_http_handlers.SubjectResponse": {
+        "internal_adapters_http_handlers_content.PublishStatusRequest": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "name_kz": {
-                    "type": "string"
-                },
-                "name_ru": {
-                    "type": "string"
-                },
-                "slug": {
-                    "type": "string"
+                "is_published": {
+                    "type": "boolean"
                 }
             }
         },
-        "internal_adapters_http_handlers.TagResponse": {
+        "internal_adapters_http_handlers_content.TagResponse": {
             "type": "object",
             "properties": {
                 "id": {
@@ -1606,7 +5175,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.UpdateCourseRequest": {
+        "internal_adapters_http_handlers_content.UpdateCourseRequest": {
             "type": "object",
             "properties": {
                 "cover_image_url": {
@@ -1632,7 +5201,7 @@ const docTemplate = `{
                 }
             }
         },
-        "internal_adapters_http_handlers.UpdateLessonRequest": {
+        "internal_adapters_http_handlers_content.UpdateLessonRequest": {
             "type": "object",
             "properties": {
                 "content_text": {
@@ -1651,11 +5220,13 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "xp_reward": {
-                    "type": "integer"
+                    "description": "XPReward — награда за урок; без поля или null — награда по умолчанию.",
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "internal_adapters_http_handlers.UpdateModuleRequest": {
+        "internal_adapters_http_handlers_content.UpdateModuleRequest": {
             "type": "object",
             "properties": {
                 "order_index": {
@@ -1665,14 +5236,6 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
-        },
-        "internal_adapters_http_handlers.UploadResponse": {
-            "type": "object",
-            "properties": {
-                "url": {
-                    "type": "string"
-                }
-            }
         }
     },
     "securityDefinitions": {
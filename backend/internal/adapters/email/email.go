@@ -1,15 +1,15 @@
 package email
 
 import (
+	"context"
 	"fmt"
 
+	"backend/internal/entities"
+
 	"gopkg.in/gomail.v2"
 )
 
-type EmailService interface {
-	SendResetCode(toEmail, code string) error
-}
-
+// GomailService отправляет готовые письма по SMTP.
 type GomailService struct {
 	dialer *gomail.Dialer
 	from   string
@@ -20,20 +20,15 @@ func NewGomailService(host string, port int, user, password, from string) *Gomai
 	return &GomailService{dialer: d, from: from}
 }
 
-func (s *GomailService) SendResetCode(toEmail, code string) error {
+// Send отправляет письмо с текстовой и HTML-версией.
+func (s *GomailService) Send(_ context.Context, msg entities.EmailMessage) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.from)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Сброс пароля - School With AI")
-
-	htmlBody := fmt.Sprintf(`
-		<h1>Сброс пароля</h1>
-		<p>Ваш код для сброса пароля: <b>%s</b></p>
-		<p>Код действителен 15 минут.</p>
-		<p>Если вы не запрашивали сброс, проигнорируйте это письмо.</p>
-	`, code)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
 
-	m.SetBody("text/html", htmlBody)
+	m.SetBody("text/plain", msg.Text)
+	m.AddAlternative("text/html", msg.HTML)
 
 	if err := s.dialer.DialAndSend(m); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
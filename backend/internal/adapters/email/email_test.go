@@ -0,0 +1,142 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+// smtpStandIn — минимальный SMTP-сервер: принимает письма без TLS и
+// авторизации и запоминает конверт и данные. rejectRcpt заставляет его
+// отклонять получателя.
+type smtpStandIn struct {
+	ln         net.Listener
+	rejectRcpt bool
+
+	mu   sync.Mutex
+	rcpt []string
+	data []string
+}
+
+func startSMTPStandIn(t *testing.T, rejectRcpt bool) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{ln: ln, rejectRcpt: rejectRcpt}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) addr() (string, int) {
+	a := s.ln.Addr().(*net.TCPAddr)
+	return a.IP.String(), a.Port
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 stand-in ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 stand-in")
+		case "MAIL":
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			if s.rejectRcpt {
+				_ = tp.PrintfLine("550 mailbox unavailable")
+				continue
+			}
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, line)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			body, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = append(s.data, string(body))
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+func TestGomailServiceSend(t *testing.T) {
+	srv := startSMTPStandIn(t, false)
+	host, port := srv.addr()
+	svc := NewGomailService(host, port, "", "", "noreply@example.com")
+
+	msg := entities.EmailMessage{
+		To:      "student@example.com",
+		Subject: "Reset code",
+		Text:    "Your code: 123456",
+		HTML:    "<p>Your code: <b>123456</b></p>",
+	}
+	if err := svc.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.rcpt) != 1 || !strings.Contains(srv.rcpt[0], "student@example.com") {
+		t.Fatalf("rcpt = %v", srv.rcpt)
+	}
+	if len(srv.data) != 1 {
+		t.Fatalf("got %d messages, want 1", len(srv.data))
+	}
+	data := srv.data[0]
+	for _, want := range []string{
+		"From: noreply@example.com",
+		"Subject: Reset code",
+		"text/plain",
+		"text/html",
+		"123456",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("message has no %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestGomailServiceSendRejected(t *testing.T) {
+	srv := startSMTPStandIn(t, true)
+	host, port := srv.addr()
+	svc := NewGomailService(host, port, "", "", "noreply@example.com")
+
+	err := svc.Send(context.Background(), entities.EmailMessage{To: "nobody@example.com", Subject: "x", Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Send error = %v, want the 550 reply", err)
+	}
+}
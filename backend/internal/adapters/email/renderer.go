@@ -0,0 +1,89 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"backend/internal/entities"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+type localizedTemplate struct {
+	text *texttemplate.Template // блоки subject и body
+	html *htmltemplate.Template
+}
+
+// Renderer собирает письма из шаблонов templates/{locale}/{name}.txt и .html.
+// Текстовый шаблон задаёт блоки "subject" и "body", HTML — тело письма.
+type Renderer struct {
+	templates map[entities.Locale]map[string]localizedTemplate
+}
+
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{templates: make(map[entities.Locale]map[string]localizedTemplate)}
+
+	for _, locale := range entities.SupportedLocales {
+		files, err := fs.Glob(templatesFS, path.Join("templates", string(locale), "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		r.templates[locale] = make(map[string]localizedTemplate, len(files))
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+
+			text, err := texttemplate.ParseFS(templatesFS, file)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", file, err)
+			}
+
+			htmlFile := strings.TrimSuffix(file, ".txt") + ".html"
+			html, err := htmltemplate.ParseFS(templatesFS, htmlFile)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", htmlFile, err)
+			}
+
+			r.templates[locale][name] = localizedTemplate{text: text, html: html}
+		}
+	}
+
+	return r, nil
+}
+
+// Render собирает письмо name на языке locale; если перевода нет,
+// используется entities.DefaultLocale.
+func (r *Renderer) Render(name string, locale entities.Locale, to string, data any) (entities.EmailMessage, error) {
+	tmpl, ok := r.templates[locale][name]
+	if !ok {
+		tmpl, ok = r.templates[entities.DefaultLocale][name]
+		if !ok {
+			return entities.EmailMessage{}, fmt.Errorf("unknown email template %q", name)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return entities.EmailMessage{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return entities.EmailMessage{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return entities.EmailMessage{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+
+	return entities.EmailMessage{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
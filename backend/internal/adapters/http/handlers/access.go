@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/entities"
+	"backend/internal/services/authz"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// actorFrom собирает пользователя запроса из claims, положенных AuthMiddleware.
+func actorFrom(c *gin.Context) authz.Actor {
+	return authz.NewActor(c.GetString("user_id"), c.GetString("role"))
+}
+
+// writeAccessError отвечает 404/403 на ошибки проверки доступа к контенту
+// и 500 с msg на всё остальное.
+func writeAccessError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, entities.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "not found"})
+	case errors.Is(err, entities.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Message: "you are not the author of this course"})
+	default:
+		log.Error().Err(err).Msg(msg)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: msg})
+	}
+}
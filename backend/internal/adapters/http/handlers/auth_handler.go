@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"errors"
+	"math"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"time"
 
 	"backend/internal/entities"
+	"backend/internal/services/auth"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -18,10 +22,15 @@ type ErrorResponse struct {
 }
 type AuthService interface {
 	Register(ctx context.Context, user *entities.User, password string, avatar *multipart.FileHeader) error
-	Login(ctx context.Context, email, password string) (string, error)
-	ForgotPassword(ctx context.Context, email string) error
+	Login(ctx context.Context, email, password string) (*auth.Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*auth.Tokens, error)
+	Logout(ctx context.Context, sessionID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
+	ForgotPassword(ctx context.Context, email, ip string) error
 	ChangePassword(ctx context.Context, userID string, oldPassword, newPassword string) error
-	ResetPassword(ctx context.Context, email, code, newPassword string) error
+	ResetPassword(ctx context.Context, email, code, newPassword, ip string) error
+	UpdateLocale(ctx context.Context, userID string, locale entities.Locale) error
 }
 
 type AuthHandler struct {
@@ -38,6 +47,7 @@ type RegisterRequest struct {
 	Role      string                `form:"role"       binding:"required,oneof=student teacher"`
 	FirstName string                `form:"first_name" binding:"required"`
 	LastName  string                `form:"last_name"  binding:"required"`
+	Locale    string                `form:"locale"     binding:"omitempty,oneof=ru kk en"`
 	Avatar    *multipart.FileHeader `form:"avatar"`
 }
 
@@ -61,6 +71,7 @@ type RegisterResponse struct {
 // @Param role formData string true "User Role (student or teacher)"
 // @Param first_name formData string true "First Name"
 // @Param last_name formData string true "Last Name"
+// @Param locale formData string false "Preferred language (ru, kk or en), negotiated from Accept-Language by default"
 // @Success 201 {object} RegisterResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 409 {object} ErrorResponse "User already exists"
@@ -81,6 +92,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
 		return
 	}
+	// Без явного выбора берём язык, согласованный по Accept-Language
+	locale := req.Locale
+	if locale == "" {
+		locale = c.GetString("locale")
+	}
+	user.Locale = entities.ParseLocale(locale)
 
 	err = h.authService.Register(c.Request.Context(), user, req.Password, file)
 	if err != nil {
@@ -113,8 +130,22 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"       example:"secret123"`
 }
 
+// LoginResponse: token — короткоживущий access-токен, refresh_token меняется
+// на новую пару через /v1/auth/refresh и после этого становится недействительным.
 type LoginResponse struct {
-	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Token            string    `json:"token"              example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+func newLoginResponse(t *auth.Tokens) LoginResponse {
+	return LoginResponse{
+		Token:            t.AccessToken,
+		ExpiresAt:        t.AccessExpiresAt,
+		RefreshToken:     t.RefreshToken,
+		RefreshExpiresAt: t.RefreshExpiresAt,
+	}
 }
 
 // Login godoc
@@ -126,6 +157,7 @@ type LoginResponse struct {
 // @Param input body LoginRequest true "Login credentials"
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Email is not verified"
 // @Failure 500 {object} ErrorResponse
 // @Router /v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -135,19 +167,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	tokens, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		if errors.Is(err, entities.ErrInvalidCredentials) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, entities.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Message: err.Error()})
+			return
 		}
 		log.Error().Err(err).Str("email", req.Email).Msg("login user failed")
 		c.Status(http.StatusInternalServerError)
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{
-		Token: token,
-	})
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
 
 	log.Info().Str("email", req.Email).Msg("user logged in successfully")
 }
@@ -164,6 +199,8 @@ type ForgotPasswordRequest struct {
 // @Produce json
 // @Param input body ForgotPasswordRequest true "Email"
 // @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Router /v1/auth/forgot-password [post]
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req ForgotPasswordRequest
@@ -172,12 +209,15 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
-		c.JSON(http.StatusOK, gin.H{"message": "If user exists, code sent"})
-		return
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		if writeRateLimitError(c, err) {
+			return
+		}
+		// Ответ не должен отличаться от успешного, иначе по нему видно, есть ли аккаунт
+		log.Error().Err(err).Msg("failed to send reset code")
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Code sent"})
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a code has been sent"})
 }
 
 type ResetPasswordRequest struct {
@@ -188,13 +228,15 @@ type ResetPasswordRequest struct {
 
 // ResetPassword godoc
 // @Summary Reset password with code
-// @Description Change password using email and the code received via email
+// @Description Change password using email and the code received via email. All sessions are revoked.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body ResetPasswordRequest true "Reset payload"
 // @Success 200
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /v1/auth/reset-password [post]
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req ResetPasswordRequest
@@ -203,9 +245,18 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ResetPassword(c.Request.Context(), req.Email, req.Code, req.NewPassword)
+	err := h.authService.ResetPassword(c.Request.Context(), req.Email, req.Code, req.NewPassword, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		if writeRateLimitError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, entities.ErrInvalidResetCode), errors.Is(err, entities.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		default:
+			log.Error().Err(err).Msg("failed to reset password")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "internal server error"})
+		}
 		return
 	}
 
@@ -219,7 +270,7 @@ type ChangePasswordRequest struct {
 
 // ChangePassword godoc
 // @Summary Change user password
-// @Description Change password for the currently authenticated user
+// @Description Change password for the currently authenticated user. All sessions, including the current one, are revoked.
 // @Tags auth
 // @Security BearerAuth
 // @Accept json
@@ -252,7 +303,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	err := h.authService.ChangePassword(c.Request.Context(), uidStr, req.OldPassword, req.NewPassword)
 	if err != nil {
-		if errors.Is(err, entities.ErrInvalidCredentials) {
+		if errors.Is(err, entities.ErrInvalidCredentials) || errors.Is(err, entities.ErrWeakPassword) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
 			return
 		}
@@ -265,3 +316,177 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new token pair. The old refresh token stops working; presenting it again revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid json"})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrRefreshTokenReused):
+			log.Warn().Msg("refresh token reuse detected, session revoked")
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Message: err.Error()})
+		case errors.Is(err, entities.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Message: err.Error()})
+		default:
+			log.Error().Err(err).Msg("failed to refresh token")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revokes the current session: its refresh token and access tokens stop working immediately
+// @Tags auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.authService.Logout(c.Request.Context(), c.GetString("session_id")); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to logout")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	log.Info().Str("user_id", userID).Msg("user logged out")
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Confirms the email with the token from the verification link. Write access opens immediately, even with tokens issued before; the email_verified claim updates on the next /v1/auth/refresh.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body VerifyEmailRequest true "Verification token"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, entities.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("failed to verify email")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Sends a new verification link if the account exists and is not verified yet
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body ResendVerificationRequest true "Email"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /v1/auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := h.authService.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		log.Error().Err(err).Msg("failed to resend verification email")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account needs verification, a new link has been sent"})
+}
+
+// writeRateLimitError отвечает 429 с Retry-After, если err — превышение лимита.
+func writeRateLimitError(c *gin.Context, err error) bool {
+	var limitErr *entities.RateLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{Message: "too many requests, try again later"})
+	return true
+}
+
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" binding:"required,oneof=ru kk en"`
+}
+
+// UpdateLocale godoc
+// @Summary Set preferred language
+// @Description Sets the language of emails and localized content. The preference overrides Accept-Language for access tokens issued after the next /v1/auth/refresh.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Param input body UpdateLocaleRequest true "Locale"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/me/locale [put]
+func (h *AuthHandler) UpdateLocale(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req UpdateLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := h.authService.UpdateLocale(c.Request.Context(), userID, entities.Locale(req.Locale)); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to update locale")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	log.Info().Str("user_id", userID).Str("locale", req.Locale).Msg("user locale updated")
+}
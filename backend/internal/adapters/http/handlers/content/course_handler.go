@@ -2,9 +2,13 @@ package content
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"backend/internal/entities"
+	"backend/internal/services/authz"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -12,22 +16,23 @@ import (
 
 type CourseService interface {
 	CreateCourse(ctx context.Context, course *entities.Course) error
-	GetUserCourse(ctx context.Context, courseID, userID string) (*entities.Course, error)
 	GetCourseByID(ctx context.Context, courseID string) (*entities.Course, error)
-	UpdateCourse(ctx context.Context, courseID string, updates *entities.Course) error
-	ChangePublishStatus(ctx context.Context, courseID string, isPublished bool) error
+	UpdateCourse(ctx context.Context, actor authz.Actor, courseID string, updates *entities.Course) error
+	ChangePublishStatus(ctx context.Context, actor authz.Actor, courseID string, isPublished bool) error
 	GetCoursesByAuthor(ctx context.Context, authorID string) ([]entities.Course, error)
-	DeleteCourse(ctx context.Context, id string) error
-	GetCatalog(ctx context.Context) ([]entities.Course, error)
+	DeleteCourse(ctx context.Context, actor authz.Actor, id string) error
+	GetCatalog(ctx context.Context, query string) ([]entities.Course, error)
+	GetCourseStats(ctx context.Context, actor authz.Actor, courseID string) (*entities.CourseStats, error)
 
-	CreateModule(ctx context.Context, userID string, module *entities.Module) error
-	UpdateModule(ctx context.Context, userID string, module *entities.Module) error
-	DeleteModule(ctx context.Context, userID, moduleID string) error
+	CreateModule(ctx context.Context, actor authz.Actor, module *entities.Module) error
+	GetModuleByID(ctx context.Context, moduleID string) (*entities.Module, error)
+	UpdateModule(ctx context.Context, actor authz.Actor, module *entities.Module) error
+	DeleteModule(ctx context.Context, actor authz.Actor, moduleID string) error
 
-	CreateLesson(ctx context.Context, userID string, lesson *entities.Lesson) error
+	CreateLesson(ctx context.Context, actor authz.Actor, lesson *entities.Lesson) error
 	GetLessonByID(ctx context.Context, lessonID string) (*entities.Lesson, error)
-	UpdateLesson(ctx context.Context, userID string, lesson *entities.Lesson) error
-	DeleteLesson(ctx context.Context, userID, lessonID string) error
+	UpdateLesson(ctx context.Context, actor authz.Actor, lesson *entities.Lesson) error
+	DeleteLesson(ctx context.Context, actor authz.Actor, lessonID string) error
 
 	GetFullStructure(ctx context.Context, courseID string) ([]entities.Module, error)
 
@@ -38,18 +43,47 @@ type CourseService interface {
 	IsCourseFavorite(ctx context.Context, userID, courseID string) (bool, error)
 
 	GetRecommendations(ctx context.Context, userID string) ([]entities.Course, error)
+
+	SaveCourseTranslation(ctx context.Context, actor authz.Actor, t *entities.CourseTranslation) error
+	DeleteCourseTranslation(ctx context.Context, actor authz.Actor, courseID string, locale entities.Locale) error
+	SaveModuleTranslation(ctx context.Context, actor authz.Actor, t *entities.ModuleTranslation) error
+	DeleteModuleTranslation(ctx context.Context, actor authz.Actor, moduleID string, locale entities.Locale) error
+	SaveLessonTranslation(ctx context.Context, actor authz.Actor, t *entities.LessonTranslation) error
+	DeleteLessonTranslation(ctx context.Context, actor authz.Actor, lessonID string, locale entities.Locale) error
 }
 
 type ErrorResponse struct {
 	Message string `json:"message" example:"something went wrong"`
 }
 
+// actorFrom собирает пользователя запроса из claims, положенных AuthMiddleware.
+func actorFrom(c *gin.Context) authz.Actor {
+	return authz.NewActor(c.GetString("user_id"), c.GetString("role"))
+}
+
+// writeContentError отвечает 403/404 на ошибки проверки доступа и 500 на всё остальное.
+func writeContentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entities.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Message: "access denied"})
+	case errors.Is(err, entities.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "not found"})
+	default:
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+type ActivityTracker interface {
+	Track(userID string, courseID *string, action string, meta map[string]any)
+}
+
 type CourseHandler struct {
 	courseService CourseService
+	tracker       ActivityTracker
 }
 
-func NewCourseHandler(service CourseService) *CourseHandler {
-	return &CourseHandler{courseService: service}
+func NewCourseHandler(service CourseService, tracker ActivityTracker) *CourseHandler {
+	return &CourseHandler{courseService: service, tracker: tracker}
 }
 
 type CreateCourseRequest struct {
@@ -76,23 +110,11 @@ type CreateCourseResponse struct {
 // @Success 201 {object} CreateCourseResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/courses [post]
 func (h *CourseHandler) CreateCourse(c *gin.Context) {
-	role, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
-
-	if role != "teacher" && role != "admin" {
-		log.Error().Msg("only teachers can create courses")
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: "only teachers can create courses"})
-		return
-	}
-
-	userID, _ := c.Get("user_id")
+	userID := c.GetString("user_id")
 
 	var req CreateCourseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -101,9 +123,9 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := entities.NewCourse(userID.(string), req.SubjectID, req.Title, req.DifficultyLevel)
+	course, err := entities.NewCourse(userID, req.SubjectID, req.Title, req.DifficultyLevel)
 	if err != nil {
-		log.Error().Err(err).Str("user_id", userID.(string)).Msg("failed to create new course entity")
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to create new course entity")
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -123,7 +145,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, CreateCourseResponse{ID: course.ID})
 	log.Info().
-		Str("user_id", userID.(string)).
+		Str("user_id", userID).
 		Str("course_id", course.ID).
 		Str("title", course.Title).
 		Int("difficulty_level", course.DifficultyLevel).
@@ -134,16 +156,26 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 // @Summary Get all published courses
 // @Tags courses
 // @Produce json
+// @Param q query string false "Search by title or description"
 // @Success 200 {object} CourseListResponse
 // @Router /v1/catalog [get]
 func (h *CourseHandler) GetCatalog(c *gin.Context) {
-	courses, err := h.courseService.GetCatalog(c.Request.Context())
+	query := strings.TrimSpace(c.Query("q"))
+
+	courses, err := h.courseService.GetCatalog(c.Request.Context(), query)
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
 		log.Error().Err(err).Msg("failed to get catalog")
 		return
 	}
 
+	if userID := c.GetString("user_id"); userID != "" && query != "" {
+		h.tracker.Track(userID, nil, entities.ActionSearch, map[string]any{
+			"query":   query,
+			"results": len(courses),
+		})
+	}
+
 	respCourses := make([]CourseDetailResponse, 0, len(courses))
 	for _, course := range courses {
 		tagsResp := make([]TagResponse, 0, len(course.Tags))
@@ -255,6 +287,10 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, resp)
+
+	if userID != "" {
+		h.tracker.Track(userID, &course.ID, entities.ActionCourseView, nil)
+	}
 }
 
 // GetStructure godoc
@@ -350,6 +386,58 @@ func (h *CourseHandler) GetMyCourses(c *gin.Context) {
 	log.Info().Str("author_id", userID).Msg("author courses got successfully")
 }
 
+type CourseStatsResponse struct {
+	CourseID         string    `json:"course_id"`
+	Students         int       `json:"students"`
+	Completions      int       `json:"completions"`
+	AvgProgress      int       `json:"avg_progress"`
+	ActiveStudents7d int       `json:"active_students_7d"`
+	Views30d         int       `json:"views_30d"`
+	Favorites        int       `json:"favorites"`
+	TestAttempts     int       `json:"test_attempts"`
+	AvgTestScore     *int      `json:"avg_test_score"`
+	TestPassRate     *int      `json:"test_pass_rate"`
+	ComputedAt       time.Time `json:"computed_at"`
+}
+
+// GetCourseStats godoc
+// @Summary Get course statistics
+// @Description Students, completions, activity and test results of the course. Recomputed hourly; computed_at tells when. Course author or admin only.
+// @Tags courses
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} CourseStatsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse "Course not found or statistics not computed yet"
+// @Failure 500
+// @Router /v1/courses/{id}/stats [get]
+func (h *CourseHandler) GetCourseStats(c *gin.Context) {
+	courseID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	stats, err := h.courseService.GetCourseStats(c.Request.Context(), actorFrom(c), courseID)
+	if err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("user_id", userID).Str("course_id", courseID).Msg("failed to get course stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, CourseStatsResponse{
+		CourseID:         stats.CourseID,
+		Students:         stats.Students,
+		Completions:      stats.Completions,
+		AvgProgress:      stats.AvgProgress,
+		ActiveStudents7d: stats.ActiveStudents7d,
+		Views30d:         stats.Views30d,
+		Favorites:        stats.Favorites,
+		TestAttempts:     stats.TestAttempts,
+		AvgTestScore:     stats.AvgTestScore,
+		TestPassRate:     stats.TestPassRate,
+		ComputedAt:       stats.ComputedAt,
+	})
+}
+
 type UpdateCourseRequest struct {
 	Title           string `json:"title"`
 	Description     string `json:"description"`
@@ -378,12 +466,6 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 	courseID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	_, err := h.courseService.GetUserCourse(c.Request.Context(), courseID, userID)
-	if err != nil {
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: err.Error()})
-		return
-	}
-
 	var req UpdateCourseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid input"})
@@ -403,8 +485,8 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 		updates.Tags = append(updates.Tags, entities.Tag{ID: tagID})
 	}
 
-	if err := h.courseService.UpdateCourse(c.Request.Context(), courseID, updates); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.UpdateCourse(c.Request.Context(), actorFrom(c), courseID, updates); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("course_id", courseID).Msg("failed to update course")
 		return
 	}
@@ -440,12 +522,6 @@ func (h *CourseHandler) ChangePublishStatus(c *gin.Context) {
 	courseID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	_, err := h.courseService.GetUserCourse(c.Request.Context(), courseID, userID)
-	if err != nil {
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: err.Error()})
-		return
-	}
-
 	var req PublishStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid input"})
@@ -453,8 +529,8 @@ func (h *CourseHandler) ChangePublishStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.courseService.ChangePublishStatus(c.Request.Context(), courseID, req.IsPublished); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.ChangePublishStatus(c.Request.Context(), actorFrom(c), courseID, req.IsPublished); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("course_id", courseID).Msg("failed to published course")
 		return
 	}
@@ -483,14 +559,8 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 	courseID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	_, err := h.courseService.GetUserCourse(c.Request.Context(), courseID, userID)
-	if err != nil {
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: err.Error()})
-		return
-	}
-
-	if err := h.courseService.DeleteCourse(c.Request.Context(), courseID); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.DeleteCourse(c.Request.Context(), actorFrom(c), courseID); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("course_id", courseID).Msg("failed to delete course")
 		return
 	}
@@ -0,0 +1,98 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+type catalogService struct {
+	CourseService
+
+	query string
+}
+
+func (s *catalogService) GetCatalog(_ context.Context, query string) ([]entities.Course, error) {
+	s.query = query
+	return []entities.Course{{ID: "c1", Title: "Python"}, {ID: "c2", Title: "Python 2"}}, nil
+}
+
+type trackedAction struct {
+	userID   string
+	courseID *string
+	action   string
+	meta     map[string]any
+}
+
+type fakeTracker struct {
+	tracked []trackedAction
+}
+
+func (t *fakeTracker) Track(userID string, courseID *string, action string, meta map[string]any) {
+	t.tracked = append(t.tracked, trackedAction{userID, courseID, action, meta})
+}
+
+func getCatalog(t *testing.T, userID, target string) (*catalogService, *fakeTracker) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	service := &catalogService{}
+	tracker := &fakeTracker{}
+	h := NewCourseHandler(service, tracker)
+
+	r := gin.New()
+	r.GET("/catalog", func(c *gin.Context) {
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		h.GetCatalog(c)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	return service, tracker
+}
+
+func TestGetCatalogTracksSearch(t *testing.T) {
+	service, tracker := getCatalog(t, "u1", "/catalog?q=+python+")
+
+	if service.query != "python" {
+		t.Errorf("GetCatalog(query = %q), want %q", service.query, "python")
+	}
+	if len(tracker.tracked) != 1 {
+		t.Fatalf("tracked %d actions, want 1", len(tracker.tracked))
+	}
+	got := tracker.tracked[0]
+	if got.userID != "u1" || got.courseID != nil || got.action != entities.ActionSearch {
+		t.Errorf("Track(%q, %v, %q), want (u1, nil, %q)", got.userID, got.courseID, got.action, entities.ActionSearch)
+	}
+	if got.meta["query"] != "python" || got.meta["results"] != 2 {
+		t.Errorf("meta = %v, want query python and 2 results", got.meta)
+	}
+}
+
+func TestGetCatalogWithoutSearchIsNotTracked(t *testing.T) {
+	tests := []struct {
+		name, userID, target string
+	}{
+		{"no query", "u1", "/catalog"},
+		{"blank query", "u1", "/catalog?q=++"},
+		{"anonymous", "", "/catalog?q=python"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, tracker := getCatalog(t, tt.userID, tt.target)
+			if len(tracker.tracked) != 0 {
+				t.Errorf("tracked %v, want nothing", tracker.tracked)
+			}
+		})
+	}
+}
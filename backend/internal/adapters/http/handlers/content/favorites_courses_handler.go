@@ -3,6 +3,8 @@ package content
 import (
 	"net/http"
 
+	"backend/internal/entities"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -31,6 +33,8 @@ func (h *CourseHandler) ToggleFavorite(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"is_favorite": isFav,
 	})
+
+	h.tracker.Track(userID, &courseID, entities.ActionFavorite, map[string]any{"is_favorite": isFav})
 }
 
 // GetFavorites godoc
@@ -17,7 +17,8 @@ type CreateLessonRequest struct {
 	VideoURL          string `json:"video_url"`
 	FileAttachmentURL string `json:"file_attachment_url"`
 	OrderIndex        int    `json:"order_index"         binding:"required"`
-	XPReward          int    `json:"xp_reward"`
+	// XPReward — награда за урок; без поля или null — награда по умолчанию.
+	XPReward *int `json:"xp_reward" binding:"omitempty,min=0"`
 }
 
 type CreateLessonResponse struct {
@@ -32,6 +33,7 @@ type CreateLessonResponse struct {
 // @Param input body CreateLessonRequest true "Lesson data"
 // @Success 201 {object} CreateLessonResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/lessons [post]
 func (h *CourseHandler) CreateLesson(c *gin.Context) {
@@ -46,13 +48,10 @@ func (h *CourseHandler) CreateLesson(c *gin.Context) {
 	lesson.ContentText = req.ContentText
 	lesson.VideoURL = req.VideoURL
 	lesson.FileAttachmentURL = req.FileAttachmentURL
+	lesson.XPReward = req.XPReward
 
-	if req.XPReward > 0 {
-		lesson.XPReward = req.XPReward
-	}
-
-	if err := h.courseService.CreateLesson(c.Request.Context(), userID, lesson); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.CreateLesson(c.Request.Context(), actorFrom(c), lesson); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("module_id", req.ModuleID).Msg("failed to create lesson")
 		return
 	}
@@ -76,8 +75,9 @@ type LessonResponse struct {
 	ContentText       string `json:"content_text"`
 	VideoURL          string `json:"video_url"`
 	FileAttachmentURL string `json:"file_attachment_url"`
-	XPReward          int    `json:"xp_reward"`
-	OrderIndex        int    `json:"order_index"`
+	// XPReward — null, если у урока награда по умолчанию.
+	XPReward   *int `json:"xp_reward"`
+	OrderIndex int  `json:"order_index"`
 }
 
 // GetLesson godoc
@@ -92,6 +92,7 @@ type LessonResponse struct {
 // @Router /v1/lessons/{id} [get]
 func (h *CourseHandler) GetLesson(c *gin.Context) {
 	lessonID := c.Param("id")
+	userID := c.GetString("user_id")
 
 	lesson, err := h.courseService.GetLessonByID(c.Request.Context(), lessonID)
 	if err != nil {
@@ -114,6 +115,14 @@ func (h *CourseHandler) GetLesson(c *gin.Context) {
 		XPReward:          lesson.XPReward,
 		OrderIndex:        lesson.OrderIndex,
 	})
+
+	if userID != "" {
+		var courseID *string
+		if module, err := h.courseService.GetModuleByID(c.Request.Context(), lesson.ModuleID); err == nil {
+			courseID = &module.CourseID
+		}
+		h.tracker.Track(userID, courseID, entities.ActionLessonOpen, map[string]any{"lesson_id": lesson.ID})
+	}
 }
 
 type UpdateLessonRequest struct {
@@ -122,7 +131,8 @@ type UpdateLessonRequest struct {
 	VideoURL          string `json:"video_url"`
 	FileAttachmentURL string `json:"file_attachment_url"`
 	OrderIndex        int    `json:"order_index"`
-	XPReward          int    `json:"xp_reward"`
+	// XPReward — награда за урок; без поля или null — награда по умолчанию.
+	XPReward *int `json:"xp_reward" binding:"omitempty,min=0"`
 }
 
 // UpdateLesson godoc
@@ -134,6 +144,7 @@ type UpdateLessonRequest struct {
 // @Param input body UpdateLessonRequest true "Data"
 // @Success 200
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/lessons/{id} [put]
 func (h *CourseHandler) UpdateLesson(c *gin.Context) {
@@ -153,14 +164,11 @@ func (h *CourseHandler) UpdateLesson(c *gin.Context) {
 		VideoURL:          req.VideoURL,
 		FileAttachmentURL: req.FileAttachmentURL,
 		OrderIndex:        req.OrderIndex,
+		XPReward:          req.XPReward,
 	}
 
-	if req.XPReward > 0 {
-		lesson.XPReward = req.XPReward
-	}
-
-	if err := h.courseService.UpdateLesson(c.Request.Context(), userID, lesson); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.UpdateLesson(c.Request.Context(), actorFrom(c), lesson); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("lesson_id", lessonID).Msg("failed to update lesson")
 		return
 	}
@@ -175,14 +183,15 @@ func (h *CourseHandler) UpdateLesson(c *gin.Context) {
 // @Param id path string true "Lesson ID"
 // @Success 200
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/lessons/{id} [delete]
 func (h *CourseHandler) DeleteLesson(c *gin.Context) {
 	userID := c.GetString("user_id")
 	lessonID := c.Param("id")
 
-	if err := h.courseService.DeleteLesson(c.Request.Context(), userID, lessonID); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.DeleteLesson(c.Request.Context(), actorFrom(c), lessonID); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("lesson_id", lessonID).Msg("failed to delete lesson")
 		return
 	}
@@ -28,6 +28,7 @@ type CreateModuleResponse struct {
 // @Param input body CreateModuleRequest true "Module data"
 // @Success 201 {object} CreateModuleResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/modules [post]
 func (h *CourseHandler) CreateModule(c *gin.Context) {
@@ -41,8 +42,8 @@ func (h *CourseHandler) CreateModule(c *gin.Context) {
 
 	module := entities.NewModule(req.CourseID, req.Title, req.OrderIndex)
 
-	if err := h.courseService.CreateModule(c.Request.Context(), userID, module); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.CreateModule(c.Request.Context(), actorFrom(c), module); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Msg("failed to create module")
 		return
 	}
@@ -73,6 +74,7 @@ type UpdateModuleRequest struct {
 // @Param input body UpdateModuleRequest true "Data"
 // @Success 200
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/modules/{id} [put]
 func (h *CourseHandler) UpdateModule(c *gin.Context) {
@@ -92,8 +94,8 @@ func (h *CourseHandler) UpdateModule(c *gin.Context) {
 		OrderIndex: req.OrderIndex,
 	}
 
-	if err := h.courseService.UpdateModule(c.Request.Context(), userID, module); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.UpdateModule(c.Request.Context(), actorFrom(c), module); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("module_id", moduleID).Msg("failed to update module")
 		return
 	}
@@ -114,14 +116,15 @@ func (h *CourseHandler) UpdateModule(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Module ID"
 // @Success 200
+// @Failure 403 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/modules/{id} [delete]
 func (h *CourseHandler) DeleteModule(c *gin.Context) {
 	userID := c.GetString("user_id")
 	moduleID := c.Param("id")
 
-	if err := h.courseService.DeleteModule(c.Request.Context(), userID, moduleID); err != nil {
-		c.Status(http.StatusInternalServerError)
+	if err := h.courseService.DeleteModule(c.Request.Context(), actorFrom(c), moduleID); err != nil {
+		writeContentError(c, err)
 		log.Error().Err(err).Str("user_id", userID).Str("module_id", moduleID).Msg("failed to delete module")
 		return
 	}
@@ -0,0 +1,245 @@
+package content
+
+import (
+	"net/http"
+
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type CourseTranslationRequest struct {
+	Title       string `json:"title"       binding:"required"`
+	Description string `json:"description"`
+}
+
+type ModuleTranslationRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type LessonTranslationRequest struct {
+	Title       string `json:"title"        binding:"required"`
+	ContentText string `json:"content_text"`
+}
+
+// translationLocale читает язык перевода из пути и отвечает 400, если он не поддерживается.
+func translationLocale(c *gin.Context) (entities.Locale, bool) {
+	locale := entities.Locale(c.Param("locale"))
+	if !locale.IsValid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "locale must be one of ru, kk, en"})
+		return "", false
+	}
+	return locale, true
+}
+
+// SaveCourseTranslation godoc
+// @Summary Save course translation
+// @Description Creates or replaces the course title and description in the given language. An empty description falls back to the original one
+// @Tags courses
+// @Security BearerAuth
+// @Accept json
+// @Param id path string true "Course ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Param input body CourseTranslationRequest true "Translation"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/courses/{id}/translations/{locale} [put]
+func (h *CourseHandler) SaveCourseTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	var req CourseTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	t := &entities.CourseTranslation{
+		CourseID:    c.Param("id"),
+		Locale:      locale,
+		Title:       req.Title,
+		Description: req.Description,
+	}
+	if err := h.courseService.SaveCourseTranslation(c.Request.Context(), actorFrom(c), t); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("course_id", t.CourseID).Str("locale", string(locale)).Msg("failed to save course translation")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	log.Info().Str("course_id", t.CourseID).Str("locale", string(locale)).Msg("course translation saved")
+}
+
+// DeleteCourseTranslation godoc
+// @Summary Delete course translation
+// @Tags courses
+// @Security BearerAuth
+// @Param id path string true "Course ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/courses/{id}/translations/{locale} [delete]
+func (h *CourseHandler) DeleteCourseTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	courseID := c.Param("id")
+	if err := h.courseService.DeleteCourseTranslation(c.Request.Context(), actorFrom(c), courseID, locale); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("course_id", courseID).Str("locale", string(locale)).Msg("failed to delete course translation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SaveModuleTranslation godoc
+// @Summary Save module translation
+// @Tags modules
+// @Security BearerAuth
+// @Accept json
+// @Param id path string true "Module ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Param input body ModuleTranslationRequest true "Translation"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/modules/{id}/translations/{locale} [put]
+func (h *CourseHandler) SaveModuleTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	var req ModuleTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	t := &entities.ModuleTranslation{
+		ModuleID: c.Param("id"),
+		Locale:   locale,
+		Title:    req.Title,
+	}
+	if err := h.courseService.SaveModuleTranslation(c.Request.Context(), actorFrom(c), t); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("module_id", t.ModuleID).Str("locale", string(locale)).Msg("failed to save module translation")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	log.Info().Str("module_id", t.ModuleID).Str("locale", string(locale)).Msg("module translation saved")
+}
+
+// DeleteModuleTranslation godoc
+// @Summary Delete module translation
+// @Tags modules
+// @Security BearerAuth
+// @Param id path string true "Module ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/modules/{id}/translations/{locale} [delete]
+func (h *CourseHandler) DeleteModuleTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	moduleID := c.Param("id")
+	if err := h.courseService.DeleteModuleTranslation(c.Request.Context(), actorFrom(c), moduleID, locale); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("module_id", moduleID).Str("locale", string(locale)).Msg("failed to delete module translation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SaveLessonTranslation godoc
+// @Summary Save lesson translation
+// @Description Creates or replaces the lesson title and text in the given language. Empty content_text falls back to the original text
+// @Tags lessons
+// @Security BearerAuth
+// @Accept json
+// @Param id path string true "Lesson ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Param input body LessonTranslationRequest true "Translation"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/lessons/{id}/translations/{locale} [put]
+func (h *CourseHandler) SaveLessonTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	var req LessonTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	t := &entities.LessonTranslation{
+		LessonID:    c.Param("id"),
+		Locale:      locale,
+		Title:       req.Title,
+		ContentText: req.ContentText,
+	}
+	if err := h.courseService.SaveLessonTranslation(c.Request.Context(), actorFrom(c), t); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("lesson_id", t.LessonID).Str("locale", string(locale)).Msg("failed to save lesson translation")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	log.Info().Str("lesson_id", t.LessonID).Str("locale", string(locale)).Msg("lesson translation saved")
+}
+
+// DeleteLessonTranslation godoc
+// @Summary Delete lesson translation
+// @Tags lessons
+// @Security BearerAuth
+// @Param id path string true "Lesson ID"
+// @Param locale path string true "Locale (ru, kk, en)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/lessons/{id}/translations/{locale} [delete]
+func (h *CourseHandler) DeleteLessonTranslation(c *gin.Context) {
+	locale, ok := translationLocale(c)
+	if !ok {
+		return
+	}
+
+	lessonID := c.Param("id")
+	if err := h.courseService.DeleteLessonTranslation(c.Request.Context(), actorFrom(c), lessonID, locale); err != nil {
+		writeContentError(c, err)
+		log.Error().Err(err).Str("lesson_id", lessonID).Str("locale", string(locale)).Msg("failed to delete lesson translation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
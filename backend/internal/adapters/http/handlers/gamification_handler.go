@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"backend/internal/entities"
+	"backend/internal/services/gamification"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -12,6 +16,10 @@ import (
 
 type GamificationService interface {
 	GetAllLeagues(ctx context.Context) ([]entities.League, error)
+	GetStudentAchievements(ctx context.Context, userID string) ([]gamification.StudentAchievement, error)
+	Policy(ctx context.Context) (entities.GamificationPolicy, error)
+	UpdatePolicy(ctx context.Context, p entities.GamificationPolicy) (entities.GamificationPolicy, error)
+	UpdateLeagueZones(ctx context.Context, leagueID int, z gamification.LeagueZones) (*entities.League, error)
 }
 
 type GamificationHandler struct {
@@ -28,6 +36,23 @@ type LeagueResponse struct {
 	Name       string `json:"name"`
 	OrderIndex int    `json:"order_index"`
 	IconURL    string `json:"icon_url"`
+
+	PromotePercent int  `json:"promote_percent"`
+	DemotePercent  int  `json:"demote_percent"`
+	DemoteInactive bool `json:"demote_inactive"`
+}
+
+func newLeagueResponse(l entities.League) LeagueResponse {
+	return LeagueResponse{
+		ID:             l.ID,
+		Slug:           l.Slug,
+		Name:           l.Name,
+		OrderIndex:     l.OrderIndex,
+		IconURL:        l.IconURL,
+		PromotePercent: l.PromotePercent,
+		DemotePercent:  l.DemotePercent,
+		DemoteInactive: l.DemoteInactive,
+	}
 }
 
 type LeaguesListResponse struct {
@@ -50,14 +75,257 @@ func (h *GamificationHandler) GetAllLeagues(c *gin.Context) {
 
 	var response []LeagueResponse
 	for _, l := range leagues {
-		response = append(response, LeagueResponse{
-			ID:         l.ID,
-			Slug:       l.Slug,
-			Name:       l.Name,
-			OrderIndex: l.OrderIndex,
-			IconURL:    l.IconURL,
-		})
+		response = append(response, newLeagueResponse(l))
 	}
 
 	c.JSON(http.StatusOK, LeaguesListResponse{Leagues: response})
 }
+
+type AchievementResponse struct {
+	ID          string     `json:"id"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	IconURL     string     `json:"icon_url"`
+	XPReward    int        `json:"xp_reward"`
+	Earned      bool       `json:"earned"`
+	EarnedAt    *time.Time `json:"earned_at,omitempty"`
+}
+
+type AchievementsListResponse struct {
+	Achievements []AchievementResponse `json:"achievements"`
+}
+
+// GetMyAchievements godoc
+// @Summary Get student achievements
+// @Description All achievements with the student's progress: earned ones first (newest first), then locked ones
+// @Tags gamification
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} AchievementsListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/student/achievements [get]
+func (h *GamificationHandler) GetMyAchievements(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	achievements, err := h.service.GetStudentAchievements(c.Request.Context(), userID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to get achievements")
+		return
+	}
+
+	response := make([]AchievementResponse, 0, len(achievements))
+	for _, sa := range achievements {
+		a := sa.Achievement
+		response = append(response, AchievementResponse{
+			ID:          a.ID,
+			Slug:        a.Slug,
+			Name:        a.Name,
+			Description: a.Description,
+			IconURL:     a.IconURL,
+			XPReward:    a.XPReward,
+			Earned:      sa.EarnedAt != nil,
+			EarnedAt:    sa.EarnedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, AchievementsListResponse{Achievements: response})
+}
+
+type LevelCurvePayload struct {
+	Kind       string  `json:"kind" binding:"required,oneof=linear quadratic table"`
+	XPPerLevel int64   `json:"xp_per_level,omitempty"`
+	Thresholds []int64 `json:"thresholds,omitempty"`
+}
+
+type StreakMultiplierPayload struct {
+	MinDays    int     `json:"min_days"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type XPRulesPayload struct {
+	LessonDefaultXP       int                       `json:"lesson_default_xp"`
+	TestMaxXP             int                       `json:"test_max_xp"`
+	ScoreProportional     bool                      `json:"score_proportional"`
+	FirstTryBonusPercent  int                       `json:"first_try_bonus_percent"`
+	DifficultyMultipliers []float64                 `json:"difficulty_multipliers"`
+	StreakMultipliers     []StreakMultiplierPayload `json:"streak_multipliers"`
+}
+
+type StreakRulesPayload struct {
+	FreezeEveryDays int `json:"freeze_every_days"`
+	MaxFreezes      int `json:"max_freezes"`
+}
+
+// GamificationPolicyPayload — тело запроса и ответа для политики.
+type GamificationPolicyPayload struct {
+	Levels  LevelCurvePayload  `json:"levels" binding:"required"`
+	XP      XPRulesPayload     `json:"xp" binding:"required"`
+	Streaks StreakRulesPayload `json:"streaks"`
+}
+
+func newPolicyPayload(p entities.GamificationPolicy) GamificationPolicyPayload {
+	streaks := make([]StreakMultiplierPayload, 0, len(p.XP.StreakMultipliers))
+	for _, m := range p.XP.StreakMultipliers {
+		streaks = append(streaks, StreakMultiplierPayload{MinDays: m.MinDays, Multiplier: m.Multiplier})
+	}
+
+	return GamificationPolicyPayload{
+		Levels: LevelCurvePayload{
+			Kind:       string(p.Levels.Kind),
+			XPPerLevel: p.Levels.XPPerLevel,
+			Thresholds: p.Levels.Thresholds,
+		},
+		XP: XPRulesPayload{
+			LessonDefaultXP:       p.XP.LessonDefaultXP,
+			TestMaxXP:             p.XP.TestMaxXP,
+			ScoreProportional:     p.XP.ScoreProportional,
+			FirstTryBonusPercent:  p.XP.FirstTryBonusPercent,
+			DifficultyMultipliers: p.XP.DifficultyMultipliers,
+			StreakMultipliers:     streaks,
+		},
+		Streaks: StreakRulesPayload{
+			FreezeEveryDays: p.Streaks.FreezeEveryDays,
+			MaxFreezes:      p.Streaks.MaxFreezes,
+		},
+	}
+}
+
+func (p GamificationPolicyPayload) toEntity() entities.GamificationPolicy {
+	streaks := make([]entities.StreakMultiplier, 0, len(p.XP.StreakMultipliers))
+	for _, m := range p.XP.StreakMultipliers {
+		streaks = append(streaks, entities.StreakMultiplier{MinDays: m.MinDays, Multiplier: m.Multiplier})
+	}
+
+	return entities.GamificationPolicy{
+		Levels: entities.LevelCurve{
+			Kind:       entities.LevelCurveKind(p.Levels.Kind),
+			XPPerLevel: p.Levels.XPPerLevel,
+			Thresholds: p.Levels.Thresholds,
+		},
+		XP: entities.XPRules{
+			LessonDefaultXP:       p.XP.LessonDefaultXP,
+			TestMaxXP:             p.XP.TestMaxXP,
+			ScoreProportional:     p.XP.ScoreProportional,
+			FirstTryBonusPercent:  p.XP.FirstTryBonusPercent,
+			DifficultyMultipliers: p.XP.DifficultyMultipliers,
+			StreakMultipliers:     streaks,
+		},
+		Streaks: entities.StreakRules{
+			FreezeEveryDays: p.Streaks.FreezeEveryDays,
+			MaxFreezes:      p.Streaks.MaxFreezes,
+		},
+	}
+}
+
+// GetPolicy godoc
+// @Summary Get gamification policy
+// @Description Level curve and XP rules currently in effect
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} GamificationPolicyPayload
+// @Failure 403 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/gamification/policy [get]
+func (h *GamificationHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.service.Policy(c.Request.Context())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Msg("failed to get gamification policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPolicyPayload(policy))
+}
+
+// UpdatePolicy godoc
+// @Summary Update gamification policy
+// @Description Replaces the level curve and XP rules. Students whose XP reaches a higher level on the new curve are raised to it; levels are never lowered.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body GamificationPolicyPayload true "Policy"
+// @Success 200 {object} GamificationPolicyPayload
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/gamification/policy [put]
+func (h *GamificationHandler) UpdatePolicy(c *gin.Context) {
+	var req GamificationPolicyPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(c.Request.Context(), req.toEntity())
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidPolicy) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Msg("failed to update gamification policy")
+		return
+	}
+
+	log.Info().Str("user_id", c.GetString("user_id")).Msg("gamification policy updated")
+	c.JSON(http.StatusOK, newPolicyPayload(policy))
+}
+
+type LeagueZonesRequest struct {
+	PromotePercent *int  `json:"promote_percent" binding:"required"`
+	DemotePercent  *int  `json:"demote_percent" binding:"required"`
+	DemoteInactive *bool `json:"demote_inactive" binding:"required"`
+}
+
+// UpdateLeagueZones godoc
+// @Summary Update league promotion and demotion zones
+// @Description Percent of league members promoted and demoted at the weekly reset (0-50 each). Students without weekly XP rank last and are demoted first; demote_inactive demotes all of them. Applies from the next reset.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param input body LeagueZonesRequest true "Zones"
+// @Success 200 {object} LeagueResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/leagues/{id}/zones [put]
+func (h *GamificationHandler) UpdateLeagueZones(c *gin.Context) {
+	leagueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid league id"})
+		return
+	}
+
+	var req LeagueZonesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	league, err := h.service.UpdateLeagueZones(c.Request.Context(), leagueID, gamification.LeagueZones{
+		PromotePercent: *req.PromotePercent,
+		DemotePercent:  *req.DemotePercent,
+		DemoteInactive: *req.DemoteInactive,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidLeagueZones):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		case errors.Is(err, entities.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "league not found"})
+		default:
+			c.Status(http.StatusInternalServerError)
+			log.Error().Err(err).Int("league_id", leagueID).Msg("failed to update league zones")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newLeagueResponse(*league))
+}
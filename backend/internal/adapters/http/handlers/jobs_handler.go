@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/entities"
+	"backend/internal/services/scheduler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type JobsHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewJobsHandler(s *scheduler.Scheduler) *JobsHandler {
+	return &JobsHandler{scheduler: s}
+}
+
+type JobRunResponse struct {
+	ID          string     `json:"id"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	Status      string     `json:"status" enums:"running,succeeded,failed,skipped"`
+	Attempts    int        `json:"attempts"`
+	Instance    string     `json:"instance"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+func newJobRunResponse(r entities.JobRun) JobRunResponse {
+	return JobRunResponse{
+		ID:          r.ID,
+		ScheduledAt: r.ScheduledAt,
+		Status:      string(r.Status),
+		Attempts:    r.Attempts,
+		Instance:    r.Instance,
+		StartedAt:   r.StartedAt,
+		FinishedAt:  r.FinishedAt,
+		Error:       r.Error,
+	}
+}
+
+type JobStatusResponse struct {
+	Name      string          `json:"name"`
+	Schedule  string          `json:"schedule"`
+	NextRunAt *time.Time      `json:"next_run_at"`
+	LastRun   *JobRunResponse `json:"last_run"`
+}
+
+type JobsListResponse struct {
+	Jobs []JobStatusResponse `json:"jobs"`
+}
+
+type JobRunsResponse struct {
+	Runs []JobRunResponse `json:"runs"`
+}
+
+// ListJobs godoc
+// @Summary List scheduled jobs
+// @Description Registered periodic jobs with their cron schedule (UTC), next run and the most recent run on any instance
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} JobsListResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/jobs [get]
+func (h *JobsHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.scheduler.Jobs(c.Request.Context())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Msg("failed to list jobs")
+		return
+	}
+
+	list := make([]JobStatusResponse, 0, len(jobs))
+	for _, j := range jobs {
+		item := JobStatusResponse{Name: j.Name, Schedule: j.Schedule}
+		if !j.NextRunAt.IsZero() {
+			next := j.NextRunAt
+			item.NextRunAt = &next
+		}
+		if j.LastRun != nil {
+			last := newJobRunResponse(*j.LastRun)
+			item.LastRun = &last
+		}
+		list = append(list, item)
+	}
+
+	c.JSON(http.StatusOK, JobsListResponse{Jobs: list})
+}
+
+// ListJobRuns godoc
+// @Summary List runs of a job
+// @Description Most recent runs of the job, newest first. History is kept for 30 days.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Job name"
+// @Param limit query int false "Number of runs (default 20, max 100)"
+// @Success 200 {object} JobRunsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/jobs/{name}/runs [get]
+func (h *JobsHandler) ListJobRuns(c *gin.Context) {
+	name := c.Param("name")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	runs, err := h.scheduler.Runs(c.Request.Context(), name, limit)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "job not found"})
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Str("job", name).Msg("failed to list job runs")
+		return
+	}
+
+	list := make([]JobRunResponse, 0, len(runs))
+	for _, r := range runs {
+		list = append(list, newJobRunResponse(r))
+	}
+
+	c.JSON(http.StatusOK, JobRunsResponse{Runs: list})
+}
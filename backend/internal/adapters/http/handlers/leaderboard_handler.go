@@ -2,12 +2,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"backend/internal/entities"
 	"backend/internal/services/student"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
 type LeaderboardHandler struct {
@@ -36,7 +40,7 @@ type LeaderboardResponse struct {
 
 // GetWeeklyLeaderboard godoc
 // @Summary Get weekly league leaderboard
-// @Description Get top players in current user's league for this week
+// @Description Get top players in current user's league cohort for this week. Until the weekly reset runs, shows last week's cohort. Read-only: cohorts are assigned by onboarding and the weekly reset.
 // @Tags leaderboard
 // @Security BearerAuth
 // @Produce json
@@ -123,3 +127,151 @@ func (h *LeaderboardHandler) GetGlobalLeaderboard(c *gin.Context) {
 		UserRank:    userRank,
 	})
 }
+
+// historyPeriodLayout — формат недели в пути /leaderboard/history/:period.
+const historyPeriodLayout = "2006-01-02"
+
+// LeagueHistoryDTO — итог одной недели ученика в лиге.
+type LeagueHistoryDTO struct {
+	PeriodStart   string `json:"period_start"`
+	PeriodEnd     string `json:"period_end"`
+	LeagueID      int    `json:"league_id"`
+	LeagueName    string `json:"league_name"`
+	LeagueIconURL string `json:"league_icon_url"`
+	Rank          int    `json:"rank"`
+	XP            int64  `json:"xp"`
+	Outcome       string `json:"outcome,omitempty" enums:"promoted,demoted,stayed"`
+	ToLeagueID    int    `json:"to_league_id,omitempty"`
+}
+
+func newLeagueHistoryDTO(h entities.LeaderboardHistory) LeagueHistoryDTO {
+	return LeagueHistoryDTO{
+		PeriodStart:   h.PeriodStart.Format(historyPeriodLayout),
+		PeriodEnd:     h.PeriodEnd.Format(historyPeriodLayout),
+		LeagueID:      h.LeagueID,
+		LeagueName:    h.LeagueName,
+		LeagueIconURL: h.LeagueIconURL,
+		Rank:          h.Rank,
+		XP:            h.TotalXP,
+		Outcome:       string(h.Outcome),
+		ToLeagueID:    h.ToLeagueID,
+	}
+}
+
+type LeagueHistoryResponse struct {
+	History []LeagueHistoryDTO `json:"history"`
+	Total   int                `json:"total"`
+}
+
+// GetHistory godoc
+// @Summary Get my league history
+// @Description Past weeks of the current user, newest first: league, final rank, XP and whether the user was promoted, demoted or stayed
+// @Tags leaderboard
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of weeks (default 20)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} LeagueHistoryResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/leaderboard/history [get]
+func (h *LeaderboardHandler) GetHistory(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	history, total, err := h.service.GetLeagueHistory(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to get league history")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to get league history"})
+		return
+	}
+
+	list := make([]LeagueHistoryDTO, 0, len(history))
+	for _, e := range history {
+		list = append(list, newLeagueHistoryDTO(e))
+	}
+
+	c.JSON(http.StatusOK, LeagueHistoryResponse{History: list, Total: total})
+}
+
+type LeagueHistoryTableEntry struct {
+	Rank      int    `json:"rank"`
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	AvatarURL string `json:"avatar_url"`
+	XP        int64  `json:"xp"`
+	Outcome   string `json:"outcome,omitempty" enums:"promoted,demoted,stayed"`
+}
+
+type LeagueHistoryTableResponse struct {
+	PeriodStart   string                    `json:"period_start"`
+	PeriodEnd     string                    `json:"period_end"`
+	LeagueID      int                       `json:"league_id"`
+	LeagueName    string                    `json:"league_name"`
+	LeagueIconURL string                    `json:"league_icon_url"`
+	Leaderboard   []LeagueHistoryTableEntry `json:"leaderboard"`
+}
+
+// GetHistoryTable godoc
+// @Summary Get final table of a past week
+// @Description Final standings of the league cohort the current user was in during the given week
+// @Tags leaderboard
+// @Security BearerAuth
+// @Produce json
+// @Param period path string true "Any date of the week, YYYY-MM-DD"
+// @Success 200 {object} LeagueHistoryTableResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/leaderboard/history/{period} [get]
+func (h *LeaderboardHandler) GetHistoryTable(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	period, err := time.Parse(historyPeriodLayout, c.Param("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "period must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	table, err := h.service.GetLeagueHistoryTable(c.Request.Context(), userID, period)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "no league results for this week"})
+			return
+		}
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to get league history table")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to get league history"})
+		return
+	}
+
+	first := table[0]
+	resp := LeagueHistoryTableResponse{
+		PeriodStart:   first.PeriodStart.Format(historyPeriodLayout),
+		PeriodEnd:     first.PeriodEnd.Format(historyPeriodLayout),
+		LeagueID:      first.LeagueID,
+		LeagueName:    first.LeagueName,
+		LeagueIconURL: first.LeagueIconURL,
+		Leaderboard:   make([]LeagueHistoryTableEntry, 0, len(table)),
+	}
+	for _, e := range table {
+		resp.Leaderboard = append(resp.Leaderboard, LeagueHistoryTableEntry{
+			Rank:      e.Rank,
+			UserID:    e.UserID,
+			FirstName: e.FirstName,
+			LastName:  e.LastName,
+			AvatarURL: e.AvatarURL,
+			XP:        e.TotalXP,
+			Outcome:   string(e.Outcome),
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type BankQuestionRequest struct {
+	CreateQuestionRequest
+	Difficulty int      `json:"difficulty" binding:"min=0,max=5"` // 0 — не задана
+	Tags       []string `json:"tags"`
+}
+
+type BankQuestionResponse struct {
+	QuestionResponse
+	CourseID   string   `json:"course_id"`
+	Difficulty int      `json:"difficulty"`
+	Tags       []string `json:"tags"`
+}
+
+func mapBankQuestionToResponse(q entities.Question) BankQuestionResponse {
+	tags := q.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return BankQuestionResponse{
+		QuestionResponse: mapQuestionWithAnswers(q),
+		CourseID:         q.CourseID,
+		Difficulty:       q.Difficulty,
+		Tags:             tags,
+	}
+}
+
+// GetBankQuestions godoc
+// @Summary Get course question bank
+// @Description Questions that module tests draw from through pool rules (course author only)
+// @Tags tests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {array} BankQuestionResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/courses/{id}/question-bank [get]
+func (h *TestHandler) GetBankQuestions(c *gin.Context) {
+	courseID := c.Param("id")
+
+	questions, err := h.service.GetBankQuestions(c.Request.Context(), actorFrom(c), courseID)
+	if err != nil {
+		writeAccessError(c, err, "failed to get question bank")
+		return
+	}
+
+	resp := make([]BankQuestionResponse, 0, len(questions))
+	for _, q := range questions {
+		resp = append(resp, mapBankQuestionToResponse(q))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateBankQuestion godoc
+// @Summary Add question to course bank
+// @Tags tests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param input body BankQuestionRequest true "Question"
+// @Success 201 {object} BankQuestionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/courses/{id}/question-bank [post]
+func (h *TestHandler) CreateBankQuestion(c *gin.Context) {
+	var req BankQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	q := entities.NewBankQuestion(c.Param("id"), req.Text, req.QuestionType, req.Difficulty, req.Tags)
+	req.fill(q, false)
+
+	err := h.service.CreateBankQuestion(c.Request.Context(), actorFrom(c), q)
+	if err != nil {
+		writeAccessError(c, err, "failed to create bank question")
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapBankQuestionToResponse(*q))
+	log.Info().Str("course_id", q.CourseID).Str("question_id", q.ID).Msg("bank question created")
+}
+
+// UpdateBankQuestion godoc
+// @Summary Update question in course bank
+// @Description Answers are matched by id: known ids are updated, others are added, missing ones are removed
+// @Tags tests
+// @Security BearerAuth
+// @Accept json
+// @Param id path string true "Question ID"
+// @Param input body BankQuestionRequest true "Question"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/question-bank/{id} [put]
+func (h *TestHandler) UpdateBankQuestion(c *gin.Context) {
+	var req BankQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	q := entities.NewBankQuestion("", req.Text, req.QuestionType, req.Difficulty, req.Tags)
+	req.ID = c.Param("id")
+	req.fill(q, true)
+
+	err := h.service.UpdateBankQuestion(c.Request.Context(), actorFrom(c), q)
+	if err != nil {
+		writeAccessError(c, err, "failed to update bank question")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	log.Info().Str("question_id", q.ID).Msg("bank question updated")
+}
+
+// DeleteBankQuestion godoc
+// @Summary Delete question from course bank
+// @Tags tests
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/question-bank/{id} [delete]
+func (h *TestHandler) DeleteBankQuestion(c *gin.Context) {
+	questionID := c.Param("id")
+
+	err := h.service.DeleteBankQuestion(c.Request.Context(), actorFrom(c), questionID)
+	if err != nil {
+		writeAccessError(c, err, "failed to delete bank question")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	log.Info().Str("question_id", questionID).Msg("bank question deleted")
+}
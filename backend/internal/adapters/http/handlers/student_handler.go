@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"backend/internal/entities"
 	"backend/internal/services/student"
 
 	"github.com/gin-gonic/gin"
@@ -17,33 +20,55 @@ func NewStudentHandler(service *student.StudentService) *StudentHandler {
 	return &StudentHandler{service: service}
 }
 
+// LevelUpResponse — переход на новый уровень; null, если уровень не изменился.
+type LevelUpResponse struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func newLevelUpResponse(l *entities.LevelUp) *LevelUpResponse {
+	if l == nil {
+		return nil
+	}
+	return &LevelUpResponse{From: l.From, To: l.To}
+}
+
+type CompleteLessonResponse struct {
+	Status   string           `json:"status"`
+	XPGained int              `json:"xp_gained"`
+	LevelUp  *LevelUpResponse `json:"level_up"`
+}
+
 // CompleteLesson godoc
 // @Summary Mark lesson as completed
 // @Tags student
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Lesson ID"
-// @Success 200 {object} map[string]any
+// @Success 200 {object} CompleteLessonResponse
 // @Router /v1/student/lessons/{id}/complete [post]
 func (h *StudentHandler) CompleteLesson(c *gin.Context) {
 	userID := c.GetString("user_id")
 	lessonID := c.Param("id")
 
-	_, xp, err := h.service.CompleteLesson(c.Request.Context(), userID, lessonID)
+	res, err := h.service.CompleteLesson(c.Request.Context(), userID, lessonID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to complete lesson"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "completed",
-		"xp_gained": xp,
+	c.JSON(http.StatusOK, CompleteLessonResponse{
+		Status:   "completed",
+		XPGained: res.XPGained,
+		LevelUp:  newLevelUpResponse(res.LevelUp),
 	})
 }
 
 type OnboardingRequest struct {
 	Grade      int      `json:"grade" binding:"required,min=1,max=11"`
 	SubjectIDs []string `json:"subject_ids" binding:"required"`
+	// Timezone — IANA-имя, например "Asia/Almaty"; по умолчанию Asia/Almaty.
+	Timezone string `json:"timezone"`
 }
 
 // CompleteOnboarding godoc
@@ -62,8 +87,12 @@ func (h *StudentHandler) CompleteOnboarding(c *gin.Context) {
 		return
 	}
 
-	err := h.service.CompleteOnboarding(c.Request.Context(), userID, req.Grade, req.SubjectIDs)
+	err := h.service.CompleteOnboarding(c.Request.Context(), userID, req.Grade, req.SubjectIDs, req.Timezone)
 	if err != nil {
+		if errors.Is(err, entities.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to complete onboarding: " + err.Error()})
 		return
 	}
@@ -96,6 +125,7 @@ type DashboardResponse struct {
 	Profile       *StudentProfileDTO `json:"profile"`
 	Interests     []SubjectDTO       `json:"interests"`
 	ActiveCourses []ActiveCourseDTO  `json:"active_courses"`
+	LastWeek      *LeagueHistoryDTO  `json:"last_week"`
 }
 
 type StudentProfileDTO struct {
@@ -109,15 +139,16 @@ type StudentProfileDTO struct {
 	CurrentStreak    int        `json:"current_streak"`
 	MaxStreak        int        `json:"max_streak"`
 	LastActivityDate *time.Time `json:"last_activity_date"`
+	Timezone         string     `json:"timezone"`
+	StreakFreezes    int        `json:"streak_freezes"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 type SubjectDTO struct {
-	ID     string `json:"id"`
-	Slug   string `json:"slug"`
-	NameRu string `json:"name_ru"`
-	NameKz string `json:"name_kz"`
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
 }
 
 type ActiveCourseDTO struct {
@@ -167,6 +198,8 @@ func toDashboardDTO(data *student.DashboardData) *DashboardResponse {
 		CurrentStreak:    data.Profile.CurrentStreak,
 		MaxStreak:        data.Profile.MaxStreak,
 		LastActivityDate: data.Profile.LastActivityDate,
+		Timezone:         data.Profile.Timezone,
+		StreakFreezes:    data.Profile.StreakFreezes,
 		CreatedAt:        data.Profile.CreatedAt,
 		UpdatedAt:        data.Profile.UpdatedAt,
 	}
@@ -175,10 +208,9 @@ func toDashboardDTO(data *student.DashboardData) *DashboardResponse {
 	interests := make([]SubjectDTO, len(data.Interests))
 	for i, s := range data.Interests {
 		interests[i] = SubjectDTO{
-			ID:     s.ID,
-			Slug:   s.Slug,
-			NameRu: s.NameRu,
-			NameKz: s.NameKz,
+			ID:   s.ID,
+			Slug: s.Slug,
+			Name: s.Name,
 		}
 	}
 
@@ -195,10 +227,18 @@ func toDashboardDTO(data *student.DashboardData) *DashboardResponse {
 		}
 	}
 
+	// last week
+	var lastWeek *LeagueHistoryDTO
+	if data.LastWeek != nil {
+		dto := newLeagueHistoryDTO(*data.LastWeek)
+		lastWeek = &dto
+	}
+
 	return &DashboardResponse{
 		Profile:       profile,
 		Interests:     interests,
 		ActiveCourses: courses,
+		LastWeek:      lastWeek,
 	}
 }
 
@@ -244,3 +284,105 @@ func (h *StudentHandler) GetMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, info)
 }
+
+type XPTransactionResponse struct {
+	ID          int64     `json:"id"`
+	SourceType  string    `json:"source_type"`
+	SourceID    string    `json:"source_id"`
+	SourceTitle string    `json:"source_title"`
+	Amount      int       `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type XPHistoryResponse struct {
+	Items  []XPTransactionResponse `json:"items"`
+	Total  int                     `json:"total"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+}
+
+// GetXPHistory godoc
+// @Summary Get XP history
+// @Description XP grants of the current student, newest first
+// @Tags student
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} XPHistoryResponse
+// @Router /v1/student/xp-history [get]
+func (h *StudentHandler) GetXPHistory(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := h.service.GetXPHistory(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to get xp history"})
+		return
+	}
+
+	response := make([]XPTransactionResponse, 0, len(items))
+	for _, t := range items {
+		response = append(response, XPTransactionResponse{
+			ID:          t.ID,
+			SourceType:  string(t.SourceType),
+			SourceID:    t.SourceID,
+			SourceTitle: t.SourceTitle,
+			Amount:      t.Amount,
+			CreatedAt:   t.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, XPHistoryResponse{
+		Items:  response,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+type TimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+// SetTimezone godoc
+// @Summary Set streak timezone
+// @Description Day boundaries for the streak are computed in this IANA timezone
+// @Tags student
+// @Security BearerAuth
+// @Accept json
+// @Param input body TimezoneRequest true "Timezone"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/student/timezone [put]
+func (h *StudentHandler) SetTimezone(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req TimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	err := h.service.SetTimezone(c.Request.Context(), userID, req.Timezone)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		case errors.Is(err, entities.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "student profile not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to set timezone"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
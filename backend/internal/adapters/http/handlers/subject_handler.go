@@ -23,10 +23,9 @@ func NewSubjectHandler(service SubjectService) *SubjectHandler {
 }
 
 type SubjectResponse struct {
-	ID     string `json:"id"`
-	Slug   string `json:"slug"`
-	NameRU string `json:"name_ru"`
-	NameKZ string `json:"name_kz"`
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
 }
 
 type GetAllSubjectsResponse struct {
@@ -35,7 +34,7 @@ type GetAllSubjectsResponse struct {
 
 // GetAllSubjects godoc
 // @Summary Get all subjects
-// @Description Get list of available subjects
+// @Description Get list of available subjects. Names are localized by the Accept-Language header or the user's locale
 // @Tags subjects
 // @Produce json
 // @Success 200 {array} GetAllSubjectsResponse
@@ -52,10 +51,9 @@ func (h *SubjectHandler) GetAllSubjects(c *gin.Context) {
 	subs := make([]SubjectResponse, 0, len(subjects))
 	for _, s := range subjects {
 		subs = append(subs, SubjectResponse{
-			ID:     s.ID,
-			Slug:   s.Slug,
-			NameRU: s.NameRu,
-			NameKZ: s.NameKz,
+			ID:   s.ID,
+			Slug: s.Slug,
+			Name: s.Name,
 		})
 	}
 
@@ -3,9 +3,16 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"backend/internal/entities"
+	"backend/internal/services/authz"
 	"backend/internal/services/student"
 
 	"github.com/gin-gonic/gin"
@@ -13,10 +20,17 @@ import (
 )
 
 type TestService interface {
-	CreateFullTest(ctx context.Context, test *entities.Test) error
-	GetTestByModule(ctx context.Context, moduleID string) (*entities.Test, error)
-	UpdateFullTest(ctx context.Context, test *entities.Test) error
-	DeleteTest(ctx context.Context, testID string) error
+	CreateFullTest(ctx context.Context, actor authz.Actor, test *entities.Test) error
+	GetTestView(ctx context.Context, actor authz.Actor, moduleID string) (*entities.Test, error)
+	GetTestWithAnswers(ctx context.Context, actor authz.Actor, moduleID string) (*entities.Test, error)
+	UpdateFullTest(ctx context.Context, actor authz.Actor, test *entities.Test) error
+	DeleteTest(ctx context.Context, actor authz.Actor, testID string) error
+	GetAttempts(ctx context.Context, actor authz.Actor, testID, studentID string) ([]entities.TestAttempt, error)
+
+	GetBankQuestions(ctx context.Context, actor authz.Actor, courseID string) ([]entities.Question, error)
+	CreateBankQuestion(ctx context.Context, actor authz.Actor, q *entities.Question) error
+	UpdateBankQuestion(ctx context.Context, actor authz.Actor, q *entities.Question) error
+	DeleteBankQuestion(ctx context.Context, actor authz.Actor, questionID string) error
 }
 
 type TestHandler struct {
@@ -28,21 +42,178 @@ func NewTestHandler(service TestService) *TestHandler {
 }
 
 type CreateAnswerRequest struct {
+	ID        string `json:"id"` // при обновлении: ID существующего варианта
 	Text      string `json:"text" binding:"required"`
 	IsCorrect bool   `json:"is_correct"`
+	MatchText string `json:"match_text"` // matching: правая часть пары
 }
 
+// CreateQuestionRequest: для ordering варианты передаются в правильном порядке,
+// для short_answer — все допустимые написания ответа.
 type CreateQuestionRequest struct {
-	Text         string                `json:"text" binding:"required"`
-	QuestionType string                `json:"question_type" binding:"required"` // single_choice
-	Answers      []CreateAnswerRequest `json:"answers" binding:"required,min=2"`
+	ID               string                `json:"id"` // при обновлении: ID существующего вопроса
+	Text             string                `json:"text" binding:"required"`
+	QuestionType     string                `json:"question_type" binding:"required"` // single_choice, multiple, short_answer, numeric, ordering, matching
+	Answers          []CreateAnswerRequest `json:"answers"`
+	NumericValue     *float64              `json:"numeric_value"`
+	NumericTolerance float64               `json:"numeric_tolerance"`
+}
+
+func (q *CreateQuestionRequest) validate() error {
+	if !entities.IsValidQuestionType(q.QuestionType) {
+		return fmt.Errorf("unknown question type %q", q.QuestionType)
+	}
+
+	correct := 0
+	for _, a := range q.Answers {
+		if a.IsCorrect {
+			correct++
+		}
+	}
+
+	switch q.QuestionType {
+	case entities.QuestionTypeSingleChoice:
+		if len(q.Answers) < 2 || correct != 1 {
+			return errors.New("single choice question needs at least two answers and exactly one correct")
+		}
+	case entities.QuestionTypeMultiple:
+		if len(q.Answers) < 2 || correct == 0 {
+			return errors.New("multiple choice question needs at least two answers and at least one correct")
+		}
+	case entities.QuestionTypeShortAnswer:
+		if len(q.Answers) == 0 {
+			return errors.New("short answer question needs at least one accepted answer")
+		}
+		for _, a := range q.Answers {
+			if strings.TrimSpace(a.Text) == "" {
+				return errors.New("short answer question has an empty accepted answer")
+			}
+		}
+	case entities.QuestionTypeNumeric:
+		if q.NumericValue == nil {
+			return errors.New("numeric question needs numeric_value")
+		}
+		if q.NumericTolerance < 0 {
+			return errors.New("numeric_tolerance must not be negative")
+		}
+		if len(q.Answers) > 0 {
+			return errors.New("numeric question must not have answers")
+		}
+	case entities.QuestionTypeOrdering:
+		if len(q.Answers) < 2 {
+			return errors.New("ordering question needs at least two items")
+		}
+	case entities.QuestionTypeMatching:
+		if len(q.Answers) < 2 {
+			return errors.New("matching question needs at least two pairs")
+		}
+		for _, a := range q.Answers {
+			if strings.TrimSpace(a.MatchText) == "" {
+				return errors.New("matching question has a pair without match_text")
+			}
+		}
+	}
+
+	return nil
 }
 
 type CreateTestRequest struct {
 	ModuleID     string                  `json:"module_id" binding:"required"`
 	Title        string                  `json:"title" binding:"required"`
 	PassingScore int                     `json:"passing_score" binding:"required"`
-	Questions    []CreateQuestionRequest `json:"questions" binding:"required,min=1"`
+	ScoringRules map[string]string       `json:"scoring_rules"` // question_type -> all_or_nothing | partial | negative
+	Questions    []CreateQuestionRequest `json:"questions"`
+
+	// 0 — без ограничения
+	MaxAttempts      int `json:"max_attempts" binding:"min=0"`
+	CooldownSeconds  int `json:"cooldown_seconds" binding:"min=0"`
+	TimeLimitSeconds int `json:"time_limit_seconds" binding:"min=0"`
+
+	// Случайные вопросы из банка курса в дополнение к questions
+	PoolRules      []PoolRuleRequest `json:"pool_rules" binding:"dive"`
+	ShuffleAnswers bool              `json:"shuffle_answers"`
+}
+
+type PoolRuleRequest struct {
+	Count      int      `json:"count" binding:"required,min=1"`
+	Tags       []string `json:"tags"`
+	Difficulty int      `json:"difficulty" binding:"min=0,max=5"` // 0 — любая
+}
+
+func (r *CreateTestRequest) validate() error {
+	for qType, mode := range r.ScoringRules {
+		if !entities.IsValidQuestionType(qType) {
+			return fmt.Errorf("scoring_rules: unknown question type %q", qType)
+		}
+		if !entities.ScoringMode(mode).IsValid() {
+			return fmt.Errorf("scoring_rules: unknown scoring mode %q", mode)
+		}
+	}
+
+	if len(r.Questions) == 0 && len(r.PoolRules) == 0 {
+		return errors.New("test needs questions or pool_rules")
+	}
+
+	for i, q := range r.Questions {
+		if err := q.validate(); err != nil {
+			return fmt.Errorf("question %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// toEntity собирает тест из запроса. keepIDs сохраняет присланные ID вопросов
+// и вариантов — это нужно при обновлении, чтобы не пересоздавать их.
+func (r *CreateTestRequest) toEntity(keepIDs bool) *entities.Test {
+	test := entities.NewTest(r.ModuleID, r.Title, r.PassingScore)
+	test.MaxAttempts = r.MaxAttempts
+	test.Cooldown = time.Duration(r.CooldownSeconds) * time.Second
+	test.TimeLimit = time.Duration(r.TimeLimitSeconds) * time.Second
+	test.ShuffleAnswers = r.ShuffleAnswers
+	for qType, mode := range r.ScoringRules {
+		test.ScoringRules[qType] = entities.ScoringMode(mode)
+	}
+	for _, rule := range r.PoolRules {
+		test.PoolRules = append(test.PoolRules, entities.PoolRule{
+			Count:      rule.Count,
+			Tags:       rule.Tags,
+			Difficulty: rule.Difficulty,
+		})
+	}
+
+	for _, qReq := range r.Questions {
+		question := entities.NewQuestion(test.ID, qReq.Text, qReq.QuestionType)
+		qReq.fill(question, keepIDs)
+		test.Questions = append(test.Questions, *question)
+	}
+
+	return test
+}
+
+// fill переносит в вопрос тип-специфичные поля и варианты ответа.
+func (q *CreateQuestionRequest) fill(question *entities.Question, keepIDs bool) {
+	if keepIDs && q.ID != "" {
+		question.ID = q.ID
+	}
+	if q.QuestionType == entities.QuestionTypeNumeric {
+		question.NumericValue = q.NumericValue
+		question.NumericTolerance = q.NumericTolerance
+	}
+
+	for i, aReq := range q.Answers {
+		// Все допустимые варианты свободного ответа считаются верными
+		isCorrect := aReq.IsCorrect || q.QuestionType == entities.QuestionTypeShortAnswer
+		answer := entities.NewAnswer(question.ID, aReq.Text, isCorrect)
+		if keepIDs && aReq.ID != "" {
+			answer.ID = aReq.ID
+		}
+		answer.OrderIndex = i
+		if q.QuestionType == entities.QuestionTypeMatching {
+			answer.MatchText = aReq.MatchText
+		}
+		question.Answers = append(question.Answers, *answer)
+	}
 }
 
 type CreateTestResponse struct {
@@ -64,19 +235,6 @@ type CreateTestResponse struct {
 // @Failure 500
 // @Router /v1/tests [post]
 func (h *TestHandler) CreateTest(c *gin.Context) {
-	role, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
-
-	if role != "teacher" && role != "admin" {
-		log.Error().Msg("only teachers can create courses")
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: "only teachers can create courses"})
-		return
-	}
-
 	var req CreateTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error().Err(err).Msg("failed to parse json request")
@@ -84,21 +242,15 @@ func (h *TestHandler) CreateTest(c *gin.Context) {
 		return
 	}
 
-	test := entities.NewTest(req.ModuleID, req.Title, req.PassingScore)
-
-	for _, qReq := range req.Questions {
-		question := entities.NewQuestion(test.ID, qReq.Text, qReq.QuestionType)
-
-		for _, aReq := range qReq.Answers {
-			answer := entities.NewAnswer(question.ID, aReq.Text, aReq.IsCorrect)
-			question.Answers = append(question.Answers, *answer)
-		}
-		test.Questions = append(test.Questions, *question)
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
 	}
 
-	if err := h.service.CreateFullTest(c.Request.Context(), test); err != nil {
-		log.Error().Err(err).Str("module_id", test.ModuleID).Str("test_id", test.ID).Msg("failed to create test")
-		c.Status(http.StatusInternalServerError)
+	test := req.toEntity(false)
+
+	if err := h.service.CreateFullTest(c.Request.Context(), actorFrom(c), test); err != nil {
+		writeAccessError(c, err, "failed to create test")
 		return
 	}
 
@@ -110,16 +262,23 @@ func (h *TestHandler) CreateTest(c *gin.Context) {
 }
 
 type AnswerResponse struct {
-	ID        string `json:"id"`
-	Text      string `json:"text"`
-	IsCorrect bool   `json:"is_correct"`
+	ID         string `json:"id"`
+	Text       string `json:"text"`
+	IsCorrect  bool   `json:"is_correct"`
+	OrderIndex *int   `json:"order_index,omitempty"`
+	MatchText  string `json:"match_text,omitempty"`
 }
 
+// QuestionResponse: для matching левые части лежат в Answers, правые — в MatchOptions.
+// Студент отправляет пары «ID левой части → ID правой».
 type QuestionResponse struct {
-	ID           string           `json:"id"`
-	Text         string           `json:"text"`
-	QuestionType string           `json:"question_type"`
-	Answers      []AnswerResponse `json:"answers"`
+	ID               string           `json:"id"`
+	Text             string           `json:"text"`
+	QuestionType     string           `json:"question_type"`
+	Answers          []AnswerResponse `json:"answers"`
+	MatchOptions     []AnswerResponse `json:"match_options,omitempty"`
+	NumericValue     *float64         `json:"numeric_value,omitempty"`
+	NumericTolerance *float64         `json:"numeric_tolerance,omitempty"`
 }
 
 type TestResponse struct {
@@ -127,12 +286,20 @@ type TestResponse struct {
 	ModuleID     string             `json:"module_id"`
 	Title        string             `json:"title"`
 	PassingScore int                `json:"passing_score"`
+	ScoringRules map[string]string  `json:"scoring_rules"`
 	Questions    []QuestionResponse `json:"questions"`
+
+	MaxAttempts      int `json:"max_attempts"`
+	CooldownSeconds  int `json:"cooldown_seconds"`
+	TimeLimitSeconds int `json:"time_limit_seconds"`
+
+	PoolRules      []PoolRuleRequest `json:"pool_rules"`
+	ShuffleAnswers bool              `json:"shuffle_answers"`
 }
 
 // GetTest godoc
 // @Summary Get test details
-// @Description Get test structure with questions and answers
+// @Description Get test settings. Questions are returned to the course author and admins only; students get them from POST /v1/student/tests/{id}/start.
 // @Tags tests
 // @Security BearerAuth
 // @Accept json
@@ -144,14 +311,8 @@ type TestResponse struct {
 // @Failure 500
 // @Router /v1/modules/{id}/test [get]
 func (h *TestHandler) GetTest(c *gin.Context) {
-	_, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
 	moduleID := c.Param("id")
-	test, err := h.service.GetTestByModule(c.Request.Context(), moduleID)
+	test, err := h.service.GetTestView(c.Request.Context(), actorFrom(c), moduleID)
 	if err != nil {
 		log.Error().Err(err).Str("module_id", moduleID).Msg("failed to get test by moduleID")
 		if errors.Is(err, entities.ErrNotFound) {
@@ -161,6 +322,7 @@ func (h *TestHandler) GetTest(c *gin.Context) {
 		c.Status(http.StatusInternalServerError)
 		return
 	}
+
 	c.JSON(http.StatusOK, mapTestToResponse(test, false))
 	log.Info().Str("module_id", moduleID).Str("test_id", test.ID).Msg("test got successfully")
 }
@@ -180,22 +342,10 @@ func (h *TestHandler) GetTest(c *gin.Context) {
 // @Failure 500
 // @Router /v1/modules/{id}/test-with-answers [get]
 func (h *TestHandler) GetTestWithAnswer(c *gin.Context) {
-	_, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
-
 	moduleID := c.Param("id")
-	test, err := h.service.GetTestByModule(c.Request.Context(), moduleID)
+	test, err := h.service.GetTestWithAnswers(c.Request.Context(), actorFrom(c), moduleID)
 	if err != nil {
-		log.Error().Err(err).Str("module_id", moduleID).Msg("failed to get test by moduleID")
-		if errors.Is(err, entities.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		c.Status(http.StatusInternalServerError)
+		writeAccessError(c, err, "failed to get test")
 		return
 	}
 	c.JSON(http.StatusOK, mapTestToResponse(test, true))
@@ -208,34 +358,110 @@ func mapTestToResponse(test *entities.Test, withCorrectAnswer bool) TestResponse
 		ModuleID:     test.ModuleID,
 		Title:        test.Title,
 		PassingScore: test.PassingScore,
+		ScoringRules: make(map[string]string, len(test.ScoringRules)),
+
+		MaxAttempts:      test.MaxAttempts,
+		CooldownSeconds:  int(test.Cooldown / time.Second),
+		TimeLimitSeconds: int(test.TimeLimit / time.Second),
+
+		PoolRules:      make([]PoolRuleRequest, 0, len(test.PoolRules)),
+		ShuffleAnswers: test.ShuffleAnswers,
+	}
+
+	for _, rule := range test.PoolRules {
+		resp.PoolRules = append(resp.PoolRules, PoolRuleRequest{
+			Count:      rule.Count,
+			Tags:       rule.Tags,
+			Difficulty: rule.Difficulty,
+		})
+	}
+
+	for qType, mode := range test.ScoringRules {
+		resp.ScoringRules[qType] = string(mode)
 	}
 
 	for _, q := range test.Questions {
-		qResp := QuestionResponse{
-			ID:           q.ID,
-			Text:         q.Text,
-			QuestionType: q.QuestionType,
+		if withCorrectAnswer {
+			resp.Questions = append(resp.Questions, mapQuestionWithAnswers(q))
+		} else {
+			resp.Questions = append(resp.Questions, mapQuestionForStudent(q))
+		}
+	}
+
+	return resp
+}
+
+func mapQuestionWithAnswers(q entities.Question) QuestionResponse {
+	qResp := QuestionResponse{
+		ID:           q.ID,
+		Text:         q.Text,
+		QuestionType: q.QuestionType,
+	}
+
+	if q.QuestionType == entities.QuestionTypeNumeric {
+		tolerance := q.NumericTolerance
+		qResp.NumericValue = q.NumericValue
+		qResp.NumericTolerance = &tolerance
+	}
+
+	for _, a := range q.Answers {
+		aResp := AnswerResponse{
+			ID:        a.ID,
+			Text:      a.Text,
+			IsCorrect: a.IsCorrect,
+			MatchText: a.MatchText,
+		}
+		if q.QuestionType == entities.QuestionTypeOrdering {
+			order := a.OrderIndex
+			aResp.OrderIndex = &order
 		}
+		qResp.Answers = append(qResp.Answers, aResp)
+	}
+
+	return qResp
+}
 
+// mapQuestionForStudent скрывает всё, что выдаёт решение: допустимые ответы
+// short_answer и numeric не отдаются, порядок ordering перемешивается, а правые
+// части matching отдаются под ключами попытки в её порядке.
+func mapQuestionForStudent(q entities.Question) QuestionResponse {
+	qResp := QuestionResponse{
+		ID:           q.ID,
+		Text:         q.Text,
+		QuestionType: q.QuestionType,
+		Answers:      []AnswerResponse{},
+	}
+
+	switch q.QuestionType {
+	case entities.QuestionTypeShortAnswer, entities.QuestionTypeNumeric:
+		return qResp
+	case entities.QuestionTypeMatching:
 		for _, a := range q.Answers {
-			if withCorrectAnswer {
-				qResp.Answers = append(qResp.Answers, AnswerResponse{
-					ID:        a.ID,
-					Text:      a.Text,
-					IsCorrect: a.IsCorrect,
-				})
-				continue
-			}
-			qResp.Answers = append(qResp.Answers, AnswerResponse{
-				ID:   a.ID,
-				Text: a.Text,
-			})
+			qResp.Answers = append(qResp.Answers, AnswerResponse{ID: a.ID, Text: a.Text})
 		}
+		for _, o := range q.MatchOptions {
+			qResp.MatchOptions = append(qResp.MatchOptions, AnswerResponse{ID: o.Key, Text: o.Text})
+		}
+		return qResp
+	}
 
-		resp.Questions = append(resp.Questions, qResp)
+	for _, a := range q.Answers {
+		qResp.Answers = append(qResp.Answers, AnswerResponse{
+			ID:   a.ID,
+			Text: a.Text,
+		})
+	}
+	if q.QuestionType == entities.QuestionTypeOrdering {
+		shuffleAnswers(qResp.Answers)
 	}
 
-	return resp
+	return qResp
+}
+
+func shuffleAnswers(answers []AnswerResponse) {
+	rand.Shuffle(len(answers), func(i, j int) {
+		answers[i], answers[j] = answers[j], answers[i]
+	})
 }
 
 // UpdateTest godoc
@@ -249,21 +475,10 @@ func mapTestToResponse(test *entities.Test, withCorrectAnswer bool) TestResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/tests/:id [put]
 func (h *TestHandler) UpdateTest(c *gin.Context) {
-	role, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
-
-	if role != "teacher" && role != "admin" {
-		log.Error().Msg("only teachers can create courses")
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: "only teachers can create courses"})
-		return
-	}
 	testID := c.Param("id")
 	var req CreateTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -271,21 +486,16 @@ func (h *TestHandler) UpdateTest(c *gin.Context) {
 		return
 	}
 
-	test := entities.NewTest(req.ModuleID, req.Title, req.PassingScore)
-	test.ID = testID
-
-	for _, qReq := range req.Questions {
-		question := entities.NewQuestion(test.ID, qReq.Text, qReq.QuestionType)
-		for _, aReq := range qReq.Answers {
-			answer := entities.NewAnswer(question.ID, aReq.Text, aReq.IsCorrect)
-			question.Answers = append(question.Answers, *answer)
-		}
-		test.Questions = append(test.Questions, *question)
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
 	}
 
-	if err := h.service.UpdateFullTest(c.Request.Context(), test); err != nil {
-		c.Status(http.StatusInternalServerError)
-		log.Error().Err(err).Str("test_id", testID).Msg("failed to update test")
+	test := req.toEntity(true)
+	test.ID = testID
+
+	if err := h.service.UpdateFullTest(c.Request.Context(), actorFrom(c), test); err != nil {
+		writeAccessError(c, err, "failed to update test")
 		return
 	}
 
@@ -301,40 +511,67 @@ func (h *TestHandler) UpdateTest(c *gin.Context) {
 // @Success 200
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Failure 500
 // @Router /v1/tests/:id [delete]
 func (h *TestHandler) DeleteTest(c *gin.Context) {
-	role, exists := c.Get("role")
-	if !exists {
-		log.Error().Msg("user unauthorized")
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "unauthorized"})
-		return
-	}
-
-	if role != "teacher" && role != "admin" {
-		log.Error().Msg("only teachers can create courses")
-		c.JSON(http.StatusForbidden, ErrorResponse{Message: "only teachers can create courses"})
-		return
-	}
 	testID := c.Param("id")
 
-	if err := h.service.DeleteTest(c.Request.Context(), testID); err != nil {
-		c.Status(http.StatusInternalServerError)
-		log.Error().Err(err).Str("test_id", testID).Msg("failed to delete test")
+	if err := h.service.DeleteTest(c.Request.Context(), actorFrom(c), testID); err != nil {
+		writeAccessError(c, err, "failed to delete test")
 		return
 	}
 	c.Status(http.StatusOK)
 	log.Info().Str("test_id", testID).Msg("test deleted successfully")
 }
 
+// SubmitAnswerRequest: answer_id/answer_ids — choice-вопросы, answer_ids в нужном
+// порядке — ordering, text — short_answer и numeric, pairs — matching
+// (ID левой части → ID правой из match_options попытки).
+type SubmitAnswerRequest struct {
+	QuestionID string            `json:"question_id"`
+	AnswerID   string            `json:"answer_id"`
+	AnswerIDs  []string          `json:"answer_ids"`
+	Text       string            `json:"text"`
+	Pairs      map[string]string `json:"pairs"`
+}
+
 type SubmitTestRequest struct {
-	TestID  string `json:"test_id" binding:"required"`
-	Answers []struct {
-		QuestionID string `json:"question_id"`
-		AnswerID   string `json:"answer_id"`
-	} `json:"answers"`
+	TestID    string                `json:"test_id" binding:"required"`
+	AttemptID string                `json:"attempt_id" binding:"required"` // из /student/tests/{id}/start
+	Answers   []SubmitAnswerRequest `json:"answers"`
 }
 
+type QuestionResultResponse struct {
+	QuestionID        string   `json:"question_id"`
+	SelectedAnswerIDs []string `json:"selected_answer_ids"`
+	IsCorrect         bool     `json:"is_correct"`
+	Points            float64  `json:"points"`
+}
+
+type SubmitTestResponse struct {
+	IsPassed  bool                     `json:"is_passed"`
+	Score     int                      `json:"score"`
+	XPGained  int                      `json:"xp_gained"`
+	LevelUp   *LevelUpResponse         `json:"level_up"`
+	Questions []QuestionResultResponse `json:"questions"`
+}
+
+// SubmitTest godoc
+// @Summary Submit test answers
+// @Description Grades the submission. answer_ids is used for multi-select and ordering questions, answer_id is kept for single choice, text for short answer and numeric, pairs for matching (left answer ID to the match option ID served in the attempt).
+// @Tags student
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body SubmitTestRequest true "Answers"
+// @Success 200 {object} SubmitTestResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/student/tests/submit [post]
 func (h *StudentHandler) SubmitTest(c *gin.Context) {
 	userID := c.GetString("user_id")
 	var req SubmitTestRequest
@@ -346,18 +583,222 @@ func (h *StudentHandler) SubmitTest(c *gin.Context) {
 	// Мапим в структуру сервиса
 	srvAnswers := make([]student.StudentAnswer, len(req.Answers))
 	for i, a := range req.Answers {
-		srvAnswers[i] = student.StudentAnswer{QuestionID: a.QuestionID, AnswerID: a.AnswerID}
+		ids := a.AnswerIDs
+		if a.AnswerID != "" {
+			ids = append(ids, a.AnswerID)
+		}
+		srvAnswers[i] = student.StudentAnswer{
+			QuestionID: a.QuestionID,
+			AnswerIDs:  ids,
+			Text:       a.Text,
+			Pairs:      a.Pairs,
+		}
 	}
 
-	res, xp, err := h.service.SubmitTest(c.Request.Context(), userID, req.TestID, srvAnswers)
+	res, err := h.service.SubmitTest(c.Request.Context(), userID, req.TestID, req.AttemptID, srvAnswers)
 	if err != nil {
+		if writeAttemptError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"is_passed": res.IsPassed,
-		"score":     res.Score,
-		"xp_gained": xp,
+	questions := make([]QuestionResultResponse, 0, len(res.Questions))
+	for _, q := range res.Questions {
+		questions = append(questions, QuestionResultResponse{
+			QuestionID:        q.QuestionID,
+			SelectedAnswerIDs: q.SelectedAnswerIDs,
+			IsCorrect:         q.IsCorrect,
+			Points:            q.Points,
+		})
+	}
+
+	c.JSON(http.StatusOK, SubmitTestResponse{
+		IsPassed:  res.Result.IsPassed,
+		Score:     res.Result.Score,
+		XPGained:  res.XPGained,
+		LevelUp:   newLevelUpResponse(res.LevelUp),
+		Questions: questions,
+	})
+}
+
+type StartTestResponse struct {
+	AttemptID        string     `json:"attempt_id"`
+	StartedAt        time.Time  `json:"started_at"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	TimeLimitSeconds int        `json:"time_limit_seconds"`
+	AttemptsUsed     int        `json:"attempts_used"`
+	MaxAttempts      int        `json:"max_attempts"`
+	// Вопросы этой попытки; для тестов с банком вопросов отличаются от попытки к попытке
+	Test TestResponse `json:"test"`
+}
+
+// StartTest godoc
+// @Summary Start a test attempt
+// @Description Issues an attempt token with a deadline and the questions served in this attempt. Returns the open attempt if there is one.
+// @Tags student
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Test ID"
+// @Success 200 {object} StartTestResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Attempt limit reached"
+// @Failure 429 {object} ErrorResponse "Cooldown is active, see Retry-After"
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/student/tests/{id}/start [post]
+func (h *StudentHandler) StartTest(c *gin.Context) {
+	userID := c.GetString("user_id")
+	testID := c.Param("id")
+
+	start, err := h.service.StartTest(c.Request.Context(), userID, testID)
+	if err != nil {
+		if writeAttemptError(c, err) {
+			return
+		}
+		log.Error().Err(err).Str("test_id", testID).Msg("failed to start test attempt")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to start attempt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, StartTestResponse{
+		AttemptID:        start.Session.ID,
+		StartedAt:        start.Session.StartedAt,
+		Deadline:         start.Session.Deadline,
+		TimeLimitSeconds: int(start.TimeLimit / time.Second),
+		AttemptsUsed:     start.AttemptsUsed,
+		MaxAttempts:      start.MaxAttempts,
+		Test:             mapTestToResponse(start.Test, false),
 	})
 }
+
+// writeAttemptError отвечает на ошибки ограничений попыток. Возвращает false,
+// если ошибка к ним не относится.
+func writeAttemptError(c *gin.Context, err error) bool {
+	var cooldown *entities.CooldownError
+	switch {
+	case errors.As(err, &cooldown):
+		seconds := int(math.Ceil(cooldown.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{Message: err.Error()})
+	case errors.Is(err, entities.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "test not found"})
+	case errors.Is(err, entities.ErrAttemptRequired), errors.Is(err, entities.ErrAttemptInvalid):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+	case errors.Is(err, entities.ErrAttemptLimitReached), errors.Is(err, entities.ErrAttemptAlreadySubmitted):
+		c.JSON(http.StatusConflict, ErrorResponse{Message: err.Error()})
+	case errors.Is(err, entities.ErrAttemptExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Message: err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+type AttemptAnswerResponse struct {
+	QuestionID      string            `json:"question_id"`
+	QuestionText    string            `json:"question_text"`
+	QuestionType    string            `json:"question_type"`
+	SelectedAnswers []AnswerResponse  `json:"selected_answers"`
+	Text            string            `json:"text,omitempty"`
+	Pairs           map[string]string `json:"pairs,omitempty"`
+	IsCorrect       bool              `json:"is_correct"`
+	Points          float64           `json:"points"`
+}
+
+type AttemptResponse struct {
+	ResultID    string                  `json:"result_id"`
+	UserID      string                  `json:"user_id"`
+	Score       int                     `json:"score"`
+	IsPassed    bool                    `json:"is_passed"`
+	AttemptDate time.Time               `json:"attempt_date"`
+	Answers     []AttemptAnswerResponse `json:"answers"`
+}
+
+func mapAttemptsToResponse(attempts []entities.TestAttempt) []AttemptResponse {
+	resp := make([]AttemptResponse, 0, len(attempts))
+	for _, a := range attempts {
+		item := AttemptResponse{
+			ResultID:    a.Result.ID,
+			UserID:      a.Result.UserID,
+			Score:       a.Result.Score,
+			IsPassed:    a.Result.IsPassed,
+			AttemptDate: a.Result.AttemptDate,
+			Answers:     make([]AttemptAnswerResponse, 0, len(a.Answers)),
+		}
+
+		for _, ans := range a.Answers {
+			selected := make([]AnswerResponse, 0, len(ans.AnswerIDs))
+			for i, id := range ans.AnswerIDs {
+				text := ""
+				if i < len(ans.AnswerTexts) {
+					text = ans.AnswerTexts[i]
+				}
+				selected = append(selected, AnswerResponse{ID: id, Text: text})
+			}
+
+			item.Answers = append(item.Answers, AttemptAnswerResponse{
+				QuestionID:      ans.QuestionID,
+				QuestionText:    ans.QuestionText,
+				QuestionType:    ans.QuestionType,
+				SelectedAnswers: selected,
+				Text:            ans.TextAnswer,
+				Pairs:           ans.Pairs,
+				IsCorrect:       ans.IsCorrect,
+				Points:          ans.Points,
+			})
+		}
+
+		resp = append(resp, item)
+	}
+	return resp
+}
+
+// GetTestAttempts godoc
+// @Summary Get my attempts for a test
+// @Description Lists the student's attempts, newest first, with the chosen answers and per-question correctness
+// @Tags student
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Test ID"
+// @Success 200 {array} AttemptResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/student/tests/{id}/attempts [get]
+func (h *StudentHandler) GetTestAttempts(c *gin.Context) {
+	userID := c.GetString("user_id")
+	testID := c.Param("id")
+
+	attempts, err := h.service.GetTestAttempts(c.Request.Context(), userID, testID)
+	if err != nil {
+		log.Error().Err(err).Str("test_id", testID).Msg("failed to get test attempts")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "failed to get attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapAttemptsToResponse(attempts))
+}
+
+// GetTestAttempts godoc
+// @Summary Review students' attempts for a test
+// @Description Lists attempts for a test in the teacher's own course. Filter by student with student_id.
+// @Tags tests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Test ID"
+// @Param student_id query string false "Student ID"
+// @Success 200 {array} AttemptResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /v1/teacher/tests/{id}/attempts [get]
+func (h *TestHandler) GetTestAttempts(c *gin.Context) {
+	testID := c.Param("id")
+
+	attempts, err := h.service.GetAttempts(c.Request.Context(), actorFrom(c), testID, c.Query("student_id"))
+	if err != nil {
+		writeAccessError(c, err, "failed to get attempts")
+		return
+	}
+
+	c.JSON(http.StatusOK, mapAttemptsToResponse(attempts))
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+	"backend/internal/services/authz"
+	"backend/internal/services/student"
+	testService "backend/internal/services/testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteAttemptError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{"cooldown rounds up", &entities.CooldownError{RetryAfter: 90*time.Second + time.Millisecond}, http.StatusTooManyRequests, "91"},
+		{"wrapped cooldown", fmt.Errorf("start: %w", &entities.CooldownError{RetryAfter: time.Minute}), http.StatusTooManyRequests, "60"},
+		{"test not found", entities.ErrNotFound, http.StatusNotFound, ""},
+		{"no attempt", entities.ErrAttemptRequired, http.StatusBadRequest, ""},
+		{"foreign attempt", entities.ErrAttemptInvalid, http.StatusBadRequest, ""},
+		{"attempt limit", entities.ErrAttemptLimitReached, http.StatusConflict, ""},
+		{"double submit", entities.ErrAttemptAlreadySubmitted, http.StatusConflict, ""},
+		{"deadline passed", entities.ErrAttemptExpired, http.StatusGone, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			if !writeAttemptError(c, tt.err) {
+				t.Fatal("writeAttemptError() = false")
+			}
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if writeAttemptError(c, errors.New("db is down")) {
+		t.Error("writeAttemptError() handled an unrelated error")
+	}
+}
+
+// attemptStore отдаёт попытки так же, как GetAttempts в Postgres:
+// пустой userID — попытки всех студентов.
+type attemptStore struct {
+	attempts []entities.TestAttempt
+}
+
+func (r *attemptStore) GetAttempts(_ context.Context, testID, userID string, _ int) ([]entities.TestAttempt, error) {
+	var res []entities.TestAttempt
+	for _, a := range r.attempts {
+		if a.Result.TestID == testID && (userID == "" || a.Result.UserID == userID) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+type studentAttempts struct {
+	student.TestRepository
+	store *attemptStore
+}
+
+func (r studentAttempts) GetAttempts(ctx context.Context, testID, userID string, limit int) ([]entities.TestAttempt, error) {
+	return r.store.GetAttempts(ctx, testID, userID, limit)
+}
+
+type teacherAttempts struct {
+	testService.TestRepository
+	store *attemptStore
+}
+
+func (r teacherAttempts) GetAttempts(ctx context.Context, testID, userID string, limit int) ([]entities.TestAttempt, error) {
+	return r.store.GetAttempts(ctx, testID, userID, limit)
+}
+
+func newAttemptStore() *attemptStore {
+	return &attemptStore{attempts: []entities.TestAttempt{
+		{
+			Result: entities.TestResult{ID: "r1", UserID: "s1", TestID: "t1", Score: 50},
+			Answers: []entities.TestAttemptAnswer{{
+				QuestionID:  "q1",
+				AnswerIDs:   []string{"a1"},
+				AnswerTexts: []string{"Астана"},
+				IsCorrect:   true,
+				Points:      1,
+			}},
+		},
+		{Result: entities.TestResult{ID: "r2", UserID: "s2", TestID: "t1"}},
+	}}
+}
+
+// testAuthors — автор теста t1 для authz.Policy.
+type testAuthors struct {
+	authz.Repository
+}
+
+func (testAuthors) GetTestAuthorID(_ context.Context, id string) (string, error) {
+	if id != "t1" {
+		return "", entities.ErrNotFound
+	}
+	return "author", nil
+}
+
+// getAttempts выполняет запрос от имени userID с ролью role.
+func getAttempts(t *testing.T, handler gin.HandlerFunc, userID, role, target string) ([]AttemptResponse, int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/tests/:id/attempts", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		handler(c)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if w.Code != http.StatusOK {
+		return nil, w.Code
+	}
+
+	var resp []AttemptResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp, w.Code
+}
+
+func resultIDs(attempts []AttemptResponse) []string {
+	ids := make([]string, 0, len(attempts))
+	for _, a := range attempts {
+		ids = append(ids, a.ResultID)
+	}
+	return ids
+}
+
+func TestStudentGetTestAttemptsReturnsOnlyOwn(t *testing.T) {
+	repo := studentAttempts{store: newAttemptStore()}
+	svc := student.NewStudentService(nil, nil, nil, nil, nil, repo, nil, nil, nil, nil, nil, nil)
+	h := NewStudentHandler(svc)
+
+	// student_id студенческий эндпоинт не читает
+	attempts, code := getAttempts(t, h.GetTestAttempts, "s1", "student", "/tests/t1/attempts?student_id=s2")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if ids := resultIDs(attempts); len(ids) != 1 || ids[0] != "r1" {
+		t.Fatalf("attempts = %v, want [r1]", ids)
+	}
+
+	answers := attempts[0].Answers
+	if len(answers) != 1 || !answers[0].IsCorrect ||
+		len(answers[0].SelectedAnswers) != 1 || answers[0].SelectedAnswers[0].Text != "Астана" {
+		t.Errorf("answers = %+v, want the saved snapshot", answers)
+	}
+}
+
+func TestTeacherGetTestAttemptsRequiresCourseAccess(t *testing.T) {
+	svc := testService.NewTestService(teacherAttempts{store: newAttemptStore()}, authz.NewPolicy(testAuthors{}))
+	h := NewTestHandler(svc)
+
+	tests := []struct {
+		name         string
+		userID, role string
+		target       string
+		status       int
+		want         int
+	}{
+		{"author sees all students", "author", "teacher", "/tests/t1/attempts", http.StatusOK, 2},
+		{"author filters by student", "author", "teacher", "/tests/t1/attempts?student_id=s2", http.StatusOK, 1},
+		{"admin", "root", "admin", "/tests/t1/attempts", http.StatusOK, 2},
+		{"other teacher", "stranger", "teacher", "/tests/t1/attempts", http.StatusForbidden, 0},
+		{"student", "s1", "student", "/tests/t1/attempts", http.StatusForbidden, 0},
+		{"unknown test", "author", "teacher", "/tests/t9/attempts", http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, code := getAttempts(t, h.GetTestAttempts, tt.userID, tt.role, tt.target)
+			if code != tt.status {
+				t.Fatalf("status = %d, want %d", code, tt.status)
+			}
+			if len(attempts) != tt.want {
+				t.Errorf("got %d attempts, want %d", len(attempts), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"backend/internal/entities"
+	"backend/internal/services/scheduler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type WeeklyResetHandler struct {
+	service *scheduler.WeeklyResetService
+}
+
+func NewWeeklyResetHandler(service *scheduler.WeeklyResetService) *WeeklyResetHandler {
+	return &WeeklyResetHandler{service: service}
+}
+
+type LeagueMoveResponse struct {
+	UserID       string `json:"user_id"`
+	CohortID     string `json:"cohort_id,omitempty"`
+	Rank         int    `json:"rank"`
+	WeeklyXP     int64  `json:"weekly_xp"`
+	FromLeagueID int    `json:"from_league_id"`
+	ToLeagueID   int    `json:"to_league_id"`
+	Outcome      string `json:"outcome"`
+}
+
+type LeagueResetPlanResponse struct {
+	LeagueID int                  `json:"league_id"`
+	Cohorts  int                  `json:"cohorts"`
+	Active   int                  `json:"active"`
+	Inactive int                  `json:"inactive"`
+	Promoted []LeagueMoveResponse `json:"promoted"`
+	Demoted  []LeagueMoveResponse `json:"demoted"`
+}
+
+type WeeklyResetPlanResponse struct {
+	WeekStart   time.Time                 `json:"week_start"`
+	LastReset   *time.Time                `json:"last_reset"`
+	Due         bool                      `json:"due"`
+	PeriodStart time.Time                 `json:"period_start"`
+	PeriodEnd   time.Time                 `json:"period_end"`
+	Leagues     []LeagueResetPlanResponse `json:"leagues"`
+}
+
+func newLeagueMoves(moves []entities.LeagueMove) []LeagueMoveResponse {
+	list := make([]LeagueMoveResponse, 0, len(moves))
+	for _, m := range moves {
+		list = append(list, LeagueMoveResponse{
+			UserID:       m.UserID,
+			CohortID:     m.CohortID,
+			Rank:         m.Rank,
+			WeeklyXP:     m.WeeklyXP,
+			FromLeagueID: m.FromLeagueID,
+			ToLeagueID:   m.ToLeagueID,
+			Outcome:      string(m.Outcome),
+		})
+	}
+	return list
+}
+
+// PreviewWeeklyReset godoc
+// @Summary Preview weekly league reset
+// @Description Dry run: reports league sizes, promotions and demotions the weekly reset would apply now. Nothing is written.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} WeeklyResetPlanResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500
+// @Router /v1/admin/leagues/weekly-reset/preview [get]
+func (h *WeeklyResetHandler) PreviewWeeklyReset(c *gin.Context) {
+	plan, err := h.service.Preview(c.Request.Context())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		log.Error().Err(err).Msg("failed to preview weekly reset")
+		return
+	}
+
+	response := WeeklyResetPlanResponse{
+		WeekStart:   plan.WeekStart,
+		Due:         plan.Due,
+		PeriodStart: plan.PeriodStart,
+		PeriodEnd:   plan.PeriodEnd,
+		Leagues:     make([]LeagueResetPlanResponse, 0, len(plan.Leagues)),
+	}
+	if !plan.LastReset.IsZero() {
+		response.LastReset = &plan.LastReset
+	}
+	for _, lp := range plan.Leagues {
+		response.Leagues = append(response.Leagues, LeagueResetPlanResponse{
+			LeagueID: lp.LeagueID,
+			Cohorts:  lp.Cohorts,
+			Active:   lp.Active,
+			Inactive: lp.Inactive,
+			Promoted: newLeagueMoves(lp.Promoted),
+			Demoted:  newLeagueMoves(lp.Demoted),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
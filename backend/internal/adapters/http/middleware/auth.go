@@ -1,15 +1,22 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
+	"backend/internal/entities"
 	"backend/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
+// SessionChecker сообщает, не отозвана ли сессия, под которую выпущен токен.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+func AuthMiddleware(jwtManager *jwt.JWTManager, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -33,10 +40,34 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// Токены без sid выпущены до появления сессий. Отозвать их нельзя,
+		// поэтому они живут до своего exp (не больше 24 часов после деплоя),
+		// чтобы обновление не разлогинило всех разом. Новые токены всегда с sid.
+		if claims.SessionID != "" {
+			active, err := sessions.IsSessionActive(c.Request.Context(), claims.SessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		// Сохраняем данные пользователя в контекст
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		c.Set("email_verified", claims.EmailVerified)
+
+		// Выбранный пользователем язык важнее Accept-Language
+		if locale := entities.Locale(claims.Locale); locale.IsValid() {
+			SetLocale(c, locale)
+		}
 
 		c.Next()
 	}
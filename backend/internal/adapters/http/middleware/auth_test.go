@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+// fakeSessions считает активными перечисленные сессии и запоминает проверки.
+type fakeSessions struct {
+	active  map[string]bool
+	checked []string
+}
+
+func (f *fakeSessions) IsSessionActive(_ context.Context, sessionID string) (bool, error) {
+	f.checked = append(f.checked, sessionID)
+	return f.active[sessionID], nil
+}
+
+// legacyToken — токен в формате до появления сессий: 24 часа, без sid.
+func legacyToken(t *testing.T, issuedAt time.Time) string {
+	t.Helper()
+	claims := gojwt.MapClaims{
+		"user_id": "u1",
+		"email":   "u1@example.com",
+		"role":    "student",
+		"iat":     issuedAt.Unix(),
+		"exp":     issuedAt.Add(24 * time.Hour).Unix(),
+	}
+	token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign legacy token: %v", err)
+	}
+	return token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := jwt.NewJWTManager(testSecret, time.Minute)
+
+	current, _, err := manager.Generate("u1", "u1@example.com", "student", "s-active", true, "ru")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	revoked, _, err := manager.Generate("u1", "u1@example.com", "student", "s-revoked", true, "ru")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		status      int
+		wantChecked bool
+	}{
+		{"active session", current, http.StatusOK, true},
+		{"revoked session", revoked, http.StatusUnauthorized, true},
+		{"legacy token within its 24h", legacyToken(t, time.Now().Add(-23*time.Hour)), http.StatusOK, false},
+		{"expired legacy token", legacyToken(t, time.Now().Add(-25*time.Hour)), http.StatusUnauthorized, false},
+		{"garbage", "not-a-token", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessions{active: map[string]bool{"s-active": true}}
+			var userID string
+
+			r := gin.New()
+			r.GET("/", AuthMiddleware(manager, sessions), func(c *gin.Context) {
+				userID = c.GetString("user_id")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && userID != "u1" {
+				t.Errorf("user_id = %q, want u1", userID)
+			}
+			if checked := len(sessions.checked) > 0; checked != tt.wantChecked {
+				t.Errorf("session checked = %v, want %v", checked, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale выбирает язык ответа по заголовку Accept-Language и кладёт его
+// в context запроса, откуда его берут репозитории. Для авторизованных
+// запросов AuthMiddleware затем подставляет язык из профиля пользователя.
+// Ответ зависит от Accept-Language, поэтому он указан в Vary для кэшей.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		SetLocale(c, negotiateLocale(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// SetLocale меняет язык текущего запроса.
+func SetLocale(c *gin.Context, locale entities.Locale) {
+	c.Set("locale", string(locale))
+	c.Request = c.Request.WithContext(entities.WithLocale(c.Request.Context(), locale))
+	c.Header("Content-Language", string(locale))
+}
+
+// negotiateLocale возвращает первый поддерживаемый язык из Accept-Language
+// с учётом q-весов. Регион не важен (kk-KZ -> kk); kz встречается
+// вместо kk достаточно часто, чтобы его принимать.
+func negotiateLocale(header string) entities.Locale {
+	type candidate struct {
+		locale entities.Locale
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if lang == "kz" {
+			lang = string(entities.LocaleKK)
+		}
+		if locale := entities.Locale(lang); locale.IsValid() {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return entities.DefaultLocale
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].locale
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		header string
+		want   entities.Locale
+	}{
+		{"", entities.DefaultLocale},
+		{"en", entities.LocaleEN},
+		{"kk-KZ,ru;q=0.8", entities.LocaleKK},
+		{"kz", entities.LocaleKK},
+		{"de-DE,en;q=0.5,ru;q=0.9", entities.LocaleRU},
+		{"en;q=0,kk;q=0.1", entities.LocaleKK},
+		{"fr,de", entities.DefaultLocale},
+	}
+	for _, tt := range tests {
+		if got := negotiateLocale(tt.header); got != tt.want {
+			t.Errorf("negotiateLocale(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleSetsVary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Locale())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Language" {
+		t.Errorf("Vary = %v, want [Accept-Language]", got)
+	}
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Errorf("Content-Language = %q, want en", got)
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/adapters/http/handlers"
+	"backend/internal/services/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitStore хранит token bucket'ы. Take пополняет корзину key
+// (не больше burst токенов, perSecond в секунду) и, если в ней есть
+// целый токен, забирает его. Возвращает остаток после запроса и то,
+// пропущен ли запрос.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, burst int, perSecond float64) (tokens float64, allowed bool, err error)
+}
+
+// Limit — Requests запросов за Per; столько же можно сделать подряд.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// KeyFunc выбирает, чей лимит расходует запрос.
+type KeyFunc func(c *gin.Context) string
+
+// ByIP считает запросы по IP клиента. X-Forwarded-For учитывается только
+// от доверенных прокси роутера (SetTrustedProxies), иначе ключ берётся из
+// соединения и подменить его заголовком нельзя.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser считает запросы по пользователю из JWT, а для анонимных — по IP.
+// Ставится после AuthMiddleware.
+func ByUser(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return ByIP(c)
+}
+
+// ByEmailAndIP считает попытки входа по паре email и IP: перебор пароля
+// к одному аккаунту упирается в лимит, а ученики за общим NAT школы не
+// расходуют лимит друг друга. Email берётся из JSON-тела запроса.
+func ByEmailAndIP(c *gin.Context) string {
+	var body struct {
+		Email string `json:"email"`
+	}
+	peekJSON(c, &body)
+	return "email:" + strings.ToLower(strings.TrimSpace(body.Email)) + ":" + ByIP(c)
+}
+
+// ByRefreshSession считает обновления токена по сессии: refresh-токен
+// меняется при каждом обмене, а сессия остаётся. Для токенов без сессии
+// и для мусора в теле — по IP.
+func ByRefreshSession(c *gin.Context) string {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	peekJSON(c, &body)
+	if sessionID := auth.RefreshTokenSession(body.RefreshToken); sessionID != "" {
+		return "session:" + sessionID
+	}
+	return ByIP(c)
+}
+
+// peekJSON разбирает JSON-тело в dst и возвращает тело на место, чтобы
+// его смог прочитать обработчик. Ошибки разбора оставляют dst пустым:
+// невалидный запрос отклонит сам обработчик.
+func peekJSON(c *gin.Context, dst any) {
+	if c.Request.Body == nil {
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBody+1))
+	if err != nil {
+		return
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+	if len(raw) > maxPeekBody {
+		return
+	}
+	_ = json.Unmarshal(raw, dst)
+}
+
+// Тела входа и обновления токена — десятки байт; больше не читаем.
+const maxPeekBody = 4 << 10
+
+// PerRoute даёт каждому маршруту группы отдельный лимит.
+func PerRoute(key KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		return c.Request.Method + " " + c.FullPath() + ":" + key(c)
+	}
+}
+
+// RateLimit ограничивает частоту запросов. name отделяет корзины разных
+// групп маршрутов друг от друга. Ответ несёт заголовки RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset, а при отказе — 429 и Retry-After.
+// Если хранилище недоступно, запрос пропускается: лимитер не должен
+// ронять API.
+func RateLimit(store RateLimitStore, name string, limit Limit, key KeyFunc) gin.HandlerFunc {
+	perSecond := limit.perSecond()
+
+	return func(c *gin.Context) {
+		tokens, allowed, err := store.Take(c.Request.Context(), name+":"+key(c), limit.Requests, perSecond)
+		if err != nil {
+			log.Error().Err(err).Str("limit", name).Msg("rate limit store failed, request let through")
+			c.Next()
+			return
+		}
+
+		tokens = math.Max(tokens, 0)
+		reset := math.Ceil((float64(limit.Requests) - tokens) / perSecond)
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(int(tokens)))
+		h.Set("RateLimit-Reset", strconv.Itoa(int(reset)))
+
+		if !allowed {
+			retryAfter := math.Ceil((1 - tokens) / perSecond)
+			h.Set("Retry-After", strconv.Itoa(int(math.Max(retryAfter, 1))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, handlers.ErrorResponse{Message: "too many requests"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// до этого момента корзина наполнится сама, и её можно забыть
+	fullAt time.Time
+}
+
+// MemoryRateLimitStore держит корзины в памяти процесса. Подходит, когда
+// API запущен в одном экземпляре; для нескольких реплик нужен общий store.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, burst int, perSecond float64) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.tokens = math.Min(float64(burst), b.tokens+math.Max(elapsed, 0)*perSecond)
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	missing := float64(burst) - b.tokens
+	b.fullAt = now.Add(time.Duration(missing / perSecond * float64(time.Second)))
+
+	return b.tokens, allowed, nil
+}
+
+// sweep раз в минуту удаляет корзины, которые уже наполнились:
+// новая корзина для того же ключа будет такой же.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClock — часы, которые идут только по команде.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore() (*MemoryRateLimitStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)}
+	s := NewMemoryRateLimitStore()
+	s.now = clock.now
+	return s, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	s, clock := newTestStore()
+	const burst, perSecond = 3, 2.0
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		wantTokens  float64
+		wantAllowed bool
+	}{
+		{"new bucket starts full", 0, 2, true},
+		{"burst", 0, 1, true},
+		{"burst", 0, 0, true},
+		{"empty", 0, 0, false},
+		{"half a token is not enough", 250 * time.Millisecond, 0.5, false},
+		{"refilled to a whole token", 250 * time.Millisecond, 0, true},
+		{"refill stops at burst", time.Hour, 2, true},
+	}
+	for i, step := range steps {
+		clock.advance(step.advance)
+		tokens, allowed, err := s.Take(context.Background(), "k", burst, perSecond)
+		if err != nil {
+			t.Fatalf("step %d: Take: %v", i+1, err)
+		}
+		if tokens != step.wantTokens || allowed != step.wantAllowed {
+			t.Errorf("step %d (%s): Take() = %v, %v; want %v, %v", i+1, step.name, tokens, allowed, step.wantTokens, step.wantAllowed)
+		}
+	}
+
+	if tokens, _, _ := s.Take(context.Background(), "other", burst, perSecond); tokens != 2 {
+		t.Errorf("other key shares the bucket: tokens = %v", tokens)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s, clock := newTestStore()
+	ctx := context.Background()
+	keys := func() []string {
+		out := make([]string, 0, len(s.buckets))
+		for k := range s.buckets {
+			out = append(out, k)
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	// fast наполнится за секунду, slow — за 100 секунд
+	s.Take(ctx, "fast", 2, 1)
+	s.Take(ctx, "slow", 2, 0.01)
+
+	clock.advance(30 * time.Second)
+	s.Take(ctx, "probe", 1, 1)
+	if got := keys(); !slices.Equal(got, []string{"fast", "probe", "slow"}) {
+		t.Errorf("before the sweep interval: buckets = %v", got)
+	}
+
+	clock.advance(30 * time.Second)
+	s.Take(ctx, "probe", 1, 1)
+	if got := keys(); !slices.Equal(got, []string{"probe", "slow"}) {
+		t.Errorf("after a minute: buckets = %v, want full ones evicted", got)
+	}
+
+	clock.advance(time.Minute)
+	s.Take(ctx, "probe", 1, 1)
+	if got := keys(); !slices.Equal(got, []string{"probe"}) {
+		t.Errorf("after two minutes: buckets = %v", got)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, int, float64) (float64, bool, error) {
+	return 0, false, errors.New("store is down")
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s, clock := newTestStore()
+
+	// 2 запроса за 4 секунды: токен возвращается каждые 2 секунды
+	limit := Limit{Requests: 2, Per: 4 * time.Second}
+	r := gin.New()
+	r.GET("/", RateLimit(s, "test", limit, ByIP), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	steps := []struct {
+		advance    time.Duration
+		status     int
+		remaining  string
+		reset      string
+		retryAfter string
+	}{
+		{0, http.StatusOK, "1", "2", ""},
+		{0, http.StatusOK, "0", "4", ""},
+		{0, http.StatusTooManyRequests, "0", "4", "2"},
+		{time.Second, http.StatusTooManyRequests, "0", "3", "1"},
+		{time.Second, http.StatusOK, "0", "4", ""},
+		{time.Minute, http.StatusOK, "1", "2", ""},
+	}
+	for i, step := range steps {
+		clock.advance(step.advance)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		h := w.Header()
+		if w.Code != step.status || h.Get("RateLimit-Remaining") != step.remaining ||
+			h.Get("RateLimit-Reset") != step.reset || h.Get("Retry-After") != step.retryAfter {
+			t.Errorf("request %d: %d remaining=%q reset=%q retry-after=%q; want %d %q %q %q",
+				i+1, w.Code, h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), h.Get("Retry-After"),
+				step.status, step.remaining, step.reset, step.retryAfter)
+		}
+		if h.Get("RateLimit-Limit") != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q", i+1, h.Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestRateLimitLetsThroughOnStoreError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", RateLimit(failingStore{}, "test", Limit{Requests: 1, Per: time.Minute}, ByIP), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 when the store fails", w.Code)
+	}
+}
+
+func TestLoginAndRefreshKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s, _ := newTestStore()
+
+	// Обработчик читает то же тело, что и лимитер
+	echo := func(c *gin.Context) {
+		var body map[string]string
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	one := Limit{Requests: 1, Per: time.Hour}
+	r := gin.New()
+	r.POST("/login", RateLimit(s, "login", one, ByEmailAndIP), echo)
+	r.POST("/refresh", RateLimit(s, "refresh", one, ByRefreshSession), echo)
+
+	steps := []struct {
+		name   string
+		path   string
+		body   string
+		ip     string
+		status int
+	}{
+		{"first login", "/login", `{"email":"a@example.com","password":"x"}`, "10.0.0.1", http.StatusOK},
+		{"same email in another case", "/login", `{"email":" A@Example.com ","password":"x"}`, "10.0.0.1", http.StatusTooManyRequests},
+		{"other email behind the same NAT", "/login", `{"email":"b@example.com","password":"x"}`, "10.0.0.1", http.StatusOK},
+		{"same email from another IP", "/login", `{"email":"a@example.com","password":"x"}`, "10.0.0.2", http.StatusOK},
+
+		{"first refresh", "/refresh", `{"refresh_token":"s1.aaa"}`, "10.0.0.1", http.StatusOK},
+		{"rotated token of the same session", "/refresh", `{"refresh_token":"s1.bbb"}`, "10.0.0.2", http.StatusTooManyRequests},
+		{"other session", "/refresh", `{"refresh_token":"s2.aaa"}`, "10.0.0.1", http.StatusOK},
+		{"token without a session falls back to IP", "/refresh", `{"refresh_token":"legacy"}`, "10.0.0.3", http.StatusOK},
+		{"second legacy token from that IP", "/refresh", `{"refresh_token":"legacy2"}`, "10.0.0.3", http.StatusTooManyRequests},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(http.MethodPost, step.path, strings.NewReader(step.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = step.ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != step.status {
+			t.Errorf("%s: status = %d, want %d", step.name, w.Code, step.status)
+		}
+	}
+}
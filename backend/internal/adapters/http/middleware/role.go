@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"backend/internal/adapters/http/handlers"
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole пропускает запрос, только если роль из JWT входит в roles.
+// Ставится после AuthMiddleware, который кладёт роль в контекст.
+func RequireRole(roles ...entities.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := entities.UserRole(c.GetString("role"))
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.ErrorResponse{Message: "unauthorized"})
+			return
+		}
+
+		if !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, handlers.ErrorResponse{Message: "insufficient permissions"})
+			return
+		}
+
+		c.Next()
+	}
+}
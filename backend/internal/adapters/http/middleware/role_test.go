@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/adapters/http/handlers"
+	"backend/internal/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		role    string // пусто — AuthMiddleware роль не положил
+		allowed []entities.UserRole
+		status  int
+		message string
+	}{
+		{"no user in context", "", []entities.UserRole{entities.RoleStudent}, http.StatusUnauthorized, "unauthorized"},
+		{"wrong role", "student", []entities.UserRole{entities.RoleTeacher}, http.StatusForbidden, "insufficient permissions"},
+		{"unknown role", "superuser", []entities.UserRole{entities.RoleAdmin}, http.StatusForbidden, "insufficient permissions"},
+		{"single allowed role", "student", []entities.UserRole{entities.RoleStudent}, http.StatusOK, ""},
+		{"first of several roles", "teacher", []entities.UserRole{entities.RoleTeacher, entities.RoleAdmin}, http.StatusOK, ""},
+		{"second of several roles", "admin", []entities.UserRole{entities.RoleTeacher, entities.RoleAdmin}, http.StatusOK, ""},
+		{"no roles allowed", "admin", nil, http.StatusForbidden, "insufficient permissions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			r := gin.New()
+			r.GET("/",
+				func(c *gin.Context) {
+					if tt.role != "" {
+						c.Set("role", tt.role)
+					}
+				},
+				RequireRole(tt.allowed...),
+				func(c *gin.Context) {
+					reached = true
+					c.Status(http.StatusOK)
+				},
+			)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if reached != (tt.status == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+			if tt.message == "" {
+				return
+			}
+
+			var body handlers.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q is not an ErrorResponse: %v", w.Body.String(), err)
+			}
+			if body.Message != tt.message {
+				t.Errorf("message = %q, want %q", body.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"backend/internal/adapters/http/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// VerificationChecker читает из базы, подтвердил ли пользователь email.
+type VerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
+}
+
+// ReadOnlyUntilVerified пропускает пользователей с неподтверждённым email
+// только на чтение. exemptPaths — маршруты (как в c.FullPath()), которые
+// доступны и без подтверждения, например выход из аккаунта.
+// Ставится после AuthMiddleware.
+//
+// Флаг email_verified в access-токене устаревает, когда пользователь
+// подтверждает email уже после входа, поэтому при неподтверждённом флаге
+// изменяющий запрос сверяется с базой. Подтверждённых пользователей и
+// чтение это не затрагивает.
+func ReadOnlyUntilVerified(users VerificationChecker, exemptPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("email_verified") || isSafeMethod(c.Request.Method) || slices.Contains(exemptPaths, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		verified, err := users.IsEmailVerified(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			log.Error().Err(err).Str("user_id", c.GetString("user_id")).Msg("failed to check email verification")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, handlers.ErrorResponse{Message: "failed to check email verification"})
+			return
+		}
+		if verified {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, handlers.ErrorResponse{Message: "email is not verified"})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
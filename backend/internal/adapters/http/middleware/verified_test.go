@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/adapters/http/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// verifiedUsers — флаг подтверждения в «базе»; calls считает обращения.
+type verifiedUsers struct {
+	verified map[string]bool
+	err      error
+	calls    int
+}
+
+func (u *verifiedUsers) IsEmailVerified(_ context.Context, userID string) (bool, error) {
+	u.calls++
+	return u.verified[userID], u.err
+}
+
+func TestReadOnlyUntilVerified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		claim       bool // email_verified из access-токена
+		dbVerified  bool
+		dbErr       error
+		status      int
+		message     string
+		wantDBCheck bool
+	}{
+		{"verified claim writes", http.MethodPost, "/courses", true, true, nil, http.StatusOK, "", false},
+		{"unverified reads", http.MethodGet, "/courses", false, false, nil, http.StatusOK, "", false},
+		{"unverified HEAD", http.MethodHead, "/courses", false, false, nil, http.StatusOK, "", false},
+		{"unverified OPTIONS", http.MethodOptions, "/courses", false, false, nil, http.StatusOK, "", false},
+		{"unverified write", http.MethodPost, "/courses", false, false, nil, http.StatusForbidden, "email is not verified", true},
+		{"unverified DELETE", http.MethodDelete, "/courses", false, false, nil, http.StatusForbidden, "email is not verified", true},
+		{"logout is allowed", http.MethodPost, "/auth/logout", false, false, nil, http.StatusOK, "", false},
+		{"locale is allowed", http.MethodPut, "/me/locale", false, false, nil, http.StatusOK, "", false},
+		{"stale claim, verified in the database", http.MethodPost, "/courses", false, true, nil, http.StatusOK, "", true},
+		{"database error", http.MethodPost, "/courses", false, false, errors.New("db is down"), http.StatusInternalServerError, "failed to check email verification", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &verifiedUsers{verified: map[string]bool{"u1": tt.dbVerified}, err: tt.dbErr}
+			reached := false
+			r := gin.New()
+			r.Use(
+				func(c *gin.Context) {
+					c.Set("user_id", "u1")
+					c.Set("email_verified", tt.claim)
+				},
+				ReadOnlyUntilVerified(users, "/auth/logout", "/me/locale"),
+			)
+			ok := func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			}
+			r.Handle(tt.method, "/courses", ok)
+			r.POST("/auth/logout", ok)
+			r.PUT("/me/locale", ok)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if reached != (tt.status == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+			if (users.calls > 0) != tt.wantDBCheck {
+				t.Errorf("database checked %d times, want check = %v", users.calls, tt.wantDBCheck)
+			}
+			if tt.message == "" {
+				return
+			}
+			var body handlers.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Message != tt.message {
+				t.Errorf("body = %s, want message %q", w.Body.String(), tt.message)
+			}
+		})
+	}
+}
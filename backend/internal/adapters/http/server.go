@@ -2,15 +2,20 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"backend/internal/adapters/http/handlers"
 	"backend/internal/adapters/http/handlers/content"
 	"backend/internal/adapters/http/middleware"
 	"backend/internal/adapters/storage"
+	"backend/internal/entities"
+	"backend/internal/services/activity"
 	"backend/internal/services/auth"
 	"backend/internal/services/course"
 	"backend/internal/services/gamification"
+	"backend/internal/services/scheduler"
 	"backend/internal/services/student"
 	"backend/internal/services/subject"
 	"backend/internal/services/testing"
@@ -34,7 +39,11 @@ type Server struct {
 	testService         *testing.TestService
 	studentService      *student.StudentService
 	gamificationService *gamification.GamificationService
+	activityTracker     *activity.Tracker
+	weeklyReset         *scheduler.WeeklyResetService
+	jobs                *scheduler.Scheduler
 	jwtManager          *jwt.JWTManager
+	rateLimitStore      middleware.RateLimitStore
 }
 
 func NewServer(
@@ -45,10 +54,17 @@ func NewServer(
 	testService *testing.TestService,
 	studentService *student.StudentService,
 	gService *gamification.GamificationService,
-	jwtSecret string,
-) *Server {
-	router := gin.Default()
-	router.Use(middleware.CORSMiddleware())
+	activityTracker *activity.Tracker,
+	weeklyReset *scheduler.WeeklyResetService,
+	jobs *scheduler.Scheduler,
+	jwtManager *jwt.JWTManager,
+	rateLimitStore middleware.RateLimitStore,
+	trustedProxies []string,
+) (*Server, error) {
+	router, err := newRouter(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
 	s := &Server{
 		router:              router,
@@ -59,13 +75,44 @@ func NewServer(
 		testService:         testService,
 		studentService:      studentService,
 		gamificationService: gService,
-		jwtManager:          jwt.NewJWTManager(jwtSecret),
+		activityTracker:     activityTracker,
+		weeklyReset:         weeklyReset,
+		jobs:                jobs,
+		jwtManager:          jwtManager,
+		rateLimitStore:      rateLimitStore,
 	}
 
 	s.setupRoutes()
-	return s
+	return s, nil
 }
 
+// newRouter создаёт gin с общими middleware. X-Forwarded-For учитывается
+// только от trustedProxies: лимиты по IP опираются на c.ClientIP(), и
+// без этого клиент мог бы подставить любой адрес. nil — прокси нет.
+func newRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	router.Use(middleware.CORSMiddleware(), middleware.Locale())
+	return router, nil
+}
+
+// Лимиты запросов. Вход считается по паре email+IP, обновление токена —
+// по сессии, регистрация и восстановление пароля — по IP; остальное — по
+// пользователю. Поверх входа и обновления есть общий лимит на IP, чтобы
+// с одного адреса нельзя было перебирать аккаунты или подделанные сессии.
+var (
+	authLimit    = middleware.Limit{Requests: 10, Per: time.Minute}
+	loginLimit   = middleware.Limit{Requests: 10, Per: time.Minute}
+	refreshLimit = middleware.Limit{Requests: 10, Per: time.Minute}
+	authIPLimit  = middleware.Limit{Requests: 100, Per: time.Minute}
+	uploadLimit  = middleware.Limit{Requests: 20, Per: time.Minute}
+	submitLimit  = middleware.Limit{Requests: 10, Per: time.Minute}
+	apiLimit     = middleware.Limit{Requests: 300, Per: time.Minute}
+	resendLimit  = middleware.Limit{Requests: 5, Per: time.Hour}
+)
+
 func (s *Server) setupRoutes() {
 	s.router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -77,16 +124,20 @@ func (s *Server) setupRoutes() {
 		c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
 	})
 
+	readOnlyUntilVerified := s.authService.VerificationMode() == auth.VerificationReadOnly
+
 	api := s.router.Group("/v1")
 	{
 		authHandler := handlers.NewAuthHandler(s.authService)
-		courseHandler := content.NewCourseHandler(s.courseService)
+		courseHandler := content.NewCourseHandler(s.courseService, s.activityTracker)
 		subjectHandler := handlers.NewSubjectHandler(s.subjectService)
 		uploadHandler := handlers.NewUploadHandler(s.uploadService)
 		testHandler := handlers.NewTestHandler(s.testService)
 		studentHandler := handlers.NewStudentHandler(s.studentService)
 		gameHandler := handlers.NewGamificationHandler(s.gamificationService)
 		leaderboarHandler := handlers.NewLeaderboardHandler(s.studentService)
+		weeklyResetHandler := handlers.NewWeeklyResetHandler(s.weeklyReset)
+		jobsHandler := handlers.NewJobsHandler(s.jobs)
 
 		api.GET("/subjects", subjectHandler.GetAllSubjects)
 		api.GET("/tags", courseHandler.GetTags)
@@ -94,61 +145,136 @@ func (s *Server) setupRoutes() {
 
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/forgot-password", authHandler.ForgotPassword)
-			auth.POST("/reset-password", authHandler.ResetPassword)
+			byIP := middleware.RateLimit(s.rateLimitStore, "auth", authLimit, middleware.PerRoute(middleware.ByIP))
+			ipCap := middleware.RateLimit(s.rateLimitStore, "auth-ip", authIPLimit, middleware.PerRoute(middleware.ByIP))
+
+			auth.POST("/register", byIP, authHandler.Register)
+			auth.POST("/login",
+				ipCap,
+				middleware.RateLimit(s.rateLimitStore, "login", loginLimit, middleware.ByEmailAndIP),
+				authHandler.Login,
+			)
+			auth.POST("/refresh",
+				ipCap,
+				middleware.RateLimit(s.rateLimitStore, "refresh", refreshLimit, middleware.ByRefreshSession),
+				authHandler.Refresh,
+			)
+			auth.POST("/forgot-password", byIP, authHandler.ForgotPassword)
+			auth.POST("/reset-password", byIP, authHandler.ResetPassword)
+			auth.POST("/verify-email", byIP, authHandler.VerifyEmail)
+			auth.POST("/resend-verification",
+				byIP,
+				middleware.RateLimit(s.rateLimitStore, "resend", resendLimit, middleware.ByIP),
+				authHandler.ResendVerification,
+			)
 		}
 
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(s.jwtManager))
+		protected.Use(
+			middleware.AuthMiddleware(s.jwtManager, s.authService),
+			middleware.RateLimit(s.rateLimitStore, "api", apiLimit, middleware.ByUser),
+		)
+		if readOnlyUntilVerified {
+			protected.Use(middleware.ReadOnlyUntilVerified(s.authService, "/v1/auth/logout", "/v1/auth/change-password", "/v1/me/locale"))
+		}
 		{
 
-			protected.POST("/upload", uploadHandler.UploadFile)
+			protected.POST("/upload",
+				middleware.RateLimit(s.rateLimitStore, "upload", uploadLimit, middleware.ByUser),
+				uploadHandler.UploadFile,
+			)
 
 			protected.POST("/auth/change-password", authHandler.ChangePassword)
+			protected.POST("/auth/logout", authHandler.Logout)
+			protected.PUT("/me/locale", authHandler.UpdateLocale)
 
 			protected.POST("/courses/:id/favorite", courseHandler.ToggleFavorite)
 			protected.GET("/courses/favorites", courseHandler.GetFavorites)
 
-			protected.POST("/courses", courseHandler.CreateCourse)
-			protected.PUT("/courses/:id", courseHandler.UpdateCourse)
-			protected.POST("/courses/:id/publish", courseHandler.ChangePublishStatus)
-			protected.DELETE("/courses/:id", courseHandler.DeleteCourse)
 			protected.GET("/courses/:id/structure", courseHandler.GetStructure)
 			protected.GET("/courses/:id", courseHandler.GetCourse)
 			protected.GET("/catalog", courseHandler.GetCatalog)
 
-			protected.GET("/teacher/courses", courseHandler.GetMyCourses)
-
-			protected.POST("/modules", courseHandler.CreateModule)
-			protected.PUT("/modules/:id", courseHandler.UpdateModule)
-			protected.DELETE("/modules/:id", courseHandler.DeleteModule)
-
-			protected.POST("/lessons", courseHandler.CreateLesson)
 			protected.GET("/lessons/:id", courseHandler.GetLesson)
-			protected.PUT("/lessons/:id", courseHandler.UpdateLesson)
-			protected.DELETE("/lessons/:id", courseHandler.DeleteLesson)
-
-			protected.POST("/tests", testHandler.CreateTest)
 			protected.GET("/modules/:id/test", testHandler.GetTest)
-			protected.GET("/modules/:id/test-with-answers", testHandler.GetTestWithAnswer)
-			protected.PUT("/tests/:id", testHandler.UpdateTest)
-			protected.DELETE("/tests/:id", testHandler.DeleteTest)
-
-			protected.POST("/student/onboarding", studentHandler.CompleteOnboarding)
-			protected.GET("/student/dashboard", studentHandler.GetDashboard)
-			protected.GET("/student/courses/:id/progress", studentHandler.GetCourseProgress)
-			protected.POST("/student/lessons/:id/complete", studentHandler.CompleteLesson)
-			protected.POST("/student/tests/submit", studentHandler.SubmitTest)
-			protected.GET("/student/my-activity-courses", studentHandler.GetAllMyActivityCourses)
-			protected.GET("/student/me", studentHandler.GetMe)
 
 			protected.GET("/leaderboard/weekly", leaderboarHandler.GetWeeklyLeaderboard)
 			protected.GET("/leaderboard/global", leaderboarHandler.GetGlobalLeaderboard)
+			protected.GET("/leaderboard/history", leaderboarHandler.GetHistory)
+			protected.GET("/leaderboard/history/:period", leaderboarHandler.GetHistoryTable)
 
 			protected.GET("/courses/recommendations", courseHandler.GetRecommendations)
 		}
+
+		// Авторинг контента. Права на конкретный курс проверяет authz.Policy в сервисах.
+		teacher := protected.Group("")
+		teacher.Use(middleware.RequireRole(entities.RoleTeacher, entities.RoleAdmin))
+		{
+			teacher.POST("/courses", courseHandler.CreateCourse)
+			teacher.PUT("/courses/:id", courseHandler.UpdateCourse)
+			teacher.POST("/courses/:id/publish", courseHandler.ChangePublishStatus)
+			teacher.DELETE("/courses/:id", courseHandler.DeleteCourse)
+			teacher.PUT("/courses/:id/translations/:locale", courseHandler.SaveCourseTranslation)
+			teacher.DELETE("/courses/:id/translations/:locale", courseHandler.DeleteCourseTranslation)
+
+			teacher.GET("/courses/:id/stats", courseHandler.GetCourseStats)
+			teacher.GET("/teacher/courses", courseHandler.GetMyCourses)
+			teacher.GET("/teacher/tests/:id/attempts", testHandler.GetTestAttempts)
+
+			teacher.POST("/modules", courseHandler.CreateModule)
+			teacher.PUT("/modules/:id", courseHandler.UpdateModule)
+			teacher.DELETE("/modules/:id", courseHandler.DeleteModule)
+			teacher.PUT("/modules/:id/translations/:locale", courseHandler.SaveModuleTranslation)
+			teacher.DELETE("/modules/:id/translations/:locale", courseHandler.DeleteModuleTranslation)
+
+			teacher.POST("/lessons", courseHandler.CreateLesson)
+			teacher.PUT("/lessons/:id", courseHandler.UpdateLesson)
+			teacher.DELETE("/lessons/:id", courseHandler.DeleteLesson)
+			teacher.PUT("/lessons/:id/translations/:locale", courseHandler.SaveLessonTranslation)
+			teacher.DELETE("/lessons/:id/translations/:locale", courseHandler.DeleteLessonTranslation)
+
+			teacher.POST("/tests", testHandler.CreateTest)
+			teacher.GET("/modules/:id/test-with-answers", testHandler.GetTestWithAnswer)
+			teacher.PUT("/tests/:id", testHandler.UpdateTest)
+			teacher.DELETE("/tests/:id", testHandler.DeleteTest)
+
+			teacher.GET("/courses/:id/question-bank", testHandler.GetBankQuestions)
+			teacher.POST("/courses/:id/question-bank", testHandler.CreateBankQuestion)
+			teacher.PUT("/question-bank/:id", testHandler.UpdateBankQuestion)
+			teacher.DELETE("/question-bank/:id", testHandler.DeleteBankQuestion)
+		}
+
+		student := protected.Group("/student")
+		student.Use(middleware.RequireRole(entities.RoleStudent))
+		{
+			student.POST("/onboarding", studentHandler.CompleteOnboarding)
+			student.GET("/dashboard", studentHandler.GetDashboard)
+			student.GET("/courses/:id/progress", studentHandler.GetCourseProgress)
+			student.POST("/lessons/:id/complete", studentHandler.CompleteLesson)
+			student.POST("/tests/:id/start", studentHandler.StartTest)
+			student.POST("/tests/submit",
+				middleware.RateLimit(s.rateLimitStore, "submit", submitLimit, middleware.ByUser),
+				studentHandler.SubmitTest,
+			)
+			student.GET("/tests/:id/attempts", studentHandler.GetTestAttempts)
+			student.GET("/my-activity-courses", studentHandler.GetAllMyActivityCourses)
+			student.GET("/me", studentHandler.GetMe)
+			student.GET("/achievements", gameHandler.GetMyAchievements)
+			student.GET("/xp-history", studentHandler.GetXPHistory)
+			student.PUT("/timezone", studentHandler.SetTimezone)
+		}
+
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireRole(entities.RoleAdmin))
+		{
+			// Служебные эндпоинты платформы
+			admin.GET("/gamification/policy", gameHandler.GetPolicy)
+			admin.PUT("/gamification/policy", gameHandler.UpdatePolicy)
+			admin.GET("/leagues/weekly-reset/preview", weeklyResetHandler.PreviewWeeklyReset)
+			admin.PUT("/leagues/:id/zones", gameHandler.UpdateLeagueZones)
+			admin.GET("/jobs", jobsHandler.ListJobs)
+			admin.GET("/jobs/:name/runs", jobsHandler.ListJobRuns)
+		}
 	}
 }
 
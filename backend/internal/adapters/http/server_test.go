@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitByIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		// ожидаемые коды ответов на запросы с разными X-Forwarded-For
+		want []int
+	}{
+		{"no proxy: header is ignored", nil, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{"trusted proxy: header names the client", []string{"10.0.0.1"}, []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := newRouter(tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("newRouter: %v", err)
+			}
+			limit := middleware.Limit{Requests: 2, Per: time.Hour}
+			router.GET("/", middleware.RateLimit(middleware.NewMemoryRateLimitStore(), "test", limit, middleware.ByIP), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for i, want := range tt.want {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = "10.0.0.1:40000"
+				req.Header.Set("X-Forwarded-For", "203.0.113."+strconv.Itoa(i+1))
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Errorf("request %d: status %d, want %d", i+1, w.Code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRouterRejectsBadProxy(t *testing.T) {
+	if _, err := newRouter([]string{"not-an-ip"}); err == nil {
+		t.Error("newRouter() = nil error for an invalid proxy")
+	}
+}
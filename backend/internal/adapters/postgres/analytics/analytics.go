@@ -6,6 +6,7 @@ import (
 
 	"backend/internal/entities"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -62,6 +63,32 @@ func (r *AnalyticsRepository) LogActivity(ctx context.Context, log *entities.Use
 	return nil
 }
 
+// LogActivities сохраняет пачку действий одним round-trip'ом.
+// Используется фоновым писателем activity.Tracker.
+func (r *AnalyticsRepository) LogActivities(ctx context.Context, logs []*entities.UserActivityLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO user_activity_logs (id, user_id, course_id, action_type, meta_data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	batch := &pgx.Batch{}
+	for _, l := range logs {
+		d := newDTO(l)
+		batch.Queue(query, d.ID, d.UserID, d.CourseID, d.ActionType, d.MetaData, d.CreatedAt)
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to log activities: %w", err)
+	}
+
+	return nil
+}
+
 // GetUserHistory возвращает последние действия пользователя (например, "Недавно просмотренные").
 // Обычно фильтруем только action_type = 'view' или 'complete'.
 func (r *AnalyticsRepository) GetUserHistory(
@@ -0,0 +1,177 @@
+package cohort
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CohortRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewCohortRepository(connectionURL string) *CohortRepository {
+	return &CohortRepository{connectionURL: connectionURL}
+}
+
+func (r *CohortRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+	return nil
+}
+
+func (r *CohortRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+// CreateCohorts создаёт count пустых когорт лиги на неделю weekStart.
+func (r *CohortRepository) CreateCohorts(ctx context.Context, leagueID int, weekStart time.Time, count int) ([]entities.LeagueCohort, error) {
+	now := time.Now().UTC()
+	list := make([]entities.LeagueCohort, count)
+	ids := make([]string, count)
+	for i := range list {
+		list[i] = entities.LeagueCohort{
+			ID:        uuid.NewString(),
+			LeagueID:  leagueID,
+			WeekStart: weekStart,
+			CreatedAt: now,
+		}
+		ids[i] = list[i].ID
+	}
+
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		INSERT INTO league_cohorts (id, league_id, week_start, created_at)
+		SELECT id, $2, $3, $4 FROM unnest($1::text[]) AS t(id)
+	`, ids, leagueID, weekStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("create cohorts: %w", err)
+	}
+	return list, nil
+}
+
+// AssignCohorts записывает ученика userIDs[i] в когорту cohortIDs[i].
+func (r *CohortRepository) AssignCohorts(ctx context.Context, userIDs, cohortIDs []string) error {
+	if len(userIDs) != len(cohortIDs) {
+		return fmt.Errorf("assign cohorts: %d users for %d cohort ids", len(userIDs), len(cohortIDs))
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		UPDATE student_profiles sp
+		SET current_cohort_id = a.cohort_id, updated_at = NOW()
+		FROM unnest($1::text[], $2::text[]) AS a(user_id, cohort_id)
+		WHERE sp.user_id = a.user_id
+	`, userIDs, cohortIDs)
+	if err != nil {
+		return fmt.Errorf("assign cohorts: %w", err)
+	}
+	return nil
+}
+
+// LockLeague берёт advisory-блокировку когорт лиги до конца транзакции из
+// ctx. Сброс формирует когорты под ней, а JoinCohort под ней же выбирает
+// когорту, поэтому новый ученик не попадает между ними в когорту
+// прошлой недели.
+func (r *CohortRepository) LockLeague(ctx context.Context, leagueID int) error {
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('league_cohorts'), $1)`, leagueID)
+	if err != nil {
+		return fmt.Errorf("lock league cohorts: %w", err)
+	}
+	return nil
+}
+
+// JoinCohort записывает ученика без когорты в наименее заполненную
+// неполную когорту его лиги за неделю последнего сброса, а если таких
+// нет — в новую когорту той же недели. Когорты следующей недели создаёт
+// только сброс. Если у лиги ещё нет когорт, ученик остаётся без когорты
+// до сброса, и возвращается пустая строка. Профиля нет — entities.ErrNotFound.
+func (r *CohortRepository) JoinCohort(ctx context.Context, userID string) (string, error) {
+	var cohortID string
+	err := pgtx.Run(ctx, r.pool, func(ctx context.Context) error {
+		db := pgtx.From(ctx, r.pool)
+
+		var (
+			leagueID      int
+			currentCohort *string
+			currentLeague *int
+		)
+		err := db.QueryRow(ctx, `
+			SELECT sp.current_league_id, sp.current_cohort_id, c.league_id
+			FROM student_profiles sp
+			LEFT JOIN league_cohorts c ON c.id = sp.current_cohort_id
+			WHERE sp.user_id = $1
+			FOR UPDATE OF sp
+		`, userID).Scan(&leagueID, &currentCohort, &currentLeague)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("get student cohort: %w", err)
+		}
+
+		if currentCohort != nil && currentLeague != nil && *currentLeague == leagueID {
+			cohortID = *currentCohort
+			return nil
+		}
+
+		// Одновременные вступления в лигу выполняются по очереди, чтобы не
+		// создать несколько полупустых когорт
+		if err := r.LockLeague(ctx, leagueID); err != nil {
+			return err
+		}
+
+		var weekStart *time.Time
+		err = db.QueryRow(ctx, `
+			SELECT MAX(week_start) FROM league_cohorts WHERE league_id = $1
+		`, leagueID).Scan(&weekStart)
+		if err != nil {
+			return fmt.Errorf("get league week: %w", err)
+		}
+		if weekStart == nil {
+			return nil
+		}
+
+		err = db.QueryRow(ctx, `
+			SELECT c.id
+			FROM league_cohorts c
+			LEFT JOIN student_profiles sp ON sp.current_cohort_id = c.id
+			WHERE c.league_id = $1 AND c.week_start = $2
+			GROUP BY c.id
+			HAVING COUNT(sp.user_id) < $3
+			ORDER BY COUNT(sp.user_id) ASC, c.id ASC
+			LIMIT 1
+		`, leagueID, *weekStart, entities.CohortSize).Scan(&cohortID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			created, err := r.CreateCohorts(ctx, leagueID, *weekStart, 1)
+			if err != nil {
+				return err
+			}
+			cohortID = created[0].ID
+		} else if err != nil {
+			return fmt.Errorf("find open cohort: %w", err)
+		}
+
+		return r.AssignCohorts(ctx, []string{userID}, []string{cohortID})
+	})
+	if err != nil {
+		return "", err
+	}
+	return cohortID, nil
+}
@@ -73,12 +73,14 @@ func (r *CourseRepository) Create(ctx context.Context, course *entities.Course)
 
 func (r *CourseRepository) GetByAuthorID(ctx context.Context, authorID string) ([]entities.Course, error) {
 	query := `
-		SELECT id, author_id, subject_id, title, description, difficulty_level, cover_image_url, is_published, created_at 
-		FROM courses 
-		WHERE author_id = $1 
-		ORDER BY created_at DESC
+		SELECT c.id, c.author_id, c.subject_id, COALESCE(ct.title, c.title), COALESCE(ct.description, c.description),
+		       c.difficulty_level, c.cover_image_url, c.is_published, c.created_at
+		FROM courses c
+		LEFT JOIN course_translations ct ON ct.course_id = c.id AND ct.locale = $2
+		WHERE c.author_id = $1
+		ORDER BY c.created_at DESC
 	`
-	rows, err := r.pool.Query(ctx, query, authorID)
+	rows, err := r.pool.Query(ctx, query, authorID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get courses by author: %w", err)
 	}
@@ -98,15 +100,20 @@ func (r *CourseRepository) GetByAuthorID(ctx context.Context, authorID string) (
 	return courses, nil
 }
 
-func (r *CourseRepository) GetCatalog(ctx context.Context) ([]entities.Course, error) {
+func (r *CourseRepository) GetCatalog(ctx context.Context, search string) ([]entities.Course, error) {
 	query := `
-		SELECT id, author_id, subject_id, title, description, difficulty_level, cover_image_url, is_published, created_at 
-		FROM courses 
-		WHERE is_published = true 
-		ORDER BY created_at DESC
+		SELECT c.id, c.author_id, c.subject_id, COALESCE(ct.title, c.title), COALESCE(ct.description, c.description),
+		       c.difficulty_level, c.cover_image_url, c.is_published, c.created_at
+		FROM courses c
+		LEFT JOIN course_translations ct ON ct.course_id = c.id AND ct.locale = $2
+		WHERE c.is_published = true
+		  AND ($1 = ''
+		       OR c.title ILIKE '%' || $1 || '%' OR c.description ILIKE '%' || $1 || '%'
+		       OR ct.title ILIKE '%' || $1 || '%' OR ct.description ILIKE '%' || $1 || '%')
+		ORDER BY c.created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, search, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get catalog: %w", err)
 	}
@@ -190,18 +197,19 @@ func (r *CourseRepository) getTagsForCourses(ctx context.Context, courseIDs []st
 
 func (r *CourseRepository) GetByID(ctx context.Context, id string) (*entities.Course, error) {
 	query := `
-		SELECT c.id, c.author_id, c.subject_id, c.title, c.description, 
+		SELECT c.id, c.author_id, c.subject_id, COALESCE(ct.title, c.title), COALESCE(ct.description, c.description),
 		       c.difficulty_level, c.cover_image_url, c.is_published, c.created_at,
 		       u.first_name, u.last_name, u.avatar_url
 		FROM courses c
 		JOIN users u ON c.author_id = u.id
+		LEFT JOIN course_translations ct ON ct.course_id = c.id AND ct.locale = $2
 		WHERE c.id = $1
 	`
 
 	var d courseDTO
 	var authorFirstName, authorLastName, authorAvatar string
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id, string(entities.LocaleFrom(ctx))).Scan(
 		&d.ID, &d.AuthorID, &d.SubjectID, &d.Title, &d.Description,
 		&d.DifficultyLevel, &d.CoverImageURL, &d.IsPublished, &d.CreatedAt,
 		&authorFirstName, &authorLastName, &authorAvatar,
@@ -386,8 +394,14 @@ func (r *CourseRepository) AddModule(ctx context.Context, module *entities.Modul
 }
 
 func (r *CourseRepository) ListModulesByCourse(ctx context.Context, courseID string) ([]entities.Module, error) {
-	query := `SELECT id, course_id, title, order_index FROM modules WHERE course_id = $1 ORDER BY order_index ASC`
-	rows, err := r.pool.Query(ctx, query, courseID)
+	query := `
+		SELECT m.id, m.course_id, COALESCE(mt.title, m.title), m.order_index
+		FROM modules m
+		LEFT JOIN module_translations mt ON mt.module_id = m.id AND mt.locale = $2
+		WHERE m.course_id = $1
+		ORDER BY m.order_index ASC
+	`
+	rows, err := r.pool.Query(ctx, query, courseID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, err
 	}
@@ -405,9 +419,14 @@ func (r *CourseRepository) ListModulesByCourse(ctx context.Context, courseID str
 }
 
 func (r *CourseRepository) GetModuleByID(ctx context.Context, moduleID string) (*entities.Module, error) {
-	query := `SELECT id, course_id, title, order_index FROM modules WHERE id = $1`
+	query := `
+		SELECT m.id, m.course_id, COALESCE(mt.title, m.title), m.order_index
+		FROM modules m
+		LEFT JOIN module_translations mt ON mt.module_id = m.id AND mt.locale = $2
+		WHERE m.id = $1
+	`
 	var m entities.Module
-	err := r.pool.QueryRow(ctx, query, moduleID).Scan(&m.ID, &m.CourseID, &m.Title, &m.OrderIndex)
+	err := r.pool.QueryRow(ctx, query, moduleID, string(entities.LocaleFrom(ctx))).Scan(&m.ID, &m.CourseID, &m.Title, &m.OrderIndex)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, entities.ErrNotFound
@@ -481,13 +500,14 @@ func (r *CourseRepository) GetModuleLessons(ctx context.Context, moduleID string
 	}
 
 	query := `
-        SELECT id, module_id, title, xp_reward, order_index
-        FROM lessons
-        WHERE module_id = $1
-        ORDER BY order_index ASC
+        SELECT l.id, l.module_id, COALESCE(lt.title, l.title), l.xp_reward, l.order_index
+        FROM lessons l
+        LEFT JOIN lesson_translations lt ON lt.lesson_id = l.id AND lt.locale = $2
+        WHERE l.module_id = $1
+        ORDER BY l.order_index ASC
     `
 
-	rows, err := r.pool.Query(ctx, query, moduleID)
+	rows, err := r.pool.Query(ctx, query, moduleID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("list module lessons: %w", err)
 	}
@@ -519,14 +539,16 @@ func (r *CourseRepository) GetLessonByID(ctx context.Context, lessonID string) (
 	}
 
 	query := `
-        SELECT id, module_id, title, content_text, video_url, file_attachment_url, xp_reward, order_index
-        FROM lessons
-        WHERE id = $1
+        SELECT l.id, l.module_id, COALESCE(lt.title, l.title), COALESCE(lt.content_text, l.content_text),
+               l.video_url, l.file_attachment_url, l.xp_reward, l.order_index
+        FROM lessons l
+        LEFT JOIN lesson_translations lt ON lt.lesson_id = l.id AND lt.locale = $2
+        WHERE l.id = $1
     `
 
 	var d lessonDTO
 
-	err := r.pool.QueryRow(ctx, query, lessonID).Scan(
+	err := r.pool.QueryRow(ctx, query, lessonID, string(entities.LocaleFrom(ctx))).Scan(
 		&d.ID,
 		&d.ModuleID,
 		&d.Title,
@@ -606,14 +628,15 @@ func (r *CourseRepository) GetCourseStructure(ctx context.Context, courseID stri
 	}
 
 	query := `
-        SELECT l.id, l.module_id, l.title, l.xp_reward, l.order_index
+        SELECT l.id, l.module_id, COALESCE(lt.title, l.title), l.xp_reward, l.order_index
         FROM lessons l
         JOIN modules m ON l.module_id = m.id
+        LEFT JOIN lesson_translations lt ON lt.lesson_id = l.id AND lt.locale = $2
         WHERE m.course_id = $1
         ORDER BY m.order_index ASC, l.order_index ASC
     `
 
-	rows, err := r.pool.Query(ctx, query, courseID)
+	rows, err := r.pool.Query(ctx, query, courseID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get course structure: %w", err)
 	}
@@ -666,15 +689,16 @@ func (r *CourseRepository) ToggleFavorite(ctx context.Context, userID, courseID
 
 func (r *CourseRepository) GetUserFavorites(ctx context.Context, userID string) ([]entities.Course, error) {
 	query := `
-		SELECT c.id, c.author_id, c.subject_id, c.title, c.description, 
+		SELECT c.id, c.author_id, c.subject_id, COALESCE(ct.title, c.title), COALESCE(ct.description, c.description),
 		       c.difficulty_level, c.cover_image_url, c.is_published, c.created_at
 		FROM courses c
 		JOIN course_favorites cf ON c.id = cf.course_id
+		LEFT JOIN course_translations ct ON ct.course_id = c.id AND ct.locale = $2
 		WHERE cf.user_id = $1
 		ORDER BY cf.created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, userID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get user favorites: %w", err)
 	}
@@ -717,14 +741,15 @@ func (r *CourseRepository) GetCoursesByIDs(
 	}
 
 	query := `
-        SELECT id, title, description, difficulty_level,
-               subject_id, author_id, created_at,
-               is_published, cover_image_url
-        FROM courses
-        WHERE id = ANY($1) AND is_published = TRUE
+        SELECT c.id, COALESCE(ct.title, c.title), COALESCE(ct.description, c.description, ''), c.difficulty_level,
+               c.subject_id, c.author_id, c.created_at,
+               c.is_published, c.cover_image_url
+        FROM courses c
+        LEFT JOIN course_translations ct ON ct.course_id = c.id AND ct.locale = $2
+        WHERE c.id = ANY($1) AND c.is_published = TRUE
     `
 
-	rows, err := r.pool.Query(ctx, query, ids)
+	rows, err := r.pool.Query(ctx, query, ids, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ type lessonDTO struct {
 	ContentText       *string
 	VideoURL          *string
 	FileAttachmentURL *string
-	XPReward          int
+	XPReward          *int
 	OrderIndex        int
 }
 
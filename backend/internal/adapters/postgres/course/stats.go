@@ -0,0 +1,101 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RefreshCourseStats пересчитывает сводку по всем курсам на момент now
+// одним запросом и возвращает число обновлённых курсов. Окна «7 дней»
+// и «30 дней» отсчитываются от now.
+func (r *CourseRepository) RefreshCourseStats(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		INSERT INTO course_stats (
+			course_id, students, completions, avg_progress, active_students_7d, views_30d,
+			favorites, test_attempts, avg_test_score, test_pass_rate, computed_at
+		)
+		SELECT c.id,
+		       COALESCE(p.students, 0), COALESCE(p.completions, 0), COALESCE(p.avg_progress, 0),
+		       COALESCE(a.active_students, 0), COALESCE(a.views, 0),
+		       COALESCE(f.favorites, 0),
+		       COALESCE(t.attempts, 0), t.avg_score, t.pass_rate,
+		       $1::timestamptz
+		FROM courses c
+		LEFT JOIN (
+			SELECT course_id,
+			       COUNT(*) AS students,
+			       COUNT(*) FILTER (WHERE is_completed) AS completions,
+			       ROUND(AVG(progress_percentage))::int AS avg_progress
+			FROM course_progress
+			GROUP BY course_id
+		) p ON p.course_id = c.id
+		LEFT JOIN (
+			SELECT course_id,
+			       COUNT(DISTINCT user_id) FILTER (WHERE created_at > $1::timestamptz - INTERVAL '7 days') AS active_students,
+			       COUNT(*) FILTER (WHERE action_type = $2) AS views
+			FROM user_activity_logs
+			WHERE course_id IS NOT NULL AND created_at > $1::timestamptz - INTERVAL '30 days'
+			GROUP BY course_id
+		) a ON a.course_id = c.id
+		LEFT JOIN (
+			SELECT course_id, COUNT(*) AS favorites
+			FROM course_favorites
+			GROUP BY course_id
+		) f ON f.course_id = c.id
+		LEFT JOIN (
+			SELECT m.course_id,
+			       COUNT(*) AS attempts,
+			       ROUND(AVG(tr.score))::int AS avg_score,
+			       ROUND(100.0 * COUNT(*) FILTER (WHERE tr.is_passed) / COUNT(*))::int AS pass_rate
+			FROM test_results tr
+			JOIN tests ts ON ts.id = tr.test_id
+			JOIN modules m ON m.id = ts.module_id
+			GROUP BY m.course_id
+		) t ON t.course_id = c.id
+		ON CONFLICT (course_id) DO UPDATE SET
+			students = EXCLUDED.students,
+			completions = EXCLUDED.completions,
+			avg_progress = EXCLUDED.avg_progress,
+			active_students_7d = EXCLUDED.active_students_7d,
+			views_30d = EXCLUDED.views_30d,
+			favorites = EXCLUDED.favorites,
+			test_attempts = EXCLUDED.test_attempts,
+			avg_test_score = EXCLUDED.avg_test_score,
+			test_pass_rate = EXCLUDED.test_pass_rate,
+			computed_at = EXCLUDED.computed_at
+	`
+	tag, err := r.pool.Exec(ctx, query, now, entities.ActionCourseView)
+	if err != nil {
+		return 0, fmt.Errorf("refresh course stats: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetCourseStats возвращает последнюю сводку по курсу или
+// entities.ErrNotFound, если её ещё не считали.
+func (r *CourseRepository) GetCourseStats(ctx context.Context, courseID string) (*entities.CourseStats, error) {
+	query := `
+		SELECT course_id, students, completions, avg_progress, active_students_7d, views_30d,
+		       favorites, test_attempts, avg_test_score, test_pass_rate, computed_at
+		FROM course_stats
+		WHERE course_id = $1
+	`
+	var s entities.CourseStats
+	err := r.pool.QueryRow(ctx, query, courseID).Scan(
+		&s.CourseID, &s.Students, &s.Completions, &s.AvgProgress, &s.ActiveStudents7d, &s.Views30d,
+		&s.Favorites, &s.TestAttempts, &s.AvgTestScore, &s.TestPassRate, &s.ComputedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, fmt.Errorf("get course stats: %w", err)
+	}
+	return &s, nil
+}
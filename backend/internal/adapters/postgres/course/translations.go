@@ -0,0 +1,91 @@
+package course
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/entities"
+)
+
+// nullIfEmpty нужен необязательным полям перевода: NULL означает
+// «брать исходный текст», а пустая строка затёрла бы его при чтении.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (r *CourseRepository) SaveCourseTranslation(ctx context.Context, t *entities.CourseTranslation) error {
+	query := `
+		INSERT INTO course_translations (course_id, locale, title, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (course_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description
+	`
+	_, err := r.pool.Exec(ctx, query, t.CourseID, string(t.Locale), t.Title, nullIfEmpty(t.Description))
+	if err != nil {
+		return fmt.Errorf("save course translation: %w", err)
+	}
+	return nil
+}
+
+func (r *CourseRepository) DeleteCourseTranslation(ctx context.Context, courseID string, locale entities.Locale) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM course_translations WHERE course_id = $1 AND locale = $2`, courseID, string(locale))
+	if err != nil {
+		return fmt.Errorf("delete course translation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CourseRepository) SaveModuleTranslation(ctx context.Context, t *entities.ModuleTranslation) error {
+	query := `
+		INSERT INTO module_translations (module_id, locale, title)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (module_id, locale) DO UPDATE SET title = EXCLUDED.title
+	`
+	_, err := r.pool.Exec(ctx, query, t.ModuleID, string(t.Locale), t.Title)
+	if err != nil {
+		return fmt.Errorf("save module translation: %w", err)
+	}
+	return nil
+}
+
+func (r *CourseRepository) DeleteModuleTranslation(ctx context.Context, moduleID string, locale entities.Locale) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM module_translations WHERE module_id = $1 AND locale = $2`, moduleID, string(locale))
+	if err != nil {
+		return fmt.Errorf("delete module translation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CourseRepository) SaveLessonTranslation(ctx context.Context, t *entities.LessonTranslation) error {
+	query := `
+		INSERT INTO lesson_translations (lesson_id, locale, title, content_text)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (lesson_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, content_text = EXCLUDED.content_text
+	`
+	_, err := r.pool.Exec(ctx, query, t.LessonID, string(t.Locale), t.Title, nullIfEmpty(t.ContentText))
+	if err != nil {
+		return fmt.Errorf("save lesson translation: %w", err)
+	}
+	return nil
+}
+
+func (r *CourseRepository) DeleteLessonTranslation(ctx context.Context, lessonID string, locale entities.Locale) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM lesson_translations WHERE lesson_id = $1 AND locale = $2`, lessonID, string(locale))
+	if err != nil {
+		return fmt.Errorf("delete lesson translation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DigestRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewDigestRepository(connectionURL string) *DigestRepository {
+	return &DigestRepository{connectionURL: connectionURL}
+}
+
+func (r *DigestRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+	return nil
+}
+
+func (r *DigestRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+// WithinTransaction выполняет fn в одной транзакции: отметка о дайджесте
+// и письмо в email_outbox фиксируются вместе.
+func (r *DigestRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+// GetWeeklyDigests возвращает до limit итогов недели [periodStart, periodEnd)
+// для учеников с id больше afterUserID. Берутся только ученики с
+// подтверждённым email, которые за неделю что-то сделали и ещё не получили
+// дайджест за эту неделю.
+func (r *DigestRepository) GetWeeklyDigests(
+	ctx context.Context,
+	periodStart, periodEnd time.Time,
+	afterUserID string,
+	limit int,
+) ([]entities.WeeklyDigest, error) {
+	// attempt_date хранится без часового пояса, в UTC
+	query := `
+		SELECT u.id, u.email, u.first_name, u.locale,
+		       COALESCE(xp.amount, 0), lessons.n, tests.n, COALESCE(sp.current_streak, 0)
+		FROM users u
+		JOIN student_profiles sp ON sp.user_id = u.id
+		CROSS JOIN LATERAL (
+			SELECT SUM(x.amount) AS amount
+			FROM xp_transactions x
+			WHERE x.user_id = u.id AND x.created_at >= $1 AND x.created_at < $2
+		) xp
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS n
+			FROM user_activity_logs l
+			WHERE l.user_id = u.id AND l.action_type = $5 AND l.created_at >= $1 AND l.created_at < $2
+		) lessons
+		CROSS JOIN LATERAL (
+			SELECT COUNT(DISTINCT t.test_id) AS n
+			FROM test_results t
+			WHERE t.user_id = u.id AND t.is_passed
+			  AND t.attempt_date AT TIME ZONE 'UTC' >= $1 AND t.attempt_date AT TIME ZONE 'UTC' < $2
+		) tests
+		WHERE u.role = 'student' AND u.email_verified_at IS NOT NULL AND u.id > $3
+		  AND (xp.amount > 0 OR lessons.n > 0 OR tests.n > 0)
+		  AND NOT EXISTS (
+			SELECT 1 FROM email_digests d WHERE d.user_id = u.id AND d.period_start = $1
+		  )
+		ORDER BY u.id
+		LIMIT $4
+	`
+	rows, err := r.pool.Query(ctx, query, periodStart, periodEnd, afterUserID, limit, entities.ActionLessonComplete)
+	if err != nil {
+		return nil, fmt.Errorf("get weekly digests: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entities.WeeklyDigest
+	for rows.Next() {
+		d := entities.WeeklyDigest{PeriodStart: periodStart, PeriodEnd: periodEnd}
+		var locale string
+		if err := rows.Scan(
+			&d.UserID, &d.Email, &d.FirstName, &locale,
+			&d.XPEarned, &d.LessonsCompleted, &d.TestsPassed, &d.CurrentStreak,
+		); err != nil {
+			return nil, fmt.Errorf("scan weekly digest: %w", err)
+		}
+		d.Locale = entities.Locale(locale)
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get weekly digests: %w", err)
+	}
+	return list, nil
+}
+
+// MarkDigestSent отмечает дайджест ученика за неделю periodStart.
+// Возвращает false, если отметка уже была.
+func (r *DigestRepository) MarkDigestSent(ctx context.Context, userID string, periodStart time.Time) (bool, error) {
+	query := `
+		INSERT INTO email_digests (user_id, period_start)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, query, userID, periodStart)
+	if err != nil {
+		return false, fmt.Errorf("mark digest sent: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
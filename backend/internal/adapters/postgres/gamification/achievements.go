@@ -0,0 +1,91 @@
+package gamification
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+)
+
+// GetPendingAchievements возвращает ещё не полученные пользователем
+// достижения, правило которых срабатывает на событие event.
+func (r *GamificationRepository) GetPendingAchievements(
+	ctx context.Context,
+	userID string,
+	event entities.LearningEventType,
+) ([]entities.Achievement, error) {
+	query := `
+		SELECT a.id, a.slug, a.name, COALESCE(a.description, ''), COALESCE(a.icon_url, ''), a.xp_reward,
+		       a.rule_event, a.rule_metric, a.rule_threshold
+		FROM achievements a
+		WHERE a.rule_event = $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_achievements ua
+			WHERE ua.user_id = $1 AND ua.achievement_id = a.id
+		  )
+		ORDER BY a.rule_threshold ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, string(event))
+	if err != nil {
+		return nil, fmt.Errorf("get pending achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entities.Achievement
+	for rows.Next() {
+		var d achievementDTO
+		err := rows.Scan(
+			&d.ID, &d.Slug, &d.Name, &d.Description, &d.IconURL, &d.XPReward,
+			&d.RuleEvent, &d.RuleMetric, &d.RuleThreshold,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d.toEntity())
+	}
+	return list, rows.Err()
+}
+
+// CountUserMetric считает накопительную метрику правила по данным пользователя.
+func (r *GamificationRepository) CountUserMetric(
+	ctx context.Context,
+	userID string,
+	metric entities.AchievementMetric,
+) (int, error) {
+	var query string
+	switch metric {
+	case entities.MetricLessonsCompleted:
+		query = `SELECT COUNT(*) FROM lesson_progress WHERE user_id = $1 AND is_completed`
+	case entities.MetricCoursesCompleted:
+		query = `SELECT COUNT(*) FROM course_progress WHERE user_id = $1 AND is_completed`
+	case entities.MetricTestsPassed:
+		query = `SELECT COUNT(DISTINCT test_id) FROM test_results WHERE user_id = $1 AND is_passed`
+	case entities.MetricPerfectTests:
+		query = `SELECT COUNT(DISTINCT test_id) FROM test_results WHERE user_id = $1 AND score >= 100`
+	default:
+		return 0, fmt.Errorf("unknown achievement metric %q", metric)
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count %s: %w", metric, err)
+	}
+	return count, nil
+}
+
+// AwardAchievement отмечает достижение полученным. Возвращает false, если
+// оно уже было у ученика. XP за достижение начисляется через журнал
+// в той же транзакции вызывающим кодом.
+func (r *GamificationRepository) AwardAchievement(ctx context.Context, userID, achievementID string) (bool, error) {
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		INSERT INTO user_achievements (user_id, achievement_id, earned_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, achievement_id) DO NOTHING
+	`, userID, achievementID)
+	if err != nil {
+		return false, fmt.Errorf("insert user achievement: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
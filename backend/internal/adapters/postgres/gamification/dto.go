@@ -12,15 +12,22 @@ type leagueDTO struct {
 	Name       string `db:"name"`
 	OrderIndex int    `db:"order_index"`
 	IconURL    string `db:"icon_url"`
+
+	PromotePercent int  `db:"promote_percent"`
+	DemotePercent  int  `db:"demote_percent"`
+	DemoteInactive bool `db:"demote_inactive"`
 }
 
 func (d *leagueDTO) toEntity() entities.League {
 	return entities.League{
-		ID:         d.ID,
-		Slug:       d.Slug,
-		Name:       d.Name,
-		OrderIndex: d.OrderIndex,
-		IconURL:    d.IconURL,
+		ID:             d.ID,
+		Slug:           d.Slug,
+		Name:           d.Name,
+		OrderIndex:     d.OrderIndex,
+		IconURL:        d.IconURL,
+		PromotePercent: d.PromotePercent,
+		DemotePercent:  d.DemotePercent,
+		DemoteInactive: d.DemoteInactive,
 	}
 }
 
@@ -31,10 +38,14 @@ type achievementDTO struct {
 	Description string `db:"description"`
 	IconURL     string `db:"icon_url"`
 	XPReward    int    `db:"xp_reward"`
+
+	RuleEvent     *string `db:"rule_event"`
+	RuleMetric    *string `db:"rule_metric"`
+	RuleThreshold int     `db:"rule_threshold"`
 }
 
 func (d *achievementDTO) toEntity() entities.Achievement {
-	return entities.Achievement{
+	a := entities.Achievement{
 		ID:          d.ID,
 		Slug:        d.Slug,
 		Name:        d.Name,
@@ -42,6 +53,14 @@ func (d *achievementDTO) toEntity() entities.Achievement {
 		IconURL:     d.IconURL,
 		XPReward:    d.XPReward,
 	}
+	if d.RuleEvent != nil && d.RuleMetric != nil {
+		a.Rule = &entities.AchievementRule{
+			Event:     entities.LearningEventType(*d.RuleEvent),
+			Metric:    entities.AchievementMetric(*d.RuleMetric),
+			Threshold: d.RuleThreshold,
+		}
+	}
+	return a
 }
 
 type userAchievementDTO struct {
@@ -78,20 +97,44 @@ type historyDTO struct {
 	PeriodEnd   time.Time `db:"period_end"`
 	UserID      string    `db:"user_id"`
 	LeagueID    int       `db:"league_id"`
+	CohortID    *string   `db:"cohort_id"`
 	Rank        int       `db:"rank"`
 	TotalXP     int64     `db:"total_xp"`
+	Outcome     *string   `db:"outcome"`
+	ToLeagueID  *int      `db:"to_league_id"`
 	CreatedAt   time.Time `db:"created_at"`
+
+	LeagueName    string
+	LeagueIconURL string
+	FirstName     string
+	LastName      string
+	AvatarURL     string
 }
 
 func (d *historyDTO) toEntity() entities.LeaderboardHistory {
-	return entities.LeaderboardHistory{
-		ID:          d.ID,
-		PeriodStart: d.PeriodStart,
-		PeriodEnd:   d.PeriodEnd,
-		UserID:      d.UserID,
-		LeagueID:    d.LeagueID,
-		Rank:        d.Rank,
-		TotalXP:     d.TotalXP,
-		CreatedAt:   d.CreatedAt.UTC(),
+	h := entities.LeaderboardHistory{
+		ID:            d.ID,
+		PeriodStart:   d.PeriodStart,
+		PeriodEnd:     d.PeriodEnd,
+		UserID:        d.UserID,
+		LeagueID:      d.LeagueID,
+		Rank:          d.Rank,
+		TotalXP:       d.TotalXP,
+		CreatedAt:     d.CreatedAt.UTC(),
+		LeagueName:    d.LeagueName,
+		LeagueIconURL: d.LeagueIconURL,
+		FirstName:     d.FirstName,
+		LastName:      d.LastName,
+		AvatarURL:     d.AvatarURL,
+	}
+	if d.CohortID != nil {
+		h.CohortID = *d.CohortID
+	}
+	if d.Outcome != nil {
+		h.Outcome = entities.LeagueOutcome(*d.Outcome)
+	}
+	if d.ToLeagueID != nil {
+		h.ToLeagueID = *d.ToLeagueID
 	}
+	return h
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"backend/internal/adapters/postgres/pgtx"
 	"backend/internal/entities"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -36,9 +37,15 @@ func (r *GamificationRepository) Close() {
 }
 
 func (r *GamificationRepository) GetAllLeagues(ctx context.Context) ([]entities.League, error) {
-	query := `SELECT id, slug, name, order_index, icon_url FROM leagues ORDER BY order_index ASC`
+	query := `
+		SELECT l.id, l.slug, COALESCE(t.name, l.name), l.order_index, l.icon_url,
+		       l.promote_percent, l.demote_percent, l.demote_inactive
+		FROM leagues l
+		LEFT JOIN league_translations t ON t.league_id = l.id AND t.locale = $1
+		ORDER BY l.order_index ASC
+	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, query, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get leagues: %w", err)
 	}
@@ -47,7 +54,11 @@ func (r *GamificationRepository) GetAllLeagues(ctx context.Context) ([]entities.
 	var list []entities.League
 	for rows.Next() {
 		var d leagueDTO
-		if err := rows.Scan(&d.ID, &d.Slug, &d.Name, &d.OrderIndex, &d.IconURL); err != nil {
+		err := rows.Scan(
+			&d.ID, &d.Slug, &d.Name, &d.OrderIndex, &d.IconURL,
+			&d.PromotePercent, &d.DemotePercent, &d.DemoteInactive,
+		)
+		if err != nil {
 			return nil, err
 		}
 		list = append(list, d.toEntity())
@@ -56,9 +67,14 @@ func (r *GamificationRepository) GetAllLeagues(ctx context.Context) ([]entities.
 }
 
 func (r *GamificationRepository) GetAllAchievements(ctx context.Context) ([]entities.Achievement, error) {
-	query := `SELECT id, slug, name, description, icon_url, xp_reward FROM achievements`
+	query := `
+		SELECT a.id, a.slug, COALESCE(t.name, a.name), COALESCE(t.description, a.description, ''),
+		       COALESCE(a.icon_url, ''), a.xp_reward, a.rule_event, a.rule_metric, a.rule_threshold
+		FROM achievements a
+		LEFT JOIN achievement_translations t ON t.achievement_id = a.id AND t.locale = $1
+	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get all achievements: %w", err)
 	}
@@ -67,7 +83,11 @@ func (r *GamificationRepository) GetAllAchievements(ctx context.Context) ([]enti
 	var list []entities.Achievement
 	for rows.Next() {
 		var d achievementDTO
-		if err := rows.Scan(&d.ID, &d.Slug, &d.Name, &d.Description, &d.IconURL, &d.XPReward); err != nil {
+		err := rows.Scan(
+			&d.ID, &d.Slug, &d.Name, &d.Description, &d.IconURL, &d.XPReward,
+			&d.RuleEvent, &d.RuleMetric, &d.RuleThreshold,
+		)
+		if err != nil {
 			return nil, err
 		}
 		list = append(list, d.toEntity())
@@ -81,14 +101,16 @@ func (r *GamificationRepository) GetUserAchievements(
 ) ([]entities.UserAchievement, error) {
 	query := `
 		SELECT ua.user_id, ua.achievement_id, ua.earned_at,
-		       a.slug, a.name, a.description, a.icon_url, a.xp_reward
+		       a.slug, COALESCE(t.name, a.name), COALESCE(t.description, a.description, ''),
+		       COALESCE(a.icon_url, ''), a.xp_reward
 		FROM user_achievements ua
 		JOIN achievements a ON ua.achievement_id = a.id
+		LEFT JOIN achievement_translations t ON t.achievement_id = a.id AND t.locale = $2
 		WHERE ua.user_id = $1
 		ORDER BY ua.earned_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, userID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("get user achievements: %w", err)
 	}
@@ -135,7 +157,7 @@ func (r *GamificationRepository) SaveHistorySnapshot(ctx context.Context, h *ent
 		INSERT INTO leaderboard_history (id, period_start, period_end, user_id, league_id, rank, total_xp, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.pool.Exec(
+	_, err := pgtx.From(ctx, r.pool).Exec(
 		ctx, query,
 		h.ID, h.PeriodStart, h.PeriodEnd, h.UserID, h.LeagueID, h.Rank, h.TotalXP, h.CreatedAt,
 	)
@@ -145,39 +167,166 @@ func (r *GamificationRepository) SaveHistorySnapshot(ctx context.Context, h *ent
 	return nil
 }
 
-func (r *GamificationRepository) GetHistoryByPeriod(
+// SaveHistorySnapshots сохраняет снимки одной вставкой.
+func (r *GamificationRepository) SaveHistorySnapshots(ctx context.Context, list []entities.LeaderboardHistory) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var (
+		ids        = make([]string, len(list))
+		starts     = make([]time.Time, len(list))
+		ends       = make([]time.Time, len(list))
+		userIDs    = make([]string, len(list))
+		leagueIDs  = make([]int32, len(list))
+		cohortIDs  = make([]string, len(list))
+		outcomes   = make([]string, len(list))
+		toLeagues  = make([]int32, len(list))
+		ranks      = make([]int32, len(list))
+		totals     = make([]int64, len(list))
+		createdAts = make([]time.Time, len(list))
+	)
+	for i, h := range list {
+		ids[i], starts[i], ends[i], userIDs[i] = h.ID, h.PeriodStart, h.PeriodEnd, h.UserID
+		leagueIDs[i], cohortIDs[i] = int32(h.LeagueID), h.CohortID
+		ranks[i], totals[i], createdAts[i] = int32(h.Rank), h.TotalXP, h.CreatedAt
+		outcomes[i], toLeagues[i] = string(h.Outcome), int32(h.ToLeagueID)
+	}
+
+	query := `
+		INSERT INTO leaderboard_history (
+			id, period_start, period_end, user_id, league_id, cohort_id,
+			rank, total_xp, outcome, to_league_id, created_at
+		)
+		SELECT id, period_start, period_end, user_id, league_id, NULLIF(cohort_id, ''),
+		       rank, total_xp, NULLIF(outcome, ''), NULLIF(to_league_id, 0), created_at
+		FROM unnest(
+			$1::text[], $2::date[], $3::date[], $4::text[], $5::int[], $6::text[],
+			$7::int[], $8::bigint[], $9::text[], $10::int[], $11::timestamptz[]
+		) AS t(id, period_start, period_end, user_id, league_id, cohort_id,
+		       rank, total_xp, outcome, to_league_id, created_at)
+	`
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, query,
+		ids, starts, ends, userIDs, leagueIDs, cohortIDs,
+		ranks, totals, outcomes, toLeagues, createdAts,
+	)
+	if err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	return nil
+}
+
+// GetUserHistory возвращает недели ученика от новых к старым и их общее
+// число. Названия лиг локализуются по ctx.
+func (r *GamificationRepository) GetUserHistory(
 	ctx context.Context,
-	start, end time.Time,
-	leagueID int,
+	userID string,
+	limit, offset int,
+) ([]entities.LeaderboardHistory, int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM leaderboard_history WHERE user_id = $1`, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count history: %w", err)
+	}
+
+	query := `
+		SELECT h.id, h.period_start, h.period_end, h.user_id, h.league_id, h.cohort_id,
+		       h.rank, h.total_xp, h.outcome, h.to_league_id, h.created_at,
+		       COALESCE(lt.name, l.name), COALESCE(l.icon_url, '')
+		FROM leaderboard_history h
+		JOIN leagues l ON l.id = h.league_id
+		LEFT JOIN league_translations lt ON lt.league_id = l.id AND lt.locale = $4
+		WHERE h.user_id = $1
+		ORDER BY h.period_start DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.pool.Query(ctx, query, userID, limit, offset, string(entities.LocaleFrom(ctx)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("get history: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]entities.LeaderboardHistory, 0)
+	for rows.Next() {
+		var d historyDTO
+		err := rows.Scan(
+			&d.ID, &d.PeriodStart, &d.PeriodEnd, &d.UserID, &d.LeagueID, &d.CohortID,
+			&d.Rank, &d.TotalXP, &d.Outcome, &d.ToLeagueID, &d.CreatedAt,
+			&d.LeagueName, &d.LeagueIconURL,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan history: %w", err)
+		}
+		list = append(list, d.toEntity())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, total, nil
+}
+
+// GetHistoryTable возвращает итоговую таблицу недели, начавшейся
+// periodStart, для лиги и когорты, в которых был ученик userID.
+// Если ученика в ту неделю не было в лигах — entities.ErrNotFound.
+func (r *GamificationRepository) GetHistoryTable(
+	ctx context.Context,
+	userID string,
+	periodStart time.Time,
 ) ([]entities.LeaderboardHistory, error) {
 	query := `
-		SELECT id, period_start, period_end, user_id, league_id, rank, total_xp, created_at
-		FROM leaderboard_history
-		WHERE period_start = $1 AND period_end = $2 AND league_id = $3
-		ORDER BY rank ASC
+		WITH mine AS (
+			SELECT league_id, cohort_id
+			FROM leaderboard_history
+			WHERE user_id = $1 AND period_start = $2
+			LIMIT 1
+		)
+		SELECT h.id, h.period_start, h.period_end, h.user_id, h.league_id, h.cohort_id,
+		       h.rank, h.total_xp, h.outcome, h.to_league_id, h.created_at,
+		       COALESCE(lt.name, l.name), COALESCE(l.icon_url, ''),
+		       u.first_name, u.last_name, COALESCE(u.avatar_url, '')
+		FROM leaderboard_history h
+		JOIN mine m ON m.league_id = h.league_id AND m.cohort_id IS NOT DISTINCT FROM h.cohort_id
+		JOIN leagues l ON l.id = h.league_id
+		LEFT JOIN league_translations lt ON lt.league_id = l.id AND lt.locale = $3
+		JOIN users u ON u.id = h.user_id
+		WHERE h.period_start = $2
+		ORDER BY h.rank ASC, h.user_id ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, start, end, leagueID)
+	rows, err := r.pool.Query(ctx, query, userID, periodStart, string(entities.LocaleFrom(ctx)))
 	if err != nil {
-		return nil, fmt.Errorf("get history: %w", err)
+		return nil, fmt.Errorf("get history table: %w", err)
 	}
 	defer rows.Close()
 
 	var list []entities.LeaderboardHistory
 	for rows.Next() {
 		var d historyDTO
-		err := rows.Scan(&d.ID, &d.PeriodStart, &d.PeriodEnd, &d.UserID, &d.LeagueID, &d.Rank, &d.TotalXP, &d.CreatedAt)
+		err := rows.Scan(
+			&d.ID, &d.PeriodStart, &d.PeriodEnd, &d.UserID, &d.LeagueID, &d.CohortID,
+			&d.Rank, &d.TotalXP, &d.Outcome, &d.ToLeagueID, &d.CreatedAt,
+			&d.LeagueName, &d.LeagueIconURL,
+			&d.FirstName, &d.LastName, &d.AvatarURL,
+		)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("scan history: %w", err)
 		}
 		list = append(list, d.toEntity())
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(list) == 0 {
+		return nil, entities.ErrNotFound
+	}
 	return list, nil
 }
 
 func (r *GamificationRepository) GetLastResetDate(ctx context.Context) (time.Time, error) {
 	var val string
-	err := r.pool.QueryRow(ctx, "SELECT value FROM system_settings WHERE key = 'last_weekly_reset'").Scan(&val)
+	err := pgtx.From(ctx, r.pool).QueryRow(ctx, "SELECT value FROM system_settings WHERE key = 'last_weekly_reset'").Scan(&val)
 	if err != nil {
 		// Если записи нет, возвращаем нулевое время (значит, сброса никогда не было)
 		return time.Time{}, nil
@@ -192,6 +341,46 @@ func (r *GamificationRepository) SetLastResetDate(ctx context.Context, date time
 		VALUES ('last_weekly_reset', $1::text, NOW())
 		ON CONFLICT (key) DO UPDATE SET value = $1::text, updated_at = NOW()
 	`
-	_, err := r.pool.Exec(ctx, query, val)
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, query, val)
 	return err
 }
+
+// UpdateLeagueZones сохраняет зоны повышения и понижения лиги.
+func (r *GamificationRepository) UpdateLeagueZones(ctx context.Context, l entities.League) error {
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		UPDATE leagues
+		SET promote_percent = $2, demote_percent = $3, demote_inactive = $4
+		WHERE id = $1
+	`, l.ID, l.PromotePercent, l.DemotePercent, l.DemoteInactive)
+	if err != nil {
+		return fmt.Errorf("update league zones: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// WithinTransaction выполняет fn в одной транзакции.
+func (r *GamificationRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+// weeklyResetLockKey — ключ advisory-блокировки еженедельного сброса.
+const weeklyResetLockKey int64 = 0x5745454b4c59 // "WEEKLY"
+
+// TryLockWeeklyReset берёт транзакционную advisory-блокировку сброса лиг.
+// Вызывается внутри WithinTransaction; блокировка снимается при коммите
+// или откате. false — сброс уже выполняет другой экземпляр.
+func (r *GamificationRepository) TryLockWeeklyReset(ctx context.Context) (bool, error) {
+	if !pgtx.InTx(ctx) {
+		return false, fmt.Errorf("weekly reset lock requires a transaction")
+	}
+
+	var locked bool
+	err := pgtx.From(ctx, r.pool).QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, weeklyResetLockKey).Scan(&locked)
+	if err != nil {
+		return false, fmt.Errorf("try weekly reset lock: %w", err)
+	}
+	return locked, nil
+}
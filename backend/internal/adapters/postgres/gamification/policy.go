@@ -0,0 +1,72 @@
+package gamification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const policySettingKey = "gamification_policy"
+
+// GetPolicy читает политику из system_settings. Если администратор её
+// ещё не сохранял, возвращает entities.ErrNotFound.
+func (r *GamificationRepository) GetPolicy(ctx context.Context) (*entities.GamificationPolicy, error) {
+	var raw string
+	err := r.pool.QueryRow(ctx, `SELECT value FROM system_settings WHERE key = $1`, policySettingKey).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get gamification policy: %w", err)
+	}
+
+	var p entities.GamificationPolicy
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, fmt.Errorf("decode gamification policy: %w", err)
+	}
+	return &p, nil
+}
+
+// SavePolicy сохраняет политику и в той же транзакции поднимает уровни
+// учеников по новым порогам: thresholds[i] — XP для уровня i+2. Уровень,
+// который по новой кривой выше заработанного XP, остаётся прежним.
+func (r *GamificationRepository) SavePolicy(ctx context.Context, p entities.GamificationPolicy, thresholds []int64) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encode gamification policy: %w", err)
+	}
+
+	return pgtx.Run(ctx, r.pool, func(ctx context.Context) error {
+		db := pgtx.From(ctx, r.pool)
+
+		_, err := db.Exec(ctx, `
+			INSERT INTO system_settings (key, value, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()
+		`, policySettingKey, string(raw))
+		if err != nil {
+			return fmt.Errorf("save gamification policy: %w", err)
+		}
+
+		_, err = db.Exec(ctx, `
+			UPDATE student_profiles sp
+			SET level = lv.level, updated_at = NOW()
+			FROM (
+				SELECT p.user_id,
+				       1 + (SELECT COUNT(*) FROM unnest($1::bigint[]) AS t(xp) WHERE t.xp <= p.xp)::int AS level
+				FROM student_profiles p
+			) lv
+			WHERE sp.user_id = lv.user_id AND sp.level < lv.level
+		`, thresholds)
+		if err != nil {
+			return fmt.Errorf("recalculate levels: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package gamification
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/adapters/postgres/pgtest"
+	"backend/internal/entities"
+)
+
+func TestSavePolicyOnlyRaisesLevels(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+
+	raised := pgtest.Student(t, pool)
+	kept := pgtest.Student(t, pool)
+	_, err := pool.Exec(ctx, `
+		UPDATE student_profiles
+		SET xp = CASE WHEN user_id = $1 THEN 350 ELSE 150 END,
+		    level = CASE WHEN user_id = $1 THEN 1 ELSE 5 END
+		WHERE user_id IN ($1, $2)
+	`, raised, kept)
+	if err != nil {
+		t.Fatalf("prepare profiles: %v", err)
+	}
+
+	repo := NewGamificationRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM system_settings WHERE key = $1`, policySettingKey)
+	})
+
+	p := entities.DefaultGamificationPolicy()
+	if err := repo.SavePolicy(ctx, p, p.Levels.LevelThresholds()); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+
+	for userID, want := range map[string]int{raised: 4, kept: 5} {
+		var level int
+		if err := pool.QueryRow(ctx, `SELECT level FROM student_profiles WHERE user_id = $1`, userID).Scan(&level); err != nil {
+			t.Fatalf("read level: %v", err)
+		}
+		if level != want {
+			t.Errorf("level = %d, want %d", level, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JobRunRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewJobRunRepository(connectionURL string) *JobRunRepository {
+	return &JobRunRepository{connectionURL: connectionURL}
+}
+
+func (r *JobRunRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+	return nil
+}
+
+func (r *JobRunRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+// ClaimRun записывает запуск run, если слот ещё никем не занят и у задачи
+// нет живого запуска. Запуски с истёкшей арендой предварительно
+// помечаются failed. Возвращает false, если слот достался другому
+// экземпляру или предыдущий запуск ещё идёт.
+func (r *JobRunRepository) ClaimRun(ctx context.Context, run *entities.JobRun, lease time.Duration) (bool, error) {
+	claimed := false
+	err := pgtx.Run(ctx, r.pool, func(ctx context.Context) error {
+		db := pgtx.From(ctx, r.pool)
+
+		// Проверка живого запуска и вставка не атомарны, поэтому экземпляры
+		// занимают слоты одной задачи по очереди
+		_, err := db.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('job_runs'), hashtext($1))`, run.JobName)
+		if err != nil {
+			return fmt.Errorf("lock job: %w", err)
+		}
+
+		_, err = db.Exec(ctx, `
+			UPDATE job_runs
+			SET status = 'failed', finished_at = NOW(), error = 'lease expired'
+			WHERE job_name = $1 AND status = 'running' AND lease_until < NOW()
+		`, run.JobName)
+		if err != nil {
+			return fmt.Errorf("expire job runs: %w", err)
+		}
+
+		query := `
+			INSERT INTO job_runs (id, job_name, scheduled_at, status, attempts, instance, started_at, lease_until)
+			SELECT $1, $2, $3, 'running', 1, $4, NOW(), NOW() + make_interval(secs => $5)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM job_runs WHERE job_name = $2 AND status = 'running'
+			)
+			ON CONFLICT (job_name, scheduled_at) DO NOTHING
+			RETURNING started_at
+		`
+		err = db.QueryRow(ctx, query, run.ID, run.JobName, run.ScheduledAt, run.Instance, lease.Seconds()).
+			Scan(&run.StartedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("claim job run: %w", err)
+		}
+
+		run.Status, run.Attempts = entities.JobRunRunning, 1
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// RenewRun продлевает аренду запуска и записывает номер текущей попытки
+// и последнюю ошибку. Если запуск уже не идёт (аренда истекла, и его
+// пометил failed другой экземпляр), возвращает entities.ErrNotFound.
+func (r *JobRunRepository) RenewRun(ctx context.Context, id string, attempts int, lastError string, lease time.Duration) error {
+	query := `
+		UPDATE job_runs
+		SET attempts = $2, error = NULLIF($3, ''), lease_until = NOW() + make_interval(secs => $4)
+		WHERE id = $1 AND status = 'running'
+	`
+	tag, err := r.pool.Exec(ctx, query, id, attempts, lastError, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("renew job run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// FinishRun завершает идущий запуск со статусом status. Запуск с истёкшей
+// арендой уже завершён другим экземпляром, и его итог не перезаписывается:
+// возвращается entities.ErrNotFound.
+func (r *JobRunRepository) FinishRun(ctx context.Context, id string, status entities.JobRunStatus, lastError string) error {
+	query := `
+		UPDATE job_runs
+		SET status = $2, error = NULLIF($3, ''), finished_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`
+	tag, err := r.pool.Exec(ctx, query, id, string(status), lastError)
+	if err != nil {
+		return fmt.Errorf("finish job run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+const runColumns = `id, job_name, scheduled_at, status, attempts, instance, started_at, finished_at, error`
+
+// GetLatestRuns возвращает последний запуск каждой задачи.
+func (r *JobRunRepository) GetLatestRuns(ctx context.Context) ([]entities.JobRun, error) {
+	query := `
+		SELECT DISTINCT ON (job_name) ` + runColumns + `
+		FROM job_runs
+		ORDER BY job_name, scheduled_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get latest job runs: %w", err)
+	}
+	return scanRuns(rows)
+}
+
+// GetRuns возвращает последние limit запусков задачи, от новых к старым.
+func (r *JobRunRepository) GetRuns(ctx context.Context, jobName string, limit int) ([]entities.JobRun, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM job_runs
+		WHERE job_name = $1
+		ORDER BY scheduled_at DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, jobName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get job runs: %w", err)
+	}
+	return scanRuns(rows)
+}
+
+// DeleteRunsBefore удаляет завершённые запуски, начатые раньше before.
+func (r *JobRunRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete job runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanRuns(rows pgx.Rows) ([]entities.JobRun, error) {
+	defer rows.Close()
+
+	list := make([]entities.JobRun, 0)
+	for rows.Next() {
+		var (
+			run       entities.JobRun
+			status    string
+			lastError *string
+		)
+		err := rows.Scan(
+			&run.ID, &run.JobName, &run.ScheduledAt, &status, &run.Attempts,
+			&run.Instance, &run.StartedAt, &run.FinishedAt, &lastError,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+
+		run.Status = entities.JobRunStatus(status)
+		if lastError != nil {
+			run.Error = *lastError
+		}
+		list = append(list, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
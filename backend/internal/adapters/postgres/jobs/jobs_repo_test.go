@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtest"
+	"backend/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+func TestFinishRunKeepsExpiredRunResult(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+	jobName := "test-" + uuid.NewString()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM job_runs WHERE job_name = $1`, jobName)
+	})
+
+	repo := NewJobRunRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	slot := time.Now().UTC().Truncate(time.Minute)
+	stale := &entities.JobRun{ID: uuid.NewString(), JobName: jobName, ScheduledAt: slot.Add(-time.Minute), Instance: "a"}
+	if ok, err := repo.ClaimRun(ctx, stale, -time.Second); err != nil || !ok {
+		t.Fatalf("claim stale run = %v, %v", ok, err)
+	}
+
+	// Аренда первого запуска истекла: второй экземпляр занимает следующий
+	// слот, а первый запуск помечается failed
+	fresh := &entities.JobRun{ID: uuid.NewString(), JobName: jobName, ScheduledAt: slot, Instance: "b"}
+	if ok, err := repo.ClaimRun(ctx, fresh, time.Minute); err != nil || !ok {
+		t.Fatalf("claim fresh run = %v, %v", ok, err)
+	}
+
+	if err := repo.FinishRun(ctx, stale.ID, entities.JobRunSucceeded, ""); !errors.Is(err, entities.ErrNotFound) {
+		t.Fatalf("FinishRun(expired) = %v, want ErrNotFound", err)
+	}
+	if err := repo.RenewRun(ctx, stale.ID, 2, "", time.Minute); !errors.Is(err, entities.ErrNotFound) {
+		t.Fatalf("RenewRun(expired) = %v, want ErrNotFound", err)
+	}
+	if err := repo.FinishRun(ctx, fresh.ID, entities.JobRunSkipped, "locked elsewhere"); err != nil {
+		t.Fatalf("FinishRun(fresh): %v", err)
+	}
+
+	runs, err := repo.GetRuns(ctx, jobName, 10)
+	if err != nil {
+		t.Fatalf("GetRuns: %v", err)
+	}
+	want := map[string]entities.JobRunStatus{fresh.ID: entities.JobRunSkipped, stale.ID: entities.JobRunFailed}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	for _, r := range runs {
+		if r.Status != want[r.ID] {
+			t.Errorf("run %s: status %q, want %q", r.Instance, r.Status, want[r.ID])
+		}
+	}
+}
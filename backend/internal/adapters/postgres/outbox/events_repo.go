@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// EnqueueEvent сохраняет событие в event_outbox. Если ctx несёт транзакцию,
+// событие станет видно relay только после её коммита.
+func (r *OutboxRepository) EnqueueEvent(ctx context.Context, e entities.DomainEvent) error {
+	query := `
+		INSERT INTO event_outbox (id, event_type, user_id, payload, occurred_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+	`
+	_, err := r.db(ctx).Exec(ctx, query, e.ID, string(e.Type), e.UserID, []byte(e.Payload), e.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("enqueue event: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueEvents забирает до limit событий так же, как ClaimDue забирает
+// письма: через FOR UPDATE SKIP LOCKED с арендой на время обработки.
+func (r *OutboxRepository) ClaimDueEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	query := `
+		UPDATE event_outbox o
+		SET attempts = o.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE o.id IN (
+			SELECT id FROM event_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.event_type, COALESCE(o.user_id, ''), o.payload, o.occurred_at, o.attempts
+	`
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []entities.OutboxEvent
+	for rows.Next() {
+		var (
+			e         entities.OutboxEvent
+			eventType string
+			payload   []byte
+		)
+		err := rows.Scan(&e.Event.ID, &eventType, &e.Event.UserID, &payload, &e.Event.OccurredAt, &e.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		e.Event.Type = entities.DomainEventType(eventType)
+		e.Event.Payload = payload
+		e.Event.OccurredAt = e.Event.OccurredAt.UTC()
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *OutboxRepository) MarkEventProcessed(ctx context.Context, id string) error {
+	query := `
+		UPDATE event_outbox
+		SET status = 'processed', processed_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("mark event processed: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkEventRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE event_outbox SET next_attempt_at = $2, last_error = $3 WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, id, nextAttemptAt, lastError); err != nil {
+		return fmt.Errorf("mark event retry: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkEventFailed(ctx context.Context, id string, lastError string) error {
+	query := `UPDATE event_outbox SET status = 'failed', last_error = $2 WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("mark event failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewOutboxRepository(connectionURL string) *OutboxRepository {
+	return &OutboxRepository{connectionURL: connectionURL}
+}
+
+func (r *OutboxRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+
+	return nil
+}
+
+func (r *OutboxRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+// db позволяет поставить письмо в очередь в транзакции вызывающего кода.
+func (r *OutboxRepository) db(ctx context.Context) pgtx.Querier {
+	return pgtx.From(ctx, r.pool)
+}
+
+func (r *OutboxRepository) Enqueue(ctx context.Context, e *entities.OutboxEmail) error {
+	query := `
+		INSERT INTO email_outbox (template, locale, to_email, subject, html_body, text_body, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, next_attempt_at, created_at
+	`
+	err := r.db(ctx).QueryRow(ctx, query,
+		e.Template, string(e.Locale), e.Message.To, e.Message.Subject, e.Message.HTML, e.Message.Text, e.ExpiresAt,
+	).Scan(&e.ID, &e.Status, &e.NextAttemptAt, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("enqueue email: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue забирает до limit писем, которым пора уходить, и сдвигает их
+// next_attempt_at на lease вперёд. Пока аренда не истекла, другие
+// отправщики эти письма не увидят; если процесс упадёт, письма вернутся
+// в очередь сами. Каждая выдача увеличивает attempts, и по этому номеру
+// попытки Mark* отличают свою аренду от чужой.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEmail, error) {
+	query := `
+		UPDATE email_outbox o
+		SET attempts = o.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE o.id IN (
+			SELECT id FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND (expires_at IS NULL OR expires_at > NOW())
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.template, o.locale, o.to_email, o.subject,
+		          COALESCE(o.html_body, ''), COALESCE(o.text_body, ''),
+		          o.status, o.attempts, o.next_attempt_at, o.expires_at, o.created_at
+	`
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []entities.OutboxEmail
+	for rows.Next() {
+		var (
+			e      entities.OutboxEmail
+			locale string
+		)
+		err := rows.Scan(
+			&e.ID, &e.Template, &locale, &e.Message.To, &e.Message.Subject,
+			&e.Message.HTML, &e.Message.Text,
+			&e.Status, &e.Attempts, &e.NextAttemptAt, &e.ExpiresAt, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan outbox email: %w", err)
+		}
+		e.Locale = entities.Locale(locale)
+		emails = append(emails, e)
+	}
+	return emails, rows.Err()
+}
+
+// MarkSent закрывает письмо и стирает тело: коды и ссылки больше не нужны.
+// attempt — номер попытки из ClaimDue; если аренду уже перехватил другой
+// отправщик, строка не меняется и возвращается entities.ErrNotFound.
+func (r *OutboxRepository) MarkSent(ctx context.Context, id int64, attempt int) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', sent_at = NOW(), html_body = NULL, text_body = NULL, last_error = NULL
+		WHERE id = $1 AND attempts = $2 AND status = 'pending'
+	`
+	return r.finish(ctx, "mark email sent", query, id, attempt)
+}
+
+func (r *OutboxRepository) MarkRetry(ctx context.Context, id int64, attempt int, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE email_outbox
+		SET next_attempt_at = $3, last_error = $4
+		WHERE id = $1 AND attempts = $2 AND status = 'pending'
+	`
+	return r.finish(ctx, "mark email retry", query, id, attempt, nextAttemptAt, lastError)
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, attempt int, lastError string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'failed', last_error = $3, html_body = NULL, text_body = NULL
+		WHERE id = $1 AND attempts = $2 AND status = 'pending'
+	`
+	return r.finish(ctx, "mark email failed", query, id, attempt, lastError)
+}
+
+func (r *OutboxRepository) finish(ctx context.Context, op, query string, args ...any) error {
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// ExpireDue закрывает неотправленные письма, срок которых истёк, и стирает
+// их тела. Возвращает число таких писем.
+func (r *OutboxRepository) ExpireDue(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE email_outbox
+		SET status = 'expired', html_body = NULL, text_body = NULL
+		WHERE status = 'pending' AND expires_at <= NOW()
+	`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("expire outbox emails: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,93 @@
+package ownership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OwnershipRepository поднимается от модуля, урока или теста к курсу
+// и возвращает его автора. Используется политикой доступа.
+type OwnershipRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewOwnershipRepository(connectionURL string) *OwnershipRepository {
+	return &OwnershipRepository{connectionURL: connectionURL}
+}
+
+func (r *OwnershipRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+
+	return nil
+}
+
+func (r *OwnershipRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+func (r *OwnershipRepository) GetCourseAuthorID(ctx context.Context, courseID string) (string, error) {
+	query := `SELECT author_id FROM courses WHERE id = $1`
+	return r.authorID(ctx, query, courseID)
+}
+
+func (r *OwnershipRepository) GetModuleAuthorID(ctx context.Context, moduleID string) (string, error) {
+	query := `
+		SELECT c.author_id
+		FROM modules m
+		JOIN courses c ON c.id = m.course_id
+		WHERE m.id = $1
+	`
+	return r.authorID(ctx, query, moduleID)
+}
+
+func (r *OwnershipRepository) GetLessonAuthorID(ctx context.Context, lessonID string) (string, error) {
+	query := `
+		SELECT c.author_id
+		FROM lessons l
+		JOIN modules m ON m.id = l.module_id
+		JOIN courses c ON c.id = m.course_id
+		WHERE l.id = $1
+	`
+	return r.authorID(ctx, query, lessonID)
+}
+
+func (r *OwnershipRepository) GetTestAuthorID(ctx context.Context, testID string) (string, error) {
+	query := `
+		SELECT c.author_id
+		FROM tests t
+		JOIN modules m ON m.id = t.module_id
+		JOIN courses c ON c.id = m.course_id
+		WHERE t.id = $1
+	`
+	return r.authorID(ctx, query, testID)
+}
+
+func (r *OwnershipRepository) authorID(ctx context.Context, query, id string) (string, error) {
+	var authorID *string
+	err := pgtx.From(ctx, r.pool).QueryRow(ctx, query, id).Scan(&authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", entities.ErrNotFound
+		}
+		return "", fmt.Errorf("get author: %w", err)
+	}
+	if authorID == nil {
+		return "", nil // курс без автора правит только администратор
+	}
+	return *authorID, nil
+}
@@ -0,0 +1,59 @@
+// Package pgtest помогает тестам репозиториев, которым нужна настоящая
+// база. Тесты запускаются, только если TEST_DATABASE_URL указывает на
+// базу с применёнными миграциями; иначе они пропускаются.
+package pgtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// URL возвращает адрес тестовой базы или пропускает тест.
+func URL(t *testing.T) string {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	return url
+}
+
+// Pool открывает пул к тестовой базе и закрывает его после теста.
+func Pool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), URL(t))
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// Student создаёт ученика с профилем и удаляет его после теста вместе со
+// всем, что на него ссылается.
+func Student(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, email, password_hash, role, first_name, last_name)
+		VALUES ($1, $1 || '@test.local', '-', 'student', 'Test', 'Student')
+	`, id)
+	if err != nil {
+		t.Fatalf("create test user: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id)
+	})
+
+	_, err = pool.Exec(ctx, `INSERT INTO student_profiles (id, user_id) VALUES ($1, $1)`, id)
+	if err != nil {
+		t.Fatalf("create test profile: %v", err)
+	}
+	return id
+}
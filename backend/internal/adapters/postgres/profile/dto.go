@@ -18,6 +18,9 @@ type dto struct {
 	CurrentStreak    int        `db:"current_streak"`
 	MaxStreak        int        `db:"max_streak"`
 	LastActivityDate *time.Time `db:"last_activity_date"`
+	Timezone         string     `db:"timezone"`
+	StreakFreezes    int        `db:"streak_freezes"`
+	CohortID         *string    `db:"current_cohort_id"`
 
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
@@ -39,6 +42,8 @@ func newDTO(sp *entities.StudentProfile) dto {
 		CurrentStreak:    sp.CurrentStreak,
 		MaxStreak:        sp.MaxStreak,
 		LastActivityDate: sp.LastActivityDate,
+		Timezone:         sp.Timezone,
+		StreakFreezes:    sp.StreakFreezes,
 		CreatedAt:        sp.CreatedAt,
 		UpdatedAt:        sp.UpdatedAt,
 	}
@@ -56,6 +61,9 @@ func (d *dto) toEntity() *entities.StudentProfile {
 		CurrentStreak:    d.CurrentStreak,
 		MaxStreak:        d.MaxStreak,
 		LastActivityDate: d.LastActivityDate,
+		Timezone:         d.Timezone,
+		StreakFreezes:    d.StreakFreezes,
+		CohortID:         derefString(d.CohortID),
 		CreatedAt:        d.CreatedAt.UTC(),
 		UpdatedAt:        d.UpdatedAt.UTC(),
 		FirstName:        d.FirstName,
@@ -63,3 +71,10 @@ func (d *dto) toEntity() *entities.StudentProfile {
 		AvatarURL:        d.AvatarURL,
 	}
 }
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"backend/internal/adapters/postgres/pgtx"
 	"backend/internal/entities"
 
 	"github.com/jackc/pgx/v5"
@@ -55,9 +56,9 @@ func (r *StudentProfileRepository) Create(ctx context.Context, profile *entities
 		INSERT INTO student_profiles (
 			id, user_id, grade, xp, level, 
 			current_league_id, weekly_xp, current_streak, max_streak, last_activity_date,
-			created_at, updated_at
+			timezone, streak_freezes, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	_, err := r.pool.Exec(
@@ -65,7 +66,7 @@ func (r *StudentProfileRepository) Create(ctx context.Context, profile *entities
 		query,
 		d.ID, d.UserID, d.Grade, d.XP, d.Level,
 		d.CurrentLeagueID, d.WeeklyXP, d.CurrentStreak, d.MaxStreak, d.LastActivityDate,
-		d.CreatedAt, d.UpdatedAt,
+		d.Timezone, d.StreakFreezes, d.CreatedAt, d.UpdatedAt,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -83,17 +84,32 @@ func (r *StudentProfileRepository) GetByUserID(ctx context.Context, userID strin
 		return nil, fmt.Errorf("not connected to pool")
 	}
 
+	return r.getByUserID(ctx, userID, "")
+}
+
+// GetByUserIDForUpdate блокирует строку профиля до конца транзакции из ctx,
+// чтобы серия считалась от свежих данных и два урока не продлили её дважды.
+func (r *StudentProfileRepository) GetByUserIDForUpdate(ctx context.Context, userID string) (*entities.StudentProfile, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("not connected to pool")
+	}
+
+	return r.getByUserID(ctx, userID, "FOR UPDATE OF sp")
+}
+
+func (r *StudentProfileRepository) getByUserID(ctx context.Context, userID, lock string) (*entities.StudentProfile, error) {
 	query := `
 		SELECT sp.id, sp.user_id, sp.grade, sp.xp, sp.level, 
 		       sp.current_league_id, sp.weekly_xp, sp.current_streak, sp.max_streak, sp.last_activity_date,
+		       sp.timezone, sp.streak_freezes, sp.current_cohort_id,
 		       sp.created_at, sp.updated_at,
                u.first_name, u.last_name, u.avatar_url
 		FROM student_profiles sp
         JOIN users u ON sp.user_id = u.id
 		WHERE sp.user_id = $1
-	`
+	` + lock
 
-	profile, err := scan(r.pool.QueryRow(ctx, query, userID))
+	profile, err := scan(pgtx.From(ctx, r.pool).QueryRow(ctx, query, userID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, entities.ErrNotFound
@@ -104,6 +120,11 @@ func (r *StudentProfileRepository) GetByUserID(ctx context.Context, userID strin
 	return &profile, nil
 }
 
+// Update сохраняет то, что ученик меняет сам: класс и часовой пояс.
+// Остальные поля меняются своими запросами, чтобы запись устаревшего
+// профиля не затёрла их: xp, weekly_xp и level — журналом xp_transactions,
+// серия — UpdateStreak, AddStreakFreeze и FinalizeStreaks, лига —
+// еженедельным сбросом.
 func (r *StudentProfileRepository) Update(ctx context.Context, profile *entities.StudentProfile) error {
 	if r.pool == nil {
 		return fmt.Errorf("not connected to pool")
@@ -115,31 +136,12 @@ func (r *StudentProfileRepository) Update(ctx context.Context, profile *entities
 	query := `
 		UPDATE student_profiles
 		SET grade = $2,
-			xp = $3,
-			level = $4,
-			current_league_id = $5,
-			weekly_xp = $6,
-			current_streak = $7,
-			max_streak = $8,
-			last_activity_date = $9,
-			updated_at = $10
+			timezone = $3,
+			updated_at = $4
 		WHERE id = $1
 	`
 
-	tag, err := r.pool.Exec(
-		ctx,
-		query,
-		d.ID,
-		d.Grade,
-		d.XP,
-		d.Level,
-		d.CurrentLeagueID,
-		d.WeeklyXP,
-		d.CurrentStreak,
-		d.MaxStreak,
-		d.LastActivityDate,
-		d.UpdatedAt,
-	)
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, query, d.ID, d.Grade, d.Timezone, d.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to update student profile: %w", err)
 	}
@@ -151,6 +153,37 @@ func (r *StudentProfileRepository) Update(ctx context.Context, profile *entities
 	return nil
 }
 
+// UpdateStreak сохраняет только серию профиля. Профиль должен быть прочитан
+// GetByUserIDForUpdate в той же транзакции.
+func (r *StudentProfileRepository) UpdateStreak(ctx context.Context, profile *entities.StudentProfile) error {
+	if r.pool == nil {
+		return fmt.Errorf("not connected to pool")
+	}
+
+	profile.UpdatedAt = time.Now().UTC()
+	d := newDTO(profile)
+
+	query := `
+		UPDATE student_profiles
+		SET current_streak = $2,
+			max_streak = $3,
+			last_activity_date = $4,
+			updated_at = $5
+		WHERE id = $1
+	`
+
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, query, d.ID, d.CurrentStreak, d.MaxStreak, d.LastActivityDate, d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update streak: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *StudentProfileRepository) Exists(ctx context.Context, userID string) (bool, error) {
 	if r.pool == nil {
 		return false, fmt.Errorf("not connected to pool")
@@ -167,6 +200,22 @@ func (r *StudentProfileRepository) Exists(ctx context.Context, userID string) (b
 	return exists, nil
 }
 
+// TimezoneSupported сообщает, знает ли Postgres часовой пояс: база Go и
+// база tzdata в Postgres могут расходиться.
+func (r *StudentProfileRepository) TimezoneSupported(ctx context.Context, name string) (bool, error) {
+	if r.pool == nil {
+		return false, fmt.Errorf("not connected to pool")
+	}
+
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_timezone_names WHERE name = $1)`, name).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check timezone: %w", err)
+	}
+
+	return ok, nil
+}
+
 func (r *StudentProfileRepository) GetLeaderboard(ctx context.Context, limit int) ([]*entities.StudentProfile, error) {
 	if r.pool == nil {
 		return nil, fmt.Errorf("not connected to pool")
@@ -175,6 +224,7 @@ func (r *StudentProfileRepository) GetLeaderboard(ctx context.Context, limit int
 	query := `
 		SELECT sp.id, sp.user_id, sp.grade, sp.xp, sp.level, 
 		       sp.current_league_id, sp.weekly_xp, sp.current_streak, sp.max_streak, sp.last_activity_date,
+		       sp.timezone, sp.streak_freezes, sp.current_cohort_id,
 		       sp.created_at, sp.updated_at,
                u.first_name, u.last_name, u.avatar_url
 		FROM student_profiles sp
@@ -202,9 +252,12 @@ func (r *StudentProfileRepository) GetLeaderboard(ctx context.Context, limit int
 	return profiles, nil
 }
 
+// GetLeagueLeaderboard возвращает недельную таблицу когорты cohortID лиги
+// leagueID. Пустой cohortID — ученики лиги без когорты.
 func (r *StudentProfileRepository) GetLeagueLeaderboard(
 	ctx context.Context,
 	leagueID int,
+	cohortID string,
 	limit int,
 ) ([]*entities.StudentProfile, error) {
 	if r.pool == nil {
@@ -214,16 +267,17 @@ func (r *StudentProfileRepository) GetLeagueLeaderboard(
 	query := `
 		SELECT sp.id, sp.user_id, sp.grade, sp.xp, sp.level, 
 		       sp.current_league_id, sp.weekly_xp, sp.current_streak, sp.max_streak, sp.last_activity_date,
+		       sp.timezone, sp.streak_freezes, sp.current_cohort_id,
 		       sp.created_at, sp.updated_at,
                u.first_name, u.last_name, u.avatar_url
 		FROM student_profiles sp
         JOIN users u ON sp.user_id = u.id
-		WHERE sp.current_league_id = $1
-		ORDER BY sp.weekly_xp DESC
+		WHERE sp.current_league_id = $1 AND COALESCE(sp.current_cohort_id, '') = $3
+		ORDER BY sp.weekly_xp DESC, sp.weekly_xp_reached_at ASC, sp.user_id ASC
 		LIMIT $2
 	`
 
-	rows, err := r.pool.Query(ctx, query, leagueID, limit)
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, query, leagueID, limit, cohortID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get league leaderboard: %w", err)
 	}
@@ -267,7 +321,7 @@ func (r *StudentProfileRepository) GetUserGlobalRank(ctx context.Context, userID
 	return rank, nil
 }
 
-// GetUserLeagueRank возвращает позицию пользователя в его лиге
+// GetUserLeagueRank возвращает позицию пользователя в его когорте лиги
 func (r *StudentProfileRepository) GetUserLeagueRank(ctx context.Context, userID string) (int, error) {
 	if r.pool == nil {
 		return 0, fmt.Errorf("not connected to pool")
@@ -275,13 +329,17 @@ func (r *StudentProfileRepository) GetUserLeagueRank(ctx context.Context, userID
 
 	query := `
 		WITH user_league AS (
-			SELECT current_league_id FROM student_profiles WHERE user_id = $1
+			SELECT current_league_id, COALESCE(current_cohort_id, '') AS cohort_id
+			FROM student_profiles WHERE user_id = $1
 		),
 		ranked_profiles AS (
-			SELECT sp.user_id, ROW_NUMBER() OVER (ORDER BY sp.weekly_xp DESC) as rank
+			SELECT sp.user_id, ROW_NUMBER() OVER (
+				ORDER BY sp.weekly_xp DESC, sp.weekly_xp_reached_at ASC, sp.user_id ASC
+			) as rank
 			FROM student_profiles sp
 			CROSS JOIN user_league ul
 			WHERE sp.current_league_id = ul.current_league_id
+			  AND COALESCE(sp.current_cohort_id, '') = ul.cohort_id
 		)
 		SELECT rank FROM ranked_profiles WHERE user_id = $1
 	`
@@ -298,6 +356,207 @@ func (r *StudentProfileRepository) GetUserLeagueRank(ctx context.Context, userID
 	return rank, nil
 }
 
+// SetLeague переводит учеников userIDs в лигу leagueID.
+func (r *StudentProfileRepository) SetLeague(ctx context.Context, userIDs []string, leagueID int) error {
+	if r.pool == nil {
+		return fmt.Errorf("not connected to pool")
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		UPDATE student_profiles SET current_league_id = $2, updated_at = NOW() WHERE user_id = ANY($1)
+	`, userIDs, leagueID)
+	if err != nil {
+		return fmt.Errorf("failed to set league: %w", err)
+	}
+
+	return nil
+}
+
+// GetLeagueCohortIDs возвращает когорты, в которых есть ученики лиги.
+// Пустая строка — ученики лиги без когорты.
+func (r *StudentProfileRepository) GetLeagueCohortIDs(ctx context.Context, leagueID int) ([]string, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("not connected to pool")
+	}
+
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, `
+		SELECT DISTINCT COALESCE(current_cohort_id, '')
+		FROM student_profiles
+		WHERE current_league_id = $1
+		ORDER BY 1
+	`, leagueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league cohorts: %w", err)
+	}
+	defer rows.Close()
+
+	var list []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		list = append(list, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetLeagueMembersByActivity возвращает всех учеников лиги от самых
+// активных за неделю к наименее активным.
+func (r *StudentProfileRepository) GetLeagueMembersByActivity(ctx context.Context, leagueID int) ([]string, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("not connected to pool")
+	}
+
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, `
+		SELECT user_id
+		FROM student_profiles
+		WHERE current_league_id = $1
+		ORDER BY weekly_xp DESC, weekly_xp_reached_at ASC, user_id ASC
+	`, leagueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league members: %w", err)
+	}
+	defer rows.Close()
+
+	var list []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		list = append(list, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
+
+// CountLeagueMembers возвращает число активных (weekly_xp > 0) и
+// неактивных учеников когорты cohortID лиги.
+func (r *StudentProfileRepository) CountLeagueMembers(ctx context.Context, leagueID int, cohortID string) (active, inactive int, err error) {
+	if r.pool == nil {
+		return 0, 0, fmt.Errorf("not connected to pool")
+	}
+
+	err = pgtx.From(ctx, r.pool).QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE weekly_xp > 0), COUNT(*) FILTER (WHERE weekly_xp <= 0)
+		FROM student_profiles
+		WHERE current_league_id = $1 AND COALESCE(current_cohort_id, '') = $2
+	`, leagueID, cohortID).Scan(&active, &inactive)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count league members: %w", err)
+	}
+
+	return active, inactive, nil
+}
+
+// GetLeagueStandingsPage возвращает следующую страницу активных учеников
+// когорты в порядке недельной таблицы, начиная после after (nil — с начала).
+func (r *StudentProfileRepository) GetLeagueStandingsPage(
+	ctx context.Context,
+	leagueID int,
+	cohortID string,
+	after *entities.LeagueStanding,
+	limit int,
+) ([]entities.LeagueStanding, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("not connected to pool")
+	}
+
+	query := `
+		SELECT user_id, weekly_xp, weekly_xp_reached_at
+		FROM student_profiles
+		WHERE current_league_id = $1 AND COALESCE(current_cohort_id, '') = $3 AND weekly_xp > 0
+		ORDER BY weekly_xp DESC, weekly_xp_reached_at ASC, user_id ASC
+		LIMIT $2
+	`
+	args := []any{leagueID, limit, cohortID}
+	if after != nil {
+		query = `
+			SELECT user_id, weekly_xp, weekly_xp_reached_at
+			FROM student_profiles
+			WHERE current_league_id = $1 AND COALESCE(current_cohort_id, '') = $3 AND weekly_xp > 0
+			  AND (weekly_xp < $4
+			       OR (weekly_xp = $4 AND (weekly_xp_reached_at > $5
+			                               OR (weekly_xp_reached_at = $5 AND user_id > $6))))
+			ORDER BY weekly_xp DESC, weekly_xp_reached_at ASC, user_id ASC
+			LIMIT $2
+		`
+		args = append(args, after.WeeklyXP, after.ReachedAt, after.UserID)
+	}
+
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league standings: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]entities.LeagueStanding, 0, limit)
+	for rows.Next() {
+		var st entities.LeagueStanding
+		if err := rows.Scan(&st.UserID, &st.WeeklyXP, &st.ReachedAt); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		list = append(list, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetInactiveLeagueMembersPage возвращает следующую страницу учеников
+// когорты без XP за неделю по возрастанию user_id, начиная после afterUserID.
+func (r *StudentProfileRepository) GetInactiveLeagueMembersPage(
+	ctx context.Context,
+	leagueID int,
+	cohortID string,
+	afterUserID string,
+	limit int,
+) ([]string, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("not connected to pool")
+	}
+
+	rows, err := pgtx.From(ctx, r.pool).Query(ctx, `
+		SELECT user_id
+		FROM student_profiles
+		WHERE current_league_id = $1 AND COALESCE(current_cohort_id, '') = $4
+		  AND weekly_xp <= 0 AND user_id > $2
+		ORDER BY user_id ASC
+		LIMIT $3
+	`, leagueID, afterUserID, limit, cohortID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inactive league members: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]string, 0, limit)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		list = append(list, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
+
 // ResetAllWeeklyXP сбрасывает weekly_xp у всех профилей (для еженедельного сброса)
 func (r *StudentProfileRepository) ResetAllWeeklyXP(ctx context.Context) error {
 	if r.pool == nil {
@@ -306,7 +565,7 @@ func (r *StudentProfileRepository) ResetAllWeeklyXP(ctx context.Context) error {
 
 	query := `UPDATE student_profiles SET weekly_xp = 0, updated_at = NOW()`
 
-	_, err := r.pool.Exec(ctx, query)
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to reset weekly XP: %w", err)
 	}
@@ -314,6 +573,92 @@ func (r *StudentProfileRepository) ResetAllWeeklyXP(ctx context.Context) error {
 	return nil
 }
 
+// AddStreakFreeze выдаёт ученику заморозку серии, если у него их меньше max.
+// Возвращает false, если запас уже полон.
+func (r *StudentProfileRepository) AddStreakFreeze(ctx context.Context, userID string, max int) (bool, error) {
+	if r.pool == nil {
+		return false, fmt.Errorf("not connected to pool")
+	}
+
+	tag, err := pgtx.From(ctx, r.pool).Exec(ctx, `
+		UPDATE student_profiles
+		SET streak_freezes = streak_freezes + 1, updated_at = NOW()
+		WHERE user_id = $1 AND streak_freezes < $2
+	`, userID, max)
+	if err != nil {
+		return false, fmt.Errorf("failed to add streak freeze: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FinalizeStreaks закрывает пропущенные дни серий на момент now: у каждого
+// ученика, чей последний засчитанный день раньше вчерашнего по его часовому
+// поясу, пропущенные дни покрываются заморозками. Заморозки тратятся, только
+// если их хватает на все пропущенные дни; иначе серия сбрасывается, а
+// заморозки остаются. Часовой пояс, которого не знает Postgres, заменяется
+// на entities.DefaultTimezone, как и в StudentProfile.Location.
+// Пустой userID — все ученики. Повторный вызов для того же now ничего
+// не меняет.
+func (r *StudentProfileRepository) FinalizeStreaks(ctx context.Context, now time.Time, userID string) (entities.StreakFinalization, error) {
+	if r.pool == nil {
+		return entities.StreakFinalization{}, fmt.Errorf("not connected to pool")
+	}
+
+	query := `
+		WITH zones AS (
+			SELECT name FROM pg_timezone_names
+		),
+		due AS (
+			SELECT sp.user_id,
+			       sp.last_activity_date AS last_day,
+			       ($1::timestamptz AT TIME ZONE COALESCE(z.name, $3))::date - 1 AS yesterday,
+			       sp.streak_freezes
+			FROM student_profiles sp
+			LEFT JOIN zones z ON z.name = sp.timezone
+			WHERE sp.current_streak > 0
+			  AND sp.last_activity_date < ($1::timestamptz AT TIME ZONE COALESCE(z.name, $3))::date - 1
+			  AND ($2 = '' OR sp.user_id = $2)
+			FOR UPDATE OF sp
+		),
+		plan AS (
+			SELECT user_id, last_day, yesterday,
+			       yesterday - last_day AS missed,
+			       CASE WHEN streak_freezes >= yesterday - last_day THEN yesterday - last_day ELSE 0 END AS covered
+			FROM due
+		),
+		used AS (
+			INSERT INTO streak_freeze_usages (user_id, day)
+			SELECT p.user_id, p.last_day + g.n
+			FROM plan p
+			CROSS JOIN LATERAL generate_series(1, p.covered) AS g(n)
+			ON CONFLICT DO NOTHING
+		),
+		updated AS (
+			UPDATE student_profiles sp
+			SET streak_freezes = sp.streak_freezes - p.covered,
+			    current_streak = CASE WHEN p.covered = p.missed THEN sp.current_streak ELSE 0 END,
+			    last_activity_date = CASE WHEN p.covered = p.missed THEN p.yesterday ELSE sp.last_activity_date END,
+			    updated_at = NOW()
+			FROM plan p
+			WHERE sp.user_id = p.user_id
+			RETURNING p.covered = p.missed AS kept, p.covered
+		)
+		SELECT COUNT(*) FILTER (WHERE kept),
+		       COUNT(*) FILTER (WHERE NOT kept),
+		       COALESCE(SUM(covered), 0)
+		FROM updated
+	`
+
+	var res entities.StreakFinalization
+	err := pgtx.From(ctx, r.pool).QueryRow(ctx, query, now, userID, entities.DefaultTimezone).Scan(&res.Kept, &res.Reset, &res.FreezesUsed)
+	if err != nil {
+		return entities.StreakFinalization{}, fmt.Errorf("failed to finalize streaks: %w", err)
+	}
+
+	return res, nil
+}
+
 type rowScanner interface {
 	Scan(dest ...any) error
 }
@@ -331,6 +676,9 @@ func scan(scanner rowScanner) (entities.StudentProfile, error) {
 		&d.CurrentStreak,
 		&d.MaxStreak,
 		&d.LastActivityDate,
+		&d.Timezone,
+		&d.StreakFreezes,
+		&d.CohortID,
 		&d.CreatedAt,
 		&d.UpdatedAt,
 		&d.FirstName,
@@ -0,0 +1,94 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtest"
+)
+
+// TestUpdatesKeepOtherColumns проверяет, что запись устаревшей копии
+// профиля не откатывает поля, которые она не меняет.
+func TestUpdatesKeepOtherColumns(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+
+	repo := NewStudentProfileRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	userID := pgtest.Student(t, pool)
+	stale, err := repo.GetByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetByUserID: %v", err)
+	}
+
+	// Параллельно поменялись класс и серия
+	_, err = pool.Exec(ctx, `
+		UPDATE student_profiles
+		SET grade = 9, current_streak = 7, max_streak = 7, last_activity_date = '2026-10-16'
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		t.Fatalf("prepare profile: %v", err)
+	}
+
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	streak := *stale
+	streak.CurrentStreak, streak.MaxStreak, streak.LastActivityDate = 8, 8, &day
+	if err := repo.UpdateStreak(ctx, &streak); err != nil {
+		t.Fatalf("UpdateStreak: %v", err)
+	}
+
+	settings := *stale
+	settings.Timezone = "Asia/Tokyo"
+	if err := repo.Update(ctx, &settings); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := repo.GetByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetByUserID: %v", err)
+	}
+	if got.CurrentStreak != 8 || got.MaxStreak != 8 {
+		t.Errorf("streak = %d/%d, want 8/8: Update reverted it", got.CurrentStreak, got.MaxStreak)
+	}
+	if got.Timezone != "Asia/Tokyo" {
+		t.Errorf("timezone = %q, want Asia/Tokyo", got.Timezone)
+	}
+	// Update пишет класс из своей копии, а UpdateStreak его не трогает
+	if got.Grade != stale.Grade {
+		t.Errorf("grade = %d, want %d from Update", got.Grade, stale.Grade)
+	}
+}
+
+func TestGetByUserIDForUpdateLocksRow(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+
+	repo := NewStudentProfileRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	userID := pgtest.Student(t, pool)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM student_profiles WHERE user_id = $1 FOR UPDATE`, userID); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := repo.GetByUserIDForUpdate(lockCtx, userID); err == nil {
+		t.Error("GetByUserIDForUpdate read a row locked by another transaction")
+	}
+}
@@ -0,0 +1,89 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtest"
+	"backend/internal/entities"
+)
+
+func TestFinalizeStreaks(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+
+	repo := NewStudentProfileRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	// 2026-03-12 20:00 UTC — уже 13 марта в Asia/Tokyo, ещё 12-е в UTC
+	now := time.Date(2026, 3, 12, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		timezone    string
+		lastDay     string
+		freezes     int
+		wantStreak  int
+		wantLastDay string
+		wantFreezes int
+		wantUsages  int
+	}{
+		{"active yesterday", "Asia/Tokyo", "2026-03-12", 0, 5, "2026-03-12", 0, 0},
+		{"one missed day covered", "Asia/Tokyo", "2026-03-11", 1, 5, "2026-03-12", 0, 1},
+		{"two missed days covered", "Asia/Tokyo", "2026-03-10", 3, 5, "2026-03-12", 1, 2},
+		{"not enough freezes keeps them", "Asia/Tokyo", "2026-03-09", 2, 0, "2026-03-09", 2, 0},
+		{"no freezes", "Asia/Tokyo", "2026-03-11", 0, 0, "2026-03-11", 0, 0},
+		{"day boundary follows the timezone", "UTC", "2026-03-11", 0, 5, "2026-03-11", 0, 0},
+		// Пояса нет в Postgres: считается по Asia/Almaty, а не валит всю пачку
+		{"unknown timezone falls back", "Mars/Olympus", "2026-03-12", 0, 5, "2026-03-12", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := pgtest.Student(t, pool)
+			_, err := pool.Exec(ctx, `
+				UPDATE student_profiles
+				SET current_streak = 5, max_streak = 5, timezone = $2,
+				    last_activity_date = $3::date, streak_freezes = $4
+				WHERE user_id = $1
+			`, userID, tt.timezone, tt.lastDay, tt.freezes)
+			if err != nil {
+				t.Fatalf("prepare profile: %v", err)
+			}
+
+			for run := 0; run < 2; run++ {
+				res, err := repo.FinalizeStreaks(ctx, now, userID)
+				if err != nil {
+					t.Fatalf("FinalizeStreaks (run %d): %v", run+1, err)
+				}
+				if run == 1 && res != (entities.StreakFinalization{}) {
+					t.Errorf("second run changed %+v, want nothing", res)
+				}
+			}
+
+			var (
+				streak, freezes, usages int
+				lastDay                 time.Time
+			)
+			err = pool.QueryRow(ctx, `
+				SELECT current_streak, last_activity_date, streak_freezes,
+				       (SELECT COUNT(*) FROM streak_freeze_usages WHERE user_id = $1)
+				FROM student_profiles WHERE user_id = $1
+			`, userID).Scan(&streak, &lastDay, &freezes, &usages)
+			if err != nil {
+				t.Fatalf("read profile: %v", err)
+			}
+
+			if streak != tt.wantStreak || lastDay.Format(time.DateOnly) != tt.wantLastDay ||
+				freezes != tt.wantFreezes || usages != tt.wantUsages {
+				t.Errorf("streak=%d last=%s freezes=%d usages=%d, want %d/%s/%d/%d",
+					streak, lastDay.Format(time.DateOnly), freezes, usages,
+					tt.wantStreak, tt.wantLastDay, tt.wantFreezes, tt.wantUsages)
+			}
+		})
+	}
+}
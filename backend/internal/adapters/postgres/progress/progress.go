@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"backend/internal/adapters/postgres/pgtx"
 	"backend/internal/entities"
 
 	"github.com/jackc/pgx/v5"
@@ -35,6 +36,16 @@ func (r *ProgressRepository) Close() {
 	}
 }
 
+// WithinTransaction выполняет fn в одной транзакции: запись прогресса
+// и события в outbox фиксируются вместе.
+func (r *ProgressRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+func (r *ProgressRepository) db(ctx context.Context) pgtx.Querier {
+	return pgtx.From(ctx, r.pool)
+}
+
 func (r *ProgressRepository) GetCompletedLessonIDs(ctx context.Context, userID, courseID string) ([]string, error) {
 	query := `
 		SELECT lp.lesson_id
@@ -79,7 +90,7 @@ func (r *ProgressRepository) UpsertLessonProgress(ctx context.Context, lp *entit
 			last_accessed_at = EXCLUDED.last_accessed_at
 	`
 
-	_, err := r.pool.Exec(ctx, query, lp.UserID, lp.LessonID, string(lp.Status), lp.IsCompleted, lp.LastAccessedAt)
+	_, err := r.db(ctx).Exec(ctx, query, lp.UserID, lp.LessonID, string(lp.Status), lp.IsCompleted, lp.LastAccessedAt)
 	if err != nil {
 		return fmt.Errorf("upsert lesson progress: %w", err)
 	}
@@ -146,7 +157,7 @@ func (r *ProgressRepository) UpsertCourseProgress(ctx context.Context, cp *entit
 			updated_at = EXCLUDED.updated_at
 	`
 
-	_, err := r.pool.Exec(
+	_, err := r.db(ctx).Exec(
 		ctx, query,
 		cp.UserID, cp.CourseID,
 		cp.CompletedLessonsCount, cp.TotalLessonsCount, cp.ProgressPercentage,
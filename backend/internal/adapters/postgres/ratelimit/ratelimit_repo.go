@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	cleanupInterval = 10 * time.Minute
+	// корзина, не тронутая час, давно наполнилась при любых разумных лимитах
+	bucketIdleTTL = time.Hour
+)
+
+// RateLimitRepository — token bucket в Postgres, общий для всех реплик API.
+// Пополнение и списание делаются одним UPSERT, поэтому параллельные
+// запросы с одним ключом не могут потратить один и тот же токен.
+//
+// Каждый запрос под лимитом — это запись в базу, включая общий apiLimit
+// на все защищённые маршруты. Поэтому этот store нужен только при
+// нескольких экземплярах API; с одним экземпляром берите память
+// (RATE_LIMIT_BACKEND=memory, по умолчанию).
+type RateLimitRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+
+	lastCleanup atomic.Int64
+}
+
+func NewRateLimitRepository(connectionURL string) *RateLimitRepository {
+	return &RateLimitRepository{connectionURL: connectionURL}
+}
+
+func (r *RateLimitRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+
+	return nil
+}
+
+func (r *RateLimitRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+func (r *RateLimitRepository) Take(ctx context.Context, key string, burst int, perSecond float64) (float64, bool, error) {
+	r.maybeCleanup()
+
+	// В DO UPDATE строка b уже заблокирована и актуальна, поэтому пополнение
+	// считается прямо от неё. $2 — ёмкость, $3 — токенов в секунду.
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, TRUE, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE
+				WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (NOW() - b.updated_at)), 0) * $3::float8) >= 1
+				THEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (NOW() - b.updated_at)), 0) * $3::float8) - 1
+				ELSE LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (NOW() - b.updated_at)), 0) * $3::float8)
+			END,
+			allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (NOW() - b.updated_at)), 0) * $3::float8) >= 1,
+			updated_at = NOW()
+		RETURNING tokens, allowed
+	`
+	var (
+		tokens  float64
+		allowed bool
+	)
+	if err := r.pool.QueryRow(ctx, query, key, burst, perSecond).Scan(&tokens, &allowed); err != nil {
+		return 0, false, fmt.Errorf("take rate limit token: %w", err)
+	}
+	return tokens, allowed, nil
+}
+
+// maybeCleanup не чаще раза в cleanupInterval удаляет в фоне старые корзины.
+func (r *RateLimitRepository) maybeCleanup() {
+	now := time.Now().Unix()
+	last := r.lastCleanup.Load()
+	if now-last < int64(cleanupInterval.Seconds()) || !r.lastCleanup.CompareAndSwap(last, now) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		query := `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => $1)`
+		if _, err := r.pool.Exec(ctx, query, bucketIdleTTL.Seconds()); err != nil {
+			log.Warn().Err(err).Msg("failed to clean up rate limit buckets")
+		}
+	}()
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewSessionRepository(connectionURL string) *SessionRepository {
+	return &SessionRepository{connectionURL: connectionURL}
+}
+
+func (r *SessionRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+
+	return nil
+}
+
+func (r *SessionRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+func (r *SessionRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+func (r *SessionRepository) db(ctx context.Context) pgtx.Querier {
+	return pgtx.From(ctx, r.pool)
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, s *entities.AuthSession) error {
+	query := `
+		INSERT INTO auth_sessions (id, user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := r.db(ctx).Exec(ctx, query, s.ID, s.UserID, s.CreatedAt, s.ExpiresAt); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*entities.AuthSession, error) {
+	query := `
+		SELECT id, user_id, created_at, expires_at, revoked_at
+		FROM auth_sessions
+		WHERE id = $1
+	`
+	var s entities.AuthSession
+	err := r.db(ctx).QueryRow(ctx, query, id).Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	return &s, nil
+}
+
+// RevokeSession отзывает сессию; уже отозванная сессия не меняется.
+func (r *SessionRepository) RevokeSession(ctx context.Context, id, reason string, at time.Time) error {
+	query := `
+		UPDATE auth_sessions
+		SET revoked_at = $2, revoke_reason = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	if _, err := r.db(ctx).Exec(ctx, query, id, at, reason); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID, reason string, at time.Time) error {
+	query := `
+		UPDATE auth_sessions
+		SET revoked_at = $2, revoke_reason = $3
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := r.db(ctx).Exec(ctx, query, userID, at, reason); err != nil {
+		return fmt.Errorf("revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) SaveRefreshToken(ctx context.Context, t *entities.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (token_hash, session_id, created_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := r.db(ctx).Exec(ctx, query, t.Hash, t.SessionID, t.CreatedAt); err != nil {
+		return fmt.Errorf("save refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshTokenForUpdate блокирует строку токена до конца транзакции,
+// чтобы два параллельных обновления одним токеном не прошли оба.
+func (r *SessionRepository) GetRefreshTokenForUpdate(ctx context.Context, hash string) (*entities.RefreshToken, error) {
+	query := `
+		SELECT token_hash, session_id, created_at, rotated_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+	var t entities.RefreshToken
+	err := r.db(ctx).QueryRow(ctx, query, hash).Scan(&t.Hash, &t.SessionID, &t.CreatedAt, &t.RotatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, fmt.Errorf("get refresh token: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *SessionRepository) MarkRefreshTokenRotated(ctx context.Context, hash string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET rotated_at = $2 WHERE token_hash = $1`
+	if _, err := r.db(ctx).Exec(ctx, query, hash, at); err != nil {
+		return fmt.Errorf("mark refresh token rotated: %w", err)
+	}
+	return nil
+}
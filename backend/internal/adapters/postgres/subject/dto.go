@@ -5,17 +5,15 @@ import (
 )
 
 type dto struct {
-	ID     string `db:"id"`
-	Slug   string `db:"slug"`
-	NameRu string `db:"name_ru"`
-	NameKz string `db:"name_kz"`
+	ID   string `db:"id"`
+	Slug string `db:"slug"`
+	Name string `db:"name"`
 }
 
 func (d *dto) toEntity() entities.Subject {
 	return entities.Subject{
-		ID:     d.ID,
-		Slug:   d.Slug,
-		NameRu: d.NameRu,
-		NameKz: d.NameKz,
+		ID:   d.ID,
+		Slug: d.Slug,
+		Name: d.Name,
 	}
 }
@@ -37,9 +37,14 @@ func (r *SubjectRepository) Close() {
 }
 
 func (r *SubjectRepository) GetAll(ctx context.Context) ([]entities.Subject, error) {
-	query := `SELECT id, slug, name_ru, name_kz FROM subjects ORDER BY slug ASC`
+	query := `
+		SELECT s.id, s.slug, COALESCE(t.name, s.name)
+		FROM subjects s
+		LEFT JOIN subject_translations t ON t.subject_id = s.id AND t.locale = $1
+		ORDER BY s.slug ASC
+	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subjects: %w", err)
 	}
@@ -48,7 +53,7 @@ func (r *SubjectRepository) GetAll(ctx context.Context) ([]entities.Subject, err
 	var subjects []entities.Subject
 	for rows.Next() {
 		var d dto
-		if err := rows.Scan(&d.ID, &d.Slug, &d.NameRu, &d.NameKz); err != nil {
+		if err := rows.Scan(&d.ID, &d.Slug, &d.Name); err != nil {
 			return nil, err
 		}
 		subjects = append(subjects, d.toEntity())
@@ -58,10 +63,15 @@ func (r *SubjectRepository) GetAll(ctx context.Context) ([]entities.Subject, err
 }
 
 func (r *SubjectRepository) GetByID(ctx context.Context, id string) (*entities.Subject, error) {
-	query := `SELECT id, slug, name_ru, name_kz FROM subjects WHERE id = $1`
+	query := `
+		SELECT s.id, s.slug, COALESCE(t.name, s.name)
+		FROM subjects s
+		LEFT JOIN subject_translations t ON t.subject_id = s.id AND t.locale = $2
+		WHERE s.id = $1
+	`
 
 	var d dto
-	err := r.pool.QueryRow(ctx, query, id).Scan(&d.ID, &d.Slug, &d.NameRu, &d.NameKz)
+	err := r.pool.QueryRow(ctx, query, id, string(entities.LocaleFrom(ctx))).Scan(&d.ID, &d.Slug, &d.Name)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, entities.ErrNotFound
@@ -100,13 +110,14 @@ func (r *SubjectRepository) RemoveInterest(ctx context.Context, userID, subjectI
 
 func (r *SubjectRepository) GetByUserID(ctx context.Context, userID string) ([]entities.Subject, error) {
 	query := `
-		SELECT s.id, s.slug, s.name_ru, s.name_kz
+		SELECT s.id, s.slug, COALESCE(t.name, s.name)
 		FROM subjects s
 		JOIN student_interests si ON s.id = si.subject_id
+		LEFT JOIN subject_translations t ON t.subject_id = s.id AND t.locale = $2
 		WHERE si.user_id = $1
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, userID, string(entities.LocaleFrom(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user interests: %w", err)
 	}
@@ -115,7 +126,7 @@ func (r *SubjectRepository) GetByUserID(ctx context.Context, userID string) ([]e
 	var subjects []entities.Subject
 	for rows.Next() {
 		var d dto
-		if err := rows.Scan(&d.ID, &d.Slug, &d.NameRu, &d.NameKz); err != nil {
+		if err := rows.Scan(&d.ID, &d.Slug, &d.Name); err != nil {
 			return nil, err
 		}
 		subjects = append(subjects, d.toEntity())
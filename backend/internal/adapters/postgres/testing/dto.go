@@ -7,44 +7,119 @@ import (
 )
 
 type testDTO struct {
-	ID           string `db:"id"`
-	ModuleID     string `db:"module_id"`
-	Title        string `db:"title"`
-	PassingScore int    `db:"passing_score"`
+	ID           string            `db:"id"`
+	ModuleID     string            `db:"module_id"`
+	Title        string            `db:"title"`
+	PassingScore int               `db:"passing_score"`
+	ScoringRules map[string]string `db:"scoring_rules"`
+	MaxAttempts  int               `db:"max_attempts"`
+	CooldownSec  int               `db:"cooldown_seconds"`
+	TimeLimitSec int               `db:"time_limit_seconds"`
+	Shuffle      bool              `db:"shuffle_answers"`
+}
+
+func newTestDTO(t *entities.Test) testDTO {
+	rules := make(map[string]string, len(t.ScoringRules))
+	for qType, mode := range t.ScoringRules {
+		rules[qType] = string(mode)
+	}
+
+	return testDTO{
+		ID:           t.ID,
+		ModuleID:     t.ModuleID,
+		Title:        t.Title,
+		PassingScore: t.PassingScore,
+		ScoringRules: rules,
+		MaxAttempts:  t.MaxAttempts,
+		CooldownSec:  int(t.Cooldown / time.Second),
+		TimeLimitSec: int(t.TimeLimit / time.Second),
+		Shuffle:      t.ShuffleAnswers,
+	}
 }
 
 func (d *testDTO) toEntity() *entities.Test {
+	rules := make(map[string]entities.ScoringMode, len(d.ScoringRules))
+	for qType, mode := range d.ScoringRules {
+		rules[qType] = entities.ScoringMode(mode)
+	}
+
 	return &entities.Test{
-		ID:           d.ID,
-		ModuleID:     d.ModuleID,
-		Title:        d.Title,
-		PassingScore: d.PassingScore,
-		Questions:    []entities.Question{}, // Инициализируем пустым слайсом
+		ID:             d.ID,
+		ModuleID:       d.ModuleID,
+		Title:          d.Title,
+		PassingScore:   d.PassingScore,
+		ScoringRules:   rules,
+		MaxAttempts:    d.MaxAttempts,
+		Cooldown:       time.Duration(d.CooldownSec) * time.Second,
+		TimeLimit:      time.Duration(d.TimeLimitSec) * time.Second,
+		ShuffleAnswers: d.Shuffle,
+		PoolRules:      []entities.PoolRule{},
+		Questions:      []entities.Question{}, // Инициализируем пустым слайсом
 	}
 }
 
 type questionDTO struct {
-	ID           string `db:"id"`
-	TestID       string `db:"test_id"`
-	Text         string `db:"text"`
-	QuestionType string `db:"question_type"`
+	ID               string   `db:"id"`
+	TestID           string   `db:"test_id"`
+	Text             string   `db:"text"`
+	QuestionType     string   `db:"question_type"`
+	NumericValue     *float64 `db:"numeric_value"`
+	NumericTolerance float64  `db:"numeric_tolerance"`
 }
 
 func (d *questionDTO) toEntity() entities.Question {
 	return entities.Question{
-		ID:           d.ID,
-		TestID:       d.TestID,
-		Text:         d.Text,
-		QuestionType: d.QuestionType,
-		Answers:      []entities.Answer{},
+		ID:               d.ID,
+		TestID:           d.TestID,
+		Text:             d.Text,
+		QuestionType:     d.QuestionType,
+		NumericValue:     d.NumericValue,
+		NumericTolerance: d.NumericTolerance,
+		Answers:          []entities.Answer{},
 	}
 }
 
+// bankQuestionDTO — вопрос банка курса; test_id у него NULL.
+type bankQuestionDTO struct {
+	questionDTO
+	CourseID   *string  `db:"course_id"`
+	Difficulty *int     `db:"difficulty"`
+	Tags       []string `db:"tags"`
+}
+
+func (d *bankQuestionDTO) toEntity() entities.Question {
+	q := d.questionDTO.toEntity()
+	if d.CourseID != nil {
+		q.CourseID = *d.CourseID
+	}
+	if d.Difficulty != nil {
+		q.Difficulty = *d.Difficulty
+	}
+	q.Tags = d.Tags
+	return q
+}
+
+type poolRuleDTO struct {
+	Count      int      `db:"question_count"`
+	Tags       []string `db:"tags"`
+	Difficulty *int     `db:"difficulty"`
+}
+
+func (d *poolRuleDTO) toEntity() entities.PoolRule {
+	r := entities.PoolRule{Count: d.Count, Tags: d.Tags}
+	if d.Difficulty != nil {
+		r.Difficulty = *d.Difficulty
+	}
+	return r
+}
+
 type answerDTO struct {
 	ID         string `db:"id"`
 	QuestionID string `db:"question_id"`
 	Text       string `db:"text"`
 	IsCorrect  bool   `db:"is_correct"`
+	OrderIndex int    `db:"order_index"`
+	MatchText  string `db:"match_text"`
 }
 
 func (d *answerDTO) toEntity() entities.Answer {
@@ -53,6 +128,8 @@ func (d *answerDTO) toEntity() entities.Answer {
 		QuestionID: d.QuestionID,
 		Text:       d.Text,
 		IsCorrect:  d.IsCorrect,
+		OrderIndex: d.OrderIndex,
+		MatchText:  d.MatchText,
 	}
 }
 
@@ -64,3 +141,129 @@ type resultDTO struct {
 	IsPassed    bool      `db:"is_passed"`
 	AttemptDate time.Time `db:"attempt_date"`
 }
+
+type attemptAnswerDTO struct {
+	ID           string            `db:"id"`
+	ResultID     string            `db:"result_id"`
+	QuestionID   string            `db:"question_id"`
+	Position     int               `db:"position"`
+	QuestionText string            `db:"question_text"`
+	QuestionType string            `db:"question_type"`
+	AnswerIDs    []string          `db:"answer_ids"`
+	AnswerTexts  []string          `db:"answer_texts"`
+	TextAnswer   string            `db:"text_answer"`
+	Pairs        map[string]string `db:"pairs"`
+	IsCorrect    bool              `db:"is_correct"`
+	Points       float64           `db:"points"`
+}
+
+func newAttemptAnswerDTO(a *entities.TestAttemptAnswer) attemptAnswerDTO {
+	d := attemptAnswerDTO{
+		ID:           a.ID,
+		ResultID:     a.ResultID,
+		QuestionID:   a.QuestionID,
+		Position:     a.Position,
+		QuestionText: a.QuestionText,
+		QuestionType: a.QuestionType,
+		AnswerIDs:    a.AnswerIDs,
+		AnswerTexts:  a.AnswerTexts,
+		TextAnswer:   a.TextAnswer,
+		Pairs:        a.Pairs,
+		IsCorrect:    a.IsCorrect,
+		Points:       a.Points,
+	}
+	// Колонки NOT NULL: nil-слайсы и map pgx передаёт как NULL
+	if d.AnswerIDs == nil {
+		d.AnswerIDs = []string{}
+	}
+	if d.AnswerTexts == nil {
+		d.AnswerTexts = []string{}
+	}
+	if d.Pairs == nil {
+		d.Pairs = map[string]string{}
+	}
+	return d
+}
+
+func (d *attemptAnswerDTO) toEntity() entities.TestAttemptAnswer {
+	return entities.TestAttemptAnswer{
+		ID:           d.ID,
+		ResultID:     d.ResultID,
+		QuestionID:   d.QuestionID,
+		Position:     d.Position,
+		QuestionText: d.QuestionText,
+		QuestionType: d.QuestionType,
+		AnswerIDs:    d.AnswerIDs,
+		AnswerTexts:  d.AnswerTexts,
+		TextAnswer:   d.TextAnswer,
+		Pairs:        d.Pairs,
+		IsCorrect:    d.IsCorrect,
+		Points:       d.Points,
+	}
+}
+
+func (d *resultDTO) toEntity() entities.TestResult {
+	return entities.TestResult{
+		ID:          d.ID,
+		UserID:      d.UserID,
+		TestID:      d.TestID,
+		Score:       d.Score,
+		IsPassed:    d.IsPassed,
+		AttemptDate: d.AttemptDate,
+	}
+}
+
+type sessionDTO struct {
+	ID          string      `db:"id"`
+	UserID      string      `db:"user_id"`
+	TestID      string      `db:"test_id"`
+	StartedAt   time.Time   `db:"started_at"`
+	Deadline    *time.Time  `db:"deadline_at"`
+	SubmittedAt *time.Time  `db:"submitted_at"`
+	ResultID    *string     `db:"result_id"`
+	Served      []servedDTO `db:"served"`
+}
+
+type servedDTO struct {
+	QuestionID   string           `json:"question_id"`
+	AnswerIDs    []string         `json:"answer_ids"`
+	MatchOptions []matchOptionDTO `json:"match_options,omitempty"`
+}
+
+type matchOptionDTO struct {
+	Key      string `json:"key"`
+	AnswerID string `json:"answer_id"`
+}
+
+func newServedDTO(served []entities.ServedQuestion) []servedDTO {
+	res := make([]servedDTO, 0, len(served))
+	for _, q := range served {
+		d := servedDTO{QuestionID: q.QuestionID, AnswerIDs: q.AnswerIDs}
+		for _, o := range q.MatchOptions {
+			d.MatchOptions = append(d.MatchOptions, matchOptionDTO{Key: o.Key, AnswerID: o.AnswerID})
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+func (d *sessionDTO) toEntity() *entities.TestSession {
+	s := &entities.TestSession{
+		ID:          d.ID,
+		UserID:      d.UserID,
+		TestID:      d.TestID,
+		StartedAt:   d.StartedAt,
+		Deadline:    d.Deadline,
+		SubmittedAt: d.SubmittedAt,
+		ResultID:    d.ResultID,
+		Served:      make([]entities.ServedQuestion, 0, len(d.Served)),
+	}
+	for _, q := range d.Served {
+		sq := entities.ServedQuestion{QuestionID: q.QuestionID, AnswerIDs: q.AnswerIDs}
+		for _, o := range q.MatchOptions {
+			sq.MatchOptions = append(sq.MatchOptions, entities.MatchOption{Key: o.Key, AnswerID: o.AnswerID})
+		}
+		s.Served = append(s.Served, sq)
+	}
+	return s
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"backend/internal/adapters/postgres/pgtx"
 	"backend/internal/entities"
 
 	"github.com/jackc/pgx/v5"
@@ -37,28 +38,69 @@ func (r *TestRepository) Close() {
 	}
 }
 
+// WithinTransaction выполняет fn в одной транзакции: все методы репозитория,
+// вызванные с переданным в fn контекстом, пишут в неё.
+func (r *TestRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+func (r *TestRepository) db(ctx context.Context) pgtx.Querier {
+	return pgtx.From(ctx, r.pool)
+}
+
 func (r *TestRepository) CreateTest(ctx context.Context, test *entities.Test) error {
-	query := `INSERT INTO tests (id, module_id, title, passing_score) VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, test.ID, test.ModuleID, test.Title, test.PassingScore)
+	d := newTestDTO(test)
+	query := `
+		INSERT INTO tests (
+			id, module_id, title, passing_score, scoring_rules,
+			max_attempts, cooldown_seconds, time_limit_seconds, shuffle_answers
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db(ctx).Exec(ctx, query,
+		d.ID, d.ModuleID, d.Title, d.PassingScore, d.ScoringRules,
+		d.MaxAttempts, d.CooldownSec, d.TimeLimitSec, d.Shuffle,
+	)
 	return err
 }
 
 func (r *TestRepository) AddQuestion(ctx context.Context, q *entities.Question) error {
-	query := `INSERT INTO questions (id, test_id, text, question_type) VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, q.ID, q.TestID, q.Text, q.QuestionType)
+	query := `
+		INSERT INTO questions (
+			id, test_id, text, question_type, numeric_value, numeric_tolerance, course_id, difficulty, tags
+		)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, 0), $9)
+	`
+	tags := q.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := r.db(ctx).Exec(ctx, query,
+		q.ID, q.TestID, q.Text, q.QuestionType, q.NumericValue, q.NumericTolerance, q.CourseID, q.Difficulty, tags,
+	)
 	return err
 }
 
 func (r *TestRepository) AddAnswer(ctx context.Context, a *entities.Answer) error {
-	query := `INSERT INTO answers (id, question_id, text, is_correct) VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, a.ID, a.QuestionID, a.Text, a.IsCorrect)
+	query := `
+		INSERT INTO answers (id, question_id, text, is_correct, order_index, match_text)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db(ctx).Exec(ctx, query, a.ID, a.QuestionID, a.Text, a.IsCorrect, a.OrderIndex, a.MatchText)
 	return err
 }
 
 func (r *TestRepository) GetTestByModuleID(ctx context.Context, moduleID string) (*entities.Test, error) {
 	var tDTO testDTO
-	queryTest := `SELECT id, module_id, title, passing_score FROM tests WHERE module_id = $1`
-	err := r.pool.QueryRow(ctx, queryTest, moduleID).Scan(&tDTO.ID, &tDTO.ModuleID, &tDTO.Title, &tDTO.PassingScore)
+	queryTest := `
+		SELECT id, module_id, title, passing_score, scoring_rules,
+		       max_attempts, cooldown_seconds, time_limit_seconds, shuffle_answers
+		FROM tests WHERE module_id = $1
+	`
+	err := r.db(ctx).QueryRow(ctx, queryTest, moduleID).Scan(
+		&tDTO.ID, &tDTO.ModuleID, &tDTO.Title, &tDTO.PassingScore, &tDTO.ScoringRules,
+		&tDTO.MaxAttempts, &tDTO.CooldownSec, &tDTO.TimeLimitSec, &tDTO.Shuffle,
+	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, entities.ErrNotFound
@@ -67,9 +109,15 @@ func (r *TestRepository) GetTestByModuleID(ctx context.Context, moduleID string)
 	}
 
 	test := tDTO.toEntity()
+	if test.PoolRules, err = r.getPoolRules(ctx, test.ID); err != nil {
+		return nil, err
+	}
 
-	queryQuestions := `SELECT id, test_id, text, question_type FROM questions WHERE test_id = $1`
-	rowsQ, err := r.pool.Query(ctx, queryQuestions, test.ID)
+	queryQuestions := `
+		SELECT id, test_id, text, question_type, numeric_value, numeric_tolerance
+		FROM questions WHERE test_id = $1
+	`
+	rowsQ, err := r.db(ctx).Query(ctx, queryQuestions, test.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get questions: %w", err)
 	}
@@ -80,7 +128,10 @@ func (r *TestRepository) GetTestByModuleID(ctx context.Context, moduleID string)
 
 	for rowsQ.Next() {
 		var qDTO questionDTO
-		if err := rowsQ.Scan(&qDTO.ID, &qDTO.TestID, &qDTO.Text, &qDTO.QuestionType); err != nil {
+		err := rowsQ.Scan(
+			&qDTO.ID, &qDTO.TestID, &qDTO.Text, &qDTO.QuestionType, &qDTO.NumericValue, &qDTO.NumericTolerance,
+		)
+		if err != nil {
 			return nil, err
 		}
 		q := qDTO.toEntity()
@@ -92,8 +143,12 @@ func (r *TestRepository) GetTestByModuleID(ctx context.Context, moduleID string)
 		return test, nil
 	}
 
-	queryAnswers := `SELECT id, question_id, text, is_correct FROM answers WHERE question_id = ANY($1)`
-	rowsA, err := r.pool.Query(ctx, queryAnswers, questionIDs)
+	queryAnswers := `
+		SELECT id, question_id, text, is_correct, order_index, match_text
+		FROM answers WHERE question_id = ANY($1)
+		ORDER BY order_index
+	`
+	rowsA, err := r.db(ctx).Query(ctx, queryAnswers, questionIDs)
 	if err != nil {
 		return nil, fmt.Errorf("get answers: %w", err)
 	}
@@ -101,7 +156,10 @@ func (r *TestRepository) GetTestByModuleID(ctx context.Context, moduleID string)
 
 	for rowsA.Next() {
 		var aDTO answerDTO
-		if err := rowsA.Scan(&aDTO.ID, &aDTO.QuestionID, &aDTO.Text, &aDTO.IsCorrect); err != nil {
+		err := rowsA.Scan(
+			&aDTO.ID, &aDTO.QuestionID, &aDTO.Text, &aDTO.IsCorrect, &aDTO.OrderIndex, &aDTO.MatchText,
+		)
+		if err != nil {
 			return nil, err
 		}
 		a := aDTO.toEntity()
@@ -129,7 +187,7 @@ func (r *TestRepository) SaveResult(ctx context.Context, res *entities.TestResul
 		INSERT INTO test_results (id, user_id, test_id, score, is_passed, attempt_date)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.pool.Exec(ctx, query, res.ID, res.UserID, res.TestID, res.Score, res.IsPassed, res.AttemptDate)
+	_, err := r.db(ctx).Exec(ctx, query, res.ID, res.UserID, res.TestID, res.Score, res.IsPassed, res.AttemptDate)
 	return err
 }
 
@@ -141,7 +199,7 @@ func (r *TestRepository) GetUserResults(ctx context.Context, userID string) ([]e
 		ORDER BY attempt_date DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.db(ctx).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +224,191 @@ func (r *TestRepository) GetUserResults(ctx context.Context, userID string) ([]e
 	return results, nil
 }
 
+func (r *TestRepository) SaveAttemptAnswers(ctx context.Context, answers []entities.TestAttemptAnswer) error {
+	query := `
+		INSERT INTO test_attempt_answers (
+			id, result_id, question_id, position, question_text, question_type,
+			answer_ids, answer_texts, text_answer, pairs, is_correct, points
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for i := range answers {
+		d := newAttemptAnswerDTO(&answers[i])
+		_, err := r.db(ctx).Exec(ctx, query,
+			d.ID, d.ResultID, d.QuestionID, d.Position, d.QuestionText, d.QuestionType,
+			d.AnswerIDs, d.AnswerTexts, d.TextAnswer, d.Pairs, d.IsCorrect, d.Points,
+		)
+		if err != nil {
+			return fmt.Errorf("save attempt answer: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetAttempts возвращает попытки по тесту от новых к старым. Пустой userID —
+// попытки всех студентов. limit <= 0 — без ограничения.
+func (r *TestRepository) GetAttempts(ctx context.Context, testID, userID string, limit int) ([]entities.TestAttempt, error) {
+	query := `
+		SELECT id, user_id, test_id, score, is_passed, attempt_date
+		FROM test_results
+		WHERE test_id = $1 AND ($2 = '' OR user_id = $2)
+		ORDER BY attempt_date DESC
+		LIMIT NULLIF($3, 0)
+	`
+	if limit < 0 {
+		limit = 0
+	}
+
+	rows, err := r.db(ctx).Query(ctx, query, testID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []entities.TestAttempt
+	byResult := make(map[string]int)
+	var resultIDs []string
+	for rows.Next() {
+		var rDTO resultDTO
+		err := rows.Scan(&rDTO.ID, &rDTO.UserID, &rDTO.TestID, &rDTO.Score, &rDTO.IsPassed, &rDTO.AttemptDate)
+		if err != nil {
+			return nil, err
+		}
+		byResult[rDTO.ID] = len(attempts)
+		resultIDs = append(resultIDs, rDTO.ID)
+		attempts = append(attempts, entities.TestAttempt{
+			Result:  rDTO.toEntity(),
+			Answers: []entities.TestAttemptAnswer{},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(resultIDs) == 0 {
+		return attempts, nil
+	}
+
+	queryAnswers := `
+		SELECT id, result_id, question_id, position, question_text, question_type,
+		       answer_ids, answer_texts, text_answer, pairs, is_correct, points
+		FROM test_attempt_answers
+		WHERE result_id = ANY($1)
+		ORDER BY position
+	`
+	rowsA, err := r.db(ctx).Query(ctx, queryAnswers, resultIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get attempt answers: %w", err)
+	}
+	defer rowsA.Close()
+
+	for rowsA.Next() {
+		var d attemptAnswerDTO
+		err := rowsA.Scan(
+			&d.ID, &d.ResultID, &d.QuestionID, &d.Position, &d.QuestionText, &d.QuestionType,
+			&d.AnswerIDs, &d.AnswerTexts, &d.TextAnswer, &d.Pairs, &d.IsCorrect, &d.Points,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := byResult[d.ResultID]; ok {
+			attempts[i].Answers = append(attempts[i].Answers, d.toEntity())
+		}
+	}
+	return attempts, rowsA.Err()
+}
+
+// LockUserTest сериализует выдачу и сдачу попыток одного студента по одному
+// тесту до конца текущей транзакции.
+func (r *TestRepository) LockUserTest(ctx context.Context, userID, testID string) error {
+	_, err := r.db(ctx).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "test_attempt:"+userID+":"+testID)
+	if err != nil {
+		return fmt.Errorf("lock test attempts: %w", err)
+	}
+	return nil
+}
+
+func (r *TestRepository) CreateSession(ctx context.Context, s *entities.TestSession) error {
+	query := `
+		INSERT INTO test_attempts (id, user_id, test_id, started_at, deadline_at, served)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db(ctx).Exec(ctx, query, s.ID, s.UserID, s.TestID, s.StartedAt, s.Deadline, newServedDTO(s.Served))
+	if err != nil {
+		return fmt.Errorf("create test attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *TestRepository) GetSession(ctx context.Context, id string) (*entities.TestSession, error) {
+	query := `
+		SELECT id, user_id, test_id, started_at, deadline_at, submitted_at, result_id, served
+		FROM test_attempts WHERE id = $1
+	`
+	var d sessionDTO
+	err := r.db(ctx).QueryRow(ctx, query, id).Scan(
+		&d.ID, &d.UserID, &d.TestID, &d.StartedAt, &d.Deadline, &d.SubmittedAt, &d.ResultID, &d.Served,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entities.ErrNotFound
+		}
+		return nil, fmt.Errorf("get test attempt: %w", err)
+	}
+	return d.toEntity(), nil
+}
+
+// GetSessions возвращает все выданные попытки студента по тесту, старые первыми.
+func (r *TestRepository) GetSessions(ctx context.Context, userID, testID string) ([]*entities.TestSession, error) {
+	query := `
+		SELECT id, user_id, test_id, started_at, deadline_at, submitted_at, result_id, served
+		FROM test_attempts
+		WHERE user_id = $1 AND test_id = $2
+		ORDER BY started_at
+	`
+	rows, err := r.db(ctx).Query(ctx, query, userID, testID)
+	if err != nil {
+		return nil, fmt.Errorf("get test attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*entities.TestSession
+	for rows.Next() {
+		var d sessionDTO
+		err := rows.Scan(&d.ID, &d.UserID, &d.TestID, &d.StartedAt, &d.Deadline, &d.SubmittedAt, &d.ResultID, &d.Served)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, d.toEntity())
+	}
+	return sessions, rows.Err()
+}
+
+// MarkSessionSubmitted закрывает попытку. Повторная отправка вернёт
+// entities.ErrAttemptAlreadySubmitted.
+func (r *TestRepository) MarkSessionSubmitted(ctx context.Context, id, resultID string, at time.Time) error {
+	query := `UPDATE test_attempts SET submitted_at = $2, result_id = $3 WHERE id = $1 AND submitted_at IS NULL`
+	tag, err := r.db(ctx).Exec(ctx, query, id, at, resultID)
+	if err != nil {
+		return fmt.Errorf("submit test attempt: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrAttemptAlreadySubmitted
+	}
+	return nil
+}
+
 func (r *TestRepository) UpdateTest(ctx context.Context, test *entities.Test) error {
-	query := `UPDATE tests SET title = $2, passing_score = $3 WHERE id = $1`
-	tag, err := r.pool.Exec(ctx, query, test.ID, test.Title, test.PassingScore)
+	d := newTestDTO(test)
+	query := `
+		UPDATE tests
+		SET title = $2, passing_score = $3, scoring_rules = $4,
+		    max_attempts = $5, cooldown_seconds = $6, time_limit_seconds = $7, shuffle_answers = $8
+		WHERE id = $1
+	`
+	tag, err := r.db(ctx).Exec(ctx, query,
+		d.ID, d.Title, d.PassingScore, d.ScoringRules, d.MaxAttempts, d.CooldownSec, d.TimeLimitSec, d.Shuffle,
+	)
 	if err != nil {
 		return fmt.Errorf("update test: %w", err)
 	}
@@ -180,7 +420,7 @@ func (r *TestRepository) UpdateTest(ctx context.Context, test *entities.Test) er
 
 func (r *TestRepository) DeleteTest(ctx context.Context, testID string) error {
 	query := `DELETE FROM tests WHERE id = $1`
-	tag, err := r.pool.Exec(ctx, query, testID)
+	tag, err := r.db(ctx).Exec(ctx, query, testID)
 	if err != nil {
 		return fmt.Errorf("delete test: %w", err)
 	}
@@ -190,17 +430,76 @@ func (r *TestRepository) DeleteTest(ctx context.Context, testID string) error {
 	return nil
 }
 
-func (r *TestRepository) DeleteQuestionsByTestID(ctx context.Context, testID string) error {
-	query := `DELETE FROM questions WHERE test_id = $1`
-	_, err := r.pool.Exec(ctx, query, testID)
-	return err
+func (r *TestRepository) UpdateQuestion(ctx context.Context, q *entities.Question) error {
+	query := `
+		UPDATE questions
+		SET text = $3, question_type = $4, numeric_value = $5, numeric_tolerance = $6
+		WHERE id = $1 AND test_id = $2
+	`
+	tag, err := r.db(ctx).Exec(ctx, query, q.ID, q.TestID, q.Text, q.QuestionType, q.NumericValue, q.NumericTolerance)
+	if err != nil {
+		return fmt.Errorf("update question: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TestRepository) UpdateAnswer(ctx context.Context, a *entities.Answer) error {
+	query := `
+		UPDATE answers
+		SET text = $3, is_correct = $4, order_index = $5, match_text = $6
+		WHERE id = $1 AND question_id = $2
+	`
+	tag, err := r.db(ctx).Exec(ctx, query, a.ID, a.QuestionID, a.Text, a.IsCorrect, a.OrderIndex, a.MatchText)
+	if err != nil {
+		return fmt.Errorf("update answer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteQuestionsExcept удаляет вопросы теста, которых нет в keepIDs.
+func (r *TestRepository) DeleteQuestionsExcept(ctx context.Context, testID string, keepIDs []string) error {
+	if keepIDs == nil {
+		keepIDs = []string{} // nil уходит в БД как NULL, и ANY не сработает
+	}
+	query := `DELETE FROM questions WHERE test_id = $1 AND NOT (id = ANY($2))`
+	_, err := r.db(ctx).Exec(ctx, query, testID, keepIDs)
+	if err != nil {
+		return fmt.Errorf("delete questions: %w", err)
+	}
+	return nil
+}
+
+// DeleteAnswersExcept удаляет варианты вопроса, которых нет в keepIDs.
+func (r *TestRepository) DeleteAnswersExcept(ctx context.Context, questionID string, keepIDs []string) error {
+	if keepIDs == nil {
+		keepIDs = []string{}
+	}
+	query := `DELETE FROM answers WHERE question_id = $1 AND NOT (id = ANY($2))`
+	_, err := r.db(ctx).Exec(ctx, query, questionID, keepIDs)
+	if err != nil {
+		return fmt.Errorf("delete answers: %w", err)
+	}
+	return nil
 }
 
 func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*entities.Test, error) {
 	// 1. Получаем сам тест
 	var tDTO testDTO
-	queryTest := `SELECT id, module_id, title, passing_score FROM tests WHERE id = $1`
-	err := r.pool.QueryRow(ctx, queryTest, testID).Scan(&tDTO.ID, &tDTO.ModuleID, &tDTO.Title, &tDTO.PassingScore)
+	queryTest := `
+		SELECT id, module_id, title, passing_score, scoring_rules,
+		       max_attempts, cooldown_seconds, time_limit_seconds, shuffle_answers
+		FROM tests WHERE id = $1
+	`
+	err := r.db(ctx).QueryRow(ctx, queryTest, testID).Scan(
+		&tDTO.ID, &tDTO.ModuleID, &tDTO.Title, &tDTO.PassingScore, &tDTO.ScoringRules,
+		&tDTO.MaxAttempts, &tDTO.CooldownSec, &tDTO.TimeLimitSec, &tDTO.Shuffle,
+	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, entities.ErrNotFound
@@ -209,10 +508,16 @@ func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*e
 	}
 
 	test := tDTO.toEntity()
+	if test.PoolRules, err = r.getPoolRules(ctx, test.ID); err != nil {
+		return nil, err
+	}
 
 	// 2. Получаем вопросы
-	queryQuestions := `SELECT id, test_id, text, question_type FROM questions WHERE test_id = $1`
-	rowsQ, err := r.pool.Query(ctx, queryQuestions, test.ID)
+	queryQuestions := `
+		SELECT id, test_id, text, question_type, numeric_value, numeric_tolerance
+		FROM questions WHERE test_id = $1
+	`
+	rowsQ, err := r.db(ctx).Query(ctx, queryQuestions, test.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get questions: %w", err)
 	}
@@ -223,7 +528,10 @@ func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*e
 
 	for rowsQ.Next() {
 		var qDTO questionDTO
-		if err := rowsQ.Scan(&qDTO.ID, &qDTO.TestID, &qDTO.Text, &qDTO.QuestionType); err != nil {
+		err := rowsQ.Scan(
+			&qDTO.ID, &qDTO.TestID, &qDTO.Text, &qDTO.QuestionType, &qDTO.NumericValue, &qDTO.NumericTolerance,
+		)
+		if err != nil {
 			return nil, err
 		}
 		q := qDTO.toEntity()
@@ -237,8 +545,12 @@ func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*e
 	}
 
 	// 3. Получаем ответы для всех вопросов разом
-	queryAnswers := `SELECT id, question_id, text, is_correct FROM answers WHERE question_id = ANY($1)`
-	rowsA, err := r.pool.Query(ctx, queryAnswers, questionIDs)
+	queryAnswers := `
+		SELECT id, question_id, text, is_correct, order_index, match_text
+		FROM answers WHERE question_id = ANY($1)
+		ORDER BY order_index
+	`
+	rowsA, err := r.db(ctx).Query(ctx, queryAnswers, questionIDs)
 	if err != nil {
 		return nil, fmt.Errorf("get answers: %w", err)
 	}
@@ -246,7 +558,10 @@ func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*e
 
 	for rowsA.Next() {
 		var aDTO answerDTO
-		if err := rowsA.Scan(&aDTO.ID, &aDTO.QuestionID, &aDTO.Text, &aDTO.IsCorrect); err != nil {
+		err := rowsA.Scan(
+			&aDTO.ID, &aDTO.QuestionID, &aDTO.Text, &aDTO.IsCorrect, &aDTO.OrderIndex, &aDTO.MatchText,
+		)
+		if err != nil {
 			return nil, err
 		}
 		a := aDTO.toEntity()
@@ -265,3 +580,173 @@ func (r *TestRepository) GetTestFullByID(ctx context.Context, testID string) (*e
 
 	return test, nil
 }
+
+func (r *TestRepository) getPoolRules(ctx context.Context, testID string) ([]entities.PoolRule, error) {
+	query := `
+		SELECT question_count, tags, difficulty
+		FROM test_pool_rules
+		WHERE test_id = $1
+		ORDER BY position
+	`
+	rows, err := r.db(ctx).Query(ctx, query, testID)
+	if err != nil {
+		return nil, fmt.Errorf("get pool rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []entities.PoolRule{}
+	for rows.Next() {
+		var d poolRuleDTO
+		if err := rows.Scan(&d.Count, &d.Tags, &d.Difficulty); err != nil {
+			return nil, err
+		}
+		rules = append(rules, d.toEntity())
+	}
+	return rules, rows.Err()
+}
+
+// ReplacePoolRules перезаписывает правила выборки из банка для теста.
+func (r *TestRepository) ReplacePoolRules(ctx context.Context, testID string, rules []entities.PoolRule) error {
+	if _, err := r.db(ctx).Exec(ctx, `DELETE FROM test_pool_rules WHERE test_id = $1`, testID); err != nil {
+		return fmt.Errorf("clear pool rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO test_pool_rules (test_id, position, question_count, tags, difficulty)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+	`
+	for i, rule := range rules {
+		tags := rule.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if _, err := r.db(ctx).Exec(ctx, query, testID, i, rule.Count, tags, rule.Difficulty); err != nil {
+			return fmt.Errorf("add pool rule: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryBankQuestions выполняет запрос, возвращающий колонки вопроса банка,
+// и подгружает варианты ответов.
+func (r *TestRepository) queryBankQuestions(ctx context.Context, query string, args ...any) ([]entities.Question, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []entities.Question
+	index := make(map[string]int)
+	for rows.Next() {
+		var d bankQuestionDTO
+		err := rows.Scan(
+			&d.ID, &d.TestID, &d.Text, &d.QuestionType, &d.NumericValue, &d.NumericTolerance,
+			&d.CourseID, &d.Difficulty, &d.Tags,
+		)
+		if err != nil {
+			return nil, err
+		}
+		index[d.ID] = len(questions)
+		questions = append(questions, d.toEntity())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return questions, nil
+	}
+
+	ids := make([]string, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+
+	queryAnswers := `
+		SELECT id, question_id, text, is_correct, order_index, match_text
+		FROM answers WHERE question_id = ANY($1)
+		ORDER BY order_index
+	`
+	rowsA, err := r.db(ctx).Query(ctx, queryAnswers, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get answers: %w", err)
+	}
+	defer rowsA.Close()
+
+	for rowsA.Next() {
+		var aDTO answerDTO
+		err := rowsA.Scan(
+			&aDTO.ID, &aDTO.QuestionID, &aDTO.Text, &aDTO.IsCorrect, &aDTO.OrderIndex, &aDTO.MatchText,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[aDTO.QuestionID]; ok {
+			questions[i].Answers = append(questions[i].Answers, aDTO.toEntity())
+		}
+	}
+	return questions, rowsA.Err()
+}
+
+const bankQuestionColumns = `
+	id, COALESCE(test_id, ''), text, question_type, numeric_value, numeric_tolerance, course_id, difficulty, tags
+`
+
+// GetBankQuestions возвращает банк вопросов курса.
+func (r *TestRepository) GetBankQuestions(ctx context.Context, courseID string) ([]entities.Question, error) {
+	query := `SELECT ` + bankQuestionColumns + ` FROM questions WHERE course_id = $1 AND test_id IS NULL ORDER BY text`
+	return r.queryBankQuestions(ctx, query, courseID)
+}
+
+func (r *TestRepository) GetBankQuestion(ctx context.Context, id string) (*entities.Question, error) {
+	query := `SELECT ` + bankQuestionColumns + ` FROM questions WHERE id = $1 AND test_id IS NULL`
+	questions, err := r.queryBankQuestions(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, entities.ErrNotFound
+	}
+	return &questions[0], nil
+}
+
+// GetQuestionsByIDs возвращает вопросы (как теста, так и банка) по ID.
+func (r *TestRepository) GetQuestionsByIDs(ctx context.Context, ids []string) ([]entities.Question, error) {
+	query := `SELECT ` + bankQuestionColumns + ` FROM questions WHERE id = ANY($1)`
+	return r.queryBankQuestions(ctx, query, ids)
+}
+
+func (r *TestRepository) UpdateBankQuestion(ctx context.Context, q *entities.Question) error {
+	query := `
+		UPDATE questions
+		SET text = $2, question_type = $3, numeric_value = $4, numeric_tolerance = $5,
+		    difficulty = NULLIF($6, 0), tags = $7
+		WHERE id = $1 AND test_id IS NULL
+	`
+	tags := q.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tag, err := r.db(ctx).Exec(ctx, query,
+		q.ID, q.Text, q.QuestionType, q.NumericValue, q.NumericTolerance, q.Difficulty, tags,
+	)
+	if err != nil {
+		return fmt.Errorf("update bank question: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TestRepository) DeleteBankQuestion(ctx context.Context, id string) error {
+	tag, err := r.db(ctx).Exec(ctx, `DELETE FROM questions WHERE id = $1 AND test_id IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("delete bank question: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
@@ -16,6 +16,9 @@ type dto struct {
 	AvatarURL    string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+
+	EmailVerifiedAt *time.Time
+	Locale          string
 }
 
 func newDTO(user *entities.User) dto {
@@ -29,6 +32,8 @@ func newDTO(user *entities.User) dto {
 		AvatarURL:    user.AvatarURL,
 		CreatedAt:    user.CreatedAt,
 		UpdatedAt:    user.UpdatedAt,
+
+		Locale: string(user.Locale),
 	}
 }
 
@@ -43,5 +48,8 @@ func (d *dto) toEntity() entities.User {
 		AvatarURL:    d.AvatarURL,
 		CreatedAt:    d.CreatedAt,
 		UpdatedAt:    d.UpdatedAt,
+
+		EmailVerifiedAt: d.EmailVerifiedAt,
+		Locale:          entities.ParseLocale(d.Locale),
 	}
 }
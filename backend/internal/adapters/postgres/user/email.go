@@ -2,34 +2,158 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
 )
 
-func (r *UserRepository) SaveResetToken(ctx context.Context, email, token string, ttl time.Duration) error {
+// SaveResetToken заменяет код для email новым и обнуляет счётчик попыток.
+func (r *UserRepository) SaveResetToken(ctx context.Context, email, codeHash string, expiresAt time.Time) error {
 	query := `
-		INSERT INTO password_reset_tokens (email, token, expires_at)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (email) 
-		DO UPDATE SET token = EXCLUDED.token, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		INSERT INTO password_reset_tokens (email, code_hash, expires_at, attempts)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (email)
+		DO UPDATE SET code_hash = EXCLUDED.code_hash, expires_at = EXCLUDED.expires_at, attempts = 0, created_at = NOW()
 	`
-	expiresAt := time.Now().UTC().Add(ttl)
-	_, err := r.pool.Exec(ctx, query, email, token, expiresAt)
+	_, err := r.pool.Exec(ctx, query, email, codeHash, expiresAt)
 	return err
 }
 
-func (r *UserRepository) VerifyResetToken(ctx context.Context, email, token string) (bool, error) {
+// ClaimResetAttempt списывает одну попытку ввода кода и возвращает его хэш.
+// Попытка считается до проверки, поэтому параллельные запросы не могут
+// превысить maxAttempts. Если кода нет, он истёк или попытки кончились,
+// возвращает entities.ErrNotFound.
+func (r *UserRepository) ClaimResetAttempt(ctx context.Context, email string, maxAttempts int) (string, error) {
 	query := `
-		SELECT EXISTS(
-			SELECT 1 FROM password_reset_tokens 
-			WHERE email = $1 AND token = $2 AND expires_at > NOW()
-		)
+		UPDATE password_reset_tokens
+		SET attempts = attempts + 1
+		WHERE email = $1 AND expires_at > NOW() AND attempts < $2
+		RETURNING code_hash
 	`
-	var valid bool
-	err := r.pool.QueryRow(ctx, query, email, token).Scan(&valid)
-	return valid, err
+	var codeHash string
+	if err := r.pool.QueryRow(ctx, query, email, maxAttempts).Scan(&codeHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", entities.ErrNotFound
+		}
+		return "", fmt.Errorf("claim reset attempt: %w", err)
+	}
+	return codeHash, nil
 }
 
 func (r *UserRepository) DeleteResetToken(ctx context.Context, email string) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM password_reset_tokens WHERE email = $1", email)
+	_, err := pgtx.From(ctx, r.pool).Exec(ctx, "DELETE FROM password_reset_tokens WHERE email = $1", email)
+	return err
+}
+
+// DeleteExpiredTokens удаляет коды сброса и ссылки подтверждения, истёкшие
+// к now, и события сброса пароля старше eventsBefore. Возвращает число
+// удалённых токенов и событий.
+func (r *UserRepository) DeleteExpiredTokens(ctx context.Context, now, eventsBefore time.Time) (int64, int64, error) {
+	query := `
+		WITH reset AS (
+			DELETE FROM password_reset_tokens WHERE expires_at <= $1 RETURNING 1
+		), verification AS (
+			DELETE FROM email_verification_tokens WHERE expires_at <= $1 RETURNING 1
+		), events AS (
+			DELETE FROM password_reset_events WHERE created_at < $2 RETURNING 1
+		)
+		SELECT
+			(SELECT COUNT(*) FROM reset) + (SELECT COUNT(*) FROM verification),
+			(SELECT COUNT(*) FROM events)
+	`
+	var tokens, events int64
+	if err := r.pool.QueryRow(ctx, query, now, eventsBefore).Scan(&tokens, &events); err != nil {
+		return 0, 0, fmt.Errorf("delete expired tokens: %w", err)
+	}
+	return tokens, events, nil
+}
+
+func (r *UserRepository) AddResetEvent(ctx context.Context, kind, email, ip string) error {
+	query := `INSERT INTO password_reset_events (kind, email, ip) VALUES ($1, $2, $3)`
+	_, err := r.pool.Exec(ctx, query, kind, email, ip)
 	return err
 }
+
+// CountResetEvents считает события вида kind с ip с момента since:
+// отдельно для email и всего.
+func (r *UserRepository) CountResetEvents(ctx context.Context, kind, email, ip string, since time.Time) (int, int, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE email = $2),
+			COUNT(*)
+		FROM password_reset_events
+		WHERE kind = $1 AND ip = $3 AND created_at > $4
+	`
+	var byIPEmail, byIP int
+	if err := r.pool.QueryRow(ctx, query, kind, email, ip, since).Scan(&byIPEmail, &byIP); err != nil {
+		return 0, 0, fmt.Errorf("count reset events: %w", err)
+	}
+	return byIPEmail, byIP, nil
+}
+
+// CountEmailResetEvents считает события вида kind для email с любых IP
+// с момента since.
+func (r *UserRepository) CountEmailResetEvents(ctx context.Context, kind, email string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM password_reset_events
+		WHERE kind = $1 AND email = $2 AND created_at > $3
+	`
+	var n int
+	if err := r.pool.QueryRow(ctx, query, kind, email, since).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count email reset events: %w", err)
+	}
+	return n, nil
+}
+
+// SaveVerificationToken заменяет ссылку подтверждения пользователя новой.
+// Если предыдущая выдана позже notBefore, ничего не меняет и возвращает false.
+func (r *UserRepository) SaveVerificationToken(
+	ctx context.Context,
+	userID, tokenHash string,
+	expiresAt, notBefore time.Time,
+) (bool, error) {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		WHERE email_verification_tokens.created_at < $4
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, tokenHash, expiresAt, notBefore)
+	if err != nil {
+		return false, fmt.Errorf("save verification token: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// VerifyEmail гасит токен и отмечает email подтверждённым одним запросом.
+// Возвращает ID пользователя или entities.ErrNotFound, если токен неизвестен
+// или истёк.
+func (r *UserRepository) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+	query := `
+		WITH token AS (
+			DELETE FROM email_verification_tokens
+			WHERE token_hash = $1 AND expires_at > NOW()
+			RETURNING user_id
+		)
+		UPDATE users u
+		SET email_verified_at = COALESCE(u.email_verified_at, NOW())
+		FROM token
+		WHERE u.id = token.user_id
+		RETURNING u.id
+	`
+	var userID string
+	if err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", entities.ErrNotFound
+		}
+		return "", fmt.Errorf("verify email: %w", err)
+	}
+	return userID, nil
+}
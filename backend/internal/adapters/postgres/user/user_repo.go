@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"backend/internal/adapters/postgres/pgtx"
 	"backend/internal/entities"
 
 	"github.com/jackc/pgx/v5/pgconn"
@@ -56,8 +57,8 @@ func (r *UserRepository) Create(ctx context.Context, user *entities.User) error
 	d := newDTO(user)
 
 	query := `
-		INSERT INTO users (id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at, locale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.pool.Exec(
@@ -72,6 +73,7 @@ func (r *UserRepository) Create(ctx context.Context, user *entities.User) error
 		d.AvatarURL,
 		d.CreatedAt,
 		d.UpdatedAt,
+		d.Locale,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -90,7 +92,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*entities.User
 	}
 
 	query := `
-		SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at
+		SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at, email_verified_at, locale
 		FROM users
 		WHERE id = $1
 	`
@@ -112,7 +114,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entitie
 	}
 
 	query := `
-        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at
+        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at, email_verified_at, locale
         FROM users
         WHERE email = $1
     `
@@ -144,11 +146,13 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.User) error
             first_name = $5, 
             last_name = $6, 
             avatar_url = $7, 
-            updated_at = $8
+            updated_at = $8,
+            locale = $9
         WHERE id = $1
     `
 
-	tag, err := r.pool.Exec(
+	// Смена пароля идёт в одной транзакции с отзывом сессий
+	tag, err := pgtx.From(ctx, r.pool).Exec(
 		ctx,
 		query,
 		d.ID,
@@ -159,6 +163,7 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.User) error
 		d.LastName,
 		d.AvatarURL,
 		d.UpdatedAt,
+		d.Locale,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -171,6 +176,21 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.User) error
 	return nil
 }
 
+func (r *UserRepository) SetLocale(ctx context.Context, userID string, locale entities.Locale) error {
+	query := `UPDATE users SET locale = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.pool.Exec(ctx, query, userID, string(locale))
+	if err != nil {
+		return fmt.Errorf("failed to set user locale: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entities.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	if r.pool == nil {
 		return fmt.Errorf("not connected to pool")
@@ -196,7 +216,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*entiti
 	}
 
 	query := `
-        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at
+        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at, email_verified_at, locale
         FROM users
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
@@ -231,7 +251,7 @@ func (r *UserRepository) GetByRole(ctx context.Context, role entities.UserRole)
 	}
 
 	query := `
-        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at
+        SELECT id, email, password_hash, role, first_name, last_name, avatar_url, created_at, updated_at, email_verified_at, locale
         FROM users
         WHERE role = $1
         ORDER BY created_at DESC
@@ -274,6 +294,8 @@ func scan(scanner rowScanner) (entities.User, error) {
 		&d.AvatarURL,
 		&d.CreatedAt,
 		&d.UpdatedAt,
+		&d.EmailVerifiedAt,
+		&d.Locale,
 	)
 	if err != nil {
 		return entities.User{}, err
@@ -0,0 +1,159 @@
+package xp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/adapters/postgres/pgtx"
+	"backend/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type XPRepository struct {
+	connectionURL string
+	pool          *pgxpool.Pool
+}
+
+func NewXPRepository(connectionURL string) *XPRepository {
+	return &XPRepository{connectionURL: connectionURL}
+}
+
+func (r *XPRepository) Connect(ctx context.Context) error {
+	p, err := pgxpool.New(ctx, r.connectionURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool new: %w", err)
+	}
+
+	r.pool = p
+	return nil
+}
+
+func (r *XPRepository) Close() {
+	if r.pool != nil {
+		r.pool.Close()
+	}
+}
+
+// WithinTransaction выполняет fn в одной транзакции с начислением XP.
+func (r *XPRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgtx.Run(ctx, r.pool, fn)
+}
+
+func (r *XPRepository) db(ctx context.Context) pgtx.Querier {
+	return pgtx.From(ctx, r.pool)
+}
+
+// Grant записывает начисление в журнал и в той же транзакции прибавляет XP
+// к профилю, пересчитывая уровень по curve. Уровень только растёт: после
+// смены кривой он может быть выше, чем даёт curve, и тогда сохраняется.
+// Строка профиля блокируется, поэтому параллельные начисления ученику
+// выполняются по очереди. Если этот источник уже давал XP, профиль не
+// меняется и Amount равен 0. Если профиля нет — entities.ErrNotFound.
+func (r *XPRepository) Grant(ctx context.Context, t *entities.XPTransaction, curve entities.LevelCurve) (*entities.XPGrant, error) {
+	grant := &entities.XPGrant{}
+	err := pgtx.Run(ctx, r.pool, func(ctx context.Context) error {
+		db := r.db(ctx)
+
+		err := db.QueryRow(ctx,
+			`SELECT xp, level FROM student_profiles WHERE user_id = $1 FOR UPDATE`, t.UserID,
+		).Scan(&grant.XP, &grant.Level)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("lock student profile: %w", err)
+		}
+
+		err = db.QueryRow(ctx, `
+			INSERT INTO xp_transactions (user_id, source_type, source_id, amount, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, source_type, source_id) DO NOTHING
+			RETURNING id
+		`, t.UserID, string(t.SourceType), t.SourceID, t.Amount, t.CreatedAt).Scan(&t.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("insert xp transaction: %w", err)
+		}
+
+		previousLevel := grant.Level
+		grant.Amount = t.Amount
+		grant.XP += int64(t.Amount)
+		grant.Level = max(previousLevel, curve.LevelFor(grant.XP))
+
+		_, err = db.Exec(ctx, `
+			UPDATE student_profiles
+			SET xp = $2,
+			    weekly_xp = weekly_xp + $3,
+			    weekly_xp_reached_at = $5,
+			    level = $4,
+			    updated_at = NOW()
+			WHERE user_id = $1
+		`, t.UserID, grant.XP, t.Amount, grant.Level, t.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("apply xp to profile: %w", err)
+		}
+
+		if grant.Level > previousLevel {
+			grant.LevelUp = &entities.LevelUp{From: previousLevel, To: grant.Level}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// GetHistory возвращает страницу журнала ученика от новых записей к старым
+// и общее число записей. Названия источников локализуются по ctx.
+func (r *XPRepository) GetHistory(ctx context.Context, userID string, limit, offset int) ([]entities.XPTransaction, int, error) {
+	var total int
+	err := r.db(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM xp_transactions WHERE user_id = $1`, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count xp transactions: %w", err)
+	}
+
+	query := `
+		SELECT x.id, x.user_id, x.source_type, x.source_id, x.amount, x.created_at,
+		       COALESCE(lt.title, l.title, t.title, atr.name, a.name, '')
+		FROM xp_transactions x
+		LEFT JOIN lessons l ON x.source_type = 'lesson' AND l.id = x.source_id
+		LEFT JOIN lesson_translations lt ON lt.lesson_id = l.id AND lt.locale = $4
+		LEFT JOIN tests t ON x.source_type = 'test' AND t.id = x.source_id
+		LEFT JOIN achievements a ON x.source_type = 'achievement' AND a.id = x.source_id
+		LEFT JOIN achievement_translations atr ON atr.achievement_id = a.id AND atr.locale = $4
+		WHERE x.user_id = $1
+		ORDER BY x.created_at DESC, x.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db(ctx).Query(ctx, query, userID, limit, offset, string(entities.LocaleFrom(ctx)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("get xp history: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]entities.XPTransaction, 0)
+	for rows.Next() {
+		var (
+			t          entities.XPTransaction
+			sourceType string
+		)
+		err := rows.Scan(&t.ID, &t.UserID, &sourceType, &t.SourceID, &t.Amount, &t.CreatedAt, &t.SourceTitle)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan xp transaction: %w", err)
+		}
+		t.SourceType = entities.XPSourceType(sourceType)
+		t.CreatedAt = t.CreatedAt.UTC()
+		list = append(list, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, total, nil
+}
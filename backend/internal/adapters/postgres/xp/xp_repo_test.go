@@ -0,0 +1,128 @@
+package xp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"backend/internal/adapters/postgres/pgtest"
+	"backend/internal/entities"
+)
+
+func TestGrantIsIdempotentPerSource(t *testing.T) {
+	pool := pgtest.Pool(t)
+	userID := pgtest.Student(t, pool)
+	ctx := context.Background()
+
+	repo := NewXPRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	curve := entities.LevelCurve{Kind: entities.LevelCurveLinear, XPPerLevel: 100}
+
+	first, err := repo.Grant(ctx, entities.NewXPTransaction(userID, entities.XPSourceLesson, "lesson-1", 60), curve)
+	if err != nil {
+		t.Fatalf("first grant: %v", err)
+	}
+	if first.Amount != 60 || first.XP != 60 {
+		t.Fatalf("first grant = %+v, want 60 XP", first)
+	}
+
+	// Повтор того же источника, в том числе одновременный, ничего не меняет
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g, err := repo.Grant(ctx, entities.NewXPTransaction(userID, entities.XPSourceLesson, "lesson-1", 60), curve)
+			if err != nil {
+				t.Errorf("repeated grant: %v", err)
+				return
+			}
+			if g.Amount != 0 || g.LevelUp != nil {
+				t.Errorf("repeated grant = %+v, want nothing granted", g)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Другой источник начисляется как обычно
+	second, err := repo.Grant(ctx, entities.NewXPTransaction(userID, entities.XPSourceTest, "lesson-1", 50), curve)
+	if err != nil {
+		t.Fatalf("grant for another source: %v", err)
+	}
+	if second.Amount != 50 || second.XP != 110 || second.LevelUp == nil {
+		t.Fatalf("grant for another source = %+v, want 110 XP and a level up", second)
+	}
+
+	var xp, weeklyXP int64
+	var level, rows int
+	err = pool.QueryRow(ctx,
+		`SELECT xp, weekly_xp, level FROM student_profiles WHERE user_id = $1`, userID,
+	).Scan(&xp, &weeklyXP, &level)
+	if err != nil {
+		t.Fatalf("read profile: %v", err)
+	}
+	if xp != 110 || weeklyXP != 110 || level != curve.LevelFor(110) {
+		t.Errorf("profile xp=%d weekly_xp=%d level=%d, want 110/110/%d", xp, weeklyXP, level, curve.LevelFor(110))
+	}
+
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM xp_transactions WHERE user_id = $1`, userID).Scan(&rows)
+	if err != nil {
+		t.Fatalf("count transactions: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("xp_transactions rows = %d, want 2", rows)
+	}
+
+	history, total, err := repo.GetHistory(ctx, userID, 10, 0)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if total != 2 || len(history) != 2 {
+		t.Errorf("history total=%d len=%d, want 2", total, len(history))
+	}
+}
+
+func TestGrantWithoutProfile(t *testing.T) {
+	ctx := context.Background()
+	repo := NewXPRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	curve := entities.LevelCurve{Kind: entities.LevelCurveLinear, XPPerLevel: 100}
+	_, err := repo.Grant(ctx, entities.NewXPTransaction("no-such-user", entities.XPSourceLesson, "l", 10), curve)
+	if err != entities.ErrNotFound {
+		t.Errorf("Grant() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGrantNeverLowersLevel(t *testing.T) {
+	pool := pgtest.Pool(t)
+	userID := pgtest.Student(t, pool)
+	ctx := context.Background()
+
+	// Уровень, заработанный по прежней, более пологой кривой
+	if _, err := pool.Exec(ctx, `UPDATE student_profiles SET xp = 100, level = 5 WHERE user_id = $1`, userID); err != nil {
+		t.Fatalf("prepare profile: %v", err)
+	}
+
+	repo := NewXPRepository(pgtest.URL(t))
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer repo.Close()
+
+	curve := entities.LevelCurve{Kind: entities.LevelCurveLinear, XPPerLevel: 100}
+	g, err := repo.Grant(ctx, entities.NewXPTransaction(userID, entities.XPSourceLesson, "lesson-1", 10), curve)
+	if err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if g.Level != 5 || g.LevelUp != nil {
+		t.Errorf("grant = %+v, want level 5 kept without a level up", g)
+	}
+}
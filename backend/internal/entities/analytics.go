@@ -7,6 +7,18 @@ import (
 	"github.com/google/uuid"
 )
 
+// Типы действий, которые пишутся в user_activity_logs.
+// ML-сервис считает популярность курса только по view и complete:
+// остальные действия (в том числе снятие из избранного) её не меняют.
+const (
+	ActionCourseView     = "view"
+	ActionLessonOpen     = "lesson_open"
+	ActionLessonComplete = "complete"
+	ActionTestSubmit     = "test_submit"
+	ActionSearch         = "search"
+	ActionFavorite       = "favorite"
+)
+
 type UserActivityLog struct {
 	ID         string
 	UserID     string
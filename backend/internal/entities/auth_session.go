@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RevokeReasonLogout         = "logout"
+	RevokeReasonPasswordChange = "password_change"
+	RevokeReasonPasswordReset  = "password_reset"
+	RevokeReasonTokenReuse     = "token_reuse"
+)
+
+// AuthSession — один вход пользователя. Все refresh-токены, полученные
+// ротацией из первого, относятся к одной сессии; отзыв сессии сразу
+// делает недействительными и их, и выданные под неё access-токены.
+type AuthSession struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+func NewAuthSession(userID string, ttl time.Duration, now time.Time) *AuthSession {
+	return &AuthSession{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func (s *AuthSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken хранится по хэшу; сам токен видит только клиент.
+// Срок жизни токена — срок жизни его сессии.
+type RefreshToken struct {
+	Hash      string
+	SessionID string
+	CreatedAt time.Time
+	RotatedAt *time.Time
+}
@@ -46,8 +46,33 @@ type Lesson struct {
 	ContentText       string
 	VideoURL          string
 	FileAttachmentURL string
-	XPReward          int
-	OrderIndex        int
+	// XPReward — базовая награда за урок; nil — не задана, и за урок
+	// даётся lesson_default_xp из действующей политики геймификации.
+	// 0 — урок не даёт XP.
+	XPReward   *int
+	OrderIndex int
+}
+
+// Переводы контента курса. Пустые необязательные поля означают, что для
+// них отдаётся исходный текст.
+type CourseTranslation struct {
+	CourseID    string
+	Locale      Locale
+	Title       string
+	Description string
+}
+
+type ModuleTranslation struct {
+	ModuleID string
+	Locale   Locale
+	Title    string
+}
+
+type LessonTranslation struct {
+	LessonID    string
+	Locale      Locale
+	Title       string
+	ContentText string
 }
 
 type CourseFavorite struct {
@@ -56,6 +81,26 @@ type CourseFavorite struct {
 	CreatedAt time.Time
 }
 
+// CourseStats — сводка по курсу для автора. Её пересчитывает задача
+// course_stats, поэтому данные отстают от живых до ComputedAt.
+type CourseStats struct {
+	CourseID string
+	// Students — ученики, начавшие курс; Completions — закончившие его
+	Students    int
+	Completions int
+	// AvgProgress — средний прогресс начавших, в процентах
+	AvgProgress      int
+	ActiveStudents7d int
+	Views30d         int
+	Favorites        int
+	TestAttempts     int
+	// AvgTestScore и TestPassRate (в процентах) — nil, пока тесты курса
+	// никто не сдавал
+	AvgTestScore *int
+	TestPassRate *int
+	ComputedAt   time.Time
+}
+
 func NewCourse(authorID, subjectID, title string, difficulty int) (*Course, error) {
 	if difficulty < 1 || difficulty > 5 {
 		return nil, errors.New("difficulty must be between 1 and 5")
@@ -85,7 +130,6 @@ func NewLesson(moduleID, title string, order int) *Lesson {
 		ID:         uuid.NewString(),
 		ModuleID:   moduleID,
 		Title:      title,
-		XPReward:   10, // Default reward
 		OrderIndex: order,
 	}
 }
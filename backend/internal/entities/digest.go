@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// WeeklyDigest — итоги недели ученика для письма-дайджеста. Неделя —
+// [PeriodStart, PeriodEnd), с понедельника по понедельник UTC, как у лиг.
+type WeeklyDigest struct {
+	UserID    string
+	Email     string
+	FirstName string
+	Locale    Locale
+
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+
+	XPEarned         int64
+	LessonsCompleted int
+	TestsPassed      int
+	CurrentStreak    int
+}
@@ -1,9 +1,61 @@
 package entities
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrAlreadyExists      = errors.New("already exists")
 	ErrNotFound           = errors.New("not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrForbidden          = errors.New("forbidden")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+
+	ErrInvalidResetCode = errors.New("invalid or expired reset code")
+	ErrWeakPassword     = errors.New("password must be at least 8 characters")
+
+	ErrEmailNotVerified         = errors.New("email is not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrTooManyRequests          = errors.New("too many requests")
+
+	ErrAttemptRequired         = errors.New("test attempt must be started first")
+	ErrAttemptInvalid          = errors.New("invalid test attempt")
+	ErrAttemptLimitReached     = errors.New("attempt limit reached")
+	ErrAttemptCooldown         = errors.New("attempt cooldown is active")
+	ErrAttemptExpired          = errors.New("attempt time limit exceeded")
+	ErrAttemptAlreadySubmitted = errors.New("attempt already submitted")
+
+	ErrInvalidPolicy   = errors.New("invalid gamification policy")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+
+	ErrInvalidLeagueZones = errors.New("invalid league zones")
 )
+
+// CooldownError несёт время до следующей попытки; errors.Is(err, ErrAttemptCooldown) == true.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return ErrAttemptCooldown.Error()
+}
+
+func (e *CooldownError) Is(target error) bool {
+	return target == ErrAttemptCooldown
+}
+
+// RateLimitError несёт время до снятия ограничения; errors.Is(err, ErrTooManyRequests) == true.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return ErrTooManyRequests.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
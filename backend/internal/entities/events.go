@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEventType — имя доменного события; оно же хранится в event_outbox.
+type DomainEventType string
+
+const (
+	DomainLessonCompleted DomainEventType = "lesson.completed"
+	DomainTestSubmitted   DomainEventType = "test.submitted"
+	DomainCourseCompleted DomainEventType = "course.completed"
+	DomainLeaguePromoted  DomainEventType = "league.promoted"
+	DomainUserRegistered  DomainEventType = "user.registered"
+)
+
+// DomainEvent — конверт события. Payload — JSON одной из структур ниже,
+// поэтому событие одинаково передаётся в памяти и через event_outbox.
+type DomainEvent struct {
+	ID         string
+	Type       DomainEventType
+	UserID     string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+func NewDomainEvent(eventType DomainEventType, userID string, payload any) (DomainEvent, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return DomainEvent{}, fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+
+	return DomainEvent{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		UserID:     userID,
+		Payload:    raw,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
+
+// Decode разбирает Payload в структуру, соответствующую Type.
+func (e DomainEvent) Decode(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// LessonCompleted — ученик впервые завершил урок.
+type LessonCompleted struct {
+	LessonID string `json:"lesson_id"`
+	CourseID string `json:"course_id"`
+	XP       int    `json:"xp"`
+	// Streak — длина серии после урока; 0, если серия не пересчитывалась.
+	Streak int `json:"streak"`
+}
+
+// TestSubmitted — ученик отправил тест, независимо от результата.
+type TestSubmitted struct {
+	TestID   string `json:"test_id"`
+	ResultID string `json:"result_id"`
+	Score    int    `json:"score"`
+	IsPassed bool   `json:"is_passed"`
+}
+
+// CourseCompleted — пройдены все уроки курса.
+type CourseCompleted struct {
+	CourseID string `json:"course_id"`
+}
+
+// LeaguePromoted — ученик поднялся в лиге по итогам недели.
+type LeaguePromoted struct {
+	FromLeagueID int `json:"from_league_id"`
+	ToLeagueID   int `json:"to_league_id"`
+	// LeagueOrder — order_index новой лиги.
+	LeagueOrder int `json:"league_order"`
+}
+
+// UserRegistered — создан новый аккаунт.
+type UserRegistered struct {
+	Email  string   `json:"email"`
+	Role   UserRole `json:"role"`
+	Locale Locale   `json:"locale"`
+}
+
+// EventOutboxStatus — состояние события в event_outbox.
+type EventOutboxStatus string
+
+const (
+	EventPending   EventOutboxStatus = "pending"
+	EventProcessed EventOutboxStatus = "processed"
+	EventFailed    EventOutboxStatus = "failed"
+)
+
+// OutboxEvent — событие, ожидающее доставки подписчикам.
+type OutboxEvent struct {
+	Event    DomainEvent
+	Attempts int
+}
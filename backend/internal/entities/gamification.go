@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -10,6 +11,24 @@ type League struct {
 	Name       string
 	OrderIndex int
 	IconURL    string
+
+	// Зоны по итогам недели — доля учеников лиги, которые переходят в
+	// соседнюю лигу. Неактивные (без XP за неделю) стоят ниже всех и
+	// попадают в зону понижения первыми; DemoteInactive понижает их всех.
+	PromotePercent int
+	DemotePercent  int
+	DemoteInactive bool
+}
+
+// ValidateZones проверяет проценты зон повышения и понижения.
+func (l League) ValidateZones() error {
+	if l.PromotePercent < 0 || l.PromotePercent > 50 {
+		return fmt.Errorf("%w: promote_percent must be between 0 and 50", ErrInvalidLeagueZones)
+	}
+	if l.DemotePercent < 0 || l.DemotePercent > 50 {
+		return fmt.Errorf("%w: demote_percent must be between 0 and 50", ErrInvalidLeagueZones)
+	}
+	return nil
 }
 
 type Achievement struct {
@@ -19,6 +38,73 @@ type Achievement struct {
 	Description string
 	IconURL     string
 	XPReward    int
+
+	// Rule — условие автоматической выдачи; nil, если достижение выдаётся вручную.
+	Rule *AchievementRule
+}
+
+// LearningEventType — событие обучения, на которое реагируют правила достижений.
+type LearningEventType string
+
+const (
+	EventLessonCompleted LearningEventType = "lesson_completed"
+	EventTestPassed      LearningEventType = "test_passed"
+	EventStreakUpdated   LearningEventType = "streak_updated"
+	EventCourseCompleted LearningEventType = "course_completed"
+	EventLeaguePromoted  LearningEventType = "league_promoted"
+)
+
+// LearningEvent — произошедшее с учеником событие. Value зависит от типа:
+// балл теста для test_passed, длина серии для streak_updated,
+// order_index новой лиги для league_promoted; для остальных не используется.
+type LearningEvent struct {
+	Type       LearningEventType
+	UserID     string
+	Value      int
+	OccurredAt time.Time
+}
+
+func NewLearningEvent(eventType LearningEventType, userID string, value int) LearningEvent {
+	return LearningEvent{
+		Type:       eventType,
+		UserID:     userID,
+		Value:      value,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// AchievementMetric — величина, которую правило сравнивает с порогом.
+type AchievementMetric string
+
+const (
+	// Накопительные метрики считаются по данным ученика в БД
+	MetricLessonsCompleted AchievementMetric = "lessons_completed"
+	MetricTestsPassed      AchievementMetric = "tests_passed"
+	MetricPerfectTests     AchievementMetric = "perfect_tests"
+	MetricCoursesCompleted AchievementMetric = "courses_completed"
+
+	// Метрики события берутся из LearningEvent.Value
+	MetricTestScore   AchievementMetric = "test_score"
+	MetricStreakDays  AchievementMetric = "streak_days"
+	MetricLeagueOrder AchievementMetric = "league_order"
+)
+
+// FromEvent сообщает, берётся ли метрика из самого события.
+func (m AchievementMetric) FromEvent() bool {
+	switch m {
+	case MetricTestScore, MetricStreakDays, MetricLeagueOrder:
+		return true
+	default:
+		return false
+	}
+}
+
+// AchievementRule выдаёт достижение, когда при событии Event метрика
+// Metric достигает Threshold. Правила хранятся в таблице achievements.
+type AchievementRule struct {
+	Event     LearningEventType
+	Metric    AchievementMetric
+	Threshold int
 }
 
 type UserAchievement struct {
@@ -29,13 +115,34 @@ type UserAchievement struct {
 	Achievement *Achievement
 }
 
+// LeagueOutcome — итог недели для ученика.
+type LeagueOutcome string
+
+const (
+	LeagueOutcomePromoted LeagueOutcome = "promoted"
+	LeagueOutcomeDemoted  LeagueOutcome = "demoted"
+	LeagueOutcomeStayed   LeagueOutcome = "stayed"
+)
+
+// LeaderboardHistory — место ученика в таблице лиги за прошедшую неделю.
+// Outcome и ToLeagueID пусты для старых записей, итог которых неизвестен.
 type LeaderboardHistory struct {
 	ID          string
 	PeriodStart time.Time
 	PeriodEnd   time.Time
 	UserID      string
 	LeagueID    int
+	CohortID    string
 	Rank        int
 	TotalXP     int64
+	Outcome     LeagueOutcome
+	ToLeagueID  int
 	CreatedAt   time.Time
+
+	// Заполняются только при чтении истории.
+	LeagueName    string
+	LeagueIconURL string
+	FirstName     string
+	LastName      string
+	AvatarURL     string
 }
@@ -0,0 +1,277 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxLevel ограничивает кривую, чтобы пороги уровней можно было передать
+// в БД одним массивом при пересчёте.
+const MaxLevel = 1000
+
+type LevelCurveKind string
+
+const (
+	// LevelCurveLinear — каждые XPPerLevel опыта дают уровень.
+	LevelCurveLinear LevelCurveKind = "linear"
+	// LevelCurveQuadratic — уровень n начинается с XPPerLevel*(n-1)^2.
+	LevelCurveQuadratic LevelCurveKind = "quadratic"
+	// LevelCurveTable — Thresholds[i] — XP, с которого начинается уровень i+2.
+	LevelCurveTable LevelCurveKind = "table"
+)
+
+type LevelCurve struct {
+	Kind       LevelCurveKind `json:"kind"`
+	XPPerLevel int64          `json:"xp_per_level,omitempty"`
+	Thresholds []int64        `json:"thresholds,omitempty"`
+}
+
+// threshold возвращает XP, с которого начинается уровень level (level >= 2).
+func (c LevelCurve) threshold(level int) (int64, bool) {
+	if level < 2 || level > MaxLevel {
+		return 0, false
+	}
+	n := int64(level - 1)
+
+	switch c.Kind {
+	case LevelCurveLinear:
+		return c.XPPerLevel * n, true
+	case LevelCurveQuadratic:
+		return c.XPPerLevel * n * n, true
+	case LevelCurveTable:
+		if level-2 >= len(c.Thresholds) {
+			return 0, false
+		}
+		return c.Thresholds[level-2], true
+	default:
+		return 0, false
+	}
+}
+
+// LevelFor возвращает уровень для суммарного опыта xp.
+func (c LevelCurve) LevelFor(xp int64) int {
+	level := 1
+	for {
+		t, ok := c.threshold(level + 1)
+		if !ok || t > xp {
+			return level
+		}
+		level++
+	}
+}
+
+// LevelThresholds возвращает пороги уровней 2..MaxLevel по возрастанию.
+func (c LevelCurve) LevelThresholds() []int64 {
+	var list []int64
+	for level := 2; ; level++ {
+		t, ok := c.threshold(level)
+		if !ok {
+			return list
+		}
+		list = append(list, t)
+	}
+}
+
+func (c LevelCurve) Validate() error {
+	switch c.Kind {
+	case LevelCurveLinear, LevelCurveQuadratic:
+		if c.XPPerLevel <= 0 || c.XPPerLevel > 1_000_000_000 {
+			return errors.New("xp_per_level must be between 1 and 1000000000")
+		}
+	case LevelCurveTable:
+		if len(c.Thresholds) == 0 {
+			return errors.New("thresholds must not be empty")
+		}
+		if len(c.Thresholds) > MaxLevel-1 {
+			return fmt.Errorf("at most %d thresholds allowed", MaxLevel-1)
+		}
+		prev := int64(0)
+		for _, t := range c.Thresholds {
+			if t <= prev {
+				return errors.New("thresholds must be positive and strictly increasing")
+			}
+			prev = t
+		}
+	default:
+		return fmt.Errorf("unknown level curve %q", c.Kind)
+	}
+	return nil
+}
+
+// StreakMultiplier действует, начиная с серии в MinDays дней.
+type StreakMultiplier struct {
+	MinDays    int     `json:"min_days"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type XPRules struct {
+	// LessonDefaultXP — награда за урок, если автор не задал свою.
+	LessonDefaultXP int `json:"lesson_default_xp"`
+	// TestMaxXP — награда за сданный тест. При ScoreProportional она
+	// умножается на долю набранных баллов.
+	TestMaxXP         int  `json:"test_max_xp"`
+	ScoreProportional bool `json:"score_proportional"`
+	// FirstTryBonusPercent — надбавка за тест, сданный с первой попытки.
+	FirstTryBonusPercent int `json:"first_try_bonus_percent"`
+	// DifficultyMultipliers[i] — множитель для курса сложности i+1.
+	DifficultyMultipliers []float64 `json:"difficulty_multipliers"`
+	// StreakMultipliers — берётся правило с наибольшим MinDays, не
+	// превышающим текущую серию.
+	StreakMultipliers []StreakMultiplier `json:"streak_multipliers"`
+}
+
+func (r XPRules) Validate() error {
+	if r.LessonDefaultXP <= 0 {
+		return errors.New("lesson_default_xp must be positive")
+	}
+	if r.TestMaxXP < 0 {
+		return errors.New("test_max_xp must not be negative")
+	}
+	if r.FirstTryBonusPercent < 0 || r.FirstTryBonusPercent > 500 {
+		return errors.New("first_try_bonus_percent must be between 0 and 500")
+	}
+	if len(r.DifficultyMultipliers) != 5 {
+		return errors.New("difficulty_multipliers must have 5 values, one per difficulty level")
+	}
+	for _, m := range r.DifficultyMultipliers {
+		if m <= 0 {
+			return errors.New("difficulty multipliers must be positive")
+		}
+	}
+	for _, s := range r.StreakMultipliers {
+		if s.MinDays <= 0 || s.Multiplier <= 0 {
+			return errors.New("streak multipliers need positive min_days and multiplier")
+		}
+	}
+	return nil
+}
+
+func (r XPRules) difficultyMultiplier(difficulty int) float64 {
+	if difficulty < 1 || difficulty > len(r.DifficultyMultipliers) {
+		return 1
+	}
+	return r.DifficultyMultipliers[difficulty-1]
+}
+
+func (r XPRules) streakMultiplier(streak int) float64 {
+	best, multiplier := 0, 1.0
+	for _, s := range r.StreakMultipliers {
+		if s.MinDays <= streak && s.MinDays > best {
+			best, multiplier = s.MinDays, s.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// LessonXP — награда за урок с базовой наградой base; nil — награда
+// по умолчанию.
+func (r XPRules) LessonXP(base *int, difficulty, streak int) int {
+	amount := r.LessonDefaultXP
+	if base != nil {
+		amount = *base
+	}
+	return applyMultipliers(float64(amount), r.difficultyMultiplier(difficulty)*r.streakMultiplier(streak))
+}
+
+// TestXP — награда за сданный тест с баллом score из 100.
+func (r XPRules) TestXP(score int, firstTry bool, difficulty, streak int) int {
+	base := float64(r.TestMaxXP)
+	if r.ScoreProportional {
+		base = base * float64(min(max(score, 0), 100)) / 100
+	}
+	if firstTry {
+		base += base * float64(r.FirstTryBonusPercent) / 100
+	}
+	return applyMultipliers(base, r.difficultyMultiplier(difficulty)*r.streakMultiplier(streak))
+}
+
+// applyMultipliers округляет награду; ненулевая награда не опускается ниже 1.
+func applyMultipliers(base, multiplier float64) int {
+	if base <= 0 {
+		return 0
+	}
+	return max(int(math.Round(base*multiplier)), 1)
+}
+
+// StreakRules — выдача заморозок серии. Заморозка автоматически покрывает
+// пропущенный день, и серия не сбрасывается.
+type StreakRules struct {
+	// FreezeEveryDays — каждые столько дней серии ученик получает
+	// заморозку; 0 отключает выдачу.
+	FreezeEveryDays int `json:"freeze_every_days"`
+	// MaxFreezes — сколько заморозок можно накопить.
+	MaxFreezes int `json:"max_freezes"`
+}
+
+func (r StreakRules) Validate() error {
+	if r.FreezeEveryDays < 0 {
+		return errors.New("freeze_every_days must not be negative")
+	}
+	if r.MaxFreezes < 0 || r.MaxFreezes > 10 {
+		return errors.New("max_freezes must be between 0 and 10")
+	}
+	return nil
+}
+
+// EarnsFreeze сообщает, даёт ли достижение серии длиной streak заморозку.
+func (r StreakRules) EarnsFreeze(streak int) bool {
+	return r.FreezeEveryDays > 0 && streak > 0 && streak%r.FreezeEveryDays == 0
+}
+
+// GamificationPolicy — правила начисления XP, кривая уровней и правила
+// серий. Хранится в system_settings и меняется администратором.
+type GamificationPolicy struct {
+	Levels  LevelCurve  `json:"levels"`
+	XP      XPRules     `json:"xp"`
+	Streaks StreakRules `json:"streaks"`
+}
+
+func (p GamificationPolicy) Validate() error {
+	if err := p.Levels.Validate(); err != nil {
+		return fmt.Errorf("levels: %w", err)
+	}
+	if err := p.XP.Validate(); err != nil {
+		return fmt.Errorf("xp: %w", err)
+	}
+	if err := p.Streaks.Validate(); err != nil {
+		return fmt.Errorf("streaks: %w", err)
+	}
+	return nil
+}
+
+// DefaultGamificationPolicy действует, пока администратор не сохранил свою.
+// Кривая совпадает с прежним правилом «100 XP = 1 уровень».
+func DefaultGamificationPolicy() GamificationPolicy {
+	return GamificationPolicy{
+		Levels: LevelCurve{Kind: LevelCurveLinear, XPPerLevel: 100},
+		XP: XPRules{
+			LessonDefaultXP:       10,
+			TestMaxXP:             50,
+			ScoreProportional:     true,
+			FirstTryBonusPercent:  20,
+			DifficultyMultipliers: []float64{1, 1.1, 1.25, 1.5, 1.75},
+			StreakMultipliers: []StreakMultiplier{
+				{MinDays: 3, Multiplier: 1.1},
+				{MinDays: 7, Multiplier: 1.25},
+				{MinDays: 30, Multiplier: 1.5},
+			},
+		},
+		Streaks: StreakRules{FreezeEveryDays: 7, MaxFreezes: 2},
+	}
+}
+
+// LevelUp — переход на новый уровень после начисления XP.
+type LevelUp struct {
+	From int
+	To   int
+}
+
+// XPGrant — итог начисления XP.
+type XPGrant struct {
+	// Amount — начисленный XP; 0, если источник уже давал XP.
+	Amount  int
+	XP      int64
+	Level   int
+	LevelUp *LevelUp
+}
@@ -0,0 +1,191 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLevelCurveLevelFor(t *testing.T) {
+	linear := LevelCurve{Kind: LevelCurveLinear, XPPerLevel: 100}
+	quadratic := LevelCurve{Kind: LevelCurveQuadratic, XPPerLevel: 50}
+	table := LevelCurve{Kind: LevelCurveTable, Thresholds: []int64{10, 30, 100}}
+
+	tests := []struct {
+		name  string
+		curve LevelCurve
+		xp    int64
+		want  int
+	}{
+		{"linear zero", linear, 0, 1},
+		{"linear below first", linear, 99, 1},
+		{"linear at first", linear, 100, 2},
+		{"linear far", linear, 1050, 11},
+		{"linear capped", linear, 1 << 40, MaxLevel},
+
+		{"quadratic zero", quadratic, 0, 1},
+		{"quadratic level 2", quadratic, 50, 2},
+		{"quadratic below 3", quadratic, 199, 2},
+		{"quadratic level 3", quadratic, 200, 3},
+		{"quadratic level 4", quadratic, 450, 4},
+
+		{"table zero", table, 0, 1},
+		{"table first", table, 10, 2},
+		{"table between", table, 99, 3},
+		{"table last", table, 100, 4},
+		{"table beyond", table, 1_000_000, 4},
+
+		{"unknown kind", LevelCurve{Kind: "cubic", XPPerLevel: 10}, 1000, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curve.LevelFor(tt.xp); got != tt.want {
+				t.Errorf("LevelFor(%d) = %d, want %d", tt.xp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelCurveThresholds(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve LevelCurve
+		head  []int64
+		count int
+	}{
+		{"linear", LevelCurve{Kind: LevelCurveLinear, XPPerLevel: 100}, []int64{100, 200, 300}, MaxLevel - 1},
+		{"quadratic", LevelCurve{Kind: LevelCurveQuadratic, XPPerLevel: 10}, []int64{10, 40, 90}, MaxLevel - 1},
+		{"table", LevelCurve{Kind: LevelCurveTable, Thresholds: []int64{5, 7, 9}}, []int64{5, 7, 9}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.curve.LevelThresholds()
+			if len(got) != tt.count {
+				t.Fatalf("got %d thresholds, want %d", len(got), tt.count)
+			}
+			if !slices.Equal(got[:len(tt.head)], tt.head) {
+				t.Errorf("thresholds start with %v, want %v", got[:len(tt.head)], tt.head)
+			}
+			// Пороги совпадают с LevelFor: на пороге уровня i+2 начинается он
+			for i, xp := range got {
+				if level := tt.curve.LevelFor(xp); level != i+2 {
+					t.Fatalf("LevelFor(threshold %d) = %d, want %d", xp, level, i+2)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelCurveValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		curve   LevelCurve
+		wantErr bool
+	}{
+		{"linear", LevelCurve{Kind: LevelCurveLinear, XPPerLevel: 100}, false},
+		{"linear zero step", LevelCurve{Kind: LevelCurveLinear}, true},
+		{"quadratic too steep", LevelCurve{Kind: LevelCurveQuadratic, XPPerLevel: 2_000_000_000}, true},
+		{"table", LevelCurve{Kind: LevelCurveTable, Thresholds: []int64{1, 2}}, false},
+		{"table empty", LevelCurve{Kind: LevelCurveTable}, true},
+		{"table not increasing", LevelCurve{Kind: LevelCurveTable, Thresholds: []int64{10, 10}}, true},
+		{"table starts at zero", LevelCurve{Kind: LevelCurveTable, Thresholds: []int64{0, 10}}, true},
+		{"table too long", LevelCurve{Kind: LevelCurveTable, Thresholds: make([]int64, MaxLevel)}, true},
+		{"unknown", LevelCurve{Kind: "log"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.curve.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestXPRulesLessonXP(t *testing.T) {
+	rules := DefaultGamificationPolicy().XP
+	reward := func(v int) *int { return &v }
+
+	tests := []struct {
+		name       string
+		base       *int
+		difficulty int
+		streak     int
+		want       int
+	}{
+		{"not set uses default", nil, 1, 0, 10},
+		{"zero gives nothing", reward(0), 5, 30, 0},
+		{"custom", reward(40), 1, 0, 40},
+		{"difficulty", reward(40), 4, 0, 60},
+		{"unknown difficulty", reward(40), 9, 0, 40},
+		{"streak below first rule", reward(40), 1, 2, 40},
+		{"streak picks the best reached rule", reward(40), 1, 10, 50},
+		{"difficulty and streak", reward(10), 5, 30, 26},
+		{"tiny reward rounds up to 1", reward(1), 1, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.LessonXP(tt.base, tt.difficulty, tt.streak); got != tt.want {
+				t.Errorf("LessonXP() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXPRulesTestXP(t *testing.T) {
+	proportional := DefaultGamificationPolicy().XP
+	flat := proportional
+	flat.ScoreProportional = false
+
+	tests := []struct {
+		name       string
+		rules      XPRules
+		score      int
+		firstTry   bool
+		difficulty int
+		streak     int
+		want       int
+	}{
+		{"full score", proportional, 100, false, 1, 0, 50},
+		{"partial score", proportional, 70, false, 1, 0, 35},
+		{"score clamped", proportional, 150, false, 1, 0, 50},
+		{"zero score", proportional, 0, true, 5, 30, 0},
+		{"first try bonus", proportional, 100, true, 1, 0, 60},
+		{"flat ignores score", flat, 40, false, 1, 0, 50},
+		{"difficulty and streak", proportional, 100, true, 3, 7, 94},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.TestXP(tt.score, tt.firstTry, tt.difficulty, tt.streak)
+			if got != tt.want {
+				t.Errorf("TestXP() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXPRulesValidate(t *testing.T) {
+	valid := DefaultGamificationPolicy().XP
+
+	tests := []struct {
+		name    string
+		modify  func(r *XPRules)
+		wantErr bool
+	}{
+		{"default", func(*XPRules) {}, false},
+		{"zero lesson default", func(r *XPRules) { r.LessonDefaultXP = 0 }, true},
+		{"negative test xp", func(r *XPRules) { r.TestMaxXP = -1 }, true},
+		{"bonus too big", func(r *XPRules) { r.FirstTryBonusPercent = 501 }, true},
+		{"four difficulties", func(r *XPRules) { r.DifficultyMultipliers = []float64{1, 1, 1, 1} }, true},
+		{"zero multiplier", func(r *XPRules) { r.DifficultyMultipliers = []float64{1, 1, 0, 1, 1} }, true},
+		{"bad streak rule", func(r *XPRules) { r.StreakMultipliers = []StreakMultiplier{{MinDays: 0, Multiplier: 2}} }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			r.DifficultyMultipliers = slices.Clone(valid.DifficultyMultipliers)
+			tt.modify(&r)
+			if err := r.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
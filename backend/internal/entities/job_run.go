@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+	// JobRunSkipped — задача ничего не сделала, потому что ту же работу
+	// уже выполнял другой экземпляр.
+	JobRunSkipped JobRunStatus = "skipped"
+)
+
+// JobRun — запуск периодической задачи в слоте расписания ScheduledAt.
+// Повторы после ошибки идут в рамках того же запуска и увеличивают
+// Attempts; Error — текст последней ошибки.
+type JobRun struct {
+	ID          string
+	JobName     string
+	ScheduledAt time.Time
+	Status      JobRunStatus
+	Attempts    int
+	Instance    string
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+	Error       string
+}
@@ -0,0 +1,47 @@
+package entities
+
+import "time"
+
+// CohortSize — желаемый размер недельной когорты лиги.
+const CohortSize = 30
+
+// LeagueCohort — недельная группа учеников одной лиги. Таблица, повышение
+// и понижение считаются внутри когорты.
+type LeagueCohort struct {
+	ID        string
+	LeagueID  int
+	WeekStart time.Time
+	CreatedAt time.Time
+}
+
+// WeekStart возвращает начало недели (понедельник 00:00 UTC), в которую
+// попадает t. Недели лиг и когорт считаются по UTC.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysToSubtract := int(t.Weekday()) - int(time.Monday)
+	if daysToSubtract < 0 {
+		daysToSubtract += 7
+	}
+
+	year, month, day := t.AddDate(0, 0, -daysToSubtract).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// SplitIntoCohorts делит n учеников на когорты размером около CohortSize
+// и возвращает размеры когорт; соседние размеры отличаются не больше
+// чем на единицу.
+func SplitIntoCohorts(n int) []int {
+	if n <= 0 {
+		return nil
+	}
+
+	count := max((n+CohortSize/2)/CohortSize, 1)
+	sizes := make([]int, count)
+	for i := range sizes {
+		sizes[i] = n / count
+		if i < n%count {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
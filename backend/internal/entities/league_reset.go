@@ -0,0 +1,46 @@
+package entities
+
+import "time"
+
+// LeagueStanding — место ученика в недельной таблице лиги. При равном
+// WeeklyXP выше тот, кто набрал его раньше (ReachedAt), затем — по UserID.
+type LeagueStanding struct {
+	UserID    string
+	WeeklyXP  int64
+	ReachedAt time.Time
+}
+
+// LeagueMove — итог недели для ученика. Rank — место в когорте; у
+// неактивных учеников он общий: следующий после последнего активного.
+// CohortID пуст, если ученик не был в когорте. ToLeagueID == FromLeagueID, если
+// ученик остаётся в лиге.
+type LeagueMove struct {
+	UserID       string
+	CohortID     string
+	Rank         int
+	WeeklyXP     int64
+	FromLeagueID int
+	ToLeagueID   int
+	Outcome      LeagueOutcome
+}
+
+// LeagueResetPlan — итоги недели одной лиги по всем её когортам.
+type LeagueResetPlan struct {
+	LeagueID int
+	Cohorts  int
+	Active   int
+	Inactive int
+	Promoted []LeagueMove
+	Demoted  []LeagueMove
+}
+
+// WeeklyResetPlan — что изменит еженедельный сброс лиг. Due — пора ли
+// выполнять сброс за неделю, начавшуюся WeekStart.
+type WeeklyResetPlan struct {
+	WeekStart   time.Time
+	LastReset   time.Time
+	Due         bool
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Leagues     []LeagueResetPlan
+}
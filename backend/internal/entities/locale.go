@@ -0,0 +1,54 @@
+package entities
+
+import "context"
+
+// Locale — язык интерфейса и писем пользователя.
+type Locale string
+
+const (
+	LocaleRU Locale = "ru"
+	LocaleKK Locale = "kk"
+	LocaleEN Locale = "en"
+
+	DefaultLocale = LocaleRU
+)
+
+var SupportedLocales = []Locale{LocaleRU, LocaleKK, LocaleEN}
+
+func (l Locale) IsValid() bool {
+	switch l {
+	case LocaleRU, LocaleKK, LocaleEN:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseLocale возвращает DefaultLocale для пустой или неизвестной строки.
+func ParseLocale(s string) Locale {
+	if l := Locale(s); l.IsValid() {
+		return l
+	}
+	return DefaultLocale
+}
+
+type localeKey struct{}
+
+// WithLocale кладёт в ctx язык, на котором нужно читать контент.
+// Репозитории берут его оттуда и подставляют переводы.
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, l)
+}
+
+// WithoutLocale убирает язык из ctx: чтение вернёт исходный текст без
+// переводов. Нужен перед изменением записи, чтобы перевод не попал
+// в исходные колонки.
+func WithoutLocale(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localeKey{}, Locale(""))
+}
+
+// LocaleFrom возвращает язык из ctx или пустую строку, если его нет.
+func LocaleFrom(ctx context.Context) Locale {
+	l, _ := ctx.Value(localeKey{}).(Locale)
+	return l
+}
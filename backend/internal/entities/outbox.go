@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxFailed  OutboxStatus = "failed"
+	// OutboxExpired — письмо не успели отправить, пока код или ссылка в нём
+	// были действительны.
+	OutboxExpired OutboxStatus = "expired"
+)
+
+// Шаблоны писем; имена совпадают с файлами в adapters/email/templates.
+const (
+	EmailTemplateResetCode    = "reset_code"
+	EmailTemplateVerifyEmail  = "verify_email"
+	EmailTemplateWeeklyDigest = "weekly_digest"
+)
+
+// EmailMessage — готовое к отправке письмо.
+type EmailMessage struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// OutboxEmail — письмо в очереди на отправку. Письмо рендерится при
+// постановке в очередь, поэтому отправка не зависит от шаблонов.
+// ExpiresAt — когда письмо теряет смысл (истекает код или ссылка);
+// nil — письмо бессрочное.
+type OutboxEmail struct {
+	ID       int64
+	Template string
+	Locale   Locale
+	Message  EmailMessage
+
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	ExpiresAt     *time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
@@ -15,11 +15,19 @@ type StudentProfile struct {
 	Level  int
 
 	CurrentLeagueID  int
+	CohortID         string
 	WeeklyXP         int64
 	CurrentStreak    int
 	MaxStreak        int
 	LastActivityDate *time.Time
 
+	// Серия считается по локальным дням в Timezone (IANA-имя).
+	// LastActivityDate — последний засчитанный в серию день: с активностью
+	// или покрытый заморозкой. StreakFreezes — накопленные заморозки,
+	// каждая автоматически покрывает один пропущенный день.
+	Timezone      string
+	StreakFreezes int
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -28,6 +36,21 @@ type StudentProfile struct {
 	AvatarURL string
 }
 
+// DefaultTimezone — часовой пояс учеников, которые не указали свой.
+const DefaultTimezone = "Asia/Almaty"
+
+// ParseTimezone проверяет IANA-имя часового пояса.
+func ParseTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
 func NewStudentProfile(userID string, grade int) (*StudentProfile, error) {
 	if grade < 1 || grade > 11 {
 		return nil, errors.New("grade must be between 1 and 11")
@@ -47,6 +70,7 @@ func NewStudentProfile(userID string, grade int) (*StudentProfile, error) {
 		CurrentStreak:    0,
 		MaxStreak:        0,
 		LastActivityDate: nil,
+		Timezone:         DefaultTimezone,
 
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -69,3 +93,41 @@ func (sp *StudentProfile) SetGrade(newGrade int) error {
 	sp.UpdatedAt = time.Now().UTC()
 	return nil
 }
+
+// Location возвращает часовой пояс ученика; для неизвестного — DefaultTimezone.
+func (sp *StudentProfile) Location() *time.Location {
+	if loc, err := ParseTimezone(sp.Timezone); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// LocalDay возвращает календарный день момента t в часовом поясе ученика
+// как полночь UTC — в таком виде дни хранятся в last_activity_date.
+func (sp *StudentProfile) LocalDay(t time.Time) time.Time {
+	y, m, d := t.In(sp.Location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// SetTimezone меняет часовой пояс серии.
+func (sp *StudentProfile) SetTimezone(name string) error {
+	if _, err := ParseTimezone(name); err != nil {
+		return err
+	}
+	sp.Timezone = name
+	sp.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// StreakFinalization — итог закрытия пропущенных дней серий.
+type StreakFinalization struct {
+	// Kept — серии, сохранённые заморозками.
+	Kept int
+	// Reset — серии, сброшенные из-за нехватки заморозок.
+	Reset int
+	// FreezesUsed — сколько заморозок потрачено.
+	FreezesUsed int
+}
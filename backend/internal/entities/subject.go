@@ -6,21 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// Subject — предмет. Name — исходное название; при чтении с языком
+// в context репозиторий подставляет перевод из subject_translations.
 type Subject struct {
-	ID     string
-	Slug   string
-	NameRu string
-	NameKz string
+	ID   string
+	Slug string
+	Name string
 }
 
-func NewSubject(slug, nameRu, nameKz string) (*Subject, error) {
-	if slug == "" || nameRu == "" || nameKz == "" {
+func NewSubject(slug, name string) (*Subject, error) {
+	if slug == "" || name == "" {
 		return nil, errors.New("all subject fields are required")
 	}
 	return &Subject{
-		ID:     uuid.NewString(),
-		Slug:   slug,
-		NameRu: nameRu,
-		NameKz: nameKz,
+		ID:   uuid.NewString(),
+		Slug: slug,
+		Name: name,
 	}, nil
 }
@@ -6,12 +6,58 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	QuestionTypeSingleChoice = "single_choice"
+	QuestionTypeMultiple     = "multiple"
+	// QuestionTypeShortAnswer — свободный ответ; варианты Answer — допустимые написания.
+	QuestionTypeShortAnswer = "short_answer"
+	// QuestionTypeNumeric — число с допуском (NumericValue ± NumericTolerance).
+	QuestionTypeNumeric = "numeric"
+	// QuestionTypeOrdering — расставить варианты Answer по OrderIndex.
+	QuestionTypeOrdering = "ordering"
+	// QuestionTypeMatching — сопоставить Answer.Text (слева) и Answer.MatchText (справа).
+	QuestionTypeMatching = "matching"
+)
+
+// ScoringMode определяет, как начисляются баллы за вопрос.
+type ScoringMode string
+
+const (
+	// ScoringAllOrNothing — балл только за полностью верный ответ.
+	ScoringAllOrNothing ScoringMode = "all_or_nothing"
+	// ScoringPartial — доля верно выбранных вариантов, не ниже нуля.
+	ScoringPartial ScoringMode = "partial"
+	// ScoringNegative — как partial, но за неверные варианты балл может уйти в минус.
+	ScoringNegative ScoringMode = "negative"
+)
+
+func (m ScoringMode) IsValid() bool {
+	switch m {
+	case ScoringAllOrNothing, ScoringPartial, ScoringNegative:
+		return true
+	}
+	return false
+}
+
 type Test struct {
 	ID           string
 	ModuleID     string
 	Title        string
 	PassingScore int
 
+	// ScoringRules: тип вопроса -> режим оценивания. Не заданные типы
+	// оцениваются по умолчанию (см. ScoringModeFor).
+	ScoringRules map[string]ScoringMode
+
+	// Ограничения попыток; нулевое значение — без ограничения.
+	MaxAttempts int
+	Cooldown    time.Duration
+	TimeLimit   time.Duration
+
+	// PoolRules добавляют к Questions случайные вопросы из банка курса.
+	PoolRules      []PoolRule
+	ShuffleAnswers bool
+
 	Questions []Question
 }
 
@@ -19,9 +65,81 @@ type Question struct {
 	ID           string
 	TestID       string
 	Text         string
-	QuestionType string // 'single_choice', 'multiple'
+	QuestionType string // 'single_choice', 'multiple', 'short_answer', 'numeric', 'ordering', 'matching'
+
+	// Только для numeric
+	NumericValue     *float64
+	NumericTolerance float64
+
+	// Только для вопросов банка (TestID пустой)
+	CourseID   string
+	Difficulty int // 1-5, 0 — не задана
+	Tags       []string
 
 	Answers []Answer
+
+	// MatchOptions — правые части matching в том виде, в каком их получил
+	// студент; заполняется только для вопросов попытки.
+	MatchOptions []MatchOption
+}
+
+// MatchOption — правая часть пары matching, выданная в попытке. Key
+// генерируется на попытку и не совпадает с ID варианта, поэтому по нему
+// нельзя угадать пару.
+type MatchOption struct {
+	Key      string
+	AnswerID string
+	Text     string // не хранится, заполняется при сборке вопросов попытки
+}
+
+func IsValidQuestionType(qType string) bool {
+	switch qType {
+	case QuestionTypeSingleChoice, QuestionTypeMultiple, QuestionTypeShortAnswer,
+		QuestionTypeNumeric, QuestionTypeOrdering, QuestionTypeMatching:
+		return true
+	}
+	return false
+}
+
+// PoolRule — "Count вопросов из банка курса" с фильтром по тегам (любой из)
+// и сложности. Пустые фильтры не ограничивают выборку.
+type PoolRule struct {
+	Count      int
+	Tags       []string
+	Difficulty int
+}
+
+// Matches проверяет, подходит ли вопрос банка под правило.
+func (r PoolRule) Matches(q *Question) bool {
+	if r.Difficulty != 0 && q.Difficulty != r.Difficulty {
+		return false
+	}
+	if len(r.Tags) == 0 {
+		return true
+	}
+	for _, want := range r.Tags {
+		for _, tag := range q.Tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsRandomized — набор вопросов или порядок вариантов меняется от попытки к попытке.
+func (t *Test) IsRandomized() bool {
+	return len(t.PoolRules) > 0 || t.ShuffleAnswers
+}
+
+func (t *Test) ScoringModeFor(qType string) ScoringMode {
+	if mode, ok := t.ScoringRules[qType]; ok && mode.IsValid() {
+		return mode
+	}
+	if qType == QuestionTypeMultiple || qType == QuestionTypeOrdering || qType == QuestionTypeMatching {
+		return ScoringPartial
+	}
+	return ScoringAllOrNothing
 }
 
 type Answer struct {
@@ -29,6 +147,9 @@ type Answer struct {
 	QuestionID string
 	Text       string
 	IsCorrect  bool
+
+	OrderIndex int    // ordering: позиция в правильном порядке
+	MatchText  string // matching: правая часть пары
 }
 
 type TestResult struct {
@@ -40,12 +161,91 @@ type TestResult struct {
 	AttemptDate time.Time
 }
 
+// TestSession — начатая студентом попытка. ID служит токеном, который
+// нужно передать при отправке ответов.
+type TestSession struct {
+	ID          string
+	UserID      string
+	TestID      string
+	StartedAt   time.Time
+	Deadline    *time.Time
+	SubmittedAt *time.Time
+	ResultID    *string
+
+	// Served — вопросы попытки в показанном порядке. Пусто для попыток,
+	// начатых до того, как набор вопросов стал сохраняться всегда.
+	Served []ServedQuestion
+}
+
+// ServedQuestion — вопрос, показанный в попытке, порядок его вариантов
+// и, для matching, перемешанные правые части.
+type ServedQuestion struct {
+	QuestionID   string
+	AnswerIDs    []string
+	MatchOptions []MatchOption
+}
+
+func NewTestSession(userID string, test *Test, now time.Time) *TestSession {
+	s := &TestSession{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		TestID:    test.ID,
+		StartedAt: now,
+	}
+	if test.TimeLimit > 0 {
+		deadline := now.Add(test.TimeLimit)
+		s.Deadline = &deadline
+	}
+	return s
+}
+
+// IsOpen — попытка ещё не отправлена и время не вышло.
+func (s *TestSession) IsOpen(now time.Time) bool {
+	return s.SubmittedAt == nil && (s.Deadline == nil || now.Before(*s.Deadline))
+}
+
+// FinishedAt — момент, с которого отсчитывается пауза до следующей попытки.
+func (s *TestSession) FinishedAt() time.Time {
+	if s.SubmittedAt != nil {
+		return *s.SubmittedAt
+	}
+	if s.Deadline != nil {
+		return *s.Deadline
+	}
+	return s.StartedAt
+}
+
+// TestAttemptAnswer — ответ студента на один вопрос в конкретной попытке.
+// QuestionText и AnswerTexts — снимок на момент сдачи.
+type TestAttemptAnswer struct {
+	ID           string
+	ResultID     string
+	QuestionID   string
+	Position     int
+	QuestionText string
+	QuestionType string
+	AnswerIDs    []string
+	AnswerTexts  []string // тексты вариантов из AnswerIDs в том же порядке
+	TextAnswer   string
+	Pairs        map[string]string
+	IsCorrect    bool
+	Points       float64
+}
+
+// TestAttempt — попытка прохождения теста вместе с ответами.
+type TestAttempt struct {
+	Result  TestResult
+	Answers []TestAttemptAnswer
+}
+
 func NewTest(moduleID, title string, passingScore int) *Test {
 	return &Test{
 		ID:           uuid.NewString(),
 		ModuleID:     moduleID,
 		Title:        title,
 		PassingScore: passingScore,
+		ScoringRules: map[string]ScoringMode{},
+		PoolRules:    []PoolRule{},
 		Questions:    []Question{},
 	}
 }
@@ -60,6 +260,14 @@ func NewQuestion(testID, text, qType string) *Question {
 	}
 }
 
+func NewBankQuestion(courseID, text, qType string, difficulty int, tags []string) *Question {
+	q := NewQuestion("", text, qType)
+	q.CourseID = courseID
+	q.Difficulty = difficulty
+	q.Tags = tags
+	return q
+}
+
 func NewAnswer(questionID, text string, isCorrect bool) *Answer {
 	return &Answer{
 		ID:         uuid.NewString(),
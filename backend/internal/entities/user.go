@@ -26,6 +26,9 @@ type User struct {
 	AvatarURL    string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+
+	EmailVerifiedAt *time.Time // nil, пока пользователь не подтвердил email
+	Locale          Locale
 }
 
 func NewUser(email, password, firstName, lastName, avatarUrl string, role UserRole) (*User, error) {
@@ -56,6 +59,7 @@ func NewUser(email, password, firstName, lastName, avatarUrl string, role UserRo
 		LastName:  lastName,
 		Role:      role,
 		AvatarURL: avatarUrl,
+		Locale:    DefaultLocale,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -73,6 +77,10 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 func isValidRole(role UserRole) bool {
 	switch role {
 	case RoleStudent, RoleTeacher, RoleAdmin:
@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+// XPSourceType — за что начислен XP. Пара (SourceType, SourceID)
+// уникальна для ученика, поэтому один источник не даст XP дважды.
+type XPSourceType string
+
+const (
+	XPSourceLesson      XPSourceType = "lesson"
+	XPSourceTest        XPSourceType = "test"
+	XPSourceAchievement XPSourceType = "achievement"
+)
+
+// XPTransaction — запись журнала xp_transactions.
+type XPTransaction struct {
+	ID         int64
+	UserID     string
+	SourceType XPSourceType
+	SourceID   string
+	Amount     int
+	CreatedAt  time.Time
+
+	// SourceTitle — название урока, теста или достижения; заполняется
+	// только при чтении истории.
+	SourceTitle string
+}
+
+func NewXPTransaction(userID string, sourceType XPSourceType, sourceID string, amount int) *XPTransaction {
+	return &XPTransaction{
+		UserID:     userID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+		Amount:     amount,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
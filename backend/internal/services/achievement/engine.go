@@ -0,0 +1,132 @@
+package achievement
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Repository interface {
+	GetPendingAchievements(ctx context.Context, userID string, event entities.LearningEventType) ([]entities.Achievement, error)
+	CountUserMetric(ctx context.Context, userID string, metric entities.AchievementMetric) (int, error)
+	AwardAchievement(ctx context.Context, userID, achievementID string) (bool, error)
+}
+
+// XPLedger начисляет награду за достижение через журнал XP.
+type XPLedger interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Grant(ctx context.Context, t *entities.XPTransaction, curve entities.LevelCurve) (*entities.XPGrant, error)
+}
+
+// PolicyProvider отдаёт действующую кривую уровней.
+type PolicyProvider interface {
+	Policy(ctx context.Context) (entities.GamificationPolicy, error)
+}
+
+// Engine выдаёт достижения по правилам из таблицы achievements.
+// На каждое событие проверяются только ещё не полученные достижения
+// с подходящим rule_event, поэтому новое правило достаточно добавить
+// в seed-данные. Каждое достижение выдаётся один раз вместе с XPReward.
+type Engine struct {
+	repo   Repository
+	ledger XPLedger
+	policy PolicyProvider
+}
+
+func NewEngine(repo Repository, ledger XPLedger, policy PolicyProvider) *Engine {
+	return &Engine{repo: repo, ledger: ledger, policy: policy}
+}
+
+// Handle проверяет правила для события и возвращает выданные достижения.
+func (e *Engine) Handle(ctx context.Context, event entities.LearningEvent) ([]entities.Achievement, error) {
+	pending, err := e.repo.GetPendingAchievements(ctx, event.UserID, event.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	// Несколько правил обычно смотрят на одну метрику с разными порогами
+	counts := make(map[entities.AchievementMetric]int)
+
+	var awarded []entities.Achievement
+	for _, a := range pending {
+		if a.Rule == nil {
+			continue
+		}
+
+		value, err := e.metricValue(ctx, event, a.Rule.Metric, counts)
+		if err != nil {
+			return awarded, err
+		}
+		if value < a.Rule.Threshold {
+			continue
+		}
+
+		ok, err := e.award(ctx, event.UserID, a)
+		if err != nil {
+			return awarded, fmt.Errorf("award achievement %s: %w", a.Slug, err)
+		}
+		if !ok {
+			continue
+		}
+
+		awarded = append(awarded, a)
+		log.Info().
+			Str("user_id", event.UserID).
+			Str("achievement", a.Slug).
+			Int("xp", a.XPReward).
+			Msg("achievement awarded")
+	}
+
+	return awarded, nil
+}
+
+func (e *Engine) metricValue(
+	ctx context.Context,
+	event entities.LearningEvent,
+	metric entities.AchievementMetric,
+	counts map[entities.AchievementMetric]int,
+) (int, error) {
+	if metric.FromEvent() {
+		return event.Value, nil
+	}
+
+	if v, ok := counts[metric]; ok {
+		return v, nil
+	}
+
+	v, err := e.repo.CountUserMetric(ctx, event.UserID, metric)
+	if err != nil {
+		return 0, err
+	}
+	counts[metric] = v
+	return v, nil
+}
+
+// award выдаёт достижение и его XP в одной транзакции.
+func (e *Engine) award(ctx context.Context, userID string, a entities.Achievement) (bool, error) {
+	awarded := false
+	err := e.ledger.WithinTransaction(ctx, func(ctx context.Context) error {
+		ok, err := e.repo.AwardAchievement(ctx, userID, a.ID)
+		if err != nil || !ok {
+			return err
+		}
+		awarded = true
+
+		if a.XPReward <= 0 {
+			return nil
+		}
+		policy, err := e.policy.Policy(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = e.ledger.Grant(ctx, entities.NewXPTransaction(userID, entities.XPSourceAchievement, a.ID, a.XPReward), policy.Levels)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return awarded, nil
+}
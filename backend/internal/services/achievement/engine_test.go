@@ -0,0 +1,264 @@
+package achievement
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+// fakeStore — достижения, выданные ученикам, и журнал XP. Как и в БД,
+// достижение и источник XP уникальны для ученика, а транзакция
+// откатывает выдачу, если начисление не прошло.
+type fakeStore struct {
+	mu           sync.Mutex
+	achievements []entities.Achievement
+	metrics      map[entities.AchievementMetric]int
+	earned       map[string]bool
+	grants       map[string]int
+	countCalls   int
+	// failGrant — ошибка для следующего начисления
+	failGrant error
+}
+
+func newFakeStore(achievements ...entities.Achievement) *fakeStore {
+	return &fakeStore{
+		achievements: achievements,
+		metrics:      map[entities.AchievementMetric]int{},
+		earned:       map[string]bool{},
+		grants:       map[string]int{},
+	}
+}
+
+func (s *fakeStore) GetPendingAchievements(_ context.Context, userID string, event entities.LearningEventType) ([]entities.Achievement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []entities.Achievement
+	for _, a := range s.achievements {
+		if a.Rule != nil && a.Rule.Event == event && !s.earned[userID+"/"+a.ID] {
+			pending = append(pending, a)
+		}
+	}
+	return pending, nil
+}
+
+func (s *fakeStore) CountUserMetric(_ context.Context, _ string, metric entities.AchievementMetric) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.countCalls++
+	return s.metrics[metric], nil
+}
+
+func (s *fakeStore) AwardAchievement(_ context.Context, userID, achievementID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := userID + "/" + achievementID
+	if s.earned[key] {
+		return false, nil
+	}
+	s.earned[key] = true
+	return true, nil
+}
+
+// WithinTransaction откатывает выдачу, если fn вернула ошибку. Откат
+// восстанавливает снимок, поэтому падающие транзакции не запускаются
+// параллельно с другими.
+func (s *fakeStore) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	earned := make(map[string]bool, len(s.earned))
+	for k, v := range s.earned {
+		earned[k] = v
+	}
+	s.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		s.mu.Lock()
+		s.earned = earned
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *fakeStore) Grant(_ context.Context, t *entities.XPTransaction, _ entities.LevelCurve) (*entities.XPGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.failGrant; err != nil {
+		s.failGrant = nil
+		return nil, err
+	}
+	key := t.UserID + "/" + string(t.SourceType) + "/" + t.SourceID
+	if _, ok := s.grants[key]; ok {
+		return &entities.XPGrant{}, nil
+	}
+	s.grants[key] = t.Amount
+	return &entities.XPGrant{Amount: t.Amount}, nil
+}
+
+func (s *fakeStore) Policy(context.Context) (entities.GamificationPolicy, error) {
+	return entities.GamificationPolicy{}, nil
+}
+
+func (s *fakeStore) totalXP() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, amount := range s.grants {
+		total += amount
+	}
+	return total
+}
+
+func testAchievements() []entities.Achievement {
+	return []entities.Achievement{
+		{ID: "a1", Slug: "first-lesson", XPReward: 50, Rule: &entities.AchievementRule{Event: entities.EventLessonCompleted, Metric: entities.MetricLessonsCompleted, Threshold: 1}},
+		{ID: "a2", Slug: "ten-lessons", XPReward: 100, Rule: &entities.AchievementRule{Event: entities.EventLessonCompleted, Metric: entities.MetricLessonsCompleted, Threshold: 10}},
+		{ID: "a3", Slug: "streak-3", XPReward: 30, Rule: &entities.AchievementRule{Event: entities.EventStreakUpdated, Metric: entities.MetricStreakDays, Threshold: 3}},
+		{ID: "a4", Slug: "no-xp", Rule: &entities.AchievementRule{Event: entities.EventLessonCompleted, Metric: entities.MetricLessonsCompleted, Threshold: 2}},
+		{ID: "a5", Slug: "manual", XPReward: 500},
+	}
+}
+
+func slugs(list []entities.Achievement) []string {
+	var out []string
+	for _, a := range list {
+		out = append(out, a.Slug)
+	}
+	return out
+}
+
+func TestHandleAwardsOnce(t *testing.T) {
+	store := newFakeStore(testAchievements()...)
+	store.metrics[entities.MetricLessonsCompleted] = 3
+	engine := NewEngine(store, store, store)
+	ctx := context.Background()
+	event := entities.NewLearningEvent(entities.EventLessonCompleted, "u1", 0)
+
+	awarded, err := engine.Handle(ctx, event)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if got := slugs(awarded); !slices.Equal(got, []string{"first-lesson", "no-xp"}) {
+		t.Errorf("awarded = %v, want [first-lesson no-xp]", got)
+	}
+	// Три правила смотрят на одну метрику: она считается один раз
+	if store.countCalls != 1 {
+		t.Errorf("CountUserMetric called %d times, want 1", store.countCalls)
+	}
+
+	awarded, err = engine.Handle(ctx, event)
+	if err != nil {
+		t.Fatalf("repeated Handle: %v", err)
+	}
+	if len(awarded) != 0 {
+		t.Errorf("repeated event awarded %v again", slugs(awarded))
+	}
+	if len(store.grants) != 1 || store.totalXP() != 50 {
+		t.Errorf("grants = %v, want only 50 XP for first-lesson", store.grants)
+	}
+}
+
+func TestHandleFailedGrantIsRetried(t *testing.T) {
+	store := newFakeStore(testAchievements()...)
+	store.metrics[entities.MetricLessonsCompleted] = 1
+	store.failGrant = errors.New("deadlock detected")
+	engine := NewEngine(store, store, store)
+	ctx := context.Background()
+	event := entities.NewLearningEvent(entities.EventLessonCompleted, "u1", 0)
+
+	if _, err := engine.Handle(ctx, event); err == nil {
+		t.Fatal("Handle() = nil, want the grant error")
+	}
+	// Достижение откатилось вместе с XP, поэтому повтор выдаёт его
+	awarded, err := engine.Handle(ctx, event)
+	if err != nil {
+		t.Fatalf("retried Handle: %v", err)
+	}
+	if got := slugs(awarded); !slices.Equal(got, []string{"first-lesson"}) {
+		t.Errorf("awarded on retry = %v, want [first-lesson]", got)
+	}
+	if store.totalXP() != 50 {
+		t.Errorf("XP granted = %d, want 50", store.totalXP())
+	}
+}
+
+func TestOnEventRedelivery(t *testing.T) {
+	store := newFakeStore(testAchievements()...)
+	store.metrics[entities.MetricLessonsCompleted] = 1
+	engine := NewEngine(store, store, store)
+
+	event, err := entities.NewDomainEvent(entities.DomainLessonCompleted, "u1", entities.LessonCompleted{LessonID: "l1", Streak: 3})
+	if err != nil {
+		t.Fatalf("NewDomainEvent: %v", err)
+	}
+
+	// Relay и повторная доставка могут прийти одновременно
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := engine.OnEvent(context.Background(), event); err != nil {
+				t.Errorf("OnEvent: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !store.earned["u1/a1"] || !store.earned["u1/a3"] || len(store.earned) != 2 {
+		t.Errorf("earned = %v, want first-lesson and streak-3", store.earned)
+	}
+	if len(store.grants) != 2 || store.totalXP() != 80 {
+		t.Errorf("grants = %v, want 50 + 30 XP once each", store.grants)
+	}
+}
+
+func TestLearningEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType entities.DomainEventType
+		payload   any
+		want      []entities.LearningEvent
+	}{
+		{
+			"lesson with streak", entities.DomainLessonCompleted, entities.LessonCompleted{Streak: 4},
+			[]entities.LearningEvent{{Type: entities.EventStreakUpdated, Value: 4}, {Type: entities.EventLessonCompleted}},
+		},
+		{
+			"lesson without profile", entities.DomainLessonCompleted, entities.LessonCompleted{},
+			[]entities.LearningEvent{{Type: entities.EventLessonCompleted}},
+		},
+		{
+			"passed test", entities.DomainTestSubmitted, entities.TestSubmitted{IsPassed: true, Score: 90},
+			[]entities.LearningEvent{{Type: entities.EventTestPassed, Value: 90}},
+		},
+		{"failed test", entities.DomainTestSubmitted, entities.TestSubmitted{Score: 20}, nil},
+		{
+			"promotion", entities.DomainLeaguePromoted, entities.LeaguePromoted{LeagueOrder: 3},
+			[]entities.LearningEvent{{Type: entities.EventLeaguePromoted, Value: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := entities.NewDomainEvent(tt.eventType, "u1", tt.payload)
+			if err != nil {
+				t.Fatalf("NewDomainEvent: %v", err)
+			}
+			got, err := learningEvents(event)
+			if err != nil {
+				t.Fatalf("learningEvents: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("learningEvents() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Type != tt.want[i].Type || got[i].Value != tt.want[i].Value || got[i].UserID != "u1" {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package achievement
+
+import (
+	"context"
+
+	"backend/internal/entities"
+)
+
+// OnEvent — подписчик шины событий. Переводит доменное событие в события
+// обучения, на которые смотрят правила. Повторная доставка безопасна:
+// полученные достижения не выдаются второй раз.
+func (e *Engine) OnEvent(ctx context.Context, event entities.DomainEvent) error {
+	learning, err := learningEvents(event)
+	if err != nil {
+		return err
+	}
+
+	for _, le := range learning {
+		le.OccurredAt = event.OccurredAt
+		if _, err := e.Handle(ctx, le); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func learningEvents(event entities.DomainEvent) ([]entities.LearningEvent, error) {
+	userID := event.UserID
+
+	switch event.Type {
+	case entities.DomainLessonCompleted:
+		var p entities.LessonCompleted
+		if err := event.Decode(&p); err != nil {
+			return nil, err
+		}
+		var list []entities.LearningEvent
+		if p.Streak > 0 {
+			list = append(list, entities.NewLearningEvent(entities.EventStreakUpdated, userID, p.Streak))
+		}
+		return append(list, entities.NewLearningEvent(entities.EventLessonCompleted, userID, 0)), nil
+
+	case entities.DomainTestSubmitted:
+		var p entities.TestSubmitted
+		if err := event.Decode(&p); err != nil {
+			return nil, err
+		}
+		if !p.IsPassed {
+			return nil, nil
+		}
+		return []entities.LearningEvent{entities.NewLearningEvent(entities.EventTestPassed, userID, p.Score)}, nil
+
+	case entities.DomainCourseCompleted:
+		return []entities.LearningEvent{entities.NewLearningEvent(entities.EventCourseCompleted, userID, 0)}, nil
+
+	case entities.DomainLeaguePromoted:
+		var p entities.LeaguePromoted
+		if err := event.Decode(&p); err != nil {
+			return nil, err
+		}
+		return []entities.LearningEvent{entities.NewLearningEvent(entities.EventLeaguePromoted, userID, p.LeagueOrder)}, nil
+
+	default:
+		return nil, nil
+	}
+}
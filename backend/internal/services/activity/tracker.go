@@ -0,0 +1,135 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Repository interface {
+	LogActivities(ctx context.Context, logs []*entities.UserActivityLog) error
+}
+
+// Tracker пишет действия пользователей в user_activity_logs в фоне.
+// Track никогда не блокирует запрос: если буфер переполнен, событие
+// отбрасывается с предупреждением в логе. Накопленные записи сбрасываются
+// пачками по размеру или по таймеру, а при Stop — дописываются до конца.
+type Tracker struct {
+	repo          Repository
+	queue         chan *entities.UserActivityLog
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+func NewTracker(repo Repository, bufferSize, batchSize int, flushInterval time.Duration) *Tracker {
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if flushInterval <= 0 {
+		flushInterval = 2 * time.Second
+	}
+
+	return &Tracker{
+		repo:          repo,
+		queue:         make(chan *entities.UserActivityLog, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+func (t *Tracker) Start() {
+	go t.run()
+}
+
+// Track ставит действие в очередь на запись. courseID может быть nil.
+func (t *Tracker) Track(userID string, courseID *string, action string, meta map[string]any) {
+	entry, err := entities.NewActivityLog(userID, courseID, action, meta)
+	if err != nil {
+		log.Warn().Err(err).Str("action", action).Msg("skip invalid activity log")
+		return
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return
+	}
+
+	select {
+	case t.queue <- entry:
+	default:
+		log.Warn().Str("user_id", userID).Str("action", action).Msg("activity buffer is full, event dropped")
+	}
+}
+
+// Stop перестаёт принимать события и ждёт, пока буфер будет записан в БД.
+func (t *Tracker) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*entities.UserActivityLog, 0, t.batchSize)
+
+	for {
+		select {
+		case entry, ok := <-t.queue:
+			if !ok {
+				t.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= t.batchSize {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (t *Tracker) flush(batch []*entities.UserActivityLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := t.repo.LogActivities(ctx, batch); err != nil {
+		log.Error().Err(err).Int("count", len(batch)).Msg("failed to write activity logs")
+	}
+}
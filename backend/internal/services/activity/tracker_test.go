@@ -0,0 +1,167 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// fakeRepo запоминает каждую записанную пачку и сообщает о ней в flushed.
+type fakeRepo struct {
+	mu      sync.Mutex
+	batches [][]string
+	flushed chan int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{flushed: make(chan int, 100)}
+}
+
+func (f *fakeRepo) LogActivities(_ context.Context, logs []*entities.UserActivityLog) error {
+	actions := make([]string, 0, len(logs))
+	for _, l := range logs {
+		actions = append(actions, l.ActionType)
+	}
+
+	f.mu.Lock()
+	f.batches = append(f.batches, actions)
+	f.mu.Unlock()
+
+	f.flushed <- len(logs)
+	return nil
+}
+
+func (f *fakeRepo) written() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.batches...)
+}
+
+func (f *fakeRepo) waitFlush(t *testing.T) int {
+	t.Helper()
+	select {
+	case n := <-f.flushed:
+		return n
+	case <-time.After(2 * time.Second):
+		t.Fatal("no flush within 2s")
+		return 0
+	}
+}
+
+func stop(t *testing.T, tr *Tracker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := tr.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+}
+
+func TestTrackerFlushesFullBatches(t *testing.T) {
+	repo := newFakeRepo()
+	tr := NewTracker(repo, 16, 2, time.Hour)
+	tr.Start()
+
+	for i := 0; i < 5; i++ {
+		tr.Track("u1", nil, entities.ActionCourseView, nil)
+	}
+
+	for i := 0; i < 2; i++ {
+		if n := repo.waitFlush(t); n != 2 {
+			t.Fatalf("batch %d has %d logs, want 2", i, n)
+		}
+	}
+	select {
+	case n := <-repo.flushed:
+		t.Fatalf("partial batch of %d flushed before the interval", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stop(t, tr)
+	if got := len(repo.written()); got != 3 {
+		t.Errorf("batches = %d, want 3 (the last one written on Stop)", got)
+	}
+}
+
+func TestTrackerFlushesOnInterval(t *testing.T) {
+	repo := newFakeRepo()
+	tr := NewTracker(repo, 16, 100, 20*time.Millisecond)
+	tr.Start()
+	defer stop(t, tr)
+
+	tr.Track("u1", nil, entities.ActionCourseView, nil)
+	tr.Track("u1", nil, entities.ActionLessonOpen, nil)
+
+	if n := repo.waitFlush(t); n != 2 {
+		t.Errorf("timed flush wrote %d logs, want 2", n)
+	}
+}
+
+func TestTrackerStopWritesBufferedLogs(t *testing.T) {
+	repo := newFakeRepo()
+	tr := NewTracker(repo, 16, 100, time.Hour)
+	tr.Start()
+
+	tr.Track("u1", nil, entities.ActionCourseView, nil)
+	tr.Track("u1", nil, entities.ActionLessonComplete, nil)
+	tr.Track("u1", nil, entities.ActionTestSubmit, nil)
+	stop(t, tr)
+
+	batches := repo.written()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("batches = %v, want one batch of 3", batches)
+	}
+
+	// После Stop события не принимаются и не паникуют на закрытом канале
+	tr.Track("u1", nil, entities.ActionCourseView, nil)
+	if got := len(repo.written()); got != 1 {
+		t.Errorf("batches after late Track = %d, want 1", got)
+	}
+}
+
+func TestTrackerDropsWhenBufferIsFull(t *testing.T) {
+	repo := newFakeRepo()
+	tr := NewTracker(repo, 2, 100, time.Hour)
+
+	// Писатель ещё не запущен, поэтому третье событие не помещается в буфер
+	done := make(chan struct{})
+	go func() {
+		tr.Track("u1", nil, entities.ActionCourseView, nil)
+		tr.Track("u1", nil, entities.ActionLessonOpen, nil)
+		tr.Track("u1", nil, entities.ActionFavorite, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Track blocked on a full buffer")
+	}
+
+	tr.Start()
+	stop(t, tr)
+
+	batches := repo.written()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("batches = %v, want one batch of 2", batches)
+	}
+	if batches[0][0] != entities.ActionCourseView || batches[0][1] != entities.ActionLessonOpen {
+		t.Errorf("written %v, want the first two events", batches[0])
+	}
+}
+
+func TestTrackerSkipsInvalidLogs(t *testing.T) {
+	repo := newFakeRepo()
+	tr := NewTracker(repo, 16, 100, time.Hour)
+	tr.Start()
+
+	tr.Track("", nil, entities.ActionCourseView, nil)
+	tr.Track("u1", nil, "", nil)
+	stop(t, tr)
+
+	if batches := repo.written(); len(batches) != 0 {
+		t.Errorf("batches = %v, want none", batches)
+	}
+}
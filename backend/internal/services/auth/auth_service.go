@@ -2,16 +2,15 @@ package auth
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"mime/multipart"
 	"time"
 
-	"backend/internal/adapters/email"
 	"backend/internal/adapters/storage"
 	"backend/internal/entities"
 	"backend/pkg/jwt"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,24 +20,73 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
 
-	SaveResetToken(ctx context.Context, email, token string, ttl time.Duration) error
-	VerifyResetToken(ctx context.Context, email, token string) (bool, error)
+	SaveResetToken(ctx context.Context, email, codeHash string, expiresAt time.Time) error
+	ClaimResetAttempt(ctx context.Context, email string, maxAttempts int) (string, error)
 	DeleteResetToken(ctx context.Context, email string) error
+	AddResetEvent(ctx context.Context, kind, email, ip string) error
+	CountResetEvents(ctx context.Context, kind, email, ip string, since time.Time) (int, int, error)
+	CountEmailResetEvents(ctx context.Context, kind, email string, since time.Time) (int, error)
+	DeleteExpiredTokens(ctx context.Context, now, eventsBefore time.Time) (int64, int64, error)
+
+	SaveVerificationToken(ctx context.Context, userID, tokenHash string, expiresAt, notBefore time.Time) (bool, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (string, error)
+	SetLocale(ctx context.Context, userID string, locale entities.Locale) error
+}
+
+type SessionRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	CreateSession(ctx context.Context, s *entities.AuthSession) error
+	GetSession(ctx context.Context, id string) (*entities.AuthSession, error)
+	RevokeSession(ctx context.Context, id, reason string, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID, reason string, at time.Time) error
+
+	SaveRefreshToken(ctx context.Context, t *entities.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, hash string) (*entities.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, hash string, at time.Time) error
+}
+
+// EmailService ставит письма в очередь на отправку на языке получателя.
+type EmailService interface {
+	SendResetCode(ctx context.Context, to string, locale entities.Locale, code string, ttl time.Duration) error
+	SendVerificationLink(ctx context.Context, to string, locale entities.Locale, link string, ttl time.Duration) error
+}
+
+// EventPublisher раздаёт доменные события подписчикам шины.
+type EventPublisher interface {
+	Publish(ctx context.Context, event entities.DomainEvent)
 }
 
 type AuthService struct {
 	userRepo     UserRepository
+	sessionRepo  SessionRepository
 	jwtManager   *jwt.JWTManager
 	storage      storage.FileStorage
-	emailService email.EmailService
+	emailService EmailService
+	events       EventPublisher
+	sessionTTL   time.Duration
+	verification VerificationConfig
 }
 
-func NewAuthService(userRepo UserRepository, jwtManager *jwt.JWTManager, storage storage.FileStorage, emailService email.EmailService) *AuthService {
+func NewAuthService(
+	userRepo UserRepository,
+	sessionRepo SessionRepository,
+	jwtManager *jwt.JWTManager,
+	storage storage.FileStorage,
+	emailService EmailService,
+	events EventPublisher,
+	sessionTTL time.Duration,
+	verification VerificationConfig,
+) *AuthService {
 	return &AuthService{
 		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
 		jwtManager:   jwtManager,
 		storage:      storage,
 		emailService: emailService,
+		events:       events,
+		sessionTTL:   sessionTTL,
+		verification: verification,
 	}
 }
 
@@ -68,78 +116,45 @@ func (s *AuthService) Register(
 		return fmt.Errorf("failed to register user: %w", err)
 	}
 
-	return nil
-}
-
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
-		return "", fmt.Errorf("failed to get user: %w", err)
-	}
-
-	if !s.verifyPassword(user.PasswordHash, password) {
-		return "", entities.ErrInvalidCredentials
+	// Аккаунт уже создан; письмо можно запросить повторно через resend
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to send verification email")
 	}
 
-	token, err := s.generateToken(user)
+	event, err := entities.NewDomainEvent(entities.DomainUserRegistered, user.ID, entities.UserRegistered{
+		Email:  user.Email,
+		Role:   user.Role,
+		Locale: user.Locale,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to build user registered event")
+		return nil
 	}
-
-	return token, nil
-}
-
-func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
-	_, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	code := fmt.Sprintf("%06d", time.Now().UnixNano()%1000000)
-
-	if err := s.userRepo.SaveResetToken(ctx, email, code, 15*time.Minute); err != nil {
-		return err
-	}
-
-	go func() {
-		if err := s.emailService.SendResetCode(email, code); err != nil {
-			fmt.Printf("Failed to send email to %s: %v\n", email, err)
-		}
-	}()
+	s.events.Publish(ctx, event)
 
 	return nil
 }
 
-func (s *AuthService) ResetPassword(ctx context.Context, email, code, newPassword string) error {
-	valid, err := s.userRepo.VerifyResetToken(ctx, email, code)
+func (s *AuthService) Login(ctx context.Context, email, password string) (*Tokens, error) {
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return err
-	}
-	if !valid {
-		return errors.New("invalid or expired reset code")
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if len(newPassword) < 8 {
-		return errors.New("new password must be at least 8 characters")
+	if !s.verifyPassword(user.PasswordHash, password) {
+		return nil, entities.ErrInvalidCredentials
 	}
 
-	newHash, err := s.hashPassword(newPassword)
-	if err != nil {
-		return err
+	if s.verification.Mode == VerificationBlock && !user.IsEmailVerified() {
+		return nil, entities.ErrEmailNotVerified
 	}
 
-	user, err := s.userRepo.GetByEmail(ctx, email)
+	tokens, err := s.startSession(ctx, user)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to start session: %w", err)
 	}
-	user.PasswordHash = newHash
-	user.UpdatedAt = time.Now().UTC()
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
-		return err
-	}
-
-	return s.userRepo.DeleteResetToken(ctx, email)
+	return tokens, nil
 }
 
 func (s *AuthService) ChangePassword(ctx context.Context, userID string, oldPassword, newPassword string) error {
@@ -153,7 +168,7 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, oldPass
 	}
 
 	if len(newPassword) < 8 {
-		return errors.New("password must be at least 8 characters")
+		return entities.ErrWeakPassword
 	}
 
 	newPasswordHash, err := s.hashPassword(newPassword)
@@ -164,7 +179,17 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, oldPass
 	user.PasswordHash = newPasswordHash
 	user.UpdatedAt = time.Now().UTC()
 
-	return s.userRepo.Update(ctx, user)
+	// Старый пароль мог утечь: выходим на всех устройствах, включая текущее.
+	// Если сессии не отозвать, пароль тоже не меняется.
+	return s.sessionRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.sessionRepo.RevokeUserSessions(ctx, user.ID, entities.RevokeReasonPasswordChange, user.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *AuthService) hashPassword(password string) (string, error) {
@@ -180,6 +205,11 @@ func (s *AuthService) verifyPassword(hash, password string) bool {
 	return err == nil
 }
 
-func (s *AuthService) generateToken(user *entities.User) (string, error) {
-	return s.jwtManager.Generate(user.ID, user.Email, string(user.Role))
+// UpdateLocale меняет язык писем и контента пользователя. В access-токене
+// язык обновится при следующем /auth/refresh.
+func (s *AuthService) UpdateLocale(ctx context.Context, userID string, locale entities.Locale) error {
+	if !locale.IsValid() {
+		return fmt.Errorf("unsupported locale %q", locale)
+	}
+	return s.userRepo.SetLocale(ctx, userID, locale)
 }
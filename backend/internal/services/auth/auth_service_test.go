@@ -0,0 +1,606 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+	"backend/pkg/jwt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// resetToken — строка password_reset_tokens.
+type resetToken struct {
+	hash      string
+	expiresAt time.Time
+	attempts  int
+}
+
+// verificationToken — строка email_verification_tokens.
+type verificationToken struct {
+	hash      string
+	expiresAt time.Time
+	createdAt time.Time
+}
+
+type resetEvent struct {
+	kind, email, ip string
+	at              time.Time
+}
+
+// memUsers — пользователи, коды сброса, события сброса и токены
+// подтверждения email в памяти.
+type memUsers struct {
+	UserRepository
+
+	mu            sync.Mutex
+	users         map[string]*entities.User
+	resets        map[string]*resetToken
+	events        []resetEvent
+	verifications map[string]*verificationToken
+}
+
+func newMemUsers(users ...*entities.User) *memUsers {
+	r := &memUsers{
+		users:         map[string]*entities.User{},
+		resets:        map[string]*resetToken{},
+		verifications: map[string]*verificationToken{},
+	}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *memUsers) GetByID(_ context.Context, id string) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, entities.ErrNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *memUsers) GetByEmail(_ context.Context, email string) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, entities.ErrNotFound
+}
+
+func (r *memUsers) Update(_ context.Context, u *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *u
+	r.users[u.ID] = &cp
+	return nil
+}
+
+func (r *memUsers) SaveResetToken(_ context.Context, email, codeHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resets[email] = &resetToken{hash: codeHash, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *memUsers) ClaimResetAttempt(_ context.Context, email string, maxAttempts int) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.resets[email]
+	if !ok || !t.expiresAt.After(time.Now()) || t.attempts >= maxAttempts {
+		return "", entities.ErrNotFound
+	}
+	t.attempts++
+	return t.hash, nil
+}
+
+func (r *memUsers) DeleteResetToken(_ context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.resets, email)
+	return nil
+}
+
+func (r *memUsers) AddResetEvent(_ context.Context, kind, email, ip string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, resetEvent{kind: kind, email: email, ip: ip, at: time.Now()})
+	return nil
+}
+
+func (r *memUsers) CountResetEvents(_ context.Context, kind, email, ip string, since time.Time) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var byIPEmail, byIP int
+	for _, e := range r.events {
+		if e.kind != kind || e.ip != ip || e.at.Before(since) {
+			continue
+		}
+		byIP++
+		if e.email == email {
+			byIPEmail++
+		}
+	}
+	return byIPEmail, byIP, nil
+}
+
+func (r *memUsers) CountEmailResetEvents(_ context.Context, kind, email string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.events {
+		if e.kind == kind && e.email == email && !e.at.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memUsers) SaveVerificationToken(_ context.Context, userID, tokenHash string, expiresAt, notBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.verifications[userID]; ok && !t.createdAt.Before(notBefore) {
+		return false, nil
+	}
+	r.verifications[userID] = &verificationToken{hash: tokenHash, expiresAt: expiresAt, createdAt: time.Now().UTC()}
+	return true, nil
+}
+
+func (r *memUsers) VerifyEmail(_ context.Context, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for userID, t := range r.verifications {
+		if t.hash != tokenHash || !t.expiresAt.After(time.Now()) {
+			continue
+		}
+		delete(r.verifications, userID)
+		if u := r.users[userID]; u.EmailVerifiedAt == nil {
+			now := time.Now().UTC()
+			u.EmailVerifiedAt = &now
+		}
+		return userID, nil
+	}
+	return "", entities.ErrNotFound
+}
+
+// memSessions — сессии и refresh-токены в памяти. Если задан users,
+// транзакция при ошибке откатывает и его пользователей и коды сброса.
+type memSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*entities.AuthSession
+	reasons  map[string]string
+	tokens   map[string]*entities.RefreshToken
+
+	users     *memUsers
+	revokeErr error
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{
+		sessions: map[string]*entities.AuthSession{},
+		reasons:  map[string]string{},
+		tokens:   map[string]*entities.RefreshToken{},
+	}
+}
+
+func (r *memSessions) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.users == nil {
+		return fn(ctx)
+	}
+
+	r.users.mu.Lock()
+	users, resets := maps.Clone(r.users.users), maps.Clone(r.users.resets)
+	r.users.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		r.users.mu.Lock()
+		r.users.users, r.users.resets = users, resets
+		r.users.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r *memSessions) CreateSession(_ context.Context, s *entities.AuthSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *s
+	r.sessions[s.ID] = &cp
+	return nil
+}
+
+func (r *memSessions) GetSession(_ context.Context, id string) (*entities.AuthSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, entities.ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *memSessions) RevokeSession(_ context.Context, id, reason string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok && s.RevokedAt == nil {
+		s.RevokedAt = &at
+		r.reasons[id] = reason
+	}
+	return nil
+}
+
+func (r *memSessions) RevokeUserSessions(_ context.Context, userID, reason string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.revokeErr != nil {
+		return r.revokeErr
+	}
+	for id, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+			r.reasons[id] = reason
+		}
+	}
+	return nil
+}
+
+func (r *memSessions) SaveRefreshToken(_ context.Context, t *entities.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *t
+	r.tokens[t.Hash] = &cp
+	return nil
+}
+
+func (r *memSessions) GetRefreshTokenForUpdate(_ context.Context, hash string) (*entities.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, entities.ErrNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (r *memSessions) MarkRefreshTokenRotated(_ context.Context, hash string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[hash].RotatedAt = &at
+	return nil
+}
+
+func (r *memSessions) reason(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reasons[id]
+}
+
+// sentCodes запоминает коды сброса и ссылки подтверждения вместо отправки писем.
+type sentCodes struct {
+	EmailService
+
+	mu    sync.Mutex
+	codes map[string]string
+	sent  int
+	links []string
+}
+
+func (e *sentCodes) SendVerificationLink(_ context.Context, _ string, _ entities.Locale, link string, _ time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.links = append(e.links, link)
+	return nil
+}
+
+func (e *sentCodes) SendResetCode(_ context.Context, to string, _ entities.Locale, code string, _ time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.codes == nil {
+		e.codes = map[string]string{}
+	}
+	e.codes[to] = code
+	e.sent++
+	return nil
+}
+
+func (e *sentCodes) code(to string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.codes[to]
+	return c, ok
+}
+
+const testPassword = "correct horse"
+
+func testUser(t *testing.T) *entities.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &entities.User{
+		ID:           "u1",
+		Email:        "student@example.com",
+		PasswordHash: string(hash),
+		Role:         entities.RoleStudent,
+		Locale:       entities.DefaultLocale,
+	}
+}
+
+type authFixture struct {
+	svc      *AuthService
+	users    *memUsers
+	sessions *memSessions
+	emails   *sentCodes
+	jwt      *jwt.JWTManager
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	f := &authFixture{
+		users:    newMemUsers(testUser(t)),
+		sessions: newMemSessions(),
+		emails:   &sentCodes{},
+		jwt:      jwt.NewJWTManager("test-secret", time.Minute),
+	}
+	f.sessions.users = f.users
+	f.svc = NewAuthService(f.users, f.sessions, f.jwt, nil, f.emails, nil, time.Hour, VerificationConfig{Mode: VerificationAllow})
+	return f
+}
+
+// login входит тестовым пользователем и возвращает токены и id сессии.
+func (f *authFixture) login(t *testing.T) (*Tokens, string) {
+	t.Helper()
+	tokens, err := f.svc.Login(context.Background(), "student@example.com", testPassword)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	claims, err := f.jwt.Verify(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("verify access token: %v", err)
+	}
+	return tokens, claims.SessionID
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	f := newAuthFixture(t)
+	_, err := f.svc.Login(context.Background(), "student@example.com", "wrong password")
+	if !errors.Is(err, entities.ErrInvalidCredentials) {
+		t.Errorf("Login() = %v, want ErrInvalidCredentials", err)
+	}
+	if len(f.sessions.sessions) != 0 {
+		t.Errorf("failed login created %d sessions", len(f.sessions.sessions))
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// present выполняет сценарий и возвращает refresh-токен, который
+		// предъявляется последним
+		present    func(t *testing.T, f *authFixture, first *Tokens, sessionID string) string
+		wantErr    error
+		wantActive bool
+		wantReason string
+	}{
+		{
+			name: "fresh token rotates",
+			present: func(_ *testing.T, _ *authFixture, first *Tokens, _ string) string {
+				return first.RefreshToken
+			},
+			wantActive: true,
+		},
+		{
+			name: "successor of a rotated token rotates",
+			present: func(t *testing.T, f *authFixture, first *Tokens, _ string) string {
+				second, err := f.svc.Refresh(ctx, first.RefreshToken)
+				if err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				return second.RefreshToken
+			},
+			wantActive: true,
+		},
+		{
+			name: "reused token revokes the session",
+			present: func(t *testing.T, f *authFixture, first *Tokens, _ string) string {
+				if _, err := f.svc.Refresh(ctx, first.RefreshToken); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				return first.RefreshToken
+			},
+			wantErr:    entities.ErrRefreshTokenReused,
+			wantReason: entities.RevokeReasonTokenReuse,
+		},
+		{
+			name: "newest token dies with the reused family",
+			present: func(t *testing.T, f *authFixture, first *Tokens, _ string) string {
+				second, err := f.svc.Refresh(ctx, first.RefreshToken)
+				if err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				if _, err := f.svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, entities.ErrRefreshTokenReused) {
+					t.Fatalf("reuse = %v, want ErrRefreshTokenReused", err)
+				}
+				return second.RefreshToken
+			},
+			wantErr:    entities.ErrInvalidRefreshToken,
+			wantReason: entities.RevokeReasonTokenReuse,
+		},
+		{
+			name: "unknown token",
+			present: func(*testing.T, *authFixture, *Tokens, string) string {
+				return "not-a-token"
+			},
+			wantErr:    entities.ErrInvalidRefreshToken,
+			wantActive: true,
+		},
+		{
+			name: "token issued before sessions were in the token rotates",
+			present: func(t *testing.T, f *authFixture, _ *Tokens, sessionID string) string {
+				err := f.sessions.SaveRefreshToken(ctx, &entities.RefreshToken{
+					Hash:      hashToken("legacy-token"),
+					SessionID: sessionID,
+					CreatedAt: time.Now(),
+				})
+				if err != nil {
+					t.Fatalf("SaveRefreshToken: %v", err)
+				}
+				return "legacy-token"
+			},
+			wantActive: true,
+		},
+		{
+			name: "secret under another session is unknown",
+			present: func(_ *testing.T, f *authFixture, first *Tokens, _ string) string {
+				_, secret, _ := strings.Cut(first.RefreshToken, ".")
+				return "00000000-0000-0000-0000-000000000000." + secret
+			},
+			wantErr:    entities.ErrInvalidRefreshToken,
+			wantActive: true,
+		},
+		{
+			name: "logout",
+			present: func(t *testing.T, f *authFixture, first *Tokens, sessionID string) string {
+				if err := f.svc.Logout(ctx, sessionID); err != nil {
+					t.Fatalf("Logout: %v", err)
+				}
+				return first.RefreshToken
+			},
+			wantErr:    entities.ErrInvalidRefreshToken,
+			wantReason: entities.RevokeReasonLogout,
+		},
+		{
+			name: "expired session",
+			present: func(_ *testing.T, f *authFixture, first *Tokens, sessionID string) string {
+				f.sessions.sessions[sessionID].ExpiresAt = time.Now().Add(-time.Second)
+				return first.RefreshToken
+			},
+			wantErr: entities.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			first, sessionID := f.login(t)
+
+			tokens, err := f.svc.Refresh(ctx, tt.present(t, f, first, sessionID))
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Refresh() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if tokens.RefreshToken == first.RefreshToken {
+					t.Error("refresh returned the same refresh token")
+				}
+				if got := RefreshTokenSession(tokens.RefreshToken); got != sessionID {
+					t.Errorf("refresh token carries session %q, want %s", got, sessionID)
+				}
+				claims, err := f.jwt.Verify(tokens.AccessToken)
+				if err != nil || claims.SessionID != sessionID {
+					t.Errorf("new access token: claims %+v, err %v; want session %s", claims, err, sessionID)
+				}
+			}
+
+			active, err := f.svc.IsSessionActive(ctx, sessionID)
+			if err != nil {
+				t.Fatalf("IsSessionActive: %v", err)
+			}
+			if active != tt.wantActive {
+				t.Errorf("session active = %v, want %v", active, tt.wantActive)
+			}
+			if got := f.sessions.reason(sessionID); got != tt.wantReason {
+				t.Errorf("revoke reason = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestIsSessionActiveUnknownSession(t *testing.T) {
+	f := newAuthFixture(t)
+	active, err := f.svc.IsSessionActive(context.Background(), "missing")
+	if err != nil || active {
+		t.Errorf("IsSessionActive(missing) = %v, %v; want false, nil", active, err)
+	}
+}
+
+func TestLogoutWithoutSession(t *testing.T) {
+	f := newAuthFixture(t)
+	if err := f.svc.Logout(context.Background(), ""); err != nil {
+		t.Errorf("Logout(\"\") = %v, want nil for a token issued before sessions", err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantErr     error
+	}{
+		{"changes and signs out everywhere", testPassword, "new password 1", nil},
+		{"wrong old password", "nope", "new password 1", entities.ErrInvalidCredentials},
+		{"weak new password", testPassword, "short", entities.ErrWeakPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newAuthFixture(t)
+			_, phone := f.login(t)
+			_, laptop := f.login(t)
+
+			err := f.svc.ChangePassword(ctx, "u1", tt.oldPassword, tt.newPassword)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("ChangePassword() = %v, want %v", err, tt.wantErr)
+			}
+
+			wantPassword := testPassword
+			if tt.wantErr == nil {
+				wantPassword = tt.newPassword
+			}
+			if _, err := f.svc.Login(ctx, "student@example.com", wantPassword); err != nil {
+				t.Errorf("login with %q: %v", wantPassword, err)
+			}
+			for _, id := range []string{phone, laptop} {
+				active, _ := f.svc.IsSessionActive(ctx, id)
+				if active != (tt.wantErr != nil) {
+					t.Errorf("session %s active = %v after ChangePassword() = %v", id, active, err)
+				}
+			}
+		})
+	}
+}
+
+func TestChangePasswordKeepsOldPasswordWhenRevokeFails(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+	_, sessionID := f.login(t)
+	f.sessions.revokeErr = errors.New("db is down")
+
+	if err := f.svc.ChangePassword(ctx, "u1", testPassword, "new password 1"); err == nil {
+		t.Fatal("ChangePassword() = nil with sessions left active")
+	}
+	if _, err := f.svc.Login(ctx, "student@example.com", testPassword); err != nil {
+		t.Errorf("old password no longer works: %v", err)
+	}
+	if active, _ := f.svc.IsSessionActive(ctx, sessionID); !active {
+		t.Error("session revoked by a failed change")
+	}
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	resetCodeTTL         = 15 * time.Minute
+	resetCodeMaxAttempts = 5
+
+	// Лимиты считаются за resetWindow с одного IP: для одного адреса и всего.
+	resetWindow             = time.Hour
+	forgotLimitPerIPEmail   = 3
+	forgotLimitPerIP        = 10
+	resetAttemptsPerIPEmail = 10
+	resetAttemptsPerIP      = 30
+
+	// Лимиты на адрес с любых IP. Считаются только выданные коды и неверные
+	// попытки, а не запросы: чужие запросы с одного IP их не исчерпают.
+	// Сверх forgotCodesPerEmail новый код не выдаётся, но последний
+	// отправленный владельцу остаётся в силе. resetFailuresPerEmail
+	// ограничивает перебор поверх resetCodeMaxAttempts на один код и
+	// закрывает только сброс по коду: вход по паролю он не трогает.
+	forgotCodesPerEmail   = 5
+	resetFailuresPerEmail = 15
+
+	resetEventForgot = "forgot"
+	resetEventReset  = "reset"
+	resetEventIssued = "issued"
+	resetEventFailed = "failed"
+)
+
+// dummyResetHash сравнивается с кодом, когда для email нет активного кода:
+// иначе ответ для неизвестного адреса приходил бы заметно быстрее.
+var dummyResetHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("000000"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// ForgotPassword отправляет код сброса, если аккаунт существует. Для
+// несуществующего email результат тот же, чтобы по ответу нельзя было
+// проверить, зарегистрирован ли адрес. Код хэшируется до поиска аккаунта:
+// bcrypt — самая долгая часть запроса, и по времени ответа неизвестный
+// адрес не отличить от известного.
+//
+// Повторные запросы для одного адреса с одного IP и запросы сверх
+// forgotCodesPerEmail выданных кодов молча ничего не отправляют: ответ
+// тот же, а почтовый ящик не заваливается.
+func (s *AuthService) ForgotPassword(ctx context.Context, email, ip string) error {
+	email = strings.TrimSpace(email)
+
+	byIPEmail, byIP, err := s.countResetEvent(ctx, resetEventForgot, email, ip)
+	if err != nil {
+		return err
+	}
+	if byIP > forgotLimitPerIP {
+		return resetLimitExceeded(resetEventForgot, email, ip)
+	}
+	if byIPEmail > forgotLimitPerIPEmail {
+		log.Warn().Str("email", email).Str("ip", ip).Msg("repeated password reset request ignored")
+		return nil
+	}
+
+	code, err := newResetCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset code: %w", err)
+	}
+
+	// Кодов всего миллион, поэтому храним bcrypt, а не быстрый хэш
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash reset code: %w", err)
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Проверяется после bcrypt, чтобы по времени ответа нельзя было узнать,
+	// что адрес зарегистрирован и упёрся в лимит
+	issued, err := s.countEmailResetEvents(ctx, resetEventIssued, email)
+	if err != nil {
+		return err
+	}
+	if issued >= forgotCodesPerEmail {
+		log.Warn().Str("email", email).Str("ip", ip).Msg("password reset code limit reached for email")
+		return nil
+	}
+	if err := s.addResetEvent(ctx, resetEventIssued, email, ip); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SaveResetToken(ctx, email, string(codeHash), time.Now().UTC().Add(resetCodeTTL)); err != nil {
+		return err
+	}
+
+	return s.emailService.SendResetCode(ctx, user.Email, user.Locale, code, resetCodeTTL)
+}
+
+// ResetPassword меняет пароль по коду. Каждая проверка тратит попытку;
+// после resetCodeMaxAttempts неудач код перестаёт приниматься и нужно
+// запросить новый. Неверный код, истёкший код и неизвестный email дают
+// одну и ту же ошибку entities.ErrInvalidResetCode и тратят одинаковое
+// время на проверку кода. После resetFailuresPerEmail неверных попыток
+// для адреса с любых IP сброс для него закрыт до конца resetWindow.
+func (s *AuthService) ResetPassword(ctx context.Context, email, code, newPassword, ip string) error {
+	email = strings.TrimSpace(email)
+
+	if len(newPassword) < 8 {
+		return entities.ErrWeakPassword
+	}
+
+	byIPEmail, byIP, err := s.countResetEvent(ctx, resetEventReset, email, ip)
+	if err != nil {
+		return err
+	}
+	if byIPEmail > resetAttemptsPerIPEmail || byIP > resetAttemptsPerIP {
+		return resetLimitExceeded(resetEventReset, email, ip)
+	}
+
+	failures, err := s.countEmailResetEvents(ctx, resetEventFailed, email)
+	if err != nil {
+		return err
+	}
+	if failures >= resetFailuresPerEmail {
+		return resetLimitExceeded(resetEventFailed, email, ip)
+	}
+
+	user, err := s.checkResetCode(ctx, email, code)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidResetCode) {
+			if err := s.addResetEvent(ctx, resetEventFailed, email, ip); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	newHash, err := s.hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = newHash
+	user.UpdatedAt = time.Now().UTC()
+
+	return s.sessionRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.sessionRepo.RevokeUserSessions(ctx, user.ID, entities.RevokeReasonPasswordReset, user.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return s.userRepo.DeleteResetToken(ctx, email)
+	})
+}
+
+// checkResetCode тратит попытку кода и возвращает владельца адреса, если
+// код верный, или entities.ErrInvalidResetCode.
+func (s *AuthService) checkResetCode(ctx context.Context, email, code string) (*entities.User, error) {
+	codeHash, err := s.userRepo.ClaimResetAttempt(ctx, email, resetCodeMaxAttempts)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyResetHash(), []byte(code))
+			return nil, entities.ErrInvalidResetCode
+		}
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(codeHash), []byte(code)) != nil {
+		return nil, entities.ErrInvalidResetCode
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, entities.ErrInvalidResetCode
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// countResetEvent записывает событие и возвращает, сколько таких событий
+// было за последний resetWindow с этого IP для этого email и с этого IP
+// всего. Считаются и отклонённые запросы, чтобы перебор не мог
+// продолжаться, упираясь в лимит.
+func (s *AuthService) countResetEvent(ctx context.Context, kind, email, ip string) (int, int, error) {
+	if err := s.addResetEvent(ctx, kind, email, ip); err != nil {
+		return 0, 0, err
+	}
+
+	return s.userRepo.CountResetEvents(ctx, kind, strings.ToLower(email), ip, time.Now().UTC().Add(-resetWindow))
+}
+
+// countEmailResetEvents возвращает, сколько событий kind было для email
+// с любых IP за последний resetWindow.
+func (s *AuthService) countEmailResetEvents(ctx context.Context, kind, email string) (int, error) {
+	n, err := s.userRepo.CountEmailResetEvents(ctx, kind, strings.ToLower(email), time.Now().UTC().Add(-resetWindow))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reset events: %w", err)
+	}
+	return n, nil
+}
+
+func (s *AuthService) addResetEvent(ctx context.Context, kind, email, ip string) error {
+	// Регистр не должен давать обойти лимит на email
+	if err := s.userRepo.AddResetEvent(ctx, kind, strings.ToLower(email), ip); err != nil {
+		return fmt.Errorf("failed to record reset event: %w", err)
+	}
+	return nil
+}
+
+func resetLimitExceeded(kind, email, ip string) error {
+	log.Warn().Str("kind", kind).Str("email", email).Str("ip", ip).Msg("password reset rate limit exceeded")
+	return &entities.RateLimitError{RetryAfter: resetWindow}
+}
+
+// CleanupExpiredTokens удаляет истёкшие коды сброса и ссылки подтверждения,
+// а также события сброса, которые уже не участвуют в лимитах.
+func (s *AuthService) CleanupExpiredTokens(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	tokens, events, err := s.userRepo.DeleteExpiredTokens(ctx, now, now.Add(-resetWindow))
+	if err != nil {
+		return err
+	}
+
+	if tokens > 0 || events > 0 {
+		log.Info().Int64("tokens", tokens).Int64("reset_events", events).Msg("expired auth tokens removed")
+	}
+	return nil
+}
+
+func newResetCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
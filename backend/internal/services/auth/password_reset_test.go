@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+func TestForgotPassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("known email gets a code", func(t *testing.T) {
+		f := newAuthFixture(t)
+		if err := f.svc.ForgotPassword(ctx, " student@example.com ", "10.0.0.1"); err != nil {
+			t.Fatalf("ForgotPassword: %v", err)
+		}
+		code, ok := f.emails.code("student@example.com")
+		if !ok || len(code) != 6 {
+			t.Fatalf("sent code = %q, %v", code, ok)
+		}
+		if stored := f.users.resets["student@example.com"]; stored == nil || stored.hash == code {
+			t.Errorf("stored reset token = %+v, want a hash of the code", stored)
+		}
+	})
+
+	t.Run("unknown email looks the same", func(t *testing.T) {
+		f := newAuthFixture(t)
+		if err := f.svc.ForgotPassword(ctx, "nobody@example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("ForgotPassword: %v", err)
+		}
+		if len(f.emails.codes) != 0 || len(f.users.resets) != 0 {
+			t.Errorf("unknown email: sent %v, stored %v", f.emails.codes, f.users.resets)
+		}
+	})
+
+	t.Run("repeats from one IP are silently ignored regardless of case", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i, email := range []string{"student@example.com", "Student@example.com", "student@EXAMPLE.com", "STUDENT@example.com"} {
+			if err := f.svc.ForgotPassword(ctx, email, "10.0.0.1"); err != nil {
+				t.Fatalf("request %d: %v", i+1, err)
+			}
+		}
+		if f.emails.sent != forgotLimitPerIPEmail {
+			t.Errorf("sent %d emails, want %d", f.emails.sent, forgotLimitPerIPEmail)
+		}
+	})
+
+	t.Run("other IPs do not block the owner", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i := 0; i < forgotLimitPerIP; i++ {
+			if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.66"); err != nil {
+				t.Fatalf("attacker request %d: %v", i+1, err)
+			}
+		}
+		sent := f.emails.sent
+
+		if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("owner request: %v", err)
+		}
+		if f.emails.sent != sent+1 {
+			t.Errorf("owner did not get a code: sent %d -> %d", sent, f.emails.sent)
+		}
+	})
+
+	t.Run("codes per email are limited across IPs", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i := 0; i < forgotCodesPerEmail+2; i++ {
+			ip := fmt.Sprintf("10.0.1.%d", i)
+			if err := f.svc.ForgotPassword(ctx, "student@example.com", ip); err != nil {
+				t.Fatalf("request %d: %v", i+1, err)
+			}
+		}
+		if f.emails.sent != forgotCodesPerEmail {
+			t.Errorf("sent %d emails, want %d", f.emails.sent, forgotCodesPerEmail)
+		}
+
+		// Последний отправленный код остаётся в силе
+		code, _ := f.emails.code("student@example.com")
+		if err := f.svc.ResetPassword(ctx, "student@example.com", code, "brand new password", "10.0.0.1"); err != nil {
+			t.Errorf("reset with the last sent code = %v", err)
+		}
+	})
+
+	t.Run("limited per IP", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i := 0; i < forgotLimitPerIP; i++ {
+			if err := f.svc.ForgotPassword(ctx, "nobody@example.com", "10.0.0.66"); err != nil {
+				t.Fatalf("request %d: %v", i+1, err)
+			}
+		}
+		err := f.svc.ForgotPassword(ctx, "other@example.com", "10.0.0.66")
+		var limited *entities.RateLimitError
+		if !errors.As(err, &limited) || limited.RetryAfter != resetWindow {
+			t.Errorf("request over the limit = %v, want RateLimitError", err)
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	const newPassword = "brand new password"
+
+	tests := []struct {
+		name string
+		// prepare получает отправленный код и возвращает email и код,
+		// которые предъявляются
+		prepare func(t *testing.T, f *authFixture, code string) (email, presented string)
+		wantErr error
+	}{
+		{
+			name: "correct code",
+			prepare: func(_ *testing.T, _ *authFixture, code string) (string, string) {
+				return "student@example.com", code
+			},
+		},
+		{
+			name: "wrong code",
+			prepare: func(_ *testing.T, _ *authFixture, code string) (string, string) {
+				return "student@example.com", wrongCode(code)
+			},
+			wantErr: entities.ErrInvalidResetCode,
+		},
+		{
+			name: "unknown email",
+			prepare: func(_ *testing.T, _ *authFixture, code string) (string, string) {
+				return "nobody@example.com", code
+			},
+			wantErr: entities.ErrInvalidResetCode,
+		},
+		{
+			name: "expired code",
+			prepare: func(_ *testing.T, f *authFixture, code string) (string, string) {
+				f.users.resets["student@example.com"].expiresAt = time.Now().Add(-time.Second)
+				return "student@example.com", code
+			},
+			wantErr: entities.ErrInvalidResetCode,
+		},
+		{
+			name: "attempts used up by wrong codes",
+			prepare: func(t *testing.T, f *authFixture, code string) (string, string) {
+				for i := 0; i < resetCodeMaxAttempts; i++ {
+					err := f.svc.ResetPassword(ctx, "student@example.com", wrongCode(code), newPassword, "10.0.0.1")
+					if !errors.Is(err, entities.ErrInvalidResetCode) {
+						t.Fatalf("wrong attempt %d = %v", i+1, err)
+					}
+				}
+				return "student@example.com", code
+			},
+			wantErr: entities.ErrInvalidResetCode,
+		},
+		{
+			name: "code is single use",
+			prepare: func(t *testing.T, f *authFixture, code string) (string, string) {
+				if err := f.svc.ResetPassword(ctx, "student@example.com", code, "first new password", "10.0.0.1"); err != nil {
+					t.Fatalf("first reset: %v", err)
+				}
+				return "student@example.com", code
+			},
+			wantErr: entities.ErrInvalidResetCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			_, sessionID := f.login(t)
+			if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+				t.Fatalf("ForgotPassword: %v", err)
+			}
+			code, _ := f.emails.code("student@example.com")
+
+			email, presented := tt.prepare(t, f, code)
+			err := f.svc.ResetPassword(ctx, email, presented, newPassword, "10.0.0.1")
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("ResetPassword() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, err := f.svc.Login(ctx, "student@example.com", newPassword); !errors.Is(err, entities.ErrInvalidCredentials) {
+					t.Errorf("password changed by a rejected reset: login = %v", err)
+				}
+				return
+			}
+
+			if _, err := f.svc.Login(ctx, "student@example.com", newPassword); err != nil {
+				t.Errorf("login with the new password: %v", err)
+			}
+			if active, _ := f.svc.IsSessionActive(ctx, sessionID); active {
+				t.Error("old session still active after reset")
+			}
+			if f.sessions.reason(sessionID) != entities.RevokeReasonPasswordReset {
+				t.Errorf("revoke reason = %q", f.sessions.reason(sessionID))
+			}
+			if _, ok := f.users.resets["student@example.com"]; ok {
+				t.Error("reset token not deleted")
+			}
+		})
+	}
+}
+
+func TestResetPasswordWeakPasswordKeepsAttempts(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	code, _ := f.emails.code("student@example.com")
+
+	if err := f.svc.ResetPassword(ctx, "student@example.com", code, "short", "10.0.0.1"); !errors.Is(err, entities.ErrWeakPassword) {
+		t.Fatalf("ResetPassword(weak) = %v, want ErrWeakPassword", err)
+	}
+	if attempts := f.users.resets["student@example.com"].attempts; attempts != 0 {
+		t.Errorf("weak password spent %d attempts", attempts)
+	}
+}
+
+func TestResetPasswordKeepsOldPasswordWhenRevokeFails(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	code, _ := f.emails.code("student@example.com")
+	f.sessions.revokeErr = errors.New("db is down")
+
+	if err := f.svc.ResetPassword(ctx, "student@example.com", code, "brand new password", "10.0.0.1"); err == nil {
+		t.Fatal("ResetPassword() = nil with sessions left active")
+	}
+	if _, err := f.svc.Login(ctx, "student@example.com", testPassword); err != nil {
+		t.Errorf("old password no longer works: %v", err)
+	}
+	if _, ok := f.users.resets["student@example.com"]; !ok {
+		t.Error("reset code deleted by a failed reset")
+	}
+}
+
+func TestResetPasswordLimitedPerIPAndEmail(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	for i := 0; i < resetAttemptsPerIPEmail; i++ {
+		err := f.svc.ResetPassword(ctx, "student@example.com", "000000", "brand new password", "10.0.0.66")
+		if !errors.Is(err, entities.ErrInvalidResetCode) {
+			t.Fatalf("attempt %d = %v, want ErrInvalidResetCode", i+1, err)
+		}
+	}
+
+	err := f.svc.ResetPassword(ctx, "student@example.com", "000000", "brand new password", "10.0.0.66")
+	var limited *entities.RateLimitError
+	if !errors.As(err, &limited) {
+		t.Fatalf("attempt over the limit = %v, want RateLimitError", err)
+	}
+
+	// С другого IP владелец по-прежнему может сбросить пароль
+	if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	code, _ := f.emails.code("student@example.com")
+	if err := f.svc.ResetPassword(ctx, "student@example.com", code, "brand new password", "10.0.0.1"); err != nil {
+		t.Errorf("owner reset from another IP = %v", err)
+	}
+}
+
+func TestResetPasswordFailuresLimitedPerEmail(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	if err := f.svc.ForgotPassword(ctx, "student@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	code, _ := f.emails.code("student@example.com")
+
+	// Перебор с разных IP: каждый IP далеко от своего лимита
+	for i := 0; i < resetFailuresPerEmail; i++ {
+		ip := fmt.Sprintf("10.0.1.%d", i)
+		err := f.svc.ResetPassword(ctx, "student@example.com", wrongCode(code), "brand new password", ip)
+		if !errors.Is(err, entities.ErrInvalidResetCode) {
+			t.Fatalf("attempt %d = %v, want ErrInvalidResetCode", i+1, err)
+		}
+	}
+
+	err := f.svc.ResetPassword(ctx, "student@example.com", wrongCode(code), "brand new password", "10.0.2.1")
+	var limited *entities.RateLimitError
+	if !errors.As(err, &limited) {
+		t.Fatalf("attempt over the limit = %v, want RateLimitError", err)
+	}
+
+	// Закрыт только сброс по коду: войти по старому паролю можно
+	if _, err := f.svc.Login(ctx, "student@example.com", testPassword); err != nil {
+		t.Errorf("login after reset lockout = %v", err)
+	}
+
+	// Другие адреса лимит не трогает
+	err = f.svc.ResetPassword(ctx, "other@example.com", "000000", "brand new password", "10.0.2.1")
+	if !errors.Is(err, entities.ErrInvalidResetCode) {
+		t.Errorf("reset for another email = %v, want ErrInvalidResetCode", err)
+	}
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// Tokens — пара, которую получает клиент при входе и при каждом обновлении.
+type Tokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Refresh обменивает refresh-токен на новую пару. Старый токен после этого
+// считается использованным; если его предъявят ещё раз, значит, он утёк,
+// и вся сессия отзывается — выйдет и злоумышленник, и настоящий владелец.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	now := time.Now().UTC()
+	hash := hashToken(refreshToken)
+
+	var (
+		tokens *Tokens
+		reused bool
+	)
+	err := s.sessionRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		stored, err := s.sessionRepo.GetRefreshTokenForUpdate(ctx, hash)
+		if err != nil {
+			if errors.Is(err, entities.ErrNotFound) {
+				return entities.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		session, err := s.sessionRepo.GetSession(ctx, stored.SessionID)
+		if err != nil {
+			return err
+		}
+		if !session.IsActive(now) {
+			return entities.ErrInvalidRefreshToken
+		}
+
+		if stored.RotatedAt != nil {
+			// Отзыв должен закоммититься, поэтому ошибку возвращаем после транзакции
+			reused = true
+			return s.sessionRepo.RevokeSession(ctx, session.ID, entities.RevokeReasonTokenReuse, now)
+		}
+
+		user, err := s.userRepo.GetByID(ctx, session.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := s.sessionRepo.MarkRefreshTokenRotated(ctx, hash, now); err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, user, session, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, entities.ErrRefreshTokenReused
+	}
+
+	return tokens, nil
+}
+
+// Logout отзывает текущую сессию: её refresh-токены и access-токены
+// перестают приниматься сразу. У токенов, выпущенных до появления сессий,
+// sessionID пустой — отзывать нечего.
+func (s *AuthService) Logout(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return s.sessionRepo.RevokeSession(ctx, sessionID, entities.RevokeReasonLogout, time.Now().UTC())
+}
+
+// IsSessionActive вызывается AuthMiddleware на каждый запрос, чтобы
+// отозванная сессия не жила до истечения access-токена.
+func (s *AuthService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.IsActive(time.Now().UTC()), nil
+}
+
+func (s *AuthService) startSession(ctx context.Context, user *entities.User) (*Tokens, error) {
+	now := time.Now().UTC()
+	session := entities.NewAuthSession(user.ID, s.sessionTTL, now)
+
+	var tokens *Tokens
+	err := s.sessionRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = s.issueTokens(ctx, user, session, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *AuthService) issueTokens(
+	ctx context.Context,
+	user *entities.User,
+	session *entities.AuthSession,
+	now time.Time,
+) (*Tokens, error) {
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	// Сессия в открытом виде нужна лимитеру /auth/refresh: сам токен
+	// меняется при каждом обновлении, а сессия остаётся той же
+	refresh := session.ID + "." + secret
+
+	err = s.sessionRepo.SaveRefreshToken(ctx, &entities.RefreshToken{
+		Hash:      hashToken(refresh),
+		SessionID: session.ID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	access, accessExpiresAt, err := s.jwtManager.Generate(
+		user.ID,
+		user.Email,
+		string(user.Role),
+		session.ID,
+		user.IsEmailVerified(),
+		string(user.Locale),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &Tokens{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// RefreshTokenSession возвращает ID сессии из refresh-токена, не проверяя
+// сам токен. У токенов, выпущенных до того, как в них появилась сессия,
+// возвращает пустую строку.
+func RefreshTokenSession(refreshToken string) string {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return ""
+	}
+	return sessionID
+}
+
+// newOpaqueToken — случайный токен для refresh-токенов и ссылок из писем.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
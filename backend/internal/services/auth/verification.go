@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+)
+
+// VerificationMode решает, что может пользователь с неподтверждённым email.
+type VerificationMode string
+
+const (
+	// VerificationAllow — подтверждение ни на что не влияет
+	VerificationAllow VerificationMode = "allow"
+	// VerificationReadOnly — вход разрешён, но изменяющие запросы отклоняются
+	VerificationReadOnly VerificationMode = "read_only"
+	// VerificationBlock — вход запрещён до подтверждения
+	VerificationBlock VerificationMode = "block"
+)
+
+func ParseVerificationMode(s string) (VerificationMode, error) {
+	switch mode := VerificationMode(s); mode {
+	case VerificationAllow, VerificationReadOnly, VerificationBlock:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown email verification mode %q", s)
+	}
+}
+
+type VerificationConfig struct {
+	Mode VerificationMode
+	// LinkBaseURL — адрес фронтенда; ссылка в письме ведёт на {LinkBaseURL}/verify-email?token=...
+	LinkBaseURL string
+}
+
+const (
+	verificationTokenTTL = 24 * time.Hour
+	// не чаще одного письма в минуту на пользователя
+	verificationResendCooldown = time.Minute
+)
+
+func (s *AuthService) VerificationMode() VerificationMode {
+	return s.verification.Mode
+}
+
+// VerifyEmail подтверждает email по токену из письма. Токен одноразовый.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	if _, err := s.userRepo.VerifyEmail(ctx, hashToken(token)); err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return nil
+}
+
+// IsEmailVerified читает флаг из базы, а не из access-токена: токен,
+// выпущенный до перехода по ссылке, ещё несёт email_verified=false.
+func (s *AuthService) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.IsEmailVerified(), nil
+}
+
+// ResendVerification отправляет новую ссылку. Для неизвестного или уже
+// подтверждённого email и при слишком частых запросах ответ тот же,
+// чтобы по нему нельзя было узнать состояние аккаунта.
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	err = s.sendVerification(ctx, user)
+	if errors.Is(err, entities.ErrTooManyRequests) {
+		log.Info().Str("user_id", user.ID).Msg("verification resend skipped, cooldown is active")
+		return nil
+	}
+	return err
+}
+
+func (s *AuthService) sendVerification(ctx context.Context, user *entities.User) error {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	saved, err := s.userRepo.SaveVerificationToken(
+		ctx,
+		user.ID,
+		hashToken(token),
+		now.Add(verificationTokenTTL),
+		now.Add(-verificationResendCooldown),
+	)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return &entities.RateLimitError{RetryAfter: verificationResendCooldown}
+	}
+
+	link := strings.TrimRight(s.verification.LinkBaseURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+
+	return s.emailService.SendVerificationLink(ctx, user.Email, user.Locale, link, verificationTokenTTL)
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+const testLinkBase = "https://app.example.com/"
+
+// withVerification пересоздаёт сервис фикстуры с заданным режимом подтверждения.
+func (f *authFixture) withVerification(mode VerificationMode) *authFixture {
+	cfg := VerificationConfig{Mode: mode, LinkBaseURL: testLinkBase}
+	f.svc = NewAuthService(f.users, f.sessions, f.jwt, nil, f.emails, nil, time.Hour, cfg)
+	return f
+}
+
+// lastToken достаёт токен из последней отправленной ссылки.
+func (e *sentCodes) lastToken(t *testing.T) string {
+	t.Helper()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.links) == 0 {
+		t.Fatal("no verification link sent")
+	}
+	link := e.links[len(e.links)-1]
+	if !strings.HasPrefix(link, "https://app.example.com/verify-email?token=") {
+		t.Fatalf("link = %q", link)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("token")
+}
+
+func (e *sentCodes) linkCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.links)
+}
+
+func TestParseVerificationMode(t *testing.T) {
+	for _, s := range []string{"allow", "read_only", "block"} {
+		if mode, err := ParseVerificationMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseVerificationMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	for _, s := range []string{"", "readonly", "BLOCK"} {
+		if _, err := ParseVerificationMode(s); err == nil {
+			t.Errorf("ParseVerificationMode(%q) accepted", s)
+		}
+	}
+}
+
+func TestLoginVerificationModes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		mode     VerificationMode
+		verified bool
+		wantErr  error
+	}{
+		{VerificationAllow, false, nil},
+		{VerificationReadOnly, false, nil},
+		{VerificationBlock, false, entities.ErrEmailNotVerified},
+		{VerificationBlock, true, nil},
+	}
+
+	for _, tt := range tests {
+		name := string(tt.mode)
+		if tt.verified {
+			name += " verified"
+		}
+		t.Run(name, func(t *testing.T) {
+			f := newAuthFixture(t).withVerification(tt.mode)
+			if tt.verified {
+				now := time.Now().UTC()
+				f.users.users["u1"].EmailVerifiedAt = &now
+			}
+
+			tokens, err := f.svc.Login(ctx, "student@example.com", testPassword)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Login() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(f.sessions.sessions) != 0 {
+					t.Error("rejected login created a session")
+				}
+				return
+			}
+			claims, err := f.jwt.Verify(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("verify access token: %v", err)
+			}
+			if claims.EmailVerified != tt.verified {
+				t.Errorf("email_verified claim = %v, want %v", claims.EmailVerified, tt.verified)
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// present получает токен из письма и возвращает тот, что предъявляется
+		present      func(t *testing.T, f *authFixture, token string) string
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name:         "token from the link",
+			present:      func(_ *testing.T, _ *authFixture, token string) string { return token },
+			wantVerified: true,
+		},
+		{
+			name:    "unknown token",
+			present: func(*testing.T, *authFixture, string) string { return "not-a-token" },
+			wantErr: entities.ErrInvalidVerificationToken,
+		},
+		{
+			name: "expired token",
+			present: func(_ *testing.T, f *authFixture, token string) string {
+				f.users.verifications["u1"].expiresAt = time.Now().Add(-time.Second)
+				return token
+			},
+			wantErr: entities.ErrInvalidVerificationToken,
+		},
+		{
+			name: "token is single use",
+			present: func(t *testing.T, f *authFixture, token string) string {
+				if err := f.svc.VerifyEmail(ctx, token); err != nil {
+					t.Fatalf("first VerifyEmail: %v", err)
+				}
+				return token
+			},
+			wantErr:      entities.ErrInvalidVerificationToken,
+			wantVerified: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t).withVerification(VerificationReadOnly)
+			if err := f.svc.ResendVerification(ctx, "student@example.com"); err != nil {
+				t.Fatalf("ResendVerification: %v", err)
+			}
+
+			err := f.svc.VerifyEmail(ctx, tt.present(t, f, f.emails.lastToken(t)))
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("VerifyEmail() = %v, want %v", err, tt.wantErr)
+			}
+			if verified, err := f.svc.IsEmailVerified(ctx, "u1"); err != nil || verified != tt.wantVerified {
+				t.Errorf("IsEmailVerified() = %v, %v; want %v", verified, err, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unverified user gets a link", func(t *testing.T) {
+		f := newAuthFixture(t).withVerification(VerificationReadOnly)
+		if err := f.svc.ResendVerification(ctx, " Student@example.com "); err != nil {
+			t.Fatalf("ResendVerification: %v", err)
+		}
+		token := f.emails.lastToken(t)
+		if stored := f.users.verifications["u1"]; stored == nil || stored.hash != hashToken(token) {
+			t.Errorf("stored token = %+v, want a hash of the sent one", stored)
+		}
+	})
+
+	t.Run("repeat within the cooldown is silent", func(t *testing.T) {
+		f := newAuthFixture(t).withVerification(VerificationReadOnly)
+		for i := 0; i < 2; i++ {
+			if err := f.svc.ResendVerification(ctx, "student@example.com"); err != nil {
+				t.Fatalf("request %d: %v", i+1, err)
+			}
+		}
+		if n := f.emails.linkCount(); n != 1 {
+			t.Errorf("sent %d links, want 1", n)
+		}
+	})
+
+	t.Run("new link after the cooldown replaces the old one", func(t *testing.T) {
+		f := newAuthFixture(t).withVerification(VerificationReadOnly)
+		if err := f.svc.ResendVerification(ctx, "student@example.com"); err != nil {
+			t.Fatalf("first request: %v", err)
+		}
+		first := f.emails.lastToken(t)
+		f.users.verifications["u1"].createdAt = time.Now().Add(-2 * verificationResendCooldown)
+
+		if err := f.svc.ResendVerification(ctx, "student@example.com"); err != nil {
+			t.Fatalf("second request: %v", err)
+		}
+		if err := f.svc.VerifyEmail(ctx, first); !errors.Is(err, entities.ErrInvalidVerificationToken) {
+			t.Errorf("old link = %v, want ErrInvalidVerificationToken", err)
+		}
+		if err := f.svc.VerifyEmail(ctx, f.emails.lastToken(t)); err != nil {
+			t.Errorf("new link: %v", err)
+		}
+	})
+
+	t.Run("unknown and verified emails look the same", func(t *testing.T) {
+		f := newAuthFixture(t).withVerification(VerificationReadOnly)
+		now := time.Now().UTC()
+		f.users.users["u1"].EmailVerifiedAt = &now
+
+		for _, email := range []string{"nobody@example.com", "student@example.com"} {
+			if err := f.svc.ResendVerification(ctx, email); err != nil {
+				t.Errorf("ResendVerification(%q) = %v, want nil", email, err)
+			}
+		}
+		if n := f.emails.linkCount(); n != 0 {
+			t.Errorf("sent %d links, want none", n)
+		}
+	})
+}
+
+func TestIsEmailVerifiedUnknownUser(t *testing.T) {
+	f := newAuthFixture(t)
+	if _, err := f.svc.IsEmailVerified(context.Background(), "missing"); !errors.Is(err, entities.ErrNotFound) {
+		t.Errorf("IsEmailVerified(missing) = %v, want ErrNotFound", err)
+	}
+}
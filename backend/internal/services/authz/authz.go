@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"context"
+
+	"backend/internal/entities"
+)
+
+// Actor — кто выполняет действие; берётся из JWT.
+type Actor struct {
+	UserID string
+	Role   entities.UserRole
+}
+
+func NewActor(userID, role string) Actor {
+	return Actor{UserID: userID, Role: entities.UserRole(role)}
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == entities.RoleAdmin
+}
+
+// Repository находит автора курса, к которому относится объект.
+// Если объекта нет, возвращает entities.ErrNotFound.
+type Repository interface {
+	GetCourseAuthorID(ctx context.Context, courseID string) (string, error)
+	GetModuleAuthorID(ctx context.Context, moduleID string) (string, error)
+	GetLessonAuthorID(ctx context.Context, lessonID string) (string, error)
+	GetTestAuthorID(ctx context.Context, testID string) (string, error)
+}
+
+// Policy — единая проверка прав на контент: менять курс и всё, что в нём
+// лежит (модули, уроки, тесты, банк вопросов), и видеть правильные ответы
+// может только автор курса. Администратор проходит всегда.
+// Методы возвращают nil, entities.ErrForbidden или entities.ErrNotFound.
+type Policy struct {
+	repo Repository
+}
+
+func NewPolicy(repo Repository) *Policy {
+	return &Policy{repo: repo}
+}
+
+func (p *Policy) CanManageCourse(ctx context.Context, actor Actor, courseID string) error {
+	return p.check(ctx, actor, courseID, p.repo.GetCourseAuthorID)
+}
+
+func (p *Policy) CanManageModule(ctx context.Context, actor Actor, moduleID string) error {
+	return p.check(ctx, actor, moduleID, p.repo.GetModuleAuthorID)
+}
+
+func (p *Policy) CanManageLesson(ctx context.Context, actor Actor, lessonID string) error {
+	return p.check(ctx, actor, lessonID, p.repo.GetLessonAuthorID)
+}
+
+func (p *Policy) CanManageTest(ctx context.Context, actor Actor, testID string) error {
+	return p.check(ctx, actor, testID, p.repo.GetTestAuthorID)
+}
+
+func (p *Policy) check(
+	ctx context.Context,
+	actor Actor,
+	id string,
+	authorOf func(ctx context.Context, id string) (string, error),
+) error {
+	authorID, err := authorOf(ctx, id)
+	if err != nil {
+		return err
+	}
+	return Allow(actor, authorID)
+}
+
+// Allow решает, может ли actor управлять контентом автора authorID.
+func Allow(actor Actor, authorID string) error {
+	switch {
+	case actor.UserID == "":
+		return entities.ErrForbidden
+	case actor.IsAdmin():
+		return nil
+	case actor.Role == entities.RoleTeacher && actor.UserID == authorID:
+		return nil
+	default:
+		return entities.ErrForbidden
+	}
+}
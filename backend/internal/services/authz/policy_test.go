@@ -0,0 +1,110 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    Actor
+		authorID string
+		want     error
+	}{
+		{"author teacher", NewActor("t1", "teacher"), "t1", nil},
+		{"other teacher", NewActor("t2", "teacher"), "t1", entities.ErrForbidden},
+		{"admin", NewActor("adm", "admin"), "t1", nil},
+		{"admin of authorless content", NewActor("adm", "admin"), "", nil},
+		{"student as author", NewActor("s1", "student"), "s1", entities.ErrForbidden},
+		{"student", NewActor("s1", "student"), "t1", entities.ErrForbidden},
+		{"anonymous", NewActor("", "admin"), "t1", entities.ErrForbidden},
+		{"anonymous vs empty author", NewActor("", "teacher"), "", entities.ErrForbidden},
+		{"unknown role", NewActor("t1", "owner"), "t1", entities.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allow(tt.actor, tt.authorID); !errors.Is(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRepo отдаёт авторов по ID объекта; неизвестный ID — ErrNotFound.
+type fakeRepo struct {
+	authors map[string]string
+	calls   []string
+}
+
+func (r *fakeRepo) lookup(kind, id string) (string, error) {
+	r.calls = append(r.calls, kind)
+	author, ok := r.authors[id]
+	if !ok {
+		return "", entities.ErrNotFound
+	}
+	return author, nil
+}
+
+func (r *fakeRepo) GetCourseAuthorID(_ context.Context, id string) (string, error) {
+	return r.lookup("course", id)
+}
+
+func (r *fakeRepo) GetModuleAuthorID(_ context.Context, id string) (string, error) {
+	return r.lookup("module", id)
+}
+
+func (r *fakeRepo) GetLessonAuthorID(_ context.Context, id string) (string, error) {
+	return r.lookup("lesson", id)
+}
+
+func (r *fakeRepo) GetTestAuthorID(_ context.Context, id string) (string, error) {
+	return r.lookup("test", id)
+}
+
+func TestPolicy(t *testing.T) {
+	type check func(p *Policy, ctx context.Context, actor Actor, id string) error
+	methods := []struct {
+		kind string
+		call check
+	}{
+		{"course", (*Policy).CanManageCourse},
+		{"module", (*Policy).CanManageModule},
+		{"lesson", (*Policy).CanManageLesson},
+		{"test", (*Policy).CanManageTest},
+	}
+	cases := []struct {
+		name  string
+		actor Actor
+		id    string
+		want  error
+	}{
+		{"author", NewActor("t1", "teacher"), "obj", nil},
+		{"other teacher", NewActor("t2", "teacher"), "obj", entities.ErrForbidden},
+		{"student", NewActor("s1", "student"), "obj", entities.ErrForbidden},
+		{"admin", NewActor("adm", "admin"), "obj", nil},
+		{"missing object", NewActor("t1", "teacher"), "missing", entities.ErrNotFound},
+		{"missing object for admin", NewActor("adm", "admin"), "missing", entities.ErrNotFound},
+	}
+
+	for _, m := range methods {
+		for _, tt := range cases {
+			t.Run(m.kind+"/"+tt.name, func(t *testing.T) {
+				repo := &fakeRepo{authors: map[string]string{"obj": "t1"}}
+				p := NewPolicy(repo)
+
+				got := m.call(p, context.Background(), tt.actor, tt.id)
+				if !errors.Is(got, tt.want) || (got == nil) != (tt.want == nil) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+				if len(repo.calls) != 1 || repo.calls[0] != m.kind {
+					t.Errorf("repository lookups = %v, want [%s]", repo.calls, m.kind)
+				}
+			})
+		}
+	}
+}
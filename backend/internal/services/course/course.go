@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"backend/internal/entities"
+	"backend/internal/services/authz"
 
 	"github.com/rs/zerolog/log"
 )
@@ -16,7 +17,7 @@ type CourseRepository interface {
 	GetCourseStructure(ctx context.Context, courseID string) ([]entities.Module, error)
 	GetByAuthorID(ctx context.Context, authorID string) ([]entities.Course, error)
 	DeleteCourse(ctx context.Context, id string) error
-	GetCatalog(ctx context.Context) ([]entities.Course, error)
+	GetCatalog(ctx context.Context, query string) ([]entities.Course, error)
 
 	AddModule(ctx context.Context, module *entities.Module) error
 	GetModuleByID(ctx context.Context, moduleID string) (*entities.Module, error) // <-- Добавили
@@ -35,21 +36,39 @@ type CourseRepository interface {
 	IsFavorite(ctx context.Context, userID, courseID string) (bool, error)
 
 	GetCoursesByIDs(ctx context.Context, ids []string) ([]entities.Course, error)
+
+	GetCourseStats(ctx context.Context, courseID string) (*entities.CourseStats, error)
+
+	SaveCourseTranslation(ctx context.Context, t *entities.CourseTranslation) error
+	DeleteCourseTranslation(ctx context.Context, courseID string, locale entities.Locale) error
+	SaveModuleTranslation(ctx context.Context, t *entities.ModuleTranslation) error
+	DeleteModuleTranslation(ctx context.Context, moduleID string, locale entities.Locale) error
+	SaveLessonTranslation(ctx context.Context, t *entities.LessonTranslation) error
+	DeleteLessonTranslation(ctx context.Context, lessonID string, locale entities.Locale) error
 }
 
 type MLClient interface {
 	GetRecommendedCourseIDs(userID string) ([]string, error)
 }
 
+// Policy проверяет, что пользователь — автор курса (или администратор).
+type Policy interface {
+	CanManageCourse(ctx context.Context, actor authz.Actor, courseID string) error
+	CanManageModule(ctx context.Context, actor authz.Actor, moduleID string) error
+	CanManageLesson(ctx context.Context, actor authz.Actor, lessonID string) error
+}
+
 type CourseService struct {
 	repo     CourseRepository
 	mlClient MLClient
+	policy   Policy
 }
 
-func NewCourseService(repo CourseRepository, mlClient MLClient) *CourseService {
+func NewCourseService(repo CourseRepository, mlClient MLClient, policy Policy) *CourseService {
 	return &CourseService{
 		repo:     repo,
 		mlClient: mlClient,
+		policy:   policy,
 	}
 }
 
@@ -60,8 +79,8 @@ func (s *CourseService) CreateCourse(ctx context.Context, course *entities.Cours
 	return nil
 }
 
-func (s *CourseService) GetCatalog(ctx context.Context) ([]entities.Course, error) {
-	return s.repo.GetCatalog(ctx)
+func (s *CourseService) GetCatalog(ctx context.Context, query string) ([]entities.Course, error) {
+	return s.repo.GetCatalog(ctx, query)
 }
 
 func (s *CourseService) GetCoursesByAuthor(ctx context.Context, authorID string) ([]entities.Course, error) {
@@ -72,21 +91,13 @@ func (s *CourseService) GetCourseByID(ctx context.Context, courseID string) (*en
 	return s.repo.GetByID(ctx, courseID)
 }
 
-func (s *CourseService) GetUserCourse(ctx context.Context, courseID, userID string) (*entities.Course, error) {
-	course, err := s.repo.GetByID(ctx, courseID)
-	if err != nil {
-		return nil, err
-	}
-
-	if course.AuthorID != userID {
-		return nil, fmt.Errorf("access denied: user is not the author")
+func (s *CourseService) UpdateCourse(ctx context.Context, actor authz.Actor, courseID string, updates *entities.Course) error {
+	if err := s.policy.CanManageCourse(ctx, actor, courseID); err != nil {
+		return err
 	}
 
-	return course, nil
-}
-
-func (s *CourseService) UpdateCourse(ctx context.Context, courseID string, updates *entities.Course) error {
-	existing, err := s.repo.GetByID(ctx, courseID)
+	// Читаем исходный текст: перевод не должен попасть в courses
+	existing, err := s.repo.GetByID(entities.WithoutLocale(ctx), courseID)
 	if err != nil {
 		return err
 	}
@@ -100,8 +111,12 @@ func (s *CourseService) UpdateCourse(ctx context.Context, courseID string, updat
 	return s.repo.UpdateCourse(ctx, existing)
 }
 
-func (s *CourseService) ChangePublishStatus(ctx context.Context, courseID string, isPublished bool) error {
-	course, err := s.repo.GetByID(ctx, courseID)
+func (s *CourseService) ChangePublishStatus(ctx context.Context, actor authz.Actor, courseID string, isPublished bool) error {
+	if err := s.policy.CanManageCourse(ctx, actor, courseID); err != nil {
+		return err
+	}
+
+	course, err := s.repo.GetByID(entities.WithoutLocale(ctx), courseID)
 	if err != nil {
 		return err
 	}
@@ -110,23 +125,31 @@ func (s *CourseService) ChangePublishStatus(ctx context.Context, courseID string
 	return s.repo.UpdateCourse(ctx, course)
 }
 
-func (s *CourseService) DeleteCourse(ctx context.Context, id string) error {
+func (s *CourseService) DeleteCourse(ctx context.Context, actor authz.Actor, id string) error {
+	if err := s.policy.CanManageCourse(ctx, actor, id); err != nil {
+		return err
+	}
 	return s.repo.DeleteCourse(ctx, id)
 }
 
-func (s *CourseService) CreateModule(ctx context.Context, userID string, module *entities.Module) error {
-	if _, err := s.GetUserCourse(ctx, module.CourseID, userID); err != nil {
+func (s *CourseService) CreateModule(ctx context.Context, actor authz.Actor, module *entities.Module) error {
+	if err := s.policy.CanManageCourse(ctx, actor, module.CourseID); err != nil {
 		return err
 	}
 	return s.repo.AddModule(ctx, module)
 }
 
-func (s *CourseService) UpdateModule(ctx context.Context, userID string, module *entities.Module) error {
-	existing, err := s.repo.GetModuleByID(ctx, module.ID)
-	if err != nil {
+func (s *CourseService) GetModuleByID(ctx context.Context, moduleID string) (*entities.Module, error) {
+	return s.repo.GetModuleByID(ctx, moduleID)
+}
+
+func (s *CourseService) UpdateModule(ctx context.Context, actor authz.Actor, module *entities.Module) error {
+	if err := s.policy.CanManageModule(ctx, actor, module.ID); err != nil {
 		return err
 	}
-	if _, err := s.GetUserCourse(ctx, existing.CourseID, userID); err != nil {
+
+	existing, err := s.repo.GetModuleByID(entities.WithoutLocale(ctx), module.ID)
+	if err != nil {
 		return err
 	}
 
@@ -136,24 +159,15 @@ func (s *CourseService) UpdateModule(ctx context.Context, userID string, module
 	return s.repo.UpdateModule(ctx, existing)
 }
 
-func (s *CourseService) DeleteModule(ctx context.Context, userID, moduleID string) error {
-	existing, err := s.repo.GetModuleByID(ctx, moduleID)
-	if err != nil {
-		return err
-	}
-	if _, err := s.GetUserCourse(ctx, existing.CourseID, userID); err != nil {
+func (s *CourseService) DeleteModule(ctx context.Context, actor authz.Actor, moduleID string) error {
+	if err := s.policy.CanManageModule(ctx, actor, moduleID); err != nil {
 		return err
 	}
 	return s.repo.DeleteModule(ctx, moduleID)
 }
 
-func (s *CourseService) CreateLesson(ctx context.Context, userID string, lesson *entities.Lesson) error {
-	module, err := s.repo.GetModuleByID(ctx, lesson.ModuleID)
-	if err != nil {
-		return fmt.Errorf("module not found: %w", err)
-	}
-
-	if _, err := s.GetUserCourse(ctx, module.CourseID, userID); err != nil {
+func (s *CourseService) CreateLesson(ctx context.Context, actor authz.Actor, lesson *entities.Lesson) error {
+	if err := s.policy.CanManageModule(ctx, actor, lesson.ModuleID); err != nil {
 		return err
 	}
 
@@ -164,19 +178,15 @@ func (s *CourseService) GetLessonByID(ctx context.Context, lessonID string) (*en
 	return s.repo.GetLessonByID(ctx, lessonID)
 }
 
-func (s *CourseService) UpdateLesson(ctx context.Context, userID string, lesson *entities.Lesson) error {
-	existing, err := s.repo.GetLessonByID(ctx, lesson.ID)
-	if err != nil {
+func (s *CourseService) UpdateLesson(ctx context.Context, actor authz.Actor, lesson *entities.Lesson) error {
+	if err := s.policy.CanManageLesson(ctx, actor, lesson.ID); err != nil {
 		return err
 	}
 
-	module, err := s.repo.GetModuleByID(ctx, existing.ModuleID)
+	existing, err := s.repo.GetLessonByID(entities.WithoutLocale(ctx), lesson.ID)
 	if err != nil {
 		return err
 	}
-	if _, err := s.GetUserCourse(ctx, module.CourseID, userID); err != nil {
-		return err
-	}
 
 	existing.Title = lesson.Title
 	existing.ContentText = lesson.ContentText
@@ -188,22 +198,23 @@ func (s *CourseService) UpdateLesson(ctx context.Context, userID string, lesson
 	return s.repo.UpdateLesson(ctx, existing)
 }
 
-func (s *CourseService) DeleteLesson(ctx context.Context, userID, lessonID string) error {
-	existing, err := s.repo.GetLessonByID(ctx, lessonID)
-	if err != nil {
-		return err
-	}
-	module, err := s.repo.GetModuleByID(ctx, existing.ModuleID)
-	if err != nil {
-		return err
-	}
-	if _, err := s.GetUserCourse(ctx, module.CourseID, userID); err != nil {
+func (s *CourseService) DeleteLesson(ctx context.Context, actor authz.Actor, lessonID string) error {
+	if err := s.policy.CanManageLesson(ctx, actor, lessonID); err != nil {
 		return err
 	}
 
 	return s.repo.DeleteLesson(ctx, lessonID)
 }
 
+// GetCourseStats отдаёт автору сводку по курсу, которую пересчитывает
+// задача course_stats.
+func (s *CourseService) GetCourseStats(ctx context.Context, actor authz.Actor, courseID string) (*entities.CourseStats, error) {
+	if err := s.policy.CanManageCourse(ctx, actor, courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCourseStats(ctx, courseID)
+}
+
 func (s *CourseService) GetFullStructure(ctx context.Context, courseID string) ([]entities.Module, error) {
 	return s.repo.GetCourseStructure(ctx, courseID)
 }
@@ -0,0 +1,53 @@
+package course
+
+import (
+	"context"
+
+	"backend/internal/entities"
+	"backend/internal/services/authz"
+)
+
+// Переводы правит автор курса, как и исходный текст. Перевод на язык,
+// для которого его нет, при чтении заменяется исходным текстом.
+
+func (s *CourseService) SaveCourseTranslation(ctx context.Context, actor authz.Actor, t *entities.CourseTranslation) error {
+	if err := s.policy.CanManageCourse(ctx, actor, t.CourseID); err != nil {
+		return err
+	}
+	return s.repo.SaveCourseTranslation(ctx, t)
+}
+
+func (s *CourseService) DeleteCourseTranslation(ctx context.Context, actor authz.Actor, courseID string, locale entities.Locale) error {
+	if err := s.policy.CanManageCourse(ctx, actor, courseID); err != nil {
+		return err
+	}
+	return s.repo.DeleteCourseTranslation(ctx, courseID, locale)
+}
+
+func (s *CourseService) SaveModuleTranslation(ctx context.Context, actor authz.Actor, t *entities.ModuleTranslation) error {
+	if err := s.policy.CanManageModule(ctx, actor, t.ModuleID); err != nil {
+		return err
+	}
+	return s.repo.SaveModuleTranslation(ctx, t)
+}
+
+func (s *CourseService) DeleteModuleTranslation(ctx context.Context, actor authz.Actor, moduleID string, locale entities.Locale) error {
+	if err := s.policy.CanManageModule(ctx, actor, moduleID); err != nil {
+		return err
+	}
+	return s.repo.DeleteModuleTranslation(ctx, moduleID, locale)
+}
+
+func (s *CourseService) SaveLessonTranslation(ctx context.Context, actor authz.Actor, t *entities.LessonTranslation) error {
+	if err := s.policy.CanManageLesson(ctx, actor, t.LessonID); err != nil {
+		return err
+	}
+	return s.repo.SaveLessonTranslation(ctx, t)
+}
+
+func (s *CourseService) DeleteLessonTranslation(ctx context.Context, actor authz.Actor, lessonID string, locale entities.Locale) error {
+	if err := s.policy.CanManageLesson(ctx, actor, lessonID); err != nil {
+		return err
+	}
+	return s.repo.DeleteLessonTranslation(ctx, lessonID, locale)
+}
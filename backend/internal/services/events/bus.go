@@ -0,0 +1,239 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handler обрабатывает одно событие. Обработчики событий, которые идут
+// через outbox, должны быть идемпотентными: при ошибке любого подписчика
+// relay повторит доставку всем подписчикам этого события.
+type Handler func(ctx context.Context, event entities.DomainEvent) error
+
+type OutboxRepository interface {
+	EnqueueEvent(ctx context.Context, e entities.DomainEvent) error
+}
+
+// Bus — шина доменных событий внутри процесса. У каждого подписчика своя
+// очередь и свой пул воркеров, поэтому медленный подписчик не задерживает
+// остальных. Паника в обработчике превращается в ошибку, ошибки пишутся
+// в лог. Stop перестаёт принимать события и дожидается, пока очереди
+// будут разобраны.
+type Bus struct {
+	outbox         OutboxRepository
+	handlerTimeout time.Duration
+
+	mu     sync.RWMutex
+	subs   map[entities.DomainEventType][]*subscription
+	all    []*subscription
+	closed bool
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+	workers int
+	queue   chan delivery
+	wg      sync.WaitGroup
+}
+
+type delivery struct {
+	ctx   context.Context
+	event entities.DomainEvent
+	// result == nil для Publish: результат никто не ждёт
+	result chan<- error
+}
+
+func NewBus(outbox OutboxRepository, handlerTimeout time.Duration) *Bus {
+	if handlerTimeout <= 0 {
+		handlerTimeout = 30 * time.Second
+	}
+
+	return &Bus{
+		outbox:         outbox,
+		handlerTimeout: handlerTimeout,
+		subs:           make(map[entities.DomainEventType][]*subscription),
+	}
+}
+
+// Subscribe регистрирует обработчик событий types с пулом из workers
+// горутин и очередью на bufferSize событий. Вызывается до Start.
+func (b *Bus) Subscribe(name string, workers, bufferSize int, handler Handler, types ...entities.DomainEventType) {
+	if workers <= 0 {
+		workers = 1
+	}
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+
+	s := &subscription{
+		name:    name,
+		handler: handler,
+		workers: workers,
+		queue:   make(chan delivery, bufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.all = append(b.all, s)
+	for _, t := range types {
+		b.subs[t] = append(b.subs[t], s)
+	}
+}
+
+func (b *Bus) Start() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.all {
+		for range s.workers {
+			s.wg.Add(1)
+			go b.work(s)
+		}
+	}
+}
+
+// Publish раздаёт событие подписчикам и не ждёт обработки. Если очередь
+// подписчика переполнена, событие для него отбрасывается с предупреждением,
+// поэтому события, которые нельзя потерять, публикуются через PublishDurable.
+// Publish нельзя вызывать внутри транзакции: обработчики получают ctx
+// вызывающего кода без отмены и выполняются уже после ответа клиенту.
+func (b *Bus) Publish(ctx context.Context, event entities.DomainEvent) {
+	ctx = context.WithoutCancel(ctx)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.subs[event.Type] {
+		select {
+		case s.queue <- delivery{ctx: ctx, event: event}:
+		default:
+			log.Warn().
+				Str("subscriber", s.name).
+				Str("event", string(event.Type)).
+				Str("event_id", event.ID).
+				Msg("event queue is full, event dropped")
+		}
+	}
+}
+
+// PublishDurable сохраняет событие в event_outbox; подписчикам его доставит
+// Relay. Если ctx несёт транзакцию, событие записывается в неё и
+// откатывается вместе с ней.
+func (b *Bus) PublishDurable(ctx context.Context, event entities.DomainEvent) error {
+	if b.outbox == nil {
+		return errors.New("event outbox is not configured")
+	}
+	return b.outbox.EnqueueEvent(ctx, event)
+}
+
+// Deliver передаёт событие всем подписчикам и ждёт результата.
+// Возвращает ошибки всех подписчиков, которые не справились.
+func (b *Bus) Deliver(ctx context.Context, event entities.DomainEvent) error {
+	b.mu.RLock()
+	subs := b.subs[event.Type]
+	if b.closed {
+		b.mu.RUnlock()
+		return errors.New("event bus is stopped")
+	}
+
+	results := make(chan error, len(subs))
+	sent := 0
+	for _, s := range subs {
+		select {
+		case s.queue <- delivery{ctx: ctx, event: event, result: results}:
+			sent++
+		case <-ctx.Done():
+			b.mu.RUnlock()
+			return ctx.Err()
+		}
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for range sent {
+		select {
+		case err := <-results:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stop перестаёт принимать события и ждёт, пока воркеры разберут очереди.
+func (b *Bus) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.all {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, s := range b.all {
+			s.wg.Wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) work(s *subscription) {
+	defer s.wg.Done()
+
+	for d := range s.queue {
+		err := b.invoke(s, d)
+		if err != nil {
+			log.Error().Err(err).
+				Str("subscriber", s.name).
+				Str("event", string(d.event.Type)).
+				Str("event_id", d.event.ID).
+				Str("user_id", d.event.UserID).
+				Msg("event handler failed")
+		}
+		if d.result != nil {
+			d.result <- err
+		}
+	}
+}
+
+func (b *Bus) invoke(s *subscription, d delivery) (err error) {
+	ctx, cancel := context.WithTimeout(d.ctx, b.handlerTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: panic: %v", s.name, r)
+			log.Error().Str("subscriber", s.name).Bytes("stack", debug.Stack()).Msg("event handler panicked")
+		}
+	}()
+
+	if err := s.handler(ctx, d.event); err != nil {
+		return fmt.Errorf("%s: %w", s.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+const testEventType entities.DomainEventType = "test.happened"
+
+func testEvent(t *testing.T, userID string) entities.DomainEvent {
+	t.Helper()
+	e, err := entities.NewDomainEvent(testEventType, userID, map[string]string{})
+	if err != nil {
+		t.Fatalf("NewDomainEvent: %v", err)
+	}
+	return e
+}
+
+func TestBusPanickingSubscriberKeepsWorking(t *testing.T) {
+	bus := NewBus(nil, time.Second)
+
+	var handled []string
+	var mu sync.Mutex
+	bus.Subscribe("fragile", 1, 0, func(_ context.Context, e entities.DomainEvent) error {
+		if e.UserID == "panic" {
+			panic("boom")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, e.UserID)
+		return nil
+	}, testEventType)
+
+	var others atomic.Int32
+	bus.Subscribe("other", 1, 0, func(context.Context, entities.DomainEvent) error {
+		others.Add(1)
+		return nil
+	}, testEventType)
+
+	bus.Start()
+	defer bus.Stop(context.Background())
+
+	ctx := context.Background()
+	err := bus.Deliver(ctx, testEvent(t, "panic"))
+	if err == nil || !strings.Contains(err.Error(), "fragile: panic: boom") {
+		t.Fatalf("Deliver(panicking) = %v, want the panic as an error", err)
+	}
+
+	// Единственный воркер подписчика пережил панику и берёт следующее событие
+	if err := bus.Deliver(ctx, testEvent(t, "u1")); err != nil {
+		t.Fatalf("Deliver after panic: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 || handled[0] != "u1" {
+		t.Errorf("handled = %v, want [u1]", handled)
+	}
+	if n := others.Load(); n != 2 {
+		t.Errorf("other subscriber got %d events, want 2", n)
+	}
+}
+
+func TestBusStopDrainsQueue(t *testing.T) {
+	bus := NewBus(nil, time.Second)
+
+	release := make(chan struct{})
+	var handled atomic.Int32
+	bus.Subscribe("slow", 1, 10, func(context.Context, entities.DomainEvent) error {
+		<-release
+		handled.Add(1)
+		return nil
+	}, testEventType)
+	bus.Start()
+
+	ctx := context.Background()
+	for range 5 {
+		bus.Publish(ctx, testEvent(t, "u1"))
+	}
+
+	// Воркер стоит на первом событии, остальные ждут в очереди
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := bus.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if n := handled.Load(); n != 5 {
+		t.Errorf("handled %d events before Stop returned, want 5", n)
+	}
+
+	bus.Publish(ctx, testEvent(t, "u1"))
+	if err := bus.Deliver(ctx, testEvent(t, "u1")); err == nil {
+		t.Error("Deliver after Stop = nil, want error")
+	}
+	if n := handled.Load(); n != 5 {
+		t.Errorf("events after Stop were handled: %d", n)
+	}
+}
+
+func TestBusStopTimesOut(t *testing.T) {
+	bus := NewBus(nil, time.Second)
+
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe("stuck", 1, 0, func(context.Context, entities.DomainEvent) error {
+		<-release
+		return nil
+	}, testEventType)
+	bus.Start()
+	bus.Publish(context.Background(), testEvent(t, "u1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() = %v, want DeadlineExceeded", err)
+	}
+}
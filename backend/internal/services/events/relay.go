@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	retryBaseDelay = 10 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+	maxAttempts    = 12
+
+	// deliveryLease должно с запасом покрывать обработку одной пачки.
+	deliveryLease = 2 * time.Minute
+)
+
+type RelayRepository interface {
+	ClaimDueEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error)
+	MarkEventProcessed(ctx context.Context, id string) error
+	MarkEventRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error
+	MarkEventFailed(ctx context.Context, id string, lastError string) error
+}
+
+type Deliverer interface {
+	Deliver(ctx context.Context, event entities.DomainEvent) error
+}
+
+// Relay доставляет события из event_outbox подписчикам шины. Как и
+// mail.Dispatcher, он разбирает строки через FOR UPDATE SKIP LOCKED и
+// откладывает неудачные попытки с экспоненциальной задержкой; после
+// maxAttempts событие помечается failed.
+type Relay struct {
+	repo         RelayRepository
+	bus          Deliverer
+	pollInterval time.Duration
+	batchSize    int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewRelay(repo RelayRepository, bus Deliverer, pollInterval time.Duration, batchSize int) *Relay {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	return &Relay{
+		repo:         repo,
+		bus:          bus,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start() {
+	go r.run()
+}
+
+// Stop дожидается окончания текущей пачки. Relay нужно останавливать
+// раньше шины, иначе доставка оборвётся на середине.
+func (r *Relay) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for r.relay() == r.batchSize {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay доставляет одну пачку и возвращает её размер.
+func (r *Relay) relay() int {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryLease)
+	defer cancel()
+
+	events, err := r.repo.ClaimDueEvents(ctx, r.batchSize, deliveryLease)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to claim outbox events")
+		return 0
+	}
+
+	for _, e := range events {
+		r.deliver(ctx, e)
+	}
+	return len(events)
+}
+
+func (r *Relay) deliver(ctx context.Context, e entities.OutboxEvent) {
+	deliverErr := r.bus.Deliver(ctx, e.Event)
+	if deliverErr == nil {
+		if err := r.repo.MarkEventProcessed(ctx, e.Event.ID); err != nil {
+			log.Error().Err(err).Str("event_id", e.Event.ID).Msg("failed to mark event as processed")
+		}
+		return
+	}
+
+	logger := log.With().
+		Str("event_id", e.Event.ID).
+		Str("event", string(e.Event.Type)).
+		Int("attempt", e.Attempts).
+		Logger()
+
+	if e.Attempts >= maxAttempts {
+		logger.Error().Err(deliverErr).Msg("event delivery failed, giving up")
+		if err := r.repo.MarkEventFailed(ctx, e.Event.ID, deliverErr.Error()); err != nil {
+			logger.Error().Err(err).Msg("failed to mark event as failed")
+		}
+		return
+	}
+
+	next := time.Now().UTC().Add(retryDelay(e.Attempts))
+	logger.Warn().Err(deliverErr).Time("next_attempt_at", next).Msg("event delivery failed, will retry")
+	if err := r.repo.MarkEventRetry(ctx, e.Event.ID, next, deliverErr.Error()); err != nil {
+		logger.Error().Err(err).Msg("failed to schedule event retry")
+	}
+}
+
+// retryDelay — задержка перед попыткой attempt+1.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// fakeEventOutbox повторяет семантику event_outbox: ClaimDueEvents
+// увеличивает attempts и сдвигает next_attempt_at на время аренды.
+type fakeEventOutbox struct {
+	mu     sync.Mutex
+	order  []string
+	events map[string]*storedEvent
+}
+
+type storedEvent struct {
+	entities.OutboxEvent
+	status        string
+	nextAttemptAt time.Time
+	lastError     string
+}
+
+func newFakeEventOutbox() *fakeEventOutbox {
+	return &fakeEventOutbox{events: map[string]*storedEvent{}}
+}
+
+func (f *fakeEventOutbox) EnqueueEvent(_ context.Context, e entities.DomainEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.order = append(f.order, e.ID)
+	f.events[e.ID] = &storedEvent{OutboxEvent: entities.OutboxEvent{Event: e}, status: "pending", nextAttemptAt: time.Now()}
+	return nil
+}
+
+func (f *fakeEventOutbox) ClaimDueEvents(_ context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	var claimed []entities.OutboxEvent
+	for _, id := range f.order {
+		e := f.events[id]
+		if len(claimed) == limit {
+			break
+		}
+		if e.status != "pending" || e.nextAttemptAt.After(now) {
+			continue
+		}
+		e.Attempts++
+		e.nextAttemptAt = now.Add(lease)
+		claimed = append(claimed, e.OutboxEvent)
+	}
+	return claimed, nil
+}
+
+func (f *fakeEventOutbox) MarkEventProcessed(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[id].status = "processed"
+	f.events[id].lastError = ""
+	return nil
+}
+
+func (f *fakeEventOutbox) MarkEventRetry(_ context.Context, id string, next time.Time, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[id].nextAttemptAt = next
+	f.events[id].lastError = lastError
+	return nil
+}
+
+func (f *fakeEventOutbox) MarkEventFailed(_ context.Context, id string, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[id].status = "failed"
+	f.events[id].lastError = lastError
+	return nil
+}
+
+func (f *fakeEventOutbox) get(id string) storedEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.events[id]
+}
+
+// makeDue имитирует наступление времени следующей попытки.
+func (f *fakeEventOutbox) makeDue(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[id].nextAttemptAt = time.Now().Add(-time.Second)
+}
+
+func TestRelayRedeliversAfterFailedHandler(t *testing.T) {
+	repo := newFakeEventOutbox()
+	bus := NewBus(repo, time.Second)
+	relay := NewRelay(repo, bus, time.Second, 10)
+	other := NewRelay(repo, bus, time.Second, 10)
+
+	var flakyCalls, steadyCalls atomic.Int32
+	bus.Subscribe("flaky", 1, 0, func(context.Context, entities.DomainEvent) error {
+		if flakyCalls.Add(1) == 1 {
+			// Пока событие доставляется, оно арендовано: другой relay его не берёт
+			if n := other.relay(); n != 0 {
+				t.Errorf("another relay claimed %d leased events", n)
+			}
+			return errors.New("boom")
+		}
+		return nil
+	}, testEventType)
+	bus.Subscribe("steady", 1, 0, func(context.Context, entities.DomainEvent) error {
+		steadyCalls.Add(1)
+		return nil
+	}, testEventType)
+	bus.Start()
+	defer bus.Stop(context.Background())
+
+	event := testEvent(t, "u1")
+	if err := bus.PublishDurable(context.Background(), event); err != nil {
+		t.Fatalf("PublishDurable: %v", err)
+	}
+
+	before := time.Now()
+	if n := relay.relay(); n != 1 {
+		t.Fatalf("first relay() = %d, want 1", n)
+	}
+	e := repo.get(event.ID)
+	if e.status != "pending" || e.Attempts != 1 || e.lastError != "flaky: boom" {
+		t.Fatalf("after failure: status %q, attempts %d, last error %q", e.status, e.Attempts, e.lastError)
+	}
+	if want := before.Add(retryDelay(1)); e.nextAttemptAt.Before(want) {
+		t.Errorf("next attempt at %v, want not before %v", e.nextAttemptAt, want)
+	}
+	if n := relay.relay(); n != 0 {
+		t.Fatalf("event delivered again before its retry time")
+	}
+
+	repo.makeDue(event.ID)
+	if n := relay.relay(); n != 1 {
+		t.Fatalf("retry relay() = %d, want 1", n)
+	}
+	e = repo.get(event.ID)
+	if e.status != "processed" || e.Attempts != 2 || e.lastError != "" {
+		t.Errorf("after retry: status %q, attempts %d, last error %q", e.status, e.Attempts, e.lastError)
+	}
+	// Повтор получают все подписчики, поэтому они должны быть идемпотентными
+	if flakyCalls.Load() != 2 || steadyCalls.Load() != 2 {
+		t.Errorf("calls: flaky %d, steady %d; want 2 and 2", flakyCalls.Load(), steadyCalls.Load())
+	}
+}
+
+func TestRelayGivesUp(t *testing.T) {
+	repo := newFakeEventOutbox()
+	bus := NewBus(repo, time.Second)
+	bus.Subscribe("broken", 1, 0, func(context.Context, entities.DomainEvent) error {
+		return errors.New("boom")
+	}, testEventType)
+	bus.Start()
+	defer bus.Stop(context.Background())
+
+	event := testEvent(t, "u1")
+	if err := bus.PublishDurable(context.Background(), event); err != nil {
+		t.Fatalf("PublishDurable: %v", err)
+	}
+	relay := NewRelay(repo, bus, time.Second, 10)
+
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		relay.relay()
+		if e := repo.get(event.ID); e.status != "pending" || e.Attempts != attempt {
+			t.Fatalf("attempt %d: status %q, attempts %d", attempt, e.status, e.Attempts)
+		}
+		repo.makeDue(event.ID)
+	}
+
+	relay.relay()
+	if e := repo.get(event.ID); e.status != "failed" || e.Attempts != maxAttempts || e.lastError != "broken: boom" {
+		t.Errorf("after %d attempts: status %q, attempts %d, last error %q", maxAttempts, e.status, e.Attempts, e.lastError)
+	}
+}
+
+func TestRelayRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{8, 1280 * time.Second},
+		{9, 30 * time.Minute},
+		{20, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -2,16 +2,27 @@ package gamification
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"backend/internal/entities"
 )
 
 type Repository interface {
 	GetAllLeagues(ctx context.Context) ([]entities.League, error)
+	GetAllAchievements(ctx context.Context) ([]entities.Achievement, error)
+	GetUserAchievements(ctx context.Context, userID string) ([]entities.UserAchievement, error)
+	GetPolicy(ctx context.Context) (*entities.GamificationPolicy, error)
+	SavePolicy(ctx context.Context, p entities.GamificationPolicy, levelThresholds []int64) error
+	UpdateLeagueZones(ctx context.Context, l entities.League) error
 }
 
 type GamificationService struct {
 	repo Repository
+
+	policyMu       sync.Mutex
+	policy         *entities.GamificationPolicy
+	policyLoadedAt time.Time
 }
 
 func NewGamificationService(repo Repository) *GamificationService {
@@ -21,3 +32,72 @@ func NewGamificationService(repo Repository) *GamificationService {
 func (s *GamificationService) GetAllLeagues(ctx context.Context) ([]entities.League, error) {
 	return s.repo.GetAllLeagues(ctx)
 }
+
+// LeagueZones — доли активных учеников, которые по итогам недели переходят
+// в соседние лиги, и понижение неактивных.
+type LeagueZones struct {
+	PromotePercent int
+	DemotePercent  int
+	DemoteInactive bool
+}
+
+// UpdateLeagueZones меняет зоны лиги; действуют со следующего сброса.
+func (s *GamificationService) UpdateLeagueZones(ctx context.Context, leagueID int, z LeagueZones) (*entities.League, error) {
+	leagues, err := s.repo.GetAllLeagues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range leagues {
+		if l.ID != leagueID {
+			continue
+		}
+
+		l.PromotePercent, l.DemotePercent, l.DemoteInactive = z.PromotePercent, z.DemotePercent, z.DemoteInactive
+		if err := l.ValidateZones(); err != nil {
+			return nil, err
+		}
+		if err := s.repo.UpdateLeagueZones(ctx, l); err != nil {
+			return nil, err
+		}
+		return &l, nil
+	}
+
+	return nil, entities.ErrNotFound
+}
+
+// StudentAchievement — достижение в списке ученика; EarnedAt == nil,
+// если оно ещё не получено.
+type StudentAchievement struct {
+	Achievement entities.Achievement
+	EarnedAt    *time.Time
+}
+
+// GetStudentAchievements возвращает все достижения: сначала полученные
+// (от новых к старым), затем остальные.
+func (s *GamificationService) GetStudentAchievements(ctx context.Context, userID string) ([]StudentAchievement, error) {
+	all, err := s.repo.GetAllAchievements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	earned, err := s.repo.GetUserAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	earnedIDs := make(map[string]bool, len(earned))
+	list := make([]StudentAchievement, 0, len(all))
+	for _, ua := range earned {
+		earnedAt := ua.EarnedAt
+		earnedIDs[ua.AchievementID] = true
+		list = append(list, StudentAchievement{Achievement: *ua.Achievement, EarnedAt: &earnedAt})
+	}
+	for _, a := range all {
+		if !earnedIDs[a.ID] {
+			list = append(list, StudentAchievement{Achievement: a})
+		}
+	}
+
+	return list, nil
+}
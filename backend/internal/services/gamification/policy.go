@@ -0,0 +1,57 @@
+package gamification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// policyCacheTTL — как долго политика берётся из памяти. Изменение,
+// сделанное через другой экземпляр backend, применится не позже чем
+// через это время.
+const policyCacheTTL = time.Minute
+
+// Policy возвращает действующую политику начисления XP и уровней.
+// Пока администратор не сохранил свою, действует политика по умолчанию.
+func (s *GamificationService) Policy(ctx context.Context) (entities.GamificationPolicy, error) {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+
+	if s.policy != nil && time.Since(s.policyLoadedAt) < policyCacheTTL {
+		return *s.policy, nil
+	}
+
+	p, err := s.repo.GetPolicy(ctx)
+	if errors.Is(err, entities.ErrNotFound) {
+		def := entities.DefaultGamificationPolicy()
+		p, err = &def, nil
+	}
+	if err != nil {
+		return entities.GamificationPolicy{}, err
+	}
+
+	s.policy, s.policyLoadedAt = p, time.Now()
+	return *p, nil
+}
+
+// UpdatePolicy проверяет и сохраняет политику. Уровни учеников
+// пересчитываются по новой кривой, но только вверх: заработанный уровень
+// не отнимается, даже если по новой кривой XP на него не хватает.
+func (s *GamificationService) UpdatePolicy(ctx context.Context, p entities.GamificationPolicy) (entities.GamificationPolicy, error) {
+	if err := p.Validate(); err != nil {
+		return entities.GamificationPolicy{}, fmt.Errorf("%w: %v", entities.ErrInvalidPolicy, err)
+	}
+
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+
+	if err := s.repo.SavePolicy(ctx, p, p.Levels.LevelThresholds()); err != nil {
+		return entities.GamificationPolicy{}, err
+	}
+
+	s.policy, s.policyLoadedAt = &p, time.Now()
+	return p, nil
+}
@@ -0,0 +1,211 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+	maxAttempts    = 10
+
+	// sendLease — на сколько письмо скрывается от других отправщиков,
+	// пока идёт попытка. Аренда берётся на одно письмо, а sendTimeout
+	// оставляет запас, чтобы успеть записать результат до её конца.
+	sendLease   = 2 * time.Minute
+	sendTimeout = time.Minute
+)
+
+type Sender interface {
+	Send(ctx context.Context, msg entities.EmailMessage) error
+}
+
+// DispatchRepository — очередь писем. Mark* принимают номер попытки из
+// ClaimDue и возвращают entities.ErrNotFound, если аренда уже чужая.
+type DispatchRepository interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEmail, error)
+	MarkSent(ctx context.Context, id int64, attempt int) error
+	MarkRetry(ctx context.Context, id int64, attempt int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id int64, attempt int, lastError string) error
+	ExpireDue(ctx context.Context) (int64, error)
+}
+
+// Dispatcher в фоне отправляет письма из email_outbox. Неудачная попытка
+// откладывается с экспоненциальной задержкой (30s, 1m, 2m, ... до 1h);
+// после maxAttempts письмо помечается failed и больше не отправляется.
+// Несколько экземпляров backend могут работать одновременно: письма
+// разбираются через FOR UPDATE SKIP LOCKED по одному, и у каждого своя
+// аренда, поэтому медленное письмо не задерживает остальные в ней.
+// Письма с истёкшим сроком не отправляются, их тела стираются.
+type Dispatcher struct {
+	repo         DispatchRepository
+	sender       Sender
+	pollInterval time.Duration
+	batchSize    int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewDispatcher(repo DispatchRepository, sender Sender, pollInterval time.Duration, batchSize int) *Dispatcher {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+
+	return &Dispatcher{
+		repo:         repo,
+		sender:       sender,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop дожидается окончания текущего письма. Неотправленные письма
+// остаются в outbox и уйдут после следующего запуска.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	close(d.stop)
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Полная пачка — скорее всего, в очереди есть ещё письма
+		for d.dispatch() == d.batchSize {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch отправляет до batchSize писем и возвращает, сколько взято.
+func (d *Dispatcher) dispatch() int {
+	d.expire()
+
+	for n := 0; n < d.batchSize; n++ {
+		select {
+		case <-d.stop:
+			return n
+		default:
+		}
+		if !d.dispatchOne() {
+			return n
+		}
+	}
+	return d.batchSize
+}
+
+// dispatchOne берёт одно письмо со своей арендой и отправляет его.
+// Возвращает false, если отправлять нечего.
+func (d *Dispatcher) dispatchOne() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	emails, err := d.repo.ClaimDue(ctx, 1, sendLease)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to claim outbox email")
+		return false
+	}
+	if len(emails) == 0 {
+		return false
+	}
+
+	d.send(ctx, emails[0])
+	return true
+}
+
+func (d *Dispatcher) expire() {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	n, err := d.repo.ExpireDue(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to expire outbox emails")
+		return
+	}
+	if n > 0 {
+		log.Warn().Int64("count", n).Msg("outbox emails expired before delivery")
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, e entities.OutboxEmail) {
+	logger := log.With().
+		Int64("email_id", e.ID).
+		Str("template", e.Template).
+		Int("attempt", e.Attempts).
+		Logger()
+
+	sendErr := d.sender.Send(ctx, e.Message)
+	if sendErr == nil {
+		logFinishError(logger, d.repo.MarkSent(ctx, e.ID, e.Attempts), "failed to mark email as sent")
+		return
+	}
+
+	if e.Attempts >= maxAttempts {
+		logger.Error().Err(sendErr).Msg("email delivery failed, giving up")
+		logFinishError(logger, d.repo.MarkFailed(ctx, e.ID, e.Attempts, sendErr.Error()), "failed to mark email as failed")
+		return
+	}
+
+	next := time.Now().UTC().Add(retryDelay(e.Attempts))
+	logger.Warn().Err(sendErr).Time("next_attempt_at", next).Msg("email delivery failed, will retry")
+	logFinishError(logger, d.repo.MarkRetry(ctx, e.ID, e.Attempts, next, sendErr.Error()), "failed to schedule email retry")
+}
+
+func logFinishError(logger zerolog.Logger, err error, msg string) {
+	switch {
+	case err == nil:
+	case errors.Is(err, entities.ErrNotFound):
+		// Аренда истекла, и письмо уже взял другой отправщик или оно просрочено
+		logger.Warn().Msg("email lease lost before the result was saved")
+	default:
+		logger.Error().Err(err).Msg(msg)
+	}
+}
+
+// retryDelay — задержка перед попыткой attempt+1.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
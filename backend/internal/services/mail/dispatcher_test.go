@@ -0,0 +1,312 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// fakeOutbox повторяет семантику email_outbox: аренду через next_attempt_at
+// и проверку номера попытки в Mark*.
+type fakeOutbox struct {
+	mu     sync.Mutex
+	emails map[int64]*entities.OutboxEmail
+	limits []int
+}
+
+func newFakeOutbox(emails ...entities.OutboxEmail) *fakeOutbox {
+	f := &fakeOutbox{emails: map[int64]*entities.OutboxEmail{}}
+	for i := range emails {
+		e := emails[i]
+		e.Status = entities.OutboxPending
+		f.emails[e.ID] = &e
+	}
+	return f
+}
+
+func (f *fakeOutbox) ClaimDue(_ context.Context, limit int, lease time.Duration) ([]entities.OutboxEmail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limits = append(f.limits, limit)
+
+	now := time.Now()
+	ids := make([]int64, 0, len(f.emails))
+	for id := range f.emails {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var claimed []entities.OutboxEmail
+	for _, id := range ids {
+		e := f.emails[id]
+		if len(claimed) == limit {
+			break
+		}
+		if e.Status != entities.OutboxPending || e.NextAttemptAt.After(now) {
+			continue
+		}
+		if e.ExpiresAt != nil && !e.ExpiresAt.After(now) {
+			continue
+		}
+		e.Attempts++
+		e.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *e)
+	}
+	return claimed, nil
+}
+
+func (f *fakeOutbox) leased(id int64, attempt int) (*entities.OutboxEmail, error) {
+	e := f.emails[id]
+	if e == nil || e.Attempts != attempt || e.Status != entities.OutboxPending {
+		return nil, entities.ErrNotFound
+	}
+	return e, nil
+}
+
+func (f *fakeOutbox) MarkSent(_ context.Context, id int64, attempt int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, err := f.leased(id, attempt)
+	if err != nil {
+		return err
+	}
+	e.Status = entities.OutboxSent
+	e.Message.HTML, e.Message.Text = "", ""
+	return nil
+}
+
+func (f *fakeOutbox) MarkRetry(_ context.Context, id int64, attempt int, next time.Time, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, err := f.leased(id, attempt)
+	if err != nil {
+		return err
+	}
+	e.NextAttemptAt = next
+	e.LastError = lastError
+	return nil
+}
+
+func (f *fakeOutbox) MarkFailed(_ context.Context, id int64, attempt int, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, err := f.leased(id, attempt)
+	if err != nil {
+		return err
+	}
+	e.Status = entities.OutboxFailed
+	e.LastError = lastError
+	e.Message.HTML, e.Message.Text = "", ""
+	return nil
+}
+
+func (f *fakeOutbox) ExpireDue(context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for _, e := range f.emails {
+		if e.Status == entities.OutboxPending && e.ExpiresAt != nil && !e.ExpiresAt.After(time.Now()) {
+			e.Status = entities.OutboxExpired
+			e.Message.HTML, e.Message.Text = "", ""
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeOutbox) get(id int64) entities.OutboxEmail {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.emails[id]
+}
+
+// expireLease имитирует истечение аренды письма.
+func (f *fakeOutbox) expireLease(id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.emails[id].NextAttemptAt = time.Now().Add(-time.Second)
+}
+
+// fakeSMTP заменяет SMTP-сервер: запоминает письма и отвечает
+// ошибкой из fail, пока она не nil.
+type fakeSMTP struct {
+	mu   sync.Mutex
+	sent []entities.EmailMessage
+	fail error
+
+	// onSend вызывается перед ответом; через него тест вмешивается
+	// в середину отправки.
+	onSend func(msg entities.EmailMessage)
+}
+
+func (s *fakeSMTP) Send(_ context.Context, msg entities.EmailMessage) error {
+	if s.onSend != nil {
+		s.onSend(msg)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func testEmail(id int64, to string) entities.OutboxEmail {
+	return entities.OutboxEmail{
+		ID:       id,
+		Template: entities.EmailTemplateResetCode,
+		Message:  entities.EmailMessage{To: to, Subject: "Code", HTML: "<b>123456</b>", Text: "123456"},
+	}
+}
+
+func TestDispatchSendsAndErasesBody(t *testing.T) {
+	repo := newFakeOutbox(testEmail(1, "a@example.com"), testEmail(2, "b@example.com"))
+	smtp := &fakeSMTP{}
+	d := NewDispatcher(repo, smtp, time.Second, 10)
+
+	if n := d.dispatch(); n != 2 {
+		t.Fatalf("dispatch() = %d, want 2", n)
+	}
+	if len(smtp.sent) != 2 || smtp.sent[0].To != "a@example.com" || smtp.sent[1].Text != "123456" {
+		t.Fatalf("sent = %+v", smtp.sent)
+	}
+	for _, id := range []int64{1, 2} {
+		e := repo.get(id)
+		if e.Status != entities.OutboxSent || e.Message.HTML != "" || e.Message.Text != "" {
+			t.Errorf("email %d: status %q, body %q/%q", id, e.Status, e.Message.HTML, e.Message.Text)
+		}
+	}
+	if n := d.dispatch(); n != 0 {
+		t.Errorf("second dispatch() = %d, want 0", n)
+	}
+}
+
+func TestDispatchRetriesThenGivesUp(t *testing.T) {
+	repo := newFakeOutbox(testEmail(1, "a@example.com"))
+	smtp := &fakeSMTP{fail: errors.New("421 service not available")}
+	d := NewDispatcher(repo, smtp, time.Second, 10)
+
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		before := time.Now()
+		d.dispatch()
+
+		e := repo.get(1)
+		if e.Status != entities.OutboxPending || e.Attempts != attempt {
+			t.Fatalf("attempt %d: status %q, attempts %d", attempt, e.Status, e.Attempts)
+		}
+		if e.LastError != "421 service not available" {
+			t.Fatalf("attempt %d: last error %q", attempt, e.LastError)
+		}
+		if want := before.Add(retryDelay(attempt)); e.NextAttemptAt.Before(want) {
+			t.Fatalf("attempt %d: next attempt at %v, want not before %v", attempt, e.NextAttemptAt, want)
+		}
+		if n := d.dispatch(); n != 0 {
+			t.Fatalf("attempt %d: email dispatched again before its retry time", attempt)
+		}
+		repo.expireLease(1)
+	}
+
+	d.dispatch()
+	e := repo.get(1)
+	if e.Status != entities.OutboxFailed || e.Attempts != maxAttempts || e.Message.Text != "" {
+		t.Errorf("after %d attempts: status %q, attempts %d, body %q", maxAttempts, e.Status, e.Attempts, e.Message.Text)
+	}
+}
+
+func TestDispatchLeasesOneEmailAtATime(t *testing.T) {
+	repo := newFakeOutbox(testEmail(1, "a@example.com"), testEmail(2, "b@example.com"), testEmail(3, "c@example.com"))
+	smtp := &fakeSMTP{}
+	smtp.onSend = func(msg entities.EmailMessage) {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		leased := 0
+		for _, e := range repo.emails {
+			if e.Status == entities.OutboxPending && e.Attempts > 0 {
+				leased++
+			}
+		}
+		if leased != 1 {
+			t.Errorf("sending to %s with %d emails leased, want 1", msg.To, leased)
+		}
+	}
+	d := NewDispatcher(repo, smtp, time.Second, 10)
+
+	if n := d.dispatch(); n != 3 {
+		t.Fatalf("dispatch() = %d, want 3", n)
+	}
+	for _, limit := range repo.limits {
+		if limit != 1 {
+			t.Errorf("ClaimDue limit = %d, want 1", limit)
+		}
+	}
+}
+
+func TestDispatchLostLeaseDoesNotOverwriteResult(t *testing.T) {
+	repo := newFakeOutbox(testEmail(1, "a@example.com"))
+	slow := &fakeSMTP{fail: errors.New("timeout")}
+	fast := &fakeSMTP{}
+	other := NewDispatcher(repo, fast, time.Second, 10)
+
+	// Пока первый отправщик ждёт SMTP, его аренда истекает, и письмо
+	// успешно отправляет второй.
+	slow.onSend = func(entities.EmailMessage) {
+		repo.expireLease(1)
+		if n := other.dispatch(); n != 1 {
+			t.Errorf("second dispatcher took %d emails, want 1", n)
+		}
+	}
+	NewDispatcher(repo, slow, time.Second, 10).dispatch()
+
+	e := repo.get(1)
+	if e.Status != entities.OutboxSent || e.LastError != "" || e.Attempts != 2 {
+		t.Errorf("status %q, last error %q, attempts %d; want sent by the second dispatcher", e.Status, e.LastError, e.Attempts)
+	}
+	if len(fast.sent) != 1 {
+		t.Errorf("second dispatcher sent %d emails, want 1", len(fast.sent))
+	}
+}
+
+func TestDispatchSkipsExpiredEmails(t *testing.T) {
+	expired := testEmail(1, "a@example.com")
+	past := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &past
+	fresh := testEmail(2, "b@example.com")
+	future := time.Now().Add(time.Hour)
+	fresh.ExpiresAt = &future
+
+	repo := newFakeOutbox(expired, fresh)
+	smtp := &fakeSMTP{}
+	NewDispatcher(repo, smtp, time.Second, 10).dispatch()
+
+	if len(smtp.sent) != 1 || smtp.sent[0].To != "b@example.com" {
+		t.Errorf("sent = %+v, want only b@example.com", smtp.sent)
+	}
+	if e := repo.get(1); e.Status != entities.OutboxExpired || e.Message.Text != "" {
+		t.Errorf("expired email: status %q, body %q", e.Status, e.Message.Text)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/entities"
+)
+
+type Renderer interface {
+	Render(name string, locale entities.Locale, to string, data any) (entities.EmailMessage, error)
+}
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, e *entities.OutboxEmail) error
+}
+
+// Mailer рендерит письма на языке получателя и кладёт их в email_outbox.
+// Отправкой по SMTP занимается Dispatcher, поэтому письмо переживает
+// рестарт процесса и недоступность почтового сервера.
+type Mailer struct {
+	renderer Renderer
+	outbox   OutboxRepository
+}
+
+func NewMailer(renderer Renderer, outbox OutboxRepository) *Mailer {
+	return &Mailer{renderer: renderer, outbox: outbox}
+}
+
+func (m *Mailer) SendResetCode(ctx context.Context, to string, locale entities.Locale, code string, ttl time.Duration) error {
+	data := struct {
+		Code       string
+		TTLMinutes int
+	}{Code: code, TTLMinutes: int(ttl.Minutes())}
+
+	return m.enqueue(ctx, entities.EmailTemplateResetCode, to, locale, data, ttl)
+}
+
+func (m *Mailer) SendVerificationLink(ctx context.Context, to string, locale entities.Locale, link string, ttl time.Duration) error {
+	data := struct {
+		Link     string
+		TTLHours int
+	}{Link: link, TTLHours: int(ttl.Hours())}
+
+	return m.enqueue(ctx, entities.EmailTemplateVerifyEmail, to, locale, data, ttl)
+}
+
+// digestTTL — через сколько неотправленный дайджест теряет смысл.
+const digestTTL = 3 * 24 * time.Hour
+
+// SendWeeklyDigest ставит в очередь итоги недели ученика. Вызывается в
+// транзакции вместе с отметкой о дайджесте.
+func (m *Mailer) SendWeeklyDigest(ctx context.Context, d *entities.WeeklyDigest) error {
+	// Неделя заканчивается в понедельник 00:00, поэтому последний её день — воскресенье
+	data := struct {
+		FirstName        string
+		PeriodStart      string
+		PeriodEnd        string
+		XPEarned         int64
+		LessonsCompleted int
+		TestsPassed      int
+		CurrentStreak    int
+	}{
+		FirstName:        d.FirstName,
+		PeriodStart:      d.PeriodStart.Format("02.01"),
+		PeriodEnd:        d.PeriodEnd.AddDate(0, 0, -1).Format("02.01"),
+		XPEarned:         d.XPEarned,
+		LessonsCompleted: d.LessonsCompleted,
+		TestsPassed:      d.TestsPassed,
+		CurrentStreak:    d.CurrentStreak,
+	}
+
+	return m.enqueue(ctx, entities.EmailTemplateWeeklyDigest, d.Email, d.Locale, data, digestTTL)
+}
+
+// enqueue кладёт письмо в очередь. Письмо живёт не дольше ttl: код или
+// ссылку в нём после этого всё равно не примут, а дайджест устареет.
+func (m *Mailer) enqueue(ctx context.Context, template, to string, locale entities.Locale, data any, ttl time.Duration) error {
+	if !locale.IsValid() {
+		locale = entities.DefaultLocale
+	}
+
+	msg, err := m.renderer.Render(template, locale, to, data)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl)
+	e := &entities.OutboxEmail{Template: template, Locale: locale, Message: msg, ExpiresAt: &expiresAt}
+	if err := m.outbox.Enqueue(ctx, e); err != nil {
+		return fmt.Errorf("failed to enqueue %s email: %w", template, err)
+	}
+	return nil
+}
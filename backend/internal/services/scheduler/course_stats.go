@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type CourseStatsRepository interface {
+	RefreshCourseStats(ctx context.Context, now time.Time) (int64, error)
+}
+
+// CourseStatsJob пересчитывает сводку по курсам, которую видят авторы.
+// Пересчёт целиком заменяет прошлую сводку, поэтому повторный запуск
+// безопасен.
+type CourseStatsJob struct {
+	repo CourseStatsRepository
+	now  func() time.Time
+}
+
+func NewCourseStatsJob(repo CourseStatsRepository) *CourseStatsJob {
+	return &CourseStatsJob{repo: repo, now: time.Now}
+}
+
+func (j *CourseStatsJob) Run(ctx context.Context) error {
+	n, err := j.repo.RefreshCourseStats(ctx, j.now().UTC())
+	if err != nil {
+		return fmt.Errorf("course stats: %w", err)
+	}
+
+	log.Printf("Course stats refreshed for %d courses", n)
+	return nil
+}
@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type statsRepo struct {
+	now time.Time
+	err error
+}
+
+func (r *statsRepo) RefreshCourseStats(_ context.Context, now time.Time) (int64, error) {
+	r.now = now
+	return 3, r.err
+}
+
+func TestCourseStatsJob(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 20, 0, 0, time.FixedZone("Almaty", 5*3600))
+	repo := &statsRepo{}
+	job := NewCourseStatsJob(repo)
+	job.now = func() time.Time { return now }
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !repo.now.Equal(now) || repo.now.Location() != time.UTC {
+		t.Errorf("RefreshCourseStats(now = %v); want %v in UTC", repo.now, now)
+	}
+
+	repo.err = errors.New("db down")
+	if err := job.Run(context.Background()); !errors.Is(err, repo.err) {
+		t.Errorf("Run() error = %v; want wrapped %v", err, repo.err)
+	}
+}
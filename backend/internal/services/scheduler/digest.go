@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/entities"
+)
+
+type DigestRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	GetWeeklyDigests(ctx context.Context, periodStart, periodEnd time.Time, afterUserID string, limit int) ([]entities.WeeklyDigest, error)
+	MarkDigestSent(ctx context.Context, userID string, periodStart time.Time) (bool, error)
+}
+
+// DigestMailer ставит письмо в email_outbox в транзакции вызывающего кода.
+type DigestMailer interface {
+	SendWeeklyDigest(ctx context.Context, digest *entities.WeeklyDigest) error
+}
+
+// digestPageSize — сколько дайджестов читается за раз.
+const digestPageSize = 200
+
+// DigestSender рассылает ученикам итоги прошедшей недели. Письма уходят
+// через email_outbox, а не по SMTP напрямую: задача только ставит их в
+// очередь.
+type DigestSender struct {
+	repo   DigestRepository
+	mailer DigestMailer
+	now    func() time.Time
+}
+
+func NewDigestSender(repo DigestRepository, mailer DigestMailer) *DigestSender {
+	return &DigestSender{repo: repo, mailer: mailer, now: time.Now}
+}
+
+// Run ставит в очередь дайджесты за прошедшую неделю лиг (с понедельника
+// по понедельник UTC). Каждое письмо ставится в одной транзакции с
+// отметкой в email_digests, поэтому повтор после сбоя и запуск на другом
+// экземпляре продолжат рассылку, не отправляя никому второе письмо.
+func (s *DigestSender) Run(ctx context.Context) error {
+	periodEnd := entities.WeekStart(s.now())
+	periodStart := periodEnd.AddDate(0, 0, -7)
+
+	queued := 0
+	afterUserID := ""
+	for {
+		page, err := s.repo.GetWeeklyDigests(ctx, periodStart, periodEnd, afterUserID, digestPageSize)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for i := range page {
+			ok, err := s.send(ctx, &page[i])
+			if err != nil {
+				return fmt.Errorf("weekly digest for user %s: %w", page[i].UserID, err)
+			}
+			if ok {
+				queued++
+			}
+		}
+		afterUserID = page[len(page)-1].UserID
+	}
+
+	if queued > 0 {
+		log.Printf("Weekly digests queued: %d for the week of %s", queued, periodStart.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// send возвращает false, если дайджест за эту неделю уже поставил
+// в очередь другой запуск.
+func (s *DigestSender) send(ctx context.Context, digest *entities.WeeklyDigest) (bool, error) {
+	queued := false
+	err := s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		marked, err := s.repo.MarkDigestSent(ctx, digest.UserID, digest.PeriodStart)
+		if err != nil || !marked {
+			return err
+		}
+		if err := s.mailer.SendWeeklyDigest(ctx, digest); err != nil {
+			return err
+		}
+		queued = true
+		return nil
+	})
+	return queued, err
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// digestRepo хранит отметки email_digests. Отметки, сделанные в
+// неудачной транзакции, откатываются.
+type digestRepo struct {
+	DigestRepository
+
+	users []string
+	// raced — ученики, которым дайджест поставил параллельный запуск уже
+	// после чтения страницы.
+	raced  map[string]bool
+	marked map[string]bool
+
+	periodStart, periodEnd time.Time
+	pages                  int
+}
+
+func (r *digestRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := maps.Clone(r.marked)
+	if err := fn(ctx); err != nil {
+		r.marked = saved
+		return err
+	}
+	return nil
+}
+
+func (r *digestRepo) GetWeeklyDigests(_ context.Context, periodStart, periodEnd time.Time, afterUserID string, limit int) ([]entities.WeeklyDigest, error) {
+	r.periodStart, r.periodEnd = periodStart, periodEnd
+	r.pages++
+	var page []entities.WeeklyDigest
+	for _, id := range r.users {
+		if id <= afterUserID || r.marked[id] || len(page) == limit {
+			continue
+		}
+		page = append(page, entities.WeeklyDigest{UserID: id, PeriodStart: periodStart, PeriodEnd: periodEnd})
+	}
+	return page, nil
+}
+
+func (r *digestRepo) MarkDigestSent(_ context.Context, userID string, _ time.Time) (bool, error) {
+	if r.marked[userID] || r.raced[userID] {
+		return false, nil
+	}
+	r.marked[userID] = true
+	return true, nil
+}
+
+type digestMailer struct {
+	sent   []string
+	failOn string
+}
+
+func (m *digestMailer) SendWeeklyDigest(_ context.Context, digest *entities.WeeklyDigest) error {
+	if digest.UserID == m.failOn {
+		return errors.New("smtp down")
+	}
+	m.sent = append(m.sent, digest.UserID)
+	return nil
+}
+
+func newDigestSender(repo *digestRepo, mailer *digestMailer) *DigestSender {
+	s := NewDigestSender(repo, mailer)
+	// среда, 14 октября 2026
+	s.now = func() time.Time { return time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC) }
+	return s
+}
+
+func TestDigestSenderQueuesPreviousWeek(t *testing.T) {
+	repo := &digestRepo{users: users("u", 2*digestPageSize+50), marked: map[string]bool{}}
+	mailer := &digestMailer{}
+
+	if err := newDigestSender(repo, mailer).Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	wantStart := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	if !repo.periodStart.Equal(wantStart) || !repo.periodEnd.Equal(wantEnd) {
+		t.Errorf("period = [%v, %v); want [%v, %v)", repo.periodStart, repo.periodEnd, wantStart, wantEnd)
+	}
+	if len(mailer.sent) != len(repo.users) {
+		t.Errorf("sent %d digests; want %d", len(mailer.sent), len(repo.users))
+	}
+	if len(repo.marked) != len(repo.users) {
+		t.Errorf("marked %d users; want %d", len(repo.marked), len(repo.users))
+	}
+	if repo.pages != 4 {
+		t.Errorf("read %d pages; want 4", repo.pages)
+	}
+}
+
+func TestDigestSenderSkipsDigestQueuedByAnotherRun(t *testing.T) {
+	repo := &digestRepo{
+		users:  []string{"u1", "u2", "u3"},
+		raced:  map[string]bool{"u2": true},
+		marked: map[string]bool{},
+	}
+	mailer := &digestMailer{}
+
+	if err := newDigestSender(repo, mailer).Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(mailer.sent) != 2 || mailer.sent[0] != "u1" || mailer.sent[1] != "u3" {
+		t.Errorf("sent = %v; want [u1 u3]", mailer.sent)
+	}
+}
+
+func TestDigestSenderMailerErrorLeavesUserUnmarked(t *testing.T) {
+	repo := &digestRepo{users: []string{"u1", "u2", "u3"}, marked: map[string]bool{}}
+	mailer := &digestMailer{failOn: "u2"}
+	sender := newDigestSender(repo, mailer)
+
+	err := sender.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() error = nil; want mailer error")
+	}
+	if !repo.marked["u1"] || repo.marked["u2"] || repo.marked["u3"] {
+		t.Errorf("marked = %v; want only u1", repo.marked)
+	}
+
+	// повтор задачи досылает оставшимся и не шлёт u1 второе письмо
+	mailer.failOn = ""
+	if err := sender.Run(context.Background()); err != nil {
+		t.Fatalf("retry Run() error = %v", err)
+	}
+	if len(mailer.sent) != 3 {
+		t.Errorf("sent = %v; want each user once", mailer.sent)
+	}
+}
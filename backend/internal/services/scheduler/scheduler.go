@@ -0,0 +1,341 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"backend/internal/entities"
+	"backend/pkg/cron"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultJobTimeout = 10 * time.Minute
+	defaultRetryDelay = time.Minute
+
+	// runRetention — сколько хранится история запусков.
+	runRetention = 30 * 24 * time.Hour
+)
+
+// ErrSkipped возвращает задача, которая ничего не сделала, потому что ту же
+// работу уже выполняет другой экземпляр. Запуск записывается как skipped
+// и не повторяется.
+var ErrSkipped = errors.New("job skipped")
+
+type JobRunStore interface {
+	ClaimRun(ctx context.Context, run *entities.JobRun, lease time.Duration) (bool, error)
+	RenewRun(ctx context.Context, id string, attempts int, lastError string, lease time.Duration) error
+	FinishRun(ctx context.Context, id string, status entities.JobRunStatus, lastError string) error
+	GetLatestRuns(ctx context.Context) ([]entities.JobRun, error)
+	GetRuns(ctx context.Context, jobName string, limit int) ([]entities.JobRun, error)
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Job — периодическая задача. Schedule — выражение crontab, которое
+// вычисляется в UTC.
+type Job struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+
+	// Timeout ограничивает одну попытку; на это же время запуск
+	// арендуется в job_runs. По умолчанию 10 минут.
+	Timeout time.Duration
+	// Retries — сколько раз повторить попытку после ошибки. Задержка
+	// перед повтором удваивается, начиная с RetryDelay (по умолчанию 1m).
+	Retries    int
+	RetryDelay time.Duration
+	// CatchUp — при старте сразу выполнить слот, пропущенный, пока
+	// backend не работал.
+	CatchUp bool
+}
+
+// JobStatus — задача с ближайшим запуском и последним выполненным.
+type JobStatus struct {
+	Name      string
+	Schedule  string
+	NextRunAt time.Time
+	LastRun   *entities.JobRun
+}
+
+type scheduledJob struct {
+	Job
+	schedule *cron.Schedule
+}
+
+// Scheduler выполняет задачи по расписанию и пишет каждый запуск в
+// job_runs. Несколько экземпляров backend могут работать одновременно:
+// слот расписания выполняет тот, кто первым его занял, а новый запуск
+// задачи не начинается, пока идёт предыдущий.
+type Scheduler struct {
+	store    JobRunStore
+	instance string
+	jobs     []*scheduledJob
+
+	// ctx отменяется, если Stop не дождался завершения задач.
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(store JobRunStore) *Scheduler {
+	host, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		store:    store,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Register добавляет задачу; вызывается до Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job name and run func are required")
+	}
+	for _, j := range s.jobs {
+		if j.Name == job.Name {
+			return fmt.Errorf("job %q is already registered", job.Name)
+		}
+	}
+
+	schedule, err := cron.Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %q: %w", job.Name, err)
+	}
+
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+	if job.RetryDelay <= 0 {
+		job.RetryDelay = defaultRetryDelay
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{Job: job, schedule: schedule})
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	log.Printf("Job scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop перестаёт запускать задачи и ждёт текущие. Если ctx истекает
+// раньше, задачи получают отмену контекста.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	close(s.stop)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(j *scheduledJob) {
+	defer s.wg.Done()
+
+	next := s.firstSlot(j)
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.execute(j, next)
+
+		// Слоты, пропущенные за время долгого запуска, не догоняются
+		next = j.schedule.Next(time.Now().UTC())
+	}
+
+	log.Printf("Job %s: schedule %q never fires again", j.Name, j.Schedule)
+}
+
+// firstSlot возвращает первый слот после старта. С CatchUp это слот,
+// пропущенный с последнего запуска, — он выполняется сразу.
+func (s *Scheduler) firstSlot(j *scheduledJob) time.Time {
+	now := time.Now().UTC()
+	if !j.CatchUp {
+		return j.schedule.Next(now)
+	}
+
+	runs, err := s.store.GetRuns(s.ctx, j.Name, 1)
+	if err != nil {
+		log.Printf("Job %s: failed to read last run, skipping catch-up: %v", j.Name, err)
+		return j.schedule.Next(now)
+	}
+
+	if len(runs) == 0 {
+		return now.Truncate(time.Minute)
+	}
+	if missed := j.schedule.Next(runs[0].ScheduledAt.UTC()); !missed.After(now) {
+		return missed
+	}
+	return j.schedule.Next(now)
+}
+
+// execute занимает слот и выполняет задачу с повторами.
+func (s *Scheduler) execute(j *scheduledJob, slot time.Time) {
+	run := entities.JobRun{
+		ID:          uuid.NewString(),
+		JobName:     j.Name,
+		ScheduledAt: slot,
+		Instance:    s.instance,
+	}
+
+	claimed, err := s.store.ClaimRun(s.ctx, &run, j.Timeout)
+	if err != nil {
+		log.Printf("Job %s: failed to claim run: %v", j.Name, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	var runErr error
+	for attempt := 1; ; attempt++ {
+		runErr = s.attempt(j)
+		if runErr == nil || errors.Is(runErr, ErrSkipped) || attempt > j.Retries {
+			break
+		}
+
+		delay := j.RetryDelay << (attempt - 1)
+		log.Printf("Job %s: attempt %d failed, retrying in %s: %v", j.Name, attempt, delay, runErr)
+
+		// Аренда покрывает ожидание и следующую попытку
+		if err := s.store.RenewRun(s.ctx, run.ID, attempt+1, runErr.Error(), delay+j.Timeout); err != nil {
+			logStoreError(j.Name, "renew run", err)
+		}
+
+		select {
+		case <-s.stop:
+			runErr = fmt.Errorf("scheduler stopped before retry: %w", runErr)
+		case <-time.After(delay):
+			continue
+		}
+		break
+	}
+
+	status, lastError := entities.JobRunSucceeded, ""
+	switch {
+	case errors.Is(runErr, ErrSkipped):
+		status, lastError = entities.JobRunSkipped, runErr.Error()
+		log.Printf("Job %s skipped: %v", j.Name, runErr)
+	case runErr != nil:
+		status, lastError = entities.JobRunFailed, runErr.Error()
+		log.Printf("Job %s failed: %v", j.Name, runErr)
+	}
+
+	// Запуск записывается даже после отмены s.ctx, иначе он повиснет
+	// до конца аренды
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 10*time.Second)
+	defer cancel()
+	if err := s.store.FinishRun(ctx, run.ID, status, lastError); err != nil {
+		logStoreError(j.Name, "record run result", err)
+	}
+}
+
+// logStoreError пишет ошибку записи запуска. ErrNotFound значит, что
+// аренда истекла и запуск уже завершён другим экземпляром.
+func logStoreError(jobName, action string, err error) {
+	if errors.Is(err, entities.ErrNotFound) {
+		log.Printf("Job %s: run lease expired, cannot %s", jobName, action)
+		return
+	}
+	log.Printf("Job %s: failed to %s: %v", jobName, action, err)
+}
+
+// attempt выполняет одну попытку; паника считается ошибкой попытки.
+func (s *Scheduler) attempt(j *scheduledJob) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, j.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			log.Printf("Job %s panicked: %v\n%s", j.Name, r, debug.Stack())
+		}
+	}()
+
+	return j.Run(ctx)
+}
+
+// Jobs возвращает зарегистрированные задачи с ближайшим и последним
+// запусками.
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobStatus, error) {
+	latest, err := s.store.GetLatestRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]entities.JobRun, len(latest))
+	for _, r := range latest {
+		byName[r.JobName] = r
+	}
+
+	now := time.Now().UTC()
+	list := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := JobStatus{
+			Name:      j.Name,
+			Schedule:  j.Schedule,
+			NextRunAt: j.schedule.Next(now),
+		}
+		if r, ok := byName[j.Name]; ok {
+			st.LastRun = &r
+		}
+		list = append(list, st)
+	}
+
+	return list, nil
+}
+
+// Runs возвращает последние limit запусков задачи name или
+// entities.ErrNotFound, если такой задачи нет.
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]entities.JobRun, error) {
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return s.store.GetRuns(ctx, name, limit)
+		}
+	}
+	return nil, entities.ErrNotFound
+}
+
+// PruneRuns удаляет историю запусков старше runRetention. Регистрируется
+// как обычная задача.
+func (s *Scheduler) PruneRuns(ctx context.Context) error {
+	n, err := s.store.DeleteRunsBefore(ctx, time.Now().UTC().Add(-runRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("Removed %d old job runs", n)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// fakeRunStore хранит запуски в памяти: последний запуск задачи для
+// догоняющего слота и записи RenewRun/FinishRun.
+type fakeRunStore struct {
+	JobRunStore
+
+	mu       sync.Mutex
+	last     *entities.JobRun
+	claimed  bool
+	renewed  []int
+	finished []entities.JobRunStatus
+	errors   []string
+}
+
+func (f *fakeRunStore) GetRuns(context.Context, string, int) ([]entities.JobRun, error) {
+	if f.last == nil {
+		return nil, nil
+	}
+	return []entities.JobRun{*f.last}, nil
+}
+
+func (f *fakeRunStore) ClaimRun(_ context.Context, run *entities.JobRun, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.claimed {
+		return false, nil
+	}
+	f.claimed = true
+	run.Status, run.Attempts = entities.JobRunRunning, 1
+	return true, nil
+}
+
+func (f *fakeRunStore) RenewRun(_ context.Context, _ string, attempts int, _ string, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renewed = append(f.renewed, attempts)
+	return nil
+}
+
+func (f *fakeRunStore) FinishRun(_ context.Context, _ string, status entities.JobRunStatus, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finished = append(f.finished, status)
+	f.errors = append(f.errors, lastError)
+	return nil
+}
+
+func TestFirstSlot(t *testing.T) {
+	// Слоты считаются от часа начала теста; если во время теста начался
+	// новый час или минута, подходит и ответ для нового момента
+	start := time.Now().UTC()
+	hour := start.Truncate(time.Hour)
+
+	tests := []struct {
+		name    string
+		catchUp bool
+		last    *time.Time
+		want    func(now time.Time) time.Time
+	}{
+		{"no catch-up", false, ptr(hour.Add(-48 * time.Hour)), nextHour},
+		{"never ran", true, nil, func(now time.Time) time.Time { return now.Truncate(time.Minute) }},
+		{"missed slot runs now", true, ptr(hour.Add(-3 * time.Hour)), func(time.Time) time.Time { return hour.Add(-2 * time.Hour) }},
+		{"nothing missed", true, ptr(hour), nextHour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRunStore{}
+			if tt.last != nil {
+				store.last = &entities.JobRun{ScheduledAt: *tt.last}
+			}
+			s := NewScheduler(store)
+			job := Job{Name: "hourly", Schedule: "0 * * * *", Run: noop, CatchUp: tt.catchUp}
+			if err := s.Register(job); err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+
+			before := time.Now().UTC()
+			got := s.firstSlot(s.jobs[0])
+			after := time.Now().UTC()
+			if !got.Equal(tt.want(before)) && !got.Equal(tt.want(after)) {
+				t.Errorf("firstSlot() = %v, want %v", got, tt.want(before))
+			}
+		})
+	}
+}
+
+func nextHour(now time.Time) time.Time {
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
+
+func TestExecuteRetries(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name        string
+		retries     int
+		results     []error
+		wantCalls   int
+		wantRenewed []int
+		wantStatus  entities.JobRunStatus
+		wantError   string
+	}{
+		{"succeeds", 2, []error{nil}, 1, nil, entities.JobRunSucceeded, ""},
+		{"succeeds after retries", 2, []error{errBoom, errBoom, nil}, 3, []int{2, 3}, entities.JobRunSucceeded, ""},
+		{"gives up", 2, []error{errBoom, errBoom, errBoom}, 3, []int{2, 3}, entities.JobRunFailed, "boom"},
+		{"no retries", 0, []error{errBoom}, 1, nil, entities.JobRunFailed, "boom"},
+		{"panic is a failure", 0, nil, 1, nil, entities.JobRunFailed, "panic: out of results"},
+		{
+			"skipped is not retried", 2,
+			[]error{fmt.Errorf("reset: %w: locked elsewhere", ErrSkipped)},
+			1, nil, entities.JobRunSkipped, "reset: job skipped: locked elsewhere",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRunStore{}
+			s := NewScheduler(store)
+
+			calls := 0
+			job := Job{
+				Name:     "job",
+				Schedule: "* * * * *",
+				Run: func(context.Context) error {
+					calls++
+					if calls > len(tt.results) {
+						panic("out of results")
+					}
+					return tt.results[calls-1]
+				},
+				Retries:    tt.retries,
+				RetryDelay: time.Millisecond,
+			}
+			if err := s.Register(job); err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+
+			s.execute(s.jobs[0], time.Now().UTC().Truncate(time.Minute))
+
+			if calls != tt.wantCalls {
+				t.Errorf("job ran %d times, want %d", calls, tt.wantCalls)
+			}
+			if fmt.Sprint(store.renewed) != fmt.Sprint(tt.wantRenewed) {
+				t.Errorf("renewed with attempts %v, want %v", store.renewed, tt.wantRenewed)
+			}
+			if len(store.finished) != 1 || store.finished[0] != tt.wantStatus || store.errors[0] != tt.wantError {
+				t.Errorf("finished %v %q, want [%s] %q", store.finished, store.errors, tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestExecuteSkipsClaimedSlot(t *testing.T) {
+	store := &fakeRunStore{claimed: true}
+	s := NewScheduler(store)
+	ran := false
+	job := Job{Name: "job", Schedule: "* * * * *", Run: func(context.Context) error { ran = true; return nil }}
+	if err := s.Register(job); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	s.execute(s.jobs[0], time.Now().UTC().Truncate(time.Minute))
+
+	if ran || len(store.finished) != 0 {
+		t.Errorf("slot taken by another instance: ran = %v, finished = %v", ran, store.finished)
+	}
+}
+
+func TestRegisterValidates(t *testing.T) {
+	s := NewScheduler(&fakeRunStore{})
+	if err := s.Register(Job{Name: "a", Schedule: "@hourly", Run: noop}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	for name, job := range map[string]Job{
+		"duplicate":    {Name: "a", Schedule: "@hourly", Run: noop},
+		"bad schedule": {Name: "b", Schedule: "61 * * * *", Run: noop},
+		"no run func":  {Name: "c", Schedule: "@hourly"},
+	} {
+		if err := s.Register(job); err == nil {
+			t.Errorf("%s: Register() = nil, want error", name)
+		}
+	}
+	if j := s.jobs[0]; j.Timeout != defaultJobTimeout || j.RetryDelay != defaultRetryDelay {
+		t.Errorf("defaults: timeout %v, retry delay %v", j.Timeout, j.RetryDelay)
+	}
+}
+
+func noop(context.Context) error { return nil }
+
+func ptr[T any](v T) *T { return &v }
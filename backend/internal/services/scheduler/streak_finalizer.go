@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/entities"
+)
+
+type StreakRepository interface {
+	FinalizeStreaks(ctx context.Context, now time.Time, userID string) (entities.StreakFinalization, error)
+}
+
+// StreakFinalizer закрывает пропущенные дни серий: тратит заморозки или
+// сбрасывает серию. Ученики живут в разных часовых поясах, поэтому «вчера»
+// наступает для них в разное время, и финализатор запускается чаще раза
+// в сутки. Повторный запуск безопасен.
+type StreakFinalizer struct {
+	repo StreakRepository
+}
+
+func NewStreakFinalizer(repo StreakRepository) *StreakFinalizer {
+	return &StreakFinalizer{repo: repo}
+}
+
+func (f *StreakFinalizer) Run(ctx context.Context) error {
+	res, err := f.repo.FinalizeStreaks(ctx, time.Now().UTC(), "")
+	if err != nil {
+		return fmt.Errorf("finalize streaks: %w", err)
+	}
+
+	if res.Kept > 0 || res.Reset > 0 {
+		log.Printf("Streaks finalized: %d kept by %d freezes, %d reset", res.Kept, res.FreezesUsed, res.Reset)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -11,173 +12,435 @@ import (
 )
 
 type ProfileRepository interface {
-	GetLeaderboard(ctx context.Context, limit int) ([]*entities.StudentProfile, error)
-	GetLeagueLeaderboard(ctx context.Context, leagueID int, limit int) ([]*entities.StudentProfile, error)
-	Update(ctx context.Context, profile *entities.StudentProfile) error
+	GetLeagueCohortIDs(ctx context.Context, leagueID int) ([]string, error)
+	GetLeagueMembersByActivity(ctx context.Context, leagueID int) ([]string, error)
+	CountLeagueMembers(ctx context.Context, leagueID int, cohortID string) (active, inactive int, err error)
+	GetLeagueStandingsPage(ctx context.Context, leagueID int, cohortID string, after *entities.LeagueStanding, limit int) ([]entities.LeagueStanding, error)
+	GetInactiveLeagueMembersPage(ctx context.Context, leagueID int, cohortID string, afterUserID string, limit int) ([]string, error)
+	SetLeague(ctx context.Context, userIDs []string, leagueID int) error
 	ResetAllWeeklyXP(ctx context.Context) error
 }
 
 type GamificationRepository interface {
-	SaveHistorySnapshot(ctx context.Context, h *entities.LeaderboardHistory) error
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	TryLockWeeklyReset(ctx context.Context) (bool, error)
+	SaveHistorySnapshots(ctx context.Context, list []entities.LeaderboardHistory) error
 	GetAllLeagues(ctx context.Context) ([]entities.League, error)
 	GetLastResetDate(ctx context.Context) (time.Time, error)
 	SetLastResetDate(ctx context.Context, date time.Time) error
 }
 
+// CohortRepository хранит недельные когорты лиг.
+type CohortRepository interface {
+	CreateCohorts(ctx context.Context, leagueID int, weekStart time.Time, count int) ([]entities.LeagueCohort, error)
+	AssignCohorts(ctx context.Context, userIDs, cohortIDs []string) error
+	LockLeague(ctx context.Context, leagueID int) error
+}
+
+// EventPublisher сохраняет события о повышении в лиге в outbox
+// в транзакции сброса.
+type EventPublisher interface {
+	PublishDurable(ctx context.Context, event entities.DomainEvent) error
+}
+
 type WeeklyResetService struct {
 	profileRepo      ProfileRepository
 	gamificationRepo GamificationRepository
+	cohortRepo       CohortRepository
+	events           EventPublisher
 }
 
-func NewWeeklyResetService(pRepo ProfileRepository, gRepo GamificationRepository) *WeeklyResetService {
+func NewWeeklyResetService(
+	pRepo ProfileRepository,
+	gRepo GamificationRepository,
+	cRepo CohortRepository,
+	events EventPublisher,
+) *WeeklyResetService {
 	return &WeeklyResetService{
 		profileRepo:      pRepo,
 		gamificationRepo: gRepo,
+		cohortRepo:       cRepo,
+		events:           events,
 	}
 }
 
-func (s *WeeklyResetService) Start(ctx context.Context) {
-	ticker := time.NewTicker(30 * time.Minute) // Проверяем каждые 30 мин
-	defer ticker.Stop()
+// standingsPageSize — сколько учеников лиги читается и сохраняется за раз.
+const standingsPageSize = 500
+
+// CheckAndRunReset выполняет сброс, если он ещё не сделан за текущую неделю.
+// Снимок таблиц, переходы между лигами, обнуление weekly_xp и отметка
+// о сбросе пишутся в одной транзакции под advisory-блокировкой: второй
+// экземпляр backend пропустит сброс и вернёт ErrSkipped, а сбой посередине
+// откатит всё, и сброс повторится при следующем запуске задачи. Неделя,
+// за которую сброс уже сделан, определяется по отметке в system_settings,
+// поэтому лишние запуски ничего не меняют.
+func (s *WeeklyResetService) CheckAndRunReset(ctx context.Context) error {
+	err := s.gamificationRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := s.gamificationRepo.TryLockWeeklyReset(ctx)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return fmt.Errorf("%w: weekly reset is running on another instance", ErrSkipped)
+		}
+
+		// Дата последнего сброса читается под блокировкой, поэтому сброс,
+		// только что завершённый другим экземпляром, не повторится
+		plan, err := s.newPlan(ctx, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if !plan.Due {
+			return nil
+		}
+
+		log.Println("New week detected! Starting weekly reset...")
+		return s.applyPlan(ctx, plan)
+	})
+	if err != nil {
+		return fmt.Errorf("weekly reset: %w", err)
+	}
+	return nil
+}
 
-	log.Println("Weekly reset scheduler started")
+// Preview подводит итоги недели на текущий момент, ничего не меняя.
+func (s *WeeklyResetService) Preview(ctx context.Context) (*entities.WeeklyResetPlan, error) {
+	plan, err := s.newPlan(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
 
-	// 1. Проверяем сразу при запуске (вдруг сервер лежал во время сброса)
-	s.CheckAndRunReset(ctx)
+	leagues, err := s.gamificationRepo.GetAllLeagues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get leagues: %w", err)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			// 2. И периодически проверяем
-			s.CheckAndRunReset(ctx)
+	for i := range leagues {
+		lp, err := s.rankLeague(ctx, leagues, i, func([]entities.LeagueMove) error { return nil })
+		if err != nil {
+			return nil, err
+		}
+		if lp.Active+lp.Inactive > 0 {
+			plan.Leagues = append(plan.Leagues, *lp)
 		}
 	}
-}
 
-func (s *WeeklyResetService) CheckAndRunReset(ctx context.Context) {
-	now := time.Now().UTC()
+	return plan, nil
+}
 
-	// Вычисляем начало текущей недели (Понедельник 00:00:00)
-	// Это наша "Целевая точка сброса"
-	currentWeekMonday := getMondayStart(now)
+// newPlan определяет неделю, за которую нужен сброс, и пора ли его выполнять.
+func (s *WeeklyResetService) newPlan(ctx context.Context, now time.Time) (*entities.WeeklyResetPlan, error) {
+	// Начало текущей недели (понедельник 00:00:00) — целевая точка сброса
+	currentWeekMonday := entities.WeekStart(now)
 
-	// Получаем дату последнего успешного сброса из БД
 	lastReset, err := s.gamificationRepo.GetLastResetDate(ctx)
 	if err != nil {
-		log.Printf("Error getting last reset date: %v", err)
-		return
+		return nil, fmt.Errorf("get last reset date: %w", err)
 	}
 
-	// ЛОГИКА:
-	// Если последний сброс был РАНЬШЕ, чем текущий понедельник -> Пора сбрасывать!
-	if lastReset.Before(currentWeekMonday) {
-		log.Println("New week detected! Starting weekly reset...")
-
-		// Выполняем сброс
-		s.performWeeklyReset(ctx)
+	return &entities.WeeklyResetPlan{
+		WeekStart:   currentWeekMonday,
+		LastReset:   lastReset,
+		Due:         lastReset.Before(currentWeekMonday),
+		PeriodStart: currentWeekMonday.AddDate(0, 0, -7),
+		PeriodEnd:   currentWeekMonday,
+	}, nil
+}
 
-		// Записываем в БД, что мы выполнили сброс для ЭТОГО понедельника
-		if err := s.gamificationRepo.SetLastResetDate(ctx, currentWeekMonday); err != nil {
-			log.Printf("Failed to update reset date: %v", err)
-		}
+// leagueZones возвращает размеры зон повышения и понижения. Проценты
+// берутся от всей лиги вместе с неактивными, которые стоят в таблице ниже
+// всех активных; повышаются только активные. Непустая зона — минимум один
+// ученик; зоны не пересекаются, при нехватке учеников приоритет у повышения.
+func leagueZones(league entities.League, active, inactive int, hasUpper, hasLower bool) (promote, demote int) {
+	total := active + inactive
+	if hasUpper {
+		promote = min(zoneSize(total, league.PromotePercent), active)
+	}
+	if hasLower {
+		demote = min(zoneSize(total, league.DemotePercent), total-promote)
 	}
+	return promote, demote
 }
 
-func getMondayStart(t time.Time) time.Time {
-	weekday := t.Weekday()
+func zoneSize(n, percent int) int {
+	if n <= 0 || percent <= 0 {
+		return 0
+	}
+	return max(n*percent/100, 1)
+}
 
-	daysToSubtract := int(weekday) - int(time.Monday)
-	if daysToSubtract < 0 {
-		daysToSubtract += 7
+// rankLeague подводит итоги недели лиги leagues[idx]: каждая когорта лиги
+// (и ученики без когорты) ранжируется отдельно. Соседние лиги берутся
+// по order_index. Каждая страница итогов передаётся в visit.
+func (s *WeeklyResetService) rankLeague(
+	ctx context.Context,
+	leagues []entities.League,
+	idx int,
+	visit func(page []entities.LeagueMove) error,
+) (*entities.LeagueResetPlan, error) {
+	cohortIDs, err := s.profileRepo.GetLeagueCohortIDs(ctx, leagues[idx].ID)
+	if err != nil {
+		return nil, err
 	}
 
-	year, month, day := t.AddDate(0, 0, -daysToSubtract).Date()
-	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	lp := &entities.LeagueResetPlan{LeagueID: leagues[idx].ID, Cohorts: len(cohortIDs)}
+	for _, cohortID := range cohortIDs {
+		if err := s.rankCohort(ctx, leagues, idx, cohortID, lp, visit); err != nil {
+			return nil, err
+		}
+	}
+	return lp, nil
 }
 
-func (s *WeeklyResetService) performWeeklyReset(ctx context.Context) {
-	now := time.Now().UTC()
-	periodEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	periodStart := periodEnd.AddDate(0, 0, -7)
-
-	leagues, err := s.gamificationRepo.GetAllLeagues(ctx)
+// rankCohort проходит всех учеников когорты страницами в порядке недельной
+// таблицы: сначала активных, затем неактивных — и решает, кто переходит
+// в соседнюю лигу. Итоги добавляются в lp.
+func (s *WeeklyResetService) rankCohort(
+	ctx context.Context,
+	leagues []entities.League,
+	idx int,
+	cohortID string,
+	lp *entities.LeagueResetPlan,
+	visit func(page []entities.LeagueMove) error,
+) error {
+	league := leagues[idx]
+	hasUpper, hasLower := idx < len(leagues)-1, idx > 0
+
+	active, inactive, err := s.profileRepo.CountLeagueMembers(ctx, league.ID, cohortID)
 	if err != nil {
-		log.Printf("Failed to get leagues: %v", err)
-		return
+		return err
+	}
+	lp.Active += active
+	lp.Inactive += inactive
+	promote, demote := leagueZones(league, active, inactive, hasUpper, hasLower)
+	// Места с demoteFrom и ниже — зона понижения
+	demoteFrom := active + inactive - demote + 1
+
+	record := func(page []entities.LeagueMove) error {
+		for _, m := range page {
+			switch m.Outcome {
+			case entities.LeagueOutcomePromoted:
+				lp.Promoted = append(lp.Promoted, m)
+			case entities.LeagueOutcomeDemoted:
+				lp.Demoted = append(lp.Demoted, m)
+			}
+		}
+		return visit(page)
 	}
 
-	for _, league := range leagues {
-		profiles, err := s.profileRepo.GetLeagueLeaderboard(ctx, league.ID, 100)
+	rank := 0
+	var after *entities.LeagueStanding
+	for {
+		standings, err := s.profileRepo.GetLeagueStandingsPage(ctx, league.ID, cohortID, after, standingsPageSize)
 		if err != nil {
-			log.Printf("Failed to get leaderboard for league %d: %v", league.ID, err)
-			continue
+			return err
 		}
-
-		if len(profiles) == 0 {
-			log.Printf("No profiles in league %d", league.ID)
-			continue
+		if len(standings) == 0 {
+			break
 		}
 
-		for rank, profile := range profiles {
-			history := &entities.LeaderboardHistory{
-				ID:          uuid.NewString(),
-				PeriodStart: periodStart,
-				PeriodEnd:   periodEnd,
-				UserID:      profile.UserID,
-				LeagueID:    league.ID,
-				Rank:        rank + 1,
-				TotalXP:     profile.WeeklyXP,
-				CreatedAt:   now,
+		page := make([]entities.LeagueMove, 0, len(standings))
+		for _, st := range standings {
+			rank++
+			move := entities.LeagueMove{
+				UserID:       st.UserID,
+				CohortID:     cohortID,
+				Rank:         rank,
+				WeeklyXP:     st.WeeklyXP,
+				FromLeagueID: league.ID,
+				ToLeagueID:   league.ID,
+				Outcome:      entities.LeagueOutcomeStayed,
 			}
-			if err := s.gamificationRepo.SaveHistorySnapshot(ctx, history); err != nil {
-				log.Printf("Failed to save history snapshot: %v", err)
+			if rank <= promote {
+				move.ToLeagueID, move.Outcome = leagues[idx+1].ID, entities.LeagueOutcomePromoted
+			} else if rank >= demoteFrom {
+				move.ToLeagueID, move.Outcome = leagues[idx-1].ID, entities.LeagueOutcomeDemoted
 			}
+			page = append(page, move)
+		}
+		if err := record(page); err != nil {
+			return err
 		}
 
-		s.updateLeagues(ctx, profiles, league.ID, len(leagues))
+		last := standings[len(standings)-1]
+		after = &last
 	}
 
-	if err := s.profileRepo.ResetAllWeeklyXP(ctx); err != nil {
-		log.Printf("Failed to reset weekly XP: %v", err)
+	// Неактивные делят место после последнего активного. Зона понижения
+	// заполняется ими по порядку страниц (по user_id), если demote_inactive
+	// не понижает их всех
+	inactiveRank := rank + 1
+	position := rank
+	afterUserID := ""
+	for {
+		userIDs, err := s.profileRepo.GetInactiveLeagueMembersPage(ctx, league.ID, cohortID, afterUserID, standingsPageSize)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		page := make([]entities.LeagueMove, 0, len(userIDs))
+		for _, userID := range userIDs {
+			position++
+			move := entities.LeagueMove{
+				UserID:       userID,
+				CohortID:     cohortID,
+				Rank:         inactiveRank,
+				FromLeagueID: league.ID,
+				ToLeagueID:   league.ID,
+				Outcome:      entities.LeagueOutcomeStayed,
+			}
+			if hasLower && (league.DemoteInactive || position >= demoteFrom) {
+				move.ToLeagueID, move.Outcome = leagues[idx-1].ID, entities.LeagueOutcomeDemoted
+			}
+			page = append(page, move)
+		}
+		if err := record(page); err != nil {
+			return err
+		}
+
+		afterUserID = userIDs[len(userIDs)-1]
 	}
 
-	log.Println("Weekly reset completed successfully!")
+	return nil
 }
 
-func (s *WeeklyResetService) updateLeagues(ctx context.Context, profiles []*entities.StudentProfile, currentLeagueID, totalLeagues int) {
-	if len(profiles) == 0 {
-		return
+// formCohorts делит учеников каждой лиги на когорты новой недели. Ученики
+// упорядочены по XP прошедшей недели, поэтому в когорту попадают ученики
+// с похожей активностью. Вызывается после переходов между лигами, но до
+// обнуления weekly_xp. Блокировка лиги не даёт ученику, который проходит
+// онбординг одновременно со сбросом, остаться в когорте прошлой недели.
+func (s *WeeklyResetService) formCohorts(ctx context.Context, leagues []entities.League, weekStart time.Time) error {
+	for _, league := range leagues {
+		if err := s.cohortRepo.LockLeague(ctx, league.ID); err != nil {
+			return err
+		}
+		userIDs, err := s.profileRepo.GetLeagueMembersByActivity(ctx, league.ID)
+		if err != nil {
+			return err
+		}
+
+		sizes := entities.SplitIntoCohorts(len(userIDs))
+		if len(sizes) == 0 {
+			continue
+		}
+
+		cohorts, err := s.cohortRepo.CreateCohorts(ctx, league.ID, weekStart, len(sizes))
+		if err != nil {
+			return err
+		}
+
+		cohortIDs := make([]string, 0, len(userIDs))
+		for i, size := range sizes {
+			for range size {
+				cohortIDs = append(cohortIDs, cohorts[i].ID)
+			}
+		}
+		if err := s.cohortRepo.AssignCohorts(ctx, userIDs, cohortIDs); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	promoteCount := len(profiles) / 5
-	if promoteCount < 1 {
-		promoteCount = 1
+// applyPlan подводит итоги недели и записывает их. Вызывается в транзакции
+// сброса: любая ошибка откатывает весь сброс. Снимки сохраняются по мере
+// обхода, а переходы — после обхода всех лиг, чтобы перемещённые ученики
+// не попали в таблицу соседней лиги второй раз. Затем формируются когорты
+// новой недели.
+func (s *WeeklyResetService) applyPlan(ctx context.Context, plan *entities.WeeklyResetPlan) error {
+	leagues, err := s.gamificationRepo.GetAllLeagues(ctx)
+	if err != nil {
+		return fmt.Errorf("get leagues: %w", err)
 	}
 
-	demoteCount := len(profiles) / 5
-	if demoteCount < 1 {
-		demoteCount = 1
+	leagueOrder := make(map[int]int, len(leagues))
+	for _, league := range leagues {
+		leagueOrder[league.ID] = league.OrderIndex
 	}
 
-	for i, profile := range profiles {
-		shouldUpdate := false
+	now := time.Now().UTC()
+	var moves []entities.LeagueMove
+	for i := range leagues {
+		_, err := s.rankLeague(ctx, leagues, i, func(page []entities.LeagueMove) error {
+			snapshots := make([]entities.LeaderboardHistory, 0, len(page))
+			for _, m := range page {
+				snapshots = append(snapshots, entities.LeaderboardHistory{
+					ID:          uuid.NewString(),
+					PeriodStart: plan.PeriodStart,
+					PeriodEnd:   plan.PeriodEnd,
+					UserID:      m.UserID,
+					LeagueID:    m.FromLeagueID,
+					CohortID:    m.CohortID,
+					Rank:        m.Rank,
+					TotalXP:     m.WeeklyXP,
+					Outcome:     m.Outcome,
+					ToLeagueID:  m.ToLeagueID,
+					CreatedAt:   now,
+				})
+				if m.ToLeagueID != m.FromLeagueID {
+					moves = append(moves, m)
+				}
+			}
+			return s.gamificationRepo.SaveHistorySnapshots(ctx, snapshots)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-		if i < promoteCount && currentLeagueID < totalLeagues {
-			profile.CurrentLeagueID++
-			shouldUpdate = true
-			log.Printf("Promoting user %s to league %d", profile.UserID, profile.CurrentLeagueID)
-		} else if i >= len(profiles)-demoteCount && currentLeagueID > 1 {
-			profile.CurrentLeagueID--
-			shouldUpdate = true
-			log.Printf("Demoting user %s to league %d", profile.UserID, profile.CurrentLeagueID)
+	byTarget := make(map[int][]string)
+	for _, m := range moves {
+		byTarget[m.ToLeagueID] = append(byTarget[m.ToLeagueID], m.UserID)
+	}
+	for leagueID, userIDs := range byTarget {
+		if err := s.profileRepo.SetLeague(ctx, userIDs, leagueID); err != nil {
+			return fmt.Errorf("move users to league %d: %w", leagueID, err)
 		}
+	}
 
-		if shouldUpdate {
-			if err := s.profileRepo.Update(ctx, profile); err != nil {
-				log.Printf("Failed to update profile for user %s: %v", profile.UserID, err)
-			}
+	promoted := 0
+	for _, m := range moves {
+		if m.Outcome != entities.LeagueOutcomePromoted {
+			continue
 		}
+		promoted++
+		if err := s.publishPromotion(ctx, m, leagueOrder); err != nil {
+			return err
+		}
+	}
+	log.Printf("Weekly reset moved %d users: %d promoted, %d demoted", len(moves), promoted, len(moves)-promoted)
+
+	if err := s.formCohorts(ctx, leagues, plan.WeekStart); err != nil {
+		return fmt.Errorf("form cohorts: %w", err)
+	}
+
+	if err := s.profileRepo.ResetAllWeeklyXP(ctx); err != nil {
+		return err
+	}
+
+	// Отметка о сбросе пишется в той же транзакции, что и сам сброс
+	if err := s.gamificationRepo.SetLastResetDate(ctx, plan.WeekStart); err != nil {
+		return fmt.Errorf("set last reset date: %w", err)
+	}
+
+	log.Println("Weekly reset completed successfully!")
+	return nil
+}
+
+func (s *WeeklyResetService) publishPromotion(ctx context.Context, move entities.LeagueMove, leagueOrder map[int]int) error {
+	event, err := entities.NewDomainEvent(entities.DomainLeaguePromoted, move.UserID, entities.LeaguePromoted{
+		FromLeagueID: move.FromLeagueID,
+		ToLeagueID:   move.ToLeagueID,
+		LeagueOrder:  leagueOrder[move.ToLeagueID],
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.events.PublishDurable(ctx, event); err != nil {
+		return fmt.Errorf("publish promotion for user %s: %w", move.UserID, err)
 	}
+	return nil
 }
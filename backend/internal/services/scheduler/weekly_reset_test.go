@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+func TestLeagueZones(t *testing.T) {
+	league := entities.League{PromotePercent: 20, DemotePercent: 20}
+
+	tests := []struct {
+		name               string
+		active, inactive   int
+		hasUpper, hasLower bool
+		promote, demote    int
+	}{
+		{"all active", 10, 0, true, true, 2, 2},
+		{"zones count inactive members", 4, 6, true, true, 2, 2},
+		{"only active are promoted", 1, 9, true, true, 1, 2},
+		{"nobody active", 0, 10, true, true, 0, 2},
+		{"top league", 10, 0, false, true, 0, 2},
+		{"bottom league", 10, 0, true, false, 2, 0},
+		{"tiny cohort prefers promotion", 1, 0, true, true, 1, 0},
+		{"two members", 2, 0, true, true, 1, 1},
+		{"empty", 0, 0, true, true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promote, demote := leagueZones(league, tt.active, tt.inactive, tt.hasUpper, tt.hasLower)
+			if promote != tt.promote || demote != tt.demote {
+				t.Errorf("leagueZones() = %d, %d; want %d, %d", promote, demote, tt.promote, tt.demote)
+			}
+		})
+	}
+}
+
+// cohortRepo — одна когорта: активные в порядке таблицы и неактивные.
+type cohortRepo struct {
+	ProfileRepository
+
+	active   []string
+	inactive []string
+}
+
+func (r *cohortRepo) CountLeagueMembers(context.Context, int, string) (int, int, error) {
+	return len(r.active), len(r.inactive), nil
+}
+
+func (r *cohortRepo) GetLeagueStandingsPage(_ context.Context, _ int, _ string, after *entities.LeagueStanding, limit int) ([]entities.LeagueStanding, error) {
+	from := 0
+	if after != nil {
+		from = slices.Index(r.active, after.UserID) + 1
+	}
+	var page []entities.LeagueStanding
+	for i := from; i < len(r.active) && len(page) < limit; i++ {
+		page = append(page, entities.LeagueStanding{UserID: r.active[i], WeeklyXP: int64(100 - i)})
+	}
+	return page, nil
+}
+
+func (r *cohortRepo) GetInactiveLeagueMembersPage(_ context.Context, _ int, _ string, afterUserID string, limit int) ([]string, error) {
+	from := 0
+	if afterUserID != "" {
+		from = slices.Index(r.inactive, afterUserID) + 1
+	}
+	return r.inactive[from:min(from+limit, len(r.inactive))], nil
+}
+
+func users(prefix string, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s%02d", prefix, i)
+	}
+	return ids
+}
+
+func TestRankCohort(t *testing.T) {
+	tests := []struct {
+		name           string
+		demoteInactive bool
+		active         int
+		inactive       int
+		wantPromoted   []string
+		wantDemoted    []string
+	}{
+		{
+			name:   "inactive fill the demotion zone",
+			active: 4, inactive: 6,
+			wantPromoted: []string{"a00", "a01"},
+			wantDemoted:  []string{"i04", "i05"},
+		},
+		{
+			name:   "zone reaches active members",
+			active: 9, inactive: 1,
+			wantPromoted: []string{"a00", "a01"},
+			wantDemoted:  []string{"a08", "i00"},
+		},
+		{
+			name:   "demote_inactive demotes every inactive member",
+			active: 4, inactive: 6, demoteInactive: true,
+			wantPromoted: []string{"a00", "a01"},
+			wantDemoted:  users("i", 6),
+		},
+		{
+			name:         "all active",
+			active:       10,
+			wantPromoted: []string{"a00", "a01"},
+			wantDemoted:  []string{"a08", "a09"},
+		},
+	}
+
+	leagues := []entities.League{
+		{ID: 1},
+		{ID: 2, PromotePercent: 20, DemotePercent: 20},
+		{ID: 3},
+	}
+	ids := func(moves []entities.LeagueMove) []string {
+		var out []string
+		for _, m := range moves {
+			out = append(out, m.UserID)
+		}
+		return out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leagues := slices.Clone(leagues)
+			leagues[1].DemoteInactive = tt.demoteInactive
+			repo := &cohortRepo{active: users("a", tt.active), inactive: users("i", tt.inactive)}
+			svc := &WeeklyResetService{profileRepo: repo}
+
+			lp := &entities.LeagueResetPlan{LeagueID: 2}
+			visited := 0
+			err := svc.rankCohort(context.Background(), leagues, 1, "c1", lp, func(page []entities.LeagueMove) error {
+				visited += len(page)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("rankCohort: %v", err)
+			}
+			if visited != tt.active+tt.inactive {
+				t.Errorf("visited %d members, want %d", visited, tt.active+tt.inactive)
+			}
+			if got := ids(lp.Promoted); !slices.Equal(got, tt.wantPromoted) {
+				t.Errorf("promoted = %v, want %v", got, tt.wantPromoted)
+			}
+			if got := ids(lp.Demoted); !slices.Equal(got, tt.wantDemoted) {
+				t.Errorf("demoted = %v, want %v", got, tt.wantDemoted)
+			}
+			for _, m := range lp.Demoted {
+				if m.ToLeagueID != 1 {
+					t.Errorf("%s demoted to league %d, want 1", m.UserID, m.ToLeagueID)
+				}
+			}
+		})
+	}
+}
+
+// lockedResetRepo — сброс, который уже выполняет другой экземпляр.
+type lockedResetRepo struct {
+	GamificationRepository
+}
+
+func (lockedResetRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (lockedResetRepo) TryLockWeeklyReset(context.Context) (bool, error) {
+	return false, nil
+}
+
+func TestCheckAndRunResetSkipsWhenLocked(t *testing.T) {
+	svc := &WeeklyResetService{gamificationRepo: lockedResetRepo{}}
+	if err := svc.CheckAndRunReset(context.Background()); !errors.Is(err, ErrSkipped) {
+		t.Errorf("CheckAndRunReset() = %v, want ErrSkipped", err)
+	}
+}
@@ -0,0 +1,57 @@
+package student
+
+import (
+	"context"
+
+	"backend/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// GetTestAttempts возвращает все попытки студента по тесту с его ответами.
+func (s *StudentService) GetTestAttempts(ctx context.Context, userID, testID string) ([]entities.TestAttempt, error) {
+	return s.testRepo.GetAttempts(ctx, testID, userID, 0)
+}
+
+// buildAttemptAnswers превращает результаты проверки в записи для истории,
+// копируя тексты вопроса и выбранных вариантов.
+func buildAttemptAnswers(resultID string, test *entities.Test, results []QuestionResult) []entities.TestAttemptAnswer {
+	questions := make(map[string]*entities.Question, len(test.Questions))
+	for i := range test.Questions {
+		questions[test.Questions[i].ID] = &test.Questions[i]
+	}
+
+	answers := make([]entities.TestAttemptAnswer, 0, len(results))
+	for i, r := range results {
+		q, ok := questions[r.QuestionID]
+		if !ok {
+			continue
+		}
+
+		texts := make(map[string]string, len(q.Answers))
+		for _, a := range q.Answers {
+			texts[a.ID] = a.Text
+		}
+		answerTexts := make([]string, 0, len(r.SelectedAnswerIDs))
+		for _, id := range r.SelectedAnswerIDs {
+			answerTexts = append(answerTexts, texts[id])
+		}
+
+		answers = append(answers, entities.TestAttemptAnswer{
+			ID:           uuid.NewString(),
+			ResultID:     resultID,
+			QuestionID:   q.ID,
+			Position:     i,
+			QuestionText: q.Text,
+			QuestionType: q.QuestionType,
+			AnswerIDs:    r.SelectedAnswerIDs,
+			AnswerTexts:  answerTexts,
+			TextAnswer:   r.Text,
+			Pairs:        r.Pairs,
+			IsCorrect:    r.IsCorrect,
+			Points:       r.Points,
+		})
+	}
+
+	return answers
+}
@@ -0,0 +1,148 @@
+package student
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+// snapshotTest — вопрос с несколькими ответами и matching из двух пар.
+func snapshotTest() *entities.Test {
+	return &entities.Test{
+		ID:           "t1",
+		ModuleID:     "m1",
+		PassingScore: 50,
+		Questions: []entities.Question{
+			{
+				ID:           "q1",
+				Text:         "Простые числа",
+				QuestionType: entities.QuestionTypeMultiple,
+				Answers: []entities.Answer{
+					{ID: "a2", Text: "2", IsCorrect: true},
+					{ID: "a3", Text: "3", IsCorrect: true},
+					{ID: "a4", Text: "4"},
+				},
+			},
+			{
+				ID:           "q2",
+				Text:         "Столицы",
+				QuestionType: entities.QuestionTypeMatching,
+				Answers: []entities.Answer{
+					{ID: "kz", Text: "Казахстан", MatchText: "Астана"},
+					{ID: "ru", Text: "Россия", MatchText: "Москва"},
+				},
+			},
+		},
+	}
+}
+
+// matchKey возвращает ключ, под которым попытка показала правую часть answerID.
+func matchKey(t *testing.T, session *entities.TestSession, questionID, answerID string) string {
+	t.Helper()
+	for _, sq := range session.Served {
+		if sq.QuestionID != questionID {
+			continue
+		}
+		for _, o := range sq.MatchOptions {
+			if o.AnswerID == answerID {
+				return o.Key
+			}
+		}
+	}
+	t.Fatalf("no match key for %s/%s", questionID, answerID)
+	return ""
+}
+
+func TestSubmitTestSnapshotsAnswers(t *testing.T) {
+	repo := &memTestRepo{test: snapshotTest()}
+	svc := newSessionService(repo)
+
+	start, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+
+	// q1 — выбран лишний вариант; q2 — обе пары верны, но присланы ключами попытки
+	answers := []StudentAnswer{
+		{QuestionID: "q1", AnswerIDs: []string{"a4", "a2"}},
+		{QuestionID: "q2", Pairs: map[string]string{
+			"kz": matchKey(t, start.Session, "q2", "kz"),
+			"ru": matchKey(t, start.Session, "q2", "ru"),
+		}},
+	}
+	sub, err := svc.SubmitTest(context.Background(), "u1", "t1", start.Session.ID, answers)
+	if err != nil {
+		t.Fatalf("SubmitTest: %v", err)
+	}
+
+	if len(repo.answers) != 2 {
+		t.Fatalf("saved %d attempt answers, want 2", len(repo.answers))
+	}
+	byQuestion := make(map[string]entities.TestAttemptAnswer)
+	for _, a := range repo.answers {
+		if a.ResultID != sub.Result.ID {
+			t.Errorf("answer %s saved for result %q, want %q", a.QuestionID, a.ResultID, sub.Result.ID)
+		}
+		byQuestion[a.QuestionID] = a
+	}
+
+	choice := byQuestion["q1"]
+	if choice.QuestionText != "Простые числа" || choice.QuestionType != entities.QuestionTypeMultiple {
+		t.Errorf("q1 snapshot = %q/%q", choice.QuestionText, choice.QuestionType)
+	}
+	if !slices.Equal(choice.AnswerIDs, []string{"a4", "a2"}) || !slices.Equal(choice.AnswerTexts, []string{"4", "2"}) {
+		t.Errorf("q1 answers = %v %v, want [a4 a2] [4 2]", choice.AnswerIDs, choice.AnswerTexts)
+	}
+	if choice.IsCorrect {
+		t.Error("q1 with a wrong option is marked correct")
+	}
+
+	matching := byQuestion["q2"]
+	if !matching.IsCorrect {
+		t.Error("q2 with both pairs right is marked wrong")
+	}
+	if matching.Pairs["kz"] != "kz" || matching.Pairs["ru"] != "ru" {
+		t.Errorf("q2 pairs = %v, want answer IDs instead of attempt keys", matching.Pairs)
+	}
+}
+
+// attemptsRepo отдаёт попытки так же, как GetAttempts в Postgres:
+// пустой userID — попытки всех студентов.
+type attemptsRepo struct {
+	TestRepository
+
+	attempts []entities.TestAttempt
+}
+
+func (r *attemptsRepo) GetAttempts(_ context.Context, testID, userID string, _ int) ([]entities.TestAttempt, error) {
+	var res []entities.TestAttempt
+	for _, a := range r.attempts {
+		if a.Result.TestID == testID && (userID == "" || a.Result.UserID == userID) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func TestGetTestAttemptsReturnsOnlyOwnAttempts(t *testing.T) {
+	repo := &attemptsRepo{attempts: []entities.TestAttempt{
+		{Result: entities.TestResult{ID: "r1", UserID: "u1", TestID: "t1"}},
+		{Result: entities.TestResult{ID: "r2", UserID: "u2", TestID: "t1"}},
+		{Result: entities.TestResult{ID: "r3", UserID: "u1", TestID: "t2"}},
+	}}
+	svc := &StudentService{testRepo: repo}
+
+	attempts, err := svc.GetTestAttempts(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("GetTestAttempts: %v", err)
+	}
+	if len(attempts) != 1 || attempts[0].Result.ID != "r1" {
+		t.Errorf("attempts = %v, want only r1", attempts)
+	}
+
+	if attempts, _ := svc.GetTestAttempts(context.Background(), "u3", "t1"); len(attempts) != 0 {
+		t.Errorf("student without attempts got %v", attempts)
+	}
+}
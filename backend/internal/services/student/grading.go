@@ -0,0 +1,309 @@
+package student
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/internal/entities"
+)
+
+// StudentAnswer — ответ на один вопрос. Какие поля заполнены, зависит от типа:
+// AnswerIDs — choice и ordering (в порядке, выбранном студентом),
+// Text — short_answer и numeric, Pairs — matching (ID левой части -> ID правой).
+type StudentAnswer struct {
+	QuestionID string
+	AnswerIDs  []string
+	Text       string
+	Pairs      map[string]string
+}
+
+// QuestionResult — итог проверки одного вопроса.
+// Points лежит в диапазоне [-1, 1]; отрицательные значения возможны
+// только в режиме entities.ScoringNegative.
+// Text и Pairs повторяют присланный ответ для short_answer/numeric и matching.
+type QuestionResult struct {
+	QuestionID        string
+	SelectedAnswerIDs []string
+	Text              string
+	Pairs             map[string]string
+	IsCorrect         bool
+	Points            float64
+}
+
+// gradeTest проверяет ответы и возвращает итоговый процент (0-100)
+// и результаты по каждому вопросу в порядке вопросов теста.
+func gradeTest(test *entities.Test, answers []StudentAnswer) (int, []QuestionResult) {
+	byQuestion := make(map[string]StudentAnswer, len(answers))
+	for _, a := range answers {
+		prev, ok := byQuestion[a.QuestionID]
+		if !ok {
+			byQuestion[a.QuestionID] = a
+			continue
+		}
+		// Для choice-вопросов фронт может прислать несколько записей на вопрос
+		prev.AnswerIDs = append(prev.AnswerIDs, a.AnswerIDs...)
+		byQuestion[a.QuestionID] = prev
+	}
+
+	results := make([]QuestionResult, 0, len(test.Questions))
+	total := 0.0
+	for _, q := range test.Questions {
+		res := gradeQuestion(q, test.ScoringModeFor(q.QuestionType), byQuestion[q.ID])
+		total += res.Points
+		results = append(results, res)
+	}
+
+	if len(test.Questions) == 0 || total <= 0 {
+		return 0, results
+	}
+
+	// Доли баллов в float дают 59.999… вместо 60: без округления ученик
+	// ровно на проходном балле не сдал бы тест
+	return int(math.Round(total * 100 / float64(len(test.Questions)))), results
+}
+
+func gradeQuestion(q entities.Question, mode entities.ScoringMode, a StudentAnswer) QuestionResult {
+	switch q.QuestionType {
+	case entities.QuestionTypeShortAnswer:
+		res := gradeShortAnswer(q, a.Text)
+		res.Text = a.Text
+		return res
+	case entities.QuestionTypeNumeric:
+		res := gradeNumeric(q, a.Text)
+		res.Text = a.Text
+		return res
+	case entities.QuestionTypeOrdering:
+		return gradeOrdering(q, mode, a.AnswerIDs)
+	case entities.QuestionTypeMatching:
+		return gradeMatching(q, mode, a.Pairs)
+	default:
+		return gradeChoice(q, mode, a.AnswerIDs)
+	}
+}
+
+func gradeChoice(q entities.Question, mode entities.ScoringMode, answerIDs []string) QuestionResult {
+	isCorrect := make(map[string]bool, len(q.Answers))
+	correctCount := 0
+	for _, a := range q.Answers {
+		isCorrect[a.ID] = a.IsCorrect
+		if a.IsCorrect {
+			correctCount++
+		}
+	}
+	wrongCount := len(q.Answers) - correctCount
+
+	// Отбрасываем дубликаты и чужие ID ответов
+	seen := make(map[string]bool, len(answerIDs))
+	chosen := make([]string, 0, len(answerIDs))
+	hits, misses := 0, 0
+	for _, id := range answerIDs {
+		correct, ok := isCorrect[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		chosen = append(chosen, id)
+		if correct {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	res := QuestionResult{
+		QuestionID:        q.ID,
+		SelectedAnswerIDs: chosen,
+		IsCorrect:         correctCount > 0 && hits == correctCount && misses == 0,
+	}
+
+	if correctCount == 0 {
+		return res
+	}
+
+	switch mode {
+	case entities.ScoringAllOrNothing:
+		if res.IsCorrect {
+			res.Points = 1
+		}
+	case entities.ScoringPartial, entities.ScoringNegative:
+		// Штраф нормирован так, что выбор всех неверных вариантов даёт -1,
+		// а случайное угадывание в среднем приносит 0.
+		points := float64(hits) / float64(correctCount)
+		if wrongCount > 0 {
+			points -= float64(misses) / float64(wrongCount)
+		}
+		if mode == entities.ScoringPartial && points < 0 {
+			points = 0
+		}
+		res.Points = points
+	}
+
+	return res
+}
+
+// gradeShortAnswer засчитывает ответ, если после нормализации он совпадает
+// с любым из допустимых вариантов.
+func gradeShortAnswer(q entities.Question, text string) QuestionResult {
+	res := QuestionResult{QuestionID: q.ID}
+
+	given := normalizeText(text)
+	if given == "" {
+		return res
+	}
+
+	for _, a := range q.Answers {
+		if normalizeText(a.Text) == given {
+			res.SelectedAnswerIDs = []string{a.ID}
+			res.IsCorrect = true
+			res.Points = 1
+			break
+		}
+	}
+
+	return res
+}
+
+func gradeNumeric(q entities.Question, text string) QuestionResult {
+	res := QuestionResult{QuestionID: q.ID}
+	if q.NumericValue == nil {
+		return res
+	}
+
+	value, ok := parseNumber(text)
+	if !ok {
+		return res
+	}
+
+	// Небольшой запас, чтобы 0.1+0.2 не отличалось от 0.3 на ошибку округления
+	tolerance := math.Abs(q.NumericTolerance) + 1e-9
+	if math.Abs(value-*q.NumericValue) <= tolerance {
+		res.IsCorrect = true
+		res.Points = 1
+	}
+
+	return res
+}
+
+// gradeOrdering сравнивает присланный порядок с OrderIndex вариантов.
+// В режимах partial и negative балл — доля элементов на своих местах
+// от длины ответа или вопроса, смотря что длиннее.
+func gradeOrdering(q entities.Question, mode entities.ScoringMode, answerIDs []string) QuestionResult {
+	res := QuestionResult{QuestionID: q.ID, SelectedAnswerIDs: knownAnswerIDs(q, answerIDs)}
+	if len(q.Answers) == 0 {
+		return res
+	}
+
+	// Не полагаемся на порядок q.Answers: он зависит от того, откуда пришёл тест
+	expected := make([]string, len(q.Answers))
+	for i, a := range sortedByOrder(q.Answers) {
+		expected[i] = a.ID
+	}
+
+	inPlace := 0
+	for i, id := range expected {
+		if i < len(answerIDs) && answerIDs[i] == id {
+			inPlace++
+		}
+	}
+
+	res.IsCorrect = inPlace == len(expected) && len(answerIDs) == len(expected)
+	res.Points = fractionPoints(mode, res.IsCorrect, inPlace, max(len(expected), len(answerIDs)))
+
+	return res
+}
+
+// gradeMatching проверяет пары: левая часть — ID варианта, правая — ключ
+// MatchOption этой попытки. Ключ переводится в ID варианта, чей MatchText
+// он обозначает; пара верна, если это тот же вариант. Неизвестные ключи
+// считаются ошибкой. В результат пары попадают уже с ID вариантов.
+func gradeMatching(q entities.Question, mode entities.ScoringMode, pairs map[string]string) QuestionResult {
+	res := QuestionResult{QuestionID: q.ID}
+	if len(q.Answers) == 0 {
+		return res
+	}
+
+	byKey := make(map[string]string, len(q.MatchOptions))
+	for _, o := range q.MatchOptions {
+		byKey[o.Key] = o.AnswerID
+	}
+
+	matched := 0
+	for _, a := range q.Answers {
+		if right, ok := byKey[pairs[a.ID]]; ok {
+			if res.Pairs == nil {
+				res.Pairs = make(map[string]string, len(q.Answers))
+			}
+			res.Pairs[a.ID] = right
+			if right == a.ID {
+				matched++
+			}
+		}
+	}
+
+	res.IsCorrect = matched == len(q.Answers)
+	res.Points = fractionPoints(mode, res.IsCorrect, matched, len(q.Answers))
+
+	return res
+}
+
+// fractionPoints — балл для ordering и matching. Штрафа за ошибки здесь нет,
+// поэтому negative ведёт себя как partial.
+func fractionPoints(mode entities.ScoringMode, isCorrect bool, hits, total int) float64 {
+	if mode == entities.ScoringAllOrNothing || total == 0 {
+		if isCorrect {
+			return 1
+		}
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+func sortedByOrder(answers []entities.Answer) []entities.Answer {
+	sorted := make([]entities.Answer, len(answers))
+	copy(sorted, answers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OrderIndex < sorted[j].OrderIndex
+	})
+	return sorted
+}
+
+// normalizeText приводит свободный ответ к сравнимому виду: регистр,
+// лишние пробелы, ё/е и точка в конце не учитываются.
+func normalizeText(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	s = strings.ReplaceAll(s, "ё", "е")
+	return strings.TrimSpace(strings.TrimRight(s, ".!"))
+}
+
+// parseNumber принимает и точку, и запятую как десятичный разделитель.
+func parseNumber(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// knownAnswerIDs оставляет только ID вариантов вопроса, сохраняя порядок.
+func knownAnswerIDs(q entities.Question, ids []string) []string {
+	known := make(map[string]bool, len(q.Answers))
+	for _, a := range q.Answers {
+		known[a.ID] = true
+	}
+
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if known[id] {
+			res = append(res, id)
+		}
+	}
+	return res
+}
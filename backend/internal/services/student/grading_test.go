@@ -0,0 +1,562 @@
+package student
+
+import (
+	"testing"
+
+	"backend/internal/entities"
+)
+
+func TestGradeMatching(t *testing.T) {
+	q := entities.Question{
+		ID:           "q1",
+		QuestionType: entities.QuestionTypeMatching,
+		Answers: []entities.Answer{
+			{ID: "a1", Text: "Paris", MatchText: "France"},
+			{ID: "a2", Text: "Berlin", MatchText: "Germany"},
+		},
+		MatchOptions: []entities.MatchOption{
+			{Key: "k-germany", AnswerID: "a2"},
+			{Key: "k-france", AnswerID: "a1"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		pairs     map[string]string
+		mode      entities.ScoringMode
+		isCorrect bool
+		points    float64
+	}{
+		{
+			name:      "all pairs right",
+			pairs:     map[string]string{"a1": "k-france", "a2": "k-germany"},
+			mode:      entities.ScoringPartial,
+			isCorrect: true,
+			points:    1,
+		},
+		{
+			name:   "one pair swapped",
+			pairs:  map[string]string{"a1": "k-germany", "a2": "k-germany"},
+			mode:   entities.ScoringPartial,
+			points: 0.5,
+		},
+		{
+			name:   "answer IDs instead of option keys",
+			pairs:  map[string]string{"a1": "a1", "a2": "a2"},
+			mode:   entities.ScoringPartial,
+			points: 0,
+		},
+		{
+			name:   "partial answer in all-or-nothing mode",
+			pairs:  map[string]string{"a1": "k-france"},
+			mode:   entities.ScoringAllOrNothing,
+			points: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := gradeMatching(q, tt.mode, tt.pairs)
+			if res.IsCorrect != tt.isCorrect || res.Points != tt.points {
+				t.Errorf("got correct=%v points=%v, want correct=%v points=%v",
+					res.IsCorrect, res.Points, tt.isCorrect, tt.points)
+			}
+		})
+	}
+}
+
+func TestGradeMatchingRecordsAnswerIDs(t *testing.T) {
+	q := entities.Question{
+		ID:           "q1",
+		QuestionType: entities.QuestionTypeMatching,
+		Answers:      []entities.Answer{{ID: "a1"}, {ID: "a2"}},
+		MatchOptions: []entities.MatchOption{{Key: "k1", AnswerID: "a1"}, {Key: "k2", AnswerID: "a2"}},
+	}
+
+	res := gradeMatching(q, entities.ScoringPartial, map[string]string{"a1": "k2", "a2": "unknown"})
+
+	if len(res.Pairs) != 1 || res.Pairs["a1"] != "a2" {
+		t.Errorf("pairs = %v, want map[a1:a2]", res.Pairs)
+	}
+}
+
+func TestNewMatchOptionsHidesAnswerIDs(t *testing.T) {
+	q := &entities.Question{
+		QuestionType: entities.QuestionTypeMatching,
+		Answers:      []entities.Answer{{ID: "a1"}, {ID: "a2"}, {ID: "a3"}},
+	}
+
+	options := newMatchOptions(q)
+	if len(options) != len(q.Answers) {
+		t.Fatalf("got %d options, want %d", len(options), len(q.Answers))
+	}
+
+	seen := make(map[string]bool)
+	for _, o := range options {
+		if o.Key == o.AnswerID || seen[o.Key] {
+			t.Errorf("option key %q must be unique and differ from the answer ID", o.Key)
+		}
+		seen[o.Key] = true
+	}
+
+	if newMatchOptions(&entities.Question{QuestionType: entities.QuestionTypeOrdering}) != nil {
+		t.Error("only matching questions get match options")
+	}
+}
+
+func TestGradeChoice(t *testing.T) {
+	q := entities.Question{
+		ID:           "q1",
+		QuestionType: entities.QuestionTypeMultiple,
+		Answers: []entities.Answer{
+			{ID: "a", IsCorrect: true},
+			{ID: "b", IsCorrect: true},
+			{ID: "c"},
+			{ID: "d"},
+		},
+	}
+
+	// points — балл в режимах all_or_nothing, partial и negative
+	tests := []struct {
+		name      string
+		picked    []string
+		isCorrect bool
+		selected  []string
+		points    [3]float64
+	}{
+		{
+			name:      "full hit",
+			picked:    []string{"b", "a"},
+			isCorrect: true,
+			selected:  []string{"b", "a"},
+			points:    [3]float64{1, 1, 1},
+		},
+		{
+			name:     "partial hit",
+			picked:   []string{"a"},
+			selected: []string{"a"},
+			points:   [3]float64{0, 0.5, 0.5},
+		},
+		{
+			name:     "right and wrong pick cancel out",
+			picked:   []string{"a", "c"},
+			selected: []string{"a", "c"},
+			points:   [3]float64{0, 0, 0},
+		},
+		{
+			name:     "one wrong pick",
+			picked:   []string{"c"},
+			selected: []string{"c"},
+			points:   [3]float64{0, 0, -0.5},
+		},
+		{
+			name:     "only wrong picks",
+			picked:   []string{"c", "d"},
+			selected: []string{"c", "d"},
+			points:   [3]float64{0, 0, -1},
+		},
+		{
+			name:     "every option picked",
+			picked:   []string{"a", "b", "c", "d"},
+			selected: []string{"a", "b", "c", "d"},
+			points:   [3]float64{0, 0, 0},
+		},
+		{
+			name:     "duplicate pick counts once",
+			picked:   []string{"a", "a"},
+			selected: []string{"a"},
+			points:   [3]float64{0, 0.5, 0.5},
+		},
+		{
+			name:      "foreign option IDs are ignored",
+			picked:    []string{"a", "x", "b", "other-question-answer"},
+			isCorrect: true,
+			selected:  []string{"a", "b"},
+			points:    [3]float64{1, 1, 1},
+		},
+		{
+			name:     "nothing picked",
+			selected: []string{},
+			points:   [3]float64{0, 0, 0},
+		},
+	}
+
+	modes := []entities.ScoringMode{entities.ScoringAllOrNothing, entities.ScoringPartial, entities.ScoringNegative}
+
+	for _, tt := range tests {
+		for i, mode := range modes {
+			t.Run(tt.name+"/"+string(mode), func(t *testing.T) {
+				res := gradeChoice(q, mode, tt.picked)
+				if res.IsCorrect != tt.isCorrect || res.Points != tt.points[i] {
+					t.Errorf("got correct=%v points=%v, want correct=%v points=%v",
+						res.IsCorrect, res.Points, tt.isCorrect, tt.points[i])
+				}
+				if !equalIDs(res.SelectedAnswerIDs, tt.selected) {
+					t.Errorf("selected = %v, want %v", res.SelectedAnswerIDs, tt.selected)
+				}
+			})
+		}
+	}
+}
+
+func TestGradeChoiceWithoutCorrectAnswers(t *testing.T) {
+	q := entities.Question{ID: "q1", Answers: []entities.Answer{{ID: "a"}, {ID: "b"}}}
+
+	for _, mode := range []entities.ScoringMode{entities.ScoringAllOrNothing, entities.ScoringPartial, entities.ScoringNegative} {
+		if res := gradeChoice(q, mode, []string{"a"}); res.IsCorrect || res.Points != 0 {
+			t.Errorf("%s: got correct=%v points=%v, want no points", mode, res.IsCorrect, res.Points)
+		}
+	}
+}
+
+func TestGradeTestClampsNegativeTotalAtZero(t *testing.T) {
+	test := &entities.Test{
+		ScoringRules: map[string]entities.ScoringMode{
+			entities.QuestionTypeMultiple: entities.ScoringNegative,
+		},
+		Questions: []entities.Question{
+			{
+				ID:           "q1",
+				QuestionType: entities.QuestionTypeMultiple,
+				Answers:      []entities.Answer{{ID: "a", IsCorrect: true}, {ID: "b"}},
+			},
+			{
+				ID:           "q2",
+				QuestionType: entities.QuestionTypeMultiple,
+				Answers:      []entities.Answer{{ID: "c", IsCorrect: true}, {ID: "d", IsCorrect: true}, {ID: "e"}},
+			},
+		},
+	}
+
+	// q1: -1, q2: 0.5 — в сумме -0.5
+	score, results := gradeTest(test, []StudentAnswer{
+		{QuestionID: "q1", AnswerIDs: []string{"b"}},
+		{QuestionID: "q2", AnswerIDs: []string{"c"}},
+	})
+
+	if score != 0 {
+		t.Errorf("score = %d, want 0", score)
+	}
+	if len(results) != 2 || results[0].Points != -1 || results[1].Points != 0.5 {
+		t.Errorf("results = %+v, want per-question points -1 and 0.5", results)
+	}
+
+	// Та же пара ответов в режиме partial: 0 + 0.5 из 2 вопросов
+	test.ScoringRules = nil
+	if score, _ := gradeTest(test, []StudentAnswer{
+		{QuestionID: "q1", AnswerIDs: []string{"b"}},
+		{QuestionID: "q2", AnswerIDs: []string{"c"}},
+	}); score != 25 {
+		t.Errorf("partial score = %d, want 25", score)
+	}
+}
+
+func TestGradeTestMergesSplitChoiceAnswers(t *testing.T) {
+	test := &entities.Test{
+		Questions: []entities.Question{{
+			ID:           "q1",
+			QuestionType: entities.QuestionTypeMultiple,
+			Answers:      []entities.Answer{{ID: "a", IsCorrect: true}, {ID: "b", IsCorrect: true}, {ID: "c"}},
+		}},
+	}
+
+	score, _ := gradeTest(test, []StudentAnswer{
+		{QuestionID: "q1", AnswerIDs: []string{"a"}},
+		{QuestionID: "q1", AnswerIDs: []string{"b"}},
+	})
+	if score != 100 {
+		t.Errorf("score = %d, want 100", score)
+	}
+}
+
+func TestGradeTestRoundsScore(t *testing.T) {
+	test := &entities.Test{PassingScore: 60}
+	for _, id := range []string{"q1", "q2", "q3"} {
+		test.Questions = append(test.Questions, entities.Question{
+			ID:           id,
+			QuestionType: entities.QuestionTypeOrdering,
+			Answers: []entities.Answer{
+				{ID: id + "a", OrderIndex: 1},
+				{ID: id + "b", OrderIndex: 2},
+				{ID: id + "c", OrderIndex: 3},
+				{ID: id + "d", OrderIndex: 4},
+				{ID: id + "e", OrderIndex: 5},
+			},
+		})
+	}
+	order := func(id string, suffixes ...string) StudentAnswer {
+		a := StudentAnswer{QuestionID: id}
+		for _, s := range suffixes {
+			a.AnswerIDs = append(a.AnswerIDs, id+s)
+		}
+		return a
+	}
+
+	// По три из пяти на месте: 3 × 0.6 в float — 1.7999…, то есть 59.999…%.
+	// Ученик ровно на проходном балле должен сдать.
+	score, _ := gradeTest(test, []StudentAnswer{
+		order("q1", "a", "b", "c", "e", "d"),
+		order("q2", "a", "b", "c", "e", "d"),
+		order("q3", "a", "b", "c", "e", "d"),
+	})
+	if score != 60 || score < test.PassingScore {
+		t.Errorf("score = %d, want 60 (passing)", score)
+	}
+
+	// Два из трёх вопросов — 66.67%
+	score, _ = gradeTest(test, []StudentAnswer{
+		order("q1", "a", "b", "c", "d", "e"),
+		order("q2", "a", "b", "c", "d", "e"),
+	})
+	if score != 67 {
+		t.Errorf("score = %d, want 67", score)
+	}
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Париж", "париж"},
+		{"  Нью   Йорк \t", "нью йорк"},
+		{"Ёлка", "елка"},
+		{"ещё", "еще"},
+		{"Париж.", "париж"},
+		{"Париж!!", "париж"},
+		{"Париж .", "париж"},
+		{"3.14", "3.14"},
+		{"...", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeText(tt.in); got != tt.want {
+			t.Errorf("normalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGradeShortAnswer(t *testing.T) {
+	q := entities.Question{
+		ID:           "q1",
+		QuestionType: entities.QuestionTypeShortAnswer,
+		Answers: []entities.Answer{
+			{ID: "a1", Text: "Ёжик"},
+			{ID: "a2", Text: "hedgehog"},
+		},
+	}
+
+	tests := []struct {
+		text      string
+		isCorrect bool
+		selected  string
+	}{
+		{text: "ежик", isCorrect: true, selected: "a1"},
+		{text: "  ЁЖИК. ", isCorrect: true, selected: "a1"},
+		{text: "Hedgehog!", isCorrect: true, selected: "a2"},
+		{text: "hedge hog", isCorrect: false},
+		{text: "ежики", isCorrect: false},
+		{text: "   ", isCorrect: false},
+		{text: ".", isCorrect: false},
+	}
+
+	for _, tt := range tests {
+		res := gradeQuestion(q, entities.ScoringAllOrNothing, StudentAnswer{QuestionID: "q1", Text: tt.text})
+		if res.IsCorrect != tt.isCorrect {
+			t.Errorf("%q: correct = %v, want %v", tt.text, res.IsCorrect, tt.isCorrect)
+		}
+		if res.Text != tt.text {
+			t.Errorf("%q: result keeps text %q", tt.text, res.Text)
+		}
+		if tt.isCorrect && (res.Points != 1 || len(res.SelectedAnswerIDs) != 1 || res.SelectedAnswerIDs[0] != tt.selected) {
+			t.Errorf("%q: points=%v selected=%v, want 1 and [%s]", tt.text, res.Points, res.SelectedAnswerIDs, tt.selected)
+		}
+		if !tt.isCorrect && res.Points != 0 {
+			t.Errorf("%q: points = %v, want 0", tt.text, res.Points)
+		}
+	}
+}
+
+func TestGradeNumeric(t *testing.T) {
+	value := 2.5
+	q := entities.Question{
+		ID:               "q1",
+		QuestionType:     entities.QuestionTypeNumeric,
+		NumericValue:     &value,
+		NumericTolerance: 0.1,
+	}
+
+	tests := []struct {
+		text      string
+		isCorrect bool
+	}{
+		{"2.5", true},
+		{"2,5", true},
+		{" 2,50 ", true},
+		{"2.4", true},
+		{"2.6", true},
+		{"2.39", false},
+		{"2.61", false},
+		{"-2.5", false},
+		{"1 000", false},
+		{"abc", false},
+		{"2.5cm", false},
+		{"", false},
+		{"NaN", false},
+		{"Inf", false},
+	}
+
+	for _, tt := range tests {
+		res := gradeNumeric(q, tt.text)
+		if res.IsCorrect != tt.isCorrect || (res.Points == 1) != tt.isCorrect {
+			t.Errorf("%q: correct=%v points=%v, want correct=%v", tt.text, res.IsCorrect, res.Points, tt.isCorrect)
+		}
+	}
+}
+
+func TestGradeNumericExactAndUnset(t *testing.T) {
+	value := 0.3
+	exact := entities.Question{ID: "q1", NumericValue: &value}
+
+	if res := gradeNumeric(exact, "0.30000000000000004"); !res.IsCorrect {
+		t.Error("rounding noise must not fail an exact answer")
+	}
+	if res := gradeNumeric(exact, "0.31"); res.IsCorrect {
+		t.Error("zero tolerance accepted 0.31 for 0.3")
+	}
+
+	negative := entities.Question{ID: "q2", NumericValue: &value, NumericTolerance: -0.05}
+	if res := gradeNumeric(negative, "0.34"); !res.IsCorrect {
+		t.Error("a negative tolerance is treated as its absolute value")
+	}
+
+	if res := gradeNumeric(entities.Question{ID: "q3"}, "0"); res.IsCorrect {
+		t.Error("question without a value accepted an answer")
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"3.14", 3.14, true},
+		{"3,14", 3.14, true},
+		{"-0,5", -0.5, true},
+		{"1e3", 1000, true},
+		{"1 000", 1000, true},
+		{"1,000.5", 0, false},
+		{"", 0, false},
+		{"три", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGradeOrdering(t *testing.T) {
+	// Порядок в q.Answers намеренно не совпадает с OrderIndex
+	q := entities.Question{
+		ID:           "q1",
+		QuestionType: entities.QuestionTypeOrdering,
+		Answers: []entities.Answer{
+			{ID: "c", OrderIndex: 3},
+			{ID: "a", OrderIndex: 1},
+			{ID: "d", OrderIndex: 4},
+			{ID: "b", OrderIndex: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		order     []string
+		mode      entities.ScoringMode
+		isCorrect bool
+		points    float64
+	}{
+		{
+			name:      "full order",
+			order:     []string{"a", "b", "c", "d"},
+			mode:      entities.ScoringPartial,
+			isCorrect: true,
+			points:    1,
+		},
+		{
+			name:   "two swapped",
+			order:  []string{"a", "b", "d", "c"},
+			mode:   entities.ScoringPartial,
+			points: 0.5,
+		},
+		{
+			name:  "two swapped in all-or-nothing mode",
+			order: []string{"a", "b", "d", "c"},
+			mode:  entities.ScoringAllOrNothing,
+		},
+		{
+			name:   "negative mode has no penalty",
+			order:  []string{"d", "c", "b", "a"},
+			mode:   entities.ScoringNegative,
+			points: 0,
+		},
+		{
+			name:   "too short",
+			order:  []string{"a", "b"},
+			mode:   entities.ScoringPartial,
+			points: 0.5,
+		},
+		{
+			name:   "extra items after a correct order",
+			order:  []string{"a", "b", "c", "d", "a"},
+			mode:   entities.ScoringPartial,
+			points: 0.8,
+		},
+		{
+			name:  "extra items in all-or-nothing mode",
+			order: []string{"a", "b", "c", "d", "x"},
+			mode:  entities.ScoringAllOrNothing,
+		},
+		{
+			name:  "empty",
+			mode:  entities.ScoringPartial,
+			order: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := gradeOrdering(q, tt.mode, tt.order)
+			if res.IsCorrect != tt.isCorrect || res.Points != tt.points {
+				t.Errorf("got correct=%v points=%v, want correct=%v points=%v",
+					res.IsCorrect, res.Points, tt.isCorrect, tt.points)
+			}
+		})
+	}
+}
+
+func TestGradeOrderingRecordsKnownIDs(t *testing.T) {
+	q := entities.Question{
+		ID:      "q1",
+		Answers: []entities.Answer{{ID: "a", OrderIndex: 1}, {ID: "b", OrderIndex: 2}},
+	}
+
+	res := gradeOrdering(q, entities.ScoringPartial, []string{"b", "x", "a"})
+	if !equalIDs(res.SelectedAnswerIDs, []string{"b", "a"}) {
+		t.Errorf("selected = %v, want [b a]", res.SelectedAnswerIDs)
+	}
+}
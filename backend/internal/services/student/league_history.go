@@ -0,0 +1,39 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// GetLeagueHistory возвращает прошедшие недели ученика в лигах от новых
+// к старым и их общее число.
+func (s *StudentService) GetLeagueHistory(ctx context.Context, userID string, limit, offset int) ([]entities.LeaderboardHistory, int, error) {
+	list, total, err := s.gamificationRepo.GetUserHistory(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get league history: %w", err)
+	}
+	return list, total, nil
+}
+
+// GetLeagueHistoryTable возвращает итоговую таблицу когорты, в которой
+// ученик был на неделе, начавшейся periodStart. Если ученик в ту неделю
+// не участвовал — entities.ErrNotFound.
+func (s *StudentService) GetLeagueHistoryTable(ctx context.Context, userID string, periodStart time.Time) ([]entities.LeaderboardHistory, error) {
+	return s.gamificationRepo.GetHistoryTable(ctx, userID, entities.WeekStart(periodStart))
+}
+
+// lastWeekResult возвращает итог недели, закончившейся в начале текущей.
+func (s *StudentService) lastWeekResult(ctx context.Context, userID string, now time.Time) (*entities.LeaderboardHistory, error) {
+	list, _, err := s.gamificationRepo.GetUserHistory(ctx, userID, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last week result: %w", err)
+	}
+
+	if len(list) == 0 || !list[0].PeriodEnd.Equal(entities.WeekStart(now)) {
+		return nil, nil
+	}
+	return &list[0], nil
+}
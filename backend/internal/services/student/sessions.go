@@ -0,0 +1,254 @@
+package student
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"backend/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// submitGrace — запас на сетевую задержку при проверке дедлайна попытки.
+const submitGrace = 10 * time.Second
+
+// TestStart — выданная попытка и сколько попыток уже израсходовано.
+type TestStart struct {
+	Session      *entities.TestSession
+	AttemptsUsed int
+	MaxAttempts  int
+	TimeLimit    time.Duration
+
+	// Test — вопросы, выданные в этой попытке
+	Test *entities.Test
+}
+
+// StartTest выдаёт токен попытки. Если у студента уже есть открытая попытка
+// по этому тесту, возвращается она, а счётчик попыток не растёт.
+func (s *StudentService) StartTest(ctx context.Context, userID, testID string) (*TestStart, error) {
+	test, err := s.testRepo.GetTestFullByID(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	var start *TestStart
+	err = s.testRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.testRepo.LockUserTest(ctx, userID, testID); err != nil {
+			return err
+		}
+
+		sessions, err := s.testRepo.GetSessions(ctx, userID, testID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		start = &TestStart{AttemptsUsed: len(sessions), MaxAttempts: test.MaxAttempts, TimeLimit: test.TimeLimit}
+
+		if len(sessions) > 0 {
+			last := sessions[len(sessions)-1]
+			if last.IsOpen(now) {
+				start.Session = last
+				return nil
+			}
+
+			if test.MaxAttempts > 0 && len(sessions) >= test.MaxAttempts {
+				return entities.ErrAttemptLimitReached
+			}
+
+			if next := last.FinishedAt().Add(test.Cooldown); test.Cooldown > 0 && now.Before(next) {
+				return &entities.CooldownError{RetryAfter: next.Sub(now)}
+			}
+		}
+
+		start.Session = entities.NewTestSession(userID, test, now)
+		bank, err := s.poolCandidates(ctx, test)
+		if err != nil {
+			return err
+		}
+		start.Session.Served = assembleTest(test, bank)
+		start.AttemptsUsed++
+		return s.testRepo.CreateSession(ctx, start.Session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if start.Test, err = s.servedTest(ctx, test, start.Session); err != nil {
+		return nil, err
+	}
+
+	return start, nil
+}
+
+// checkSession проверяет, что попытку можно закрыть этой отправкой.
+// Вызывается внутри транзакции SubmitTest.
+func (s *StudentService) checkSession(
+	ctx context.Context,
+	userID, testID, attemptID string,
+	now time.Time,
+) (*entities.TestSession, error) {
+	if err := s.testRepo.LockUserTest(ctx, userID, testID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.testRepo.GetSession(ctx, attemptID)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, entities.ErrAttemptInvalid
+		}
+		return nil, err
+	}
+
+	if session.UserID != userID || session.TestID != testID {
+		return nil, entities.ErrAttemptInvalid
+	}
+	if session.SubmittedAt != nil {
+		return nil, entities.ErrAttemptAlreadySubmitted
+	}
+	if session.Deadline != nil && now.After(session.Deadline.Add(submitGrace)) {
+		return nil, entities.ErrAttemptExpired
+	}
+	return session, nil
+}
+
+// assembleTest выбирает вопросы для новой попытки: сначала вопросы самого
+// теста, затем по каждому PoolRule случайные вопросы банка. Если подходящих
+// вопросов в банке меньше, чем нужно, берутся все оставшиеся. Правые части
+// matching всегда перемешиваются и получают ключи этой попытки.
+func assembleTest(test *entities.Test, bank []entities.Question) []entities.ServedQuestion {
+	served := make([]entities.ServedQuestion, 0, len(test.Questions))
+	used := make(map[string]bool)
+
+	serve := func(q *entities.Question) {
+		ids := make([]string, 0, len(q.Answers))
+		for _, a := range q.Answers {
+			ids = append(ids, a.ID)
+		}
+		if test.ShuffleAnswers {
+			rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+		}
+		used[q.ID] = true
+		served = append(served, entities.ServedQuestion{
+			QuestionID:   q.ID,
+			AnswerIDs:    ids,
+			MatchOptions: newMatchOptions(q),
+		})
+	}
+
+	for i := range test.Questions {
+		serve(&test.Questions[i])
+	}
+
+	for _, rule := range test.PoolRules {
+		var candidates []*entities.Question
+		for i := range bank {
+			if !used[bank[i].ID] && rule.Matches(&bank[i]) {
+				candidates = append(candidates, &bank[i])
+			}
+		}
+		rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+		for i := 0; i < rule.Count && i < len(candidates); i++ {
+			serve(candidates[i])
+		}
+	}
+
+	return served
+}
+
+func newMatchOptions(q *entities.Question) []entities.MatchOption {
+	if q.QuestionType != entities.QuestionTypeMatching {
+		return nil
+	}
+
+	options := make([]entities.MatchOption, 0, len(q.Answers))
+	for _, a := range q.Answers {
+		options = append(options, entities.MatchOption{Key: uuid.NewString(), AnswerID: a.ID})
+	}
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	return options
+}
+
+// servedTest возвращает тест в том виде, в каком его получил студент:
+// только вопросы попытки, в её порядке и с её порядком вариантов.
+// Для попыток без сохранённого набора возвращается сам тест.
+func (s *StudentService) servedTest(
+	ctx context.Context,
+	test *entities.Test,
+	session *entities.TestSession,
+) (*entities.Test, error) {
+	if len(session.Served) == 0 {
+		return test, nil
+	}
+
+	byID := make(map[string]*entities.Question, len(test.Questions))
+	for i := range test.Questions {
+		byID[test.Questions[i].ID] = &test.Questions[i]
+	}
+
+	var missing []string
+	for _, sq := range session.Served {
+		if _, ok := byID[sq.QuestionID]; !ok {
+			missing = append(missing, sq.QuestionID)
+		}
+	}
+	if len(missing) > 0 {
+		bank, err := s.testRepo.GetQuestionsByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for i := range bank {
+			byID[bank[i].ID] = &bank[i]
+		}
+	}
+
+	view := *test
+	view.Questions = make([]entities.Question, 0, len(session.Served))
+	for _, sq := range session.Served {
+		q, ok := byID[sq.QuestionID]
+		if !ok {
+			continue // вопрос удалили после начала попытки
+		}
+
+		answers := make(map[string]entities.Answer, len(q.Answers))
+		for _, a := range q.Answers {
+			answers[a.ID] = a
+		}
+
+		served := *q
+		served.MatchOptions = make([]entities.MatchOption, 0, len(sq.MatchOptions))
+		for _, o := range sq.MatchOptions {
+			if a, ok := answers[o.AnswerID]; ok {
+				o.Text = a.MatchText
+				served.MatchOptions = append(served.MatchOptions, o)
+			}
+		}
+		served.Answers = make([]entities.Answer, 0, len(q.Answers))
+		for _, id := range sq.AnswerIDs {
+			if a, ok := answers[id]; ok {
+				served.Answers = append(served.Answers, a)
+				delete(answers, id)
+			}
+		}
+		// Варианты, добавленные после начала попытки, студент не видел
+		view.Questions = append(view.Questions, served)
+	}
+
+	return &view, nil
+}
+
+func (s *StudentService) poolCandidates(ctx context.Context, test *entities.Test) ([]entities.Question, error) {
+	if len(test.PoolRules) == 0 {
+		return nil, nil
+	}
+
+	module, err := s.courseRepo.GetModuleByID(ctx, test.ModuleID)
+	if err != nil {
+		return nil, fmt.Errorf("get module: %w", err)
+	}
+	return s.testRepo.GetBankQuestions(ctx, module.CourseID)
+}
@@ -0,0 +1,560 @@
+package student
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+// memTestRepo хранит тест, банк, попытки и результаты в памяти.
+// Транзакция просто вызывает fn: откат тестам не нужен.
+type memTestRepo struct {
+	TestRepository
+
+	test     *entities.Test
+	bank     []entities.Question
+	sessions []*entities.TestSession
+	results  []entities.TestResult
+	answers  []entities.TestAttemptAnswer
+}
+
+func (r *memTestRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *memTestRepo) GetTestFullByID(_ context.Context, id string) (*entities.Test, error) {
+	if r.test == nil || r.test.ID != id {
+		return nil, entities.ErrNotFound
+	}
+	return r.test, nil
+}
+
+func (r *memTestRepo) LockUserTest(context.Context, string, string) error { return nil }
+
+func (r *memTestRepo) CreateSession(_ context.Context, s *entities.TestSession) error {
+	r.sessions = append(r.sessions, s)
+	return nil
+}
+
+func (r *memTestRepo) GetSession(_ context.Context, id string) (*entities.TestSession, error) {
+	for _, s := range r.sessions {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, entities.ErrNotFound
+}
+
+func (r *memTestRepo) GetSessions(_ context.Context, userID, testID string) ([]*entities.TestSession, error) {
+	var res []*entities.TestSession
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.TestID == testID {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+func (r *memTestRepo) MarkSessionSubmitted(_ context.Context, id, resultID string, at time.Time) error {
+	s, err := r.GetSession(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	s.SubmittedAt, s.ResultID = &at, &resultID
+	return nil
+}
+
+func (r *memTestRepo) SaveResult(_ context.Context, res *entities.TestResult) error {
+	r.results = append(r.results, *res)
+	return nil
+}
+
+func (r *memTestRepo) SaveAttemptAnswers(_ context.Context, answers []entities.TestAttemptAnswer) error {
+	r.answers = append(r.answers, answers...)
+	return nil
+}
+
+func (r *memTestRepo) GetUserResults(_ context.Context, userID string) ([]entities.TestResult, error) {
+	var res []entities.TestResult
+	for _, tr := range r.results {
+		if tr.UserID == userID {
+			res = append(res, tr)
+		}
+	}
+	return res, nil
+}
+
+func (r *memTestRepo) GetBankQuestions(context.Context, string) ([]entities.Question, error) {
+	return r.bank, nil
+}
+
+func (r *memTestRepo) GetQuestionsByIDs(_ context.Context, ids []string) ([]entities.Question, error) {
+	var res []entities.Question
+	for _, id := range ids {
+		for _, q := range r.bank {
+			if q.ID == id {
+				res = append(res, q)
+			}
+		}
+	}
+	return res, nil
+}
+
+type sessionCourses struct {
+	CourseRepository
+}
+
+func (sessionCourses) GetModuleByID(_ context.Context, id string) (*entities.Module, error) {
+	return &entities.Module{ID: id, CourseID: "c1"}, nil
+}
+
+func (sessionCourses) GetByID(_ context.Context, id string) (*entities.Course, error) {
+	return &entities.Course{ID: id, DifficultyLevel: 1}, nil
+}
+
+type noProfile struct {
+	ProfileRepository
+}
+
+func (noProfile) GetByUserID(context.Context, string) (*entities.StudentProfile, error) {
+	return nil, entities.ErrNotFound
+}
+
+type defaultPolicy struct{}
+
+func (defaultPolicy) Policy(context.Context) (entities.GamificationPolicy, error) {
+	return entities.DefaultGamificationPolicy(), nil
+}
+
+type noXP struct {
+	XPLedger
+}
+
+func (noXP) Grant(context.Context, *entities.XPTransaction, entities.LevelCurve) (*entities.XPGrant, error) {
+	return &entities.XPGrant{}, nil
+}
+
+type noEvents struct{}
+
+func (noEvents) PublishDurable(context.Context, entities.DomainEvent) error { return nil }
+
+type noTracker struct{}
+
+func (noTracker) Track(string, *string, string, map[string]any) {}
+
+func newSessionService(repo *memTestRepo) *StudentService {
+	return &StudentService{
+		profileRepo: noProfile{},
+		courseRepo:  sessionCourses{},
+		testRepo:    repo,
+		tracker:     noTracker{},
+		xpLedger:    noXP{},
+		policy:      defaultPolicy{},
+		events:      noEvents{},
+	}
+}
+
+// choiceTest — тест из одного вопроса: верный вариант "right".
+func choiceTest() *entities.Test {
+	return &entities.Test{
+		ID:           "t1",
+		ModuleID:     "m1",
+		PassingScore: 100,
+		Questions: []entities.Question{{
+			ID:           "q1",
+			QuestionType: entities.QuestionTypeSingleChoice,
+			Answers:      []entities.Answer{{ID: "right", IsCorrect: true}, {ID: "wrong"}},
+		}},
+	}
+}
+
+func answer(id string) []StudentAnswer {
+	return []StudentAnswer{{QuestionID: "q1", AnswerIDs: []string{id}}}
+}
+
+// finishedSession — попытка, отправленная ago назад.
+func finishedSession(userID string, ago time.Duration) *entities.TestSession {
+	at := time.Now().UTC().Add(-ago)
+	return &entities.TestSession{ID: "old-" + ago.String(), UserID: userID, TestID: "t1", StartedAt: at.Add(-time.Minute), SubmittedAt: &at}
+}
+
+func TestStartTestReturnsOpenAttempt(t *testing.T) {
+	repo := &memTestRepo{test: choiceTest()}
+	repo.test.MaxAttempts = 1
+	svc := newSessionService(repo)
+
+	first, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+	second, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("second StartTest: %v", err)
+	}
+
+	if second.Session.ID != first.Session.ID {
+		t.Error("open attempt was not reused")
+	}
+	if first.AttemptsUsed != 1 || second.AttemptsUsed != 1 || len(repo.sessions) != 1 {
+		t.Errorf("attempts used %d/%d with %d sessions, want 1/1 with 1", first.AttemptsUsed, second.AttemptsUsed, len(repo.sessions))
+	}
+	if len(second.Test.Questions) != 1 {
+		t.Errorf("served %d questions, want 1", len(second.Test.Questions))
+	}
+}
+
+func TestStartTestAttemptLimit(t *testing.T) {
+	repo := &memTestRepo{test: choiceTest()}
+	repo.test.MaxAttempts = 2
+	repo.sessions = []*entities.TestSession{finishedSession("u1", 2*time.Hour), finishedSession("u1", time.Hour)}
+	svc := newSessionService(repo)
+
+	if _, err := svc.StartTest(context.Background(), "u1", "t1"); !errors.Is(err, entities.ErrAttemptLimitReached) {
+		t.Fatalf("StartTest after 2 of 2 attempts: err = %v, want ErrAttemptLimitReached", err)
+	}
+
+	// Попытки другого студента не считаются
+	if _, err := svc.StartTest(context.Background(), "u2", "t1"); err != nil {
+		t.Errorf("StartTest for another student: %v", err)
+	}
+}
+
+func TestStartTestCooldown(t *testing.T) {
+	tests := []struct {
+		name      string
+		last      *entities.TestSession
+		wantRetry time.Duration // 0 — попытка выдаётся
+	}{
+		{
+			name:      "submitted 10 minutes ago",
+			last:      finishedSession("u1", 10*time.Minute),
+			wantRetry: 50 * time.Minute,
+		},
+		{
+			name: "submitted 2 hours ago",
+			last: finishedSession("u1", 2*time.Hour),
+		},
+		{
+			// Брошенная попытка считается законченной в момент дедлайна
+			name: "expired unsubmitted attempt",
+			last: func() *entities.TestSession {
+				deadline := time.Now().UTC().Add(-20 * time.Minute)
+				return &entities.TestSession{ID: "expired", UserID: "u1", TestID: "t1", StartedAt: deadline.Add(-time.Hour), Deadline: &deadline}
+			}(),
+			wantRetry: 40 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTestRepo{test: choiceTest(), sessions: []*entities.TestSession{tt.last}}
+			repo.test.Cooldown = time.Hour
+			svc := newSessionService(repo)
+
+			start, err := svc.StartTest(context.Background(), "u1", "t1")
+			if tt.wantRetry == 0 {
+				if err != nil {
+					t.Fatalf("StartTest: %v", err)
+				}
+				if start.AttemptsUsed != 2 {
+					t.Errorf("attempts used = %d, want 2", start.AttemptsUsed)
+				}
+				return
+			}
+
+			var cooldown *entities.CooldownError
+			if !errors.As(err, &cooldown) || !errors.Is(err, entities.ErrAttemptCooldown) {
+				t.Fatalf("err = %v, want CooldownError", err)
+			}
+			if diff := cooldown.RetryAfter - tt.wantRetry; diff < -time.Minute || diff > 0 {
+				t.Errorf("retry after %v, want about %v", cooldown.RetryAfter, tt.wantRetry)
+			}
+			if len(repo.sessions) != 1 {
+				t.Errorf("cooldown created a session")
+			}
+		})
+	}
+}
+
+func TestStartTestSetsDeadline(t *testing.T) {
+	repo := &memTestRepo{test: choiceTest()}
+	repo.test.TimeLimit = 15 * time.Minute
+	svc := newSessionService(repo)
+
+	start, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+	s := start.Session
+	if s.Deadline == nil || !s.Deadline.Equal(s.StartedAt.Add(15*time.Minute)) {
+		t.Errorf("deadline = %v, want started at + 15m", s.Deadline)
+	}
+
+	if _, err := svc.StartTest(context.Background(), "u1", "missing"); !errors.Is(err, entities.ErrNotFound) {
+		t.Errorf("unknown test: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSubmitTestDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		overdue time.Duration
+		wantErr error
+	}{
+		{"before the deadline", -time.Minute, nil},
+		{"late but within the grace period", submitGrace - 2*time.Second, nil},
+		{"after the grace period", submitGrace + 2*time.Second, entities.ErrAttemptExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTestRepo{test: choiceTest()}
+			repo.test.TimeLimit = time.Hour
+			svc := newSessionService(repo)
+
+			start, err := svc.StartTest(context.Background(), "u1", "t1")
+			if err != nil {
+				t.Fatalf("StartTest: %v", err)
+			}
+			deadline := time.Now().UTC().Add(-tt.overdue)
+			start.Session.Deadline = &deadline
+
+			_, err = svc.SubmitTest(context.Background(), "u1", "t1", start.Session.ID, answer("right"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitTest: err = %v, want %v", err, tt.wantErr)
+			}
+			if submitted := start.Session.SubmittedAt != nil; submitted != (tt.wantErr == nil) {
+				t.Errorf("session submitted = %v", submitted)
+			}
+		})
+	}
+}
+
+func TestSubmitTestTwiceWithSameAttempt(t *testing.T) {
+	repo := &memTestRepo{test: choiceTest()}
+	svc := newSessionService(repo)
+
+	start, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+
+	sub, err := svc.SubmitTest(context.Background(), "u1", "t1", start.Session.ID, answer("wrong"))
+	if err != nil {
+		t.Fatalf("SubmitTest: %v", err)
+	}
+	if sub.Result.IsPassed {
+		t.Fatal("wrong answer passed the test")
+	}
+
+	if _, err := svc.SubmitTest(context.Background(), "u1", "t1", start.Session.ID, answer("right")); !errors.Is(err, entities.ErrAttemptAlreadySubmitted) {
+		t.Fatalf("second SubmitTest: err = %v, want ErrAttemptAlreadySubmitted", err)
+	}
+	if len(repo.results) != 1 {
+		t.Errorf("saved %d results, want 1", len(repo.results))
+	}
+}
+
+func TestSubmitTestRejectsForeignAttempts(t *testing.T) {
+	repo := &memTestRepo{test: choiceTest()}
+	svc := newSessionService(repo)
+
+	start, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		userID    string
+		attemptID string
+		wantErr   error
+	}{
+		{"no attempt", "u1", "", entities.ErrAttemptRequired},
+		{"unknown attempt", "u1", "nope", entities.ErrAttemptInvalid},
+		{"another student's attempt", "u2", start.Session.ID, entities.ErrAttemptInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.SubmitTest(context.Background(), tt.userID, "t1", tt.attemptID, answer("right")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(repo.results) != 0 {
+		t.Errorf("saved %d results, want 0", len(repo.results))
+	}
+}
+
+// bankQuestion — вопрос банка с единственным верным вариантом "<id>-right".
+func bankQuestion(id string, difficulty int, tags ...string) entities.Question {
+	return entities.Question{
+		ID:           id,
+		CourseID:     "c1",
+		QuestionType: entities.QuestionTypeSingleChoice,
+		Difficulty:   difficulty,
+		Tags:         tags,
+		Answers:      []entities.Answer{{ID: id + "-right", IsCorrect: true}, {ID: id + "-wrong"}},
+	}
+}
+
+func servedIDs(served []entities.ServedQuestion) []string {
+	ids := make([]string, 0, len(served))
+	for _, sq := range served {
+		ids = append(ids, sq.QuestionID)
+	}
+	return ids
+}
+
+func TestAssembleTestDrawsByRule(t *testing.T) {
+	test := &entities.Test{
+		Questions: []entities.Question{{ID: "fixed"}},
+		PoolRules: []entities.PoolRule{
+			{Count: 2, Tags: []string{"algebra"}},
+			{Count: 1, Difficulty: 3},
+		},
+	}
+	bank := []entities.Question{
+		bankQuestion("alg1", 1, "algebra"),
+		bankQuestion("alg2", 3, "algebra"),
+		bankQuestion("alg3", 2, "algebra", "geometry"),
+		bankQuestion("geo1", 3, "geometry"),
+		bankQuestion("geo2", 1, "geometry"),
+	}
+
+	for i := 0; i < 50; i++ {
+		ids := servedIDs(assembleTest(test, bank))
+		if len(ids) != 4 || ids[0] != "fixed" {
+			t.Fatalf("served %v, want the fixed question and 3 from the bank", ids)
+		}
+
+		seen := map[string]bool{}
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("served %v: %s twice", ids, id)
+			}
+			seen[id] = true
+		}
+		for _, id := range ids[1:3] {
+			if id != "alg1" && id != "alg2" && id != "alg3" {
+				t.Fatalf("served %v: %s does not match the algebra rule", ids, id)
+			}
+		}
+		// Вторым правилом подходят alg2 и geo1; alg2 мог уйти первому
+		if last := ids[3]; last != "geo1" && last != "alg2" {
+			t.Fatalf("served %v: %s does not match the difficulty rule", ids, last)
+		}
+	}
+}
+
+func TestAssembleTestSkipsFixedQuestionsInBank(t *testing.T) {
+	// Вопрос теста лежит и в банке курса: второй раз он не выдаётся
+	test := &entities.Test{
+		Questions: []entities.Question{bankQuestion("b1", 1)},
+		PoolRules: []entities.PoolRule{{Count: 5}},
+	}
+	bank := []entities.Question{bankQuestion("b1", 1), bankQuestion("b2", 1), bankQuestion("b3", 1)}
+
+	ids := servedIDs(assembleTest(test, bank))
+	if len(ids) != 3 || ids[0] != "b1" {
+		t.Fatalf("served %v, want b1 and the two other bank questions", ids)
+	}
+	if ids[1] == "b1" || ids[2] == "b1" || ids[1] == ids[2] {
+		t.Errorf("served %v with a duplicate", ids)
+	}
+}
+
+func TestAssembleTestSmallPool(t *testing.T) {
+	test := &entities.Test{PoolRules: []entities.PoolRule{{Count: 10, Tags: []string{"rare"}}}}
+	bank := []entities.Question{bankQuestion("r1", 1, "rare"), bankQuestion("c1", 1, "common")}
+
+	if ids := servedIDs(assembleTest(test, bank)); len(ids) != 1 || ids[0] != "r1" {
+		t.Errorf("served %v, want [r1]", ids)
+	}
+	if ids := servedIDs(assembleTest(test, nil)); len(ids) != 0 {
+		t.Errorf("served %v from an empty bank", ids)
+	}
+}
+
+func TestStartTestFreezesServedQuestions(t *testing.T) {
+	repo := &memTestRepo{
+		test: choiceTest(),
+		bank: []entities.Question{bankQuestion("b1", 1), bankQuestion("b2", 1), bankQuestion("b3", 1), bankQuestion("b4", 1)},
+	}
+	repo.test.PoolRules = []entities.PoolRule{{Count: 2}}
+	svc := newSessionService(repo)
+
+	first, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+	frozen := servedIDs(first.Session.Served)
+	if len(frozen) != 3 || frozen[0] != "q1" {
+		t.Fatalf("served %v, want q1 and 2 bank questions", frozen)
+	}
+
+	// Банк меняется, пока попытка открыта: она видит прежний набор
+	repo.bank = append(repo.bank, bankQuestion("b5", 1), bankQuestion("b6", 1))
+	for i := 0; i < 10; i++ {
+		again, err := svc.StartTest(context.Background(), "u1", "t1")
+		if err != nil {
+			t.Fatalf("StartTest: %v", err)
+		}
+		got := make([]string, 0, len(again.Test.Questions))
+		for _, q := range again.Test.Questions {
+			got = append(got, q.ID)
+		}
+		if !equalIDs(got, frozen) {
+			t.Fatalf("reopened attempt serves %v, want %v", got, frozen)
+		}
+	}
+}
+
+func TestSubmitTestGradesOnlyFrozenQuestions(t *testing.T) {
+	repo := &memTestRepo{
+		test: choiceTest(),
+		bank: []entities.Question{bankQuestion("b1", 1), bankQuestion("b2", 1), bankQuestion("b3", 1), bankQuestion("b4", 1)},
+	}
+	repo.test.PoolRules = []entities.PoolRule{{Count: 2}}
+	svc := newSessionService(repo)
+
+	start, err := svc.StartTest(context.Background(), "u1", "t1")
+	if err != nil {
+		t.Fatalf("StartTest: %v", err)
+	}
+	frozen := servedIDs(start.Session.Served)
+
+	// Отвечаем верно на всё, что есть в банке, и на вопрос теста; неверно —
+	// на первый вопрос банка из попытки. Невыданные вопросы не должны ни
+	// поднять, ни опустить балл.
+	answers := answer("right")
+	for _, q := range repo.bank {
+		pick := q.ID + "-right"
+		if q.ID == frozen[1] {
+			pick = q.ID + "-wrong"
+		}
+		answers = append(answers, StudentAnswer{QuestionID: q.ID, AnswerIDs: []string{pick}})
+	}
+
+	sub, err := svc.SubmitTest(context.Background(), "u1", "t1", start.Session.ID, answers)
+	if err != nil {
+		t.Fatalf("SubmitTest: %v", err)
+	}
+
+	graded := make([]string, 0, len(sub.Questions))
+	for _, r := range sub.Questions {
+		graded = append(graded, r.QuestionID)
+	}
+	if !equalIDs(graded, frozen) {
+		t.Errorf("graded %v, want %v", graded, frozen)
+	}
+	if sub.Result.Score != 67 {
+		t.Errorf("score = %d, want 67 (2 of 3 served questions)", sub.Result.Score)
+	}
+	if len(repo.answers) != len(frozen) {
+		t.Errorf("saved %d attempt answers, want %d", len(repo.answers), len(frozen))
+	}
+}
@@ -0,0 +1,39 @@
+package student
+
+import (
+	"context"
+
+	"backend/internal/entities"
+)
+
+// SetTimezone меняет часовой пояс, по которому считаются дни серии.
+// Уже засчитанные дни не пересчитываются.
+func (s *StudentService) SetTimezone(ctx context.Context, userID, timezone string) error {
+	profile, err := s.profileRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.setTimezone(ctx, profile, timezone); err != nil {
+		return err
+	}
+
+	return s.profileRepo.Update(ctx, profile)
+}
+
+// setTimezone принимает только часовые пояса, которые знают и Go, и
+// Postgres: по ним FinalizeStreaks считает дни в БД.
+func (s *StudentService) setTimezone(ctx context.Context, profile *entities.StudentProfile, timezone string) error {
+	if err := profile.SetTimezone(timezone); err != nil {
+		return err
+	}
+
+	ok, err := s.profileRepo.TimezoneSupported(ctx, timezone)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return entities.ErrInvalidTimezone
+	}
+	return nil
+}
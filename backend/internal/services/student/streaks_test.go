@@ -0,0 +1,147 @@
+package student
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/entities"
+)
+
+func day(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestUpdateStreak(t *testing.T) {
+	// Asia/Tokyo — UTC+9 без перехода на летнее время
+	tests := []struct {
+		name       string
+		last       *time.Time
+		streak     int
+		now        time.Time
+		wantStreak int
+		wantDay    time.Time
+		wantChange bool
+	}{
+		{
+			name:       "first activity",
+			now:        time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			wantStreak: 1, wantDay: *day(2026, 3, 10), wantChange: true,
+		},
+		{
+			name: "early local morning counts for the local day",
+			last: day(2026, 3, 10), streak: 4,
+			// 2026-03-10 18:00 UTC — уже 03:00 11 марта в Токио
+			now:        time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC),
+			wantStreak: 5, wantDay: *day(2026, 3, 11), wantChange: true,
+		},
+		{
+			name: "same local day",
+			last: day(2026, 3, 11), streak: 5,
+			// 2026-03-11 14:59 UTC — 23:59 11 марта в Токио
+			now:        time.Date(2026, 3, 11, 14, 59, 0, 0, time.UTC),
+			wantStreak: 5, wantDay: *day(2026, 3, 11), wantChange: false,
+		},
+		{
+			name: "unfinalized gap restarts the streak",
+			last: day(2026, 3, 8), streak: 5,
+			now:        time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC),
+			wantStreak: 1, wantDay: *day(2026, 3, 11), wantChange: true,
+		},
+		{
+			name: "reset streak starts over",
+			last: day(2026, 3, 10), streak: 0,
+			now:        time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC),
+			wantStreak: 1, wantDay: *day(2026, 3, 11), wantChange: true,
+		},
+		{
+			name: "across a year boundary",
+			last: day(2026, 12, 31), streak: 9,
+			now:        time.Date(2026, 12, 31, 15, 30, 0, 0, time.UTC),
+			wantStreak: 10, wantDay: *day(2027, 1, 1), wantChange: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &entities.StudentProfile{
+				Timezone:         "Asia/Tokyo",
+				CurrentStreak:    tt.streak,
+				MaxStreak:        tt.streak,
+				LastActivityDate: tt.last,
+			}
+
+			changed := updateStreak(p, tt.now)
+			if changed != tt.wantChange {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChange)
+			}
+			if p.CurrentStreak != tt.wantStreak {
+				t.Errorf("streak = %d, want %d", p.CurrentStreak, tt.wantStreak)
+			}
+			if p.LastActivityDate == nil || !p.LastActivityDate.Equal(tt.wantDay) {
+				t.Errorf("last day = %v, want %v", p.LastActivityDate, tt.wantDay)
+			}
+			if p.MaxStreak < p.CurrentStreak {
+				t.Errorf("max streak %d below current %d", p.MaxStreak, p.CurrentStreak)
+			}
+		})
+	}
+}
+
+// timezoneRepo — профиль в памяти и список поясов, известных «Postgres».
+type timezoneRepo struct {
+	ProfileRepository
+
+	profile *entities.StudentProfile
+	known   map[string]bool
+	updated bool
+}
+
+func (r *timezoneRepo) GetByUserID(context.Context, string) (*entities.StudentProfile, error) {
+	return r.profile, nil
+}
+
+func (r *timezoneRepo) Update(context.Context, *entities.StudentProfile) error {
+	r.updated = true
+	return nil
+}
+
+func (r *timezoneRepo) TimezoneSupported(_ context.Context, name string) (bool, error) {
+	return r.known[name], nil
+}
+
+func TestSetTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		wantErr  error
+	}{
+		{"known everywhere", "Asia/Almaty", nil},
+		{"unknown to Go", "Mars/Olympus", entities.ErrInvalidTimezone},
+		{"unknown to Postgres", "Asia/Qostanay", entities.ErrInvalidTimezone},
+		{"empty", "", entities.ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &timezoneRepo{
+				profile: &entities.StudentProfile{Timezone: entities.DefaultTimezone},
+				known:   map[string]bool{"Asia/Almaty": true},
+			}
+			svc := &StudentService{profileRepo: repo}
+
+			err := svc.SetTimezone(context.Background(), "u1", tt.timezone)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("SetTimezone() = %v, want %v", err, tt.wantErr)
+			}
+			if repo.updated != (tt.wantErr == nil) {
+				t.Errorf("profile updated = %v", repo.updated)
+			}
+			if tt.wantErr == nil && repo.profile.Timezone != tt.timezone {
+				t.Errorf("timezone = %q, want %q", repo.profile.Timezone, tt.timezone)
+			}
+		})
+	}
+}
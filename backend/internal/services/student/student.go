@@ -2,6 +2,7 @@ package student
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,12 +14,17 @@ import (
 type ProfileRepository interface {
 	Create(ctx context.Context, profile *entities.StudentProfile) error
 	GetByUserID(ctx context.Context, userID string) (*entities.StudentProfile, error)
+	GetByUserIDForUpdate(ctx context.Context, userID string) (*entities.StudentProfile, error)
 	Update(ctx context.Context, profile *entities.StudentProfile) error
+	UpdateStreak(ctx context.Context, profile *entities.StudentProfile) error
 	Exists(ctx context.Context, userID string) (bool, error)
+	TimezoneSupported(ctx context.Context, name string) (bool, error)
 	GetLeaderboard(ctx context.Context, limit int) ([]*entities.StudentProfile, error)
-	GetLeagueLeaderboard(ctx context.Context, leagueID int, limit int) ([]*entities.StudentProfile, error)
+	GetLeagueLeaderboard(ctx context.Context, leagueID int, cohortID string, limit int) ([]*entities.StudentProfile, error)
 	GetUserGlobalRank(ctx context.Context, userID string) (int, error)
 	GetUserLeagueRank(ctx context.Context, userID string) (int, error)
+	AddStreakFreeze(ctx context.Context, userID string, max int) (bool, error)
+	FinalizeStreaks(ctx context.Context, now time.Time, userID string) (entities.StreakFinalization, error)
 }
 
 type SubjectRepository interface {
@@ -34,6 +40,7 @@ type ProgressRepository interface {
 	CountCompletedLessonsInCourse(ctx context.Context, userID, courseID string) (int, error)
 	UpsertCourseProgress(ctx context.Context, cp *entities.CourseProgress) error
 	GetAllUserActiveCourses(ctx context.Context, userID string) ([]entities.CourseProgress, error)
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type CourseRepository interface {
@@ -45,11 +52,23 @@ type CourseRepository interface {
 
 type GamificationRepository interface {
 	GetAllLeagues(ctx context.Context) ([]entities.League, error)
+	GetUserHistory(ctx context.Context, userID string, limit, offset int) ([]entities.LeaderboardHistory, int, error)
+	GetHistoryTable(ctx context.Context, userID string, periodStart time.Time) ([]entities.LeaderboardHistory, error)
 }
 
 type TestRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	GetTestByModuleID(ctx context.Context, moduleID string) (*entities.Test, error)
 	SaveResult(ctx context.Context, res *entities.TestResult) error
+	SaveAttemptAnswers(ctx context.Context, answers []entities.TestAttemptAnswer) error
+	GetAttempts(ctx context.Context, testID, userID string, limit int) ([]entities.TestAttempt, error)
+	LockUserTest(ctx context.Context, userID, testID string) error
+	CreateSession(ctx context.Context, s *entities.TestSession) error
+	GetSession(ctx context.Context, id string) (*entities.TestSession, error)
+	GetSessions(ctx context.Context, userID, testID string) ([]*entities.TestSession, error)
+	MarkSessionSubmitted(ctx context.Context, id, resultID string, at time.Time) error
+	GetBankQuestions(ctx context.Context, courseID string) ([]entities.Question, error)
+	GetQuestionsByIDs(ctx context.Context, ids []string) ([]entities.Question, error)
 	GetTestFullByID(ctx context.Context, testID string) (*entities.Test, error)
 	GetUserResults(ctx context.Context, userID string) ([]entities.TestResult, error)
 }
@@ -58,6 +77,33 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id string) (*entities.User, error)
 }
 
+type ActivityTracker interface {
+	Track(userID string, courseID *string, action string, meta map[string]any)
+}
+
+// XPLedger начисляет XP через журнал xp_transactions: один источник даёт
+// ученику XP не больше одного раза, а профиль обновляется атомарно.
+type XPLedger interface {
+	Grant(ctx context.Context, t *entities.XPTransaction, curve entities.LevelCurve) (*entities.XPGrant, error)
+	GetHistory(ctx context.Context, userID string, limit, offset int) ([]entities.XPTransaction, int, error)
+}
+
+// PolicyProvider отдаёт действующие правила начисления XP и кривую уровней.
+type PolicyProvider interface {
+	Policy(ctx context.Context) (entities.GamificationPolicy, error)
+}
+
+// CohortStore распределяет учеников по недельным когортам лиг.
+type CohortStore interface {
+	JoinCohort(ctx context.Context, userID string) (string, error)
+}
+
+// EventPublisher — шина доменных событий. PublishDurable пишет событие
+// в транзакцию из ctx, поэтому оно не теряется вместе с прогрессом.
+type EventPublisher interface {
+	PublishDurable(ctx context.Context, event entities.DomainEvent) error
+}
+
 type StudentService struct {
 	profileRepo      ProfileRepository
 	subjectRepo      SubjectRepository
@@ -66,6 +112,11 @@ type StudentService struct {
 	gamificationRepo GamificationRepository
 	testRepo         TestRepository
 	userRepo         UserRepository
+	tracker          ActivityTracker
+	xpLedger         XPLedger
+	policy           PolicyProvider
+	cohorts          CohortStore
+	events           EventPublisher
 }
 
 func NewStudentService(
@@ -76,6 +127,11 @@ func NewStudentService(
 	gRepo GamificationRepository,
 	tRepo TestRepository,
 	uRepo UserRepository,
+	tracker ActivityTracker,
+	xpLedger XPLedger,
+	policy PolicyProvider,
+	cohorts CohortStore,
+	events EventPublisher,
 ) *StudentService {
 	return &StudentService{
 		profileRepo:      pRepo,
@@ -85,126 +141,249 @@ func NewStudentService(
 		gamificationRepo: gRepo,
 		testRepo:         tRepo,
 		userRepo:         uRepo,
+		tracker:          tracker,
+		xpLedger:         xpLedger,
+		policy:           policy,
+		cohorts:          cohorts,
+		events:           events,
 	}
 }
 
-type StudentAnswer struct {
-	QuestionID string
-	AnswerID   string
+// TestSubmission — результат отправки теста вместе с разбором по вопросам.
+type TestSubmission struct {
+	Result    *entities.TestResult
+	XPGained  int
+	LevelUp   *entities.LevelUp
+	Questions []QuestionResult
+}
+
+// LessonCompletion — итог завершения урока.
+type LessonCompletion struct {
+	Progress *entities.LessonProgress
+	XPGained int
+	LevelUp  *entities.LevelUp
 }
 
-func (s *StudentService) SubmitTest(ctx context.Context, userID, testID string, answers []StudentAnswer) (*entities.TestResult, int, error) {
+// SubmitTest проверяет ответы. attemptID — токен из StartTest: вопросы
+// студент получает только в попытке, поэтому без неё тест не сдать.
+func (s *StudentService) SubmitTest(
+	ctx context.Context,
+	userID, testID, attemptID string,
+	answers []StudentAnswer,
+) (*TestSubmission, error) {
 	test, err := s.testRepo.GetTestFullByID(ctx, testID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
+	firstTry := false
 	results, err := s.testRepo.GetUserResults(ctx, userID)
 	if err == nil {
+		firstTry = true
 		for _, r := range results {
-			if r.TestID == testID && r.IsPassed {
-				return &r, 0, nil
+			if r.TestID != testID {
+				continue
 			}
-		}
-	}
-
-	correctCount := 0
-	totalQuestions := len(test.Questions)
-
-	correctMap := make(map[string]string)
-	for _, q := range test.Questions {
-		for _, a := range q.Answers {
-			if a.IsCorrect {
-				correctMap[q.ID] = a.ID
-				break
+			if r.IsPassed {
+				return &TestSubmission{Result: &r}, nil
 			}
+			firstTry = false
 		}
 	}
 
-	for _, userAns := range answers {
-		if correctID, ok := correctMap[userAns.QuestionID]; ok {
-			if correctID == userAns.AnswerID {
-				correctCount++
-			}
-		}
+	if attemptID == "" {
+		return nil, entities.ErrAttemptRequired
 	}
 
-	score := 0
-	if totalQuestions > 0 {
-		score = (correctCount * 100) / totalQuestions
+	policy, err := s.policy.Policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var course *entities.Course
+	if module, err := s.courseRepo.GetModuleByID(ctx, test.ModuleID); err == nil {
+		course, _ = s.courseRepo.GetByID(ctx, module.CourseID)
 	}
-	isPassed := score >= test.PassingScore
 
+	now := time.Now().UTC()
 	result := &entities.TestResult{
 		ID:          uuid.NewString(),
 		UserID:      userID,
 		TestID:      testID,
-		Score:       score,
-		IsPassed:    isPassed,
-		AttemptDate: time.Now().UTC(),
+		AttemptDate: now,
 	}
-	if err := s.testRepo.SaveResult(ctx, result); err != nil {
-		return nil, 0, err
+	var (
+		questionResults []QuestionResult
+		grant           *entities.XPGrant
+	)
+	err = s.testRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Проверяем ровно те вопросы, которые студент видел в попытке
+		session, err := s.checkSession(ctx, userID, testID, attemptID, now)
+		if err != nil {
+			return err
+		}
+		served, err := s.servedTest(ctx, test, session)
+		if err != nil {
+			return err
+		}
+
+		result.Score, questionResults = gradeTest(served, answers)
+		result.IsPassed = result.Score >= test.PassingScore
+
+		if err := s.testRepo.SaveResult(ctx, result); err != nil {
+			return err
+		}
+		if err := s.testRepo.MarkSessionSubmitted(ctx, attemptID, result.ID, now); err != nil {
+			return err
+		}
+		if err := s.testRepo.SaveAttemptAnswers(ctx, buildAttemptAnswers(result.ID, served, questionResults)); err != nil {
+			return err
+		}
+		if result.IsPassed {
+			streak := 0
+			if profile, err := s.profileRepo.GetByUserID(ctx, userID); err == nil {
+				streak = profile.CurrentStreak
+			}
+			amount := policy.XP.TestXP(result.Score, firstTry, courseDifficulty(course), streak)
+
+			var err error
+			grant, err = s.grantXP(ctx, userID, entities.XPSourceTest, testID, amount, policy.Levels)
+			if err != nil {
+				return err
+			}
+		}
+		return s.publish(ctx, entities.DomainTestSubmitted, userID, entities.TestSubmitted{
+			TestID:   testID,
+			ResultID: result.ID,
+			Score:    result.Score,
+			IsPassed: result.IsPassed,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	xp := 0
-	if isPassed {
-		xp = 50
-		profile, _ := s.profileRepo.GetByUserID(ctx, userID)
-		s.addXPToProfile(ctx, profile, int64(xp))
+	score, isPassed := result.Score, result.IsPassed
+
+	var courseID *string
+	if course != nil {
+		courseID = &course.ID
 	}
+	s.tracker.Track(userID, courseID, entities.ActionTestSubmit, map[string]any{
+		"test_id":   testID,
+		"score":     score,
+		"is_passed": isPassed,
+	})
 
-	return result, xp, nil
+	submission := &TestSubmission{
+		Result:    result,
+		Questions: questionResults,
+	}
+	if grant != nil {
+		submission.XPGained, submission.LevelUp = grant.Amount, grant.LevelUp
+	}
+	return submission, nil
 }
 
-func (s *StudentService) CompleteLesson(ctx context.Context, userID, lessonID string) (*entities.LessonProgress, int, error) {
+func (s *StudentService) CompleteLesson(ctx context.Context, userID, lessonID string) (*LessonCompletion, error) {
 	lesson, err := s.courseRepo.GetLessonByID(ctx, lessonID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("lesson not found: %w", err)
+		return nil, fmt.Errorf("lesson not found: %w", err)
 	}
 
 	progress, err := s.progressRepo.GetLessonProgress(ctx, userID, lessonID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	if progress.IsCompleted {
-		return progress, 0, nil
+		return &LessonCompletion{Progress: progress}, nil
+	}
+
+	module, err := s.courseRepo.GetModuleByID(ctx, lesson.ModuleID)
+	if err != nil {
+		return nil, fmt.Errorf("module not found: %w", err)
+	}
+	course, err := s.courseRepo.GetByID(ctx, module.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
 	}
 
+	policy, err := s.policy.Policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
 	progress.IsCompleted = true
 	progress.Status = entities.StatusCompleted
-	progress.LastAccessedAt = time.Now().UTC()
+	progress.LastAccessedAt = now
 
-	if err := s.progressRepo.UpsertLessonProgress(ctx, progress); err != nil {
-		return nil, 0, err
+	// Пропуски, которые ежедневный финализатор ещё не успел закрыть,
+	// закрываются здесь, чтобы урок продлил уже актуальную серию
+	if _, err := s.profileRepo.FinalizeStreaks(ctx, now, userID); err != nil {
+		return nil, err
 	}
 
-	xpAwarded := 0
-	if lesson.XPReward > 0 {
-		profile, err := s.profileRepo.GetByUserID(ctx, userID)
-		if err == nil {
-			s.updateStreak(profile)
+	completed := entities.LessonCompleted{LessonID: lessonID, CourseID: module.CourseID}
 
-			s.addXPToProfile(ctx, profile, int64(lesson.XPReward))
-			xpAwarded = lesson.XPReward
+	// Урок, серия, XP и событие сохраняются вместе. Профиль читается под
+	// блокировкой, а пишется только серия: иначе устаревшая копия затёрла бы
+	// то, что поменялось параллельно
+	var grant *entities.XPGrant
+	err = s.progressRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.progressRepo.UpsertLessonProgress(ctx, progress); err != nil {
+			return err
 		}
-	}
+		profile, err := s.profileRepo.GetByUserIDForUpdate(ctx, userID)
+		if err != nil && !errors.Is(err, entities.ErrNotFound) {
+			return err
+		}
+		if profile != nil {
+			extended := updateStreak(profile, now)
+			completed.Streak = profile.CurrentStreak
+			if extended {
+				if err := s.profileRepo.UpdateStreak(ctx, profile); err != nil {
+					return err
+				}
+				if policy.Streaks.EarnsFreeze(profile.CurrentStreak) {
+					if _, err := s.profileRepo.AddStreakFreeze(ctx, userID, policy.Streaks.MaxFreezes); err != nil {
+						return err
+					}
+				}
+			}
+			amount := policy.XP.LessonXP(lesson.XPReward, course.DifficultyLevel, profile.CurrentStreak)
 
-	module, err := s.courseRepo.GetModuleByID(ctx, lesson.ModuleID)
-	if err == nil {
-		go s.recalculateCourseProgress(context.Background(), userID, module.CourseID)
+			if grant, err = s.grantXP(ctx, userID, entities.XPSourceLesson, lessonID, amount, policy.Levels); err != nil {
+				return err
+			}
+			if grant != nil {
+				completed.XP = grant.Amount
+			}
+		}
+		return s.publish(ctx, entities.DomainLessonCompleted, userID, completed)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return progress, xpAwarded, nil
+	s.tracker.Track(userID, &module.CourseID, entities.ActionLessonComplete, map[string]any{"lesson_id": lessonID})
+
+	completion := &LessonCompletion{Progress: progress, XPGained: completed.XP}
+	if grant != nil {
+		completion.LevelUp = grant.LevelUp
+	}
+	return completion, nil
 }
 
-func (s *StudentService) updateStreak(profile *entities.StudentProfile) {
-	now := time.Now().UTC()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// updateStreak засчитывает в серию локальный день ученика, в который
+// пришлось now. Пропущенные дни к этому моменту уже закрыты FinalizeStreaks:
+// покрытые заморозкой продвигают LastActivityDate, иначе серия обнулена.
+// Возвращает true, если серия выросла.
+func updateStreak(profile *entities.StudentProfile, now time.Time) bool {
+	today := profile.LocalDay(now)
 
-	if profile.LastActivityDate == nil {
-		// Первая активность
+	if profile.LastActivityDate == nil || profile.CurrentStreak == 0 {
+		// Первая активность или серия начинается заново
 		profile.CurrentStreak = 1
 		profile.LastActivityDate = &today
 	} else {
@@ -217,15 +396,15 @@ func (s *StudentService) updateStreak(profile *entities.StudentProfile) {
 
 		daysDiff := int(today.Sub(lastActivity).Hours() / 24)
 
-		if daysDiff == 0 {
-			// Активность сегодня уже была, ничего не меняем
-			return
+		if daysDiff <= 0 {
+			// Этот день уже засчитан, ничего не меняем
+			return false
 		} else if daysDiff == 1 {
 			// Следующий день подряд - увеличиваем стрик
 			profile.CurrentStreak++
 			profile.LastActivityDate = &today
 		} else {
-			// Пропустил дни - стрик сбрасывается
+			// Пропуск не закрыт финализатором - стрик сбрасывается
 			profile.CurrentStreak = 1
 			profile.LastActivityDate = &today
 		}
@@ -235,26 +414,61 @@ func (s *StudentService) updateStreak(profile *entities.StudentProfile) {
 	if profile.CurrentStreak > profile.MaxStreak {
 		profile.MaxStreak = profile.CurrentStreak
 	}
+	return true
 }
 
-func (s *StudentService) addXPToProfile(ctx context.Context, profile *entities.StudentProfile, xp int64) {
-	profile.XP += xp
-	profile.WeeklyXP += xp
+// grantXP начисляет XP за источник. Возвращает nil, если начислять нечего,
+// источник уже давал XP или у пользователя нет профиля.
+func (s *StudentService) grantXP(
+	ctx context.Context,
+	userID string,
+	sourceType entities.XPSourceType,
+	sourceID string,
+	amount int,
+	curve entities.LevelCurve,
+) (*entities.XPGrant, error) {
+	if amount <= 0 {
+		return nil, nil
+	}
 
-	// Простой расчет уровня: 100 XP = 1 уровень
-	newLevel := int(profile.XP/100) + 1
-	if newLevel > profile.Level {
-		profile.Level = newLevel
+	grant, err := s.xpLedger.Grant(ctx, entities.NewXPTransaction(userID, sourceType, sourceID, amount), curve)
+	if errors.Is(err, entities.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil || grant.Amount == 0 {
+		return nil, err
 	}
+	return grant, nil
+}
 
-	profile.UpdatedAt = time.Now().UTC()
-	s.profileRepo.Update(ctx, profile)
+func courseDifficulty(course *entities.Course) int {
+	if course == nil {
+		return 0
+	}
+	return course.DifficultyLevel
 }
 
-func (s *StudentService) recalculateCourseProgress(ctx context.Context, userID, courseID string) {
+// GetXPHistory возвращает страницу журнала начислений ученика и общее
+// число записей.
+func (s *StudentService) GetXPHistory(ctx context.Context, userID string, limit, offset int) ([]entities.XPTransaction, int, error) {
+	return s.xpLedger.GetHistory(ctx, userID, limit, offset)
+}
+
+// OnLessonCompleted — подписчик шины: пересчитывает прогресс курса после
+// завершения урока. Пересчёт идемпотентен, поэтому повторная доставка
+// события безопасна.
+func (s *StudentService) OnLessonCompleted(ctx context.Context, event entities.DomainEvent) error {
+	var p entities.LessonCompleted
+	if err := event.Decode(&p); err != nil {
+		return err
+	}
+	return s.recalculateCourseProgress(ctx, event.UserID, p.CourseID)
+}
+
+func (s *StudentService) recalculateCourseProgress(ctx context.Context, userID, courseID string) error {
 	modules, err := s.courseRepo.GetCourseStructure(ctx, courseID)
 	if err != nil {
-		return
+		return fmt.Errorf("get course structure: %w", err)
 	}
 
 	totalLessons := 0
@@ -262,13 +476,13 @@ func (s *StudentService) recalculateCourseProgress(ctx context.Context, userID,
 		totalLessons += len(m.Lessons)
 	}
 	if totalLessons == 0 {
-		return
+		return nil
 	}
 
 	// 2. Считаем пройденные
 	completedCount, err := s.progressRepo.CountCompletedLessonsInCourse(ctx, userID, courseID)
 	if err != nil {
-		return
+		return err
 	}
 
 	// 3. Вычисляем процент
@@ -285,14 +499,33 @@ func (s *StudentService) recalculateCourseProgress(ctx context.Context, userID,
 		IsCompleted:           isCompleted,
 		UpdatedAt:             time.Now().UTC(),
 	}
-	s.progressRepo.UpsertCourseProgress(ctx, progress)
+	return s.progressRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.progressRepo.UpsertCourseProgress(ctx, progress); err != nil {
+			return err
+		}
+		if !isCompleted {
+			return nil
+		}
+		return s.publish(ctx, entities.DomainCourseCompleted, userID, entities.CourseCompleted{CourseID: courseID})
+	})
+}
+
+// publish сохраняет событие в outbox в транзакции из ctx.
+func (s *StudentService) publish(ctx context.Context, eventType entities.DomainEventType, userID string, payload any) error {
+	event, err := entities.NewDomainEvent(eventType, userID, payload)
+	if err != nil {
+		return err
+	}
+	return s.events.PublishDurable(ctx, event)
 }
 
 func (s *StudentService) GetCourseProgress(ctx context.Context, userID, courseID string) ([]string, error) {
 	return s.progressRepo.GetCompletedLessonIDs(ctx, userID, courseID)
 }
 
-func (s *StudentService) CompleteOnboarding(ctx context.Context, userID string, grade int, subjectIDs []string) error {
+// CompleteOnboarding создаёт или обновляет профиль. Пустой timezone
+// оставляет текущий часовой пояс серии.
+func (s *StudentService) CompleteOnboarding(ctx context.Context, userID string, grade int, subjectIDs []string, timezone string) error {
 	exists, err := s.profileRepo.Exists(ctx, userID)
 	if err != nil {
 		return err
@@ -304,6 +537,11 @@ func (s *StudentService) CompleteOnboarding(ctx context.Context, userID string,
 			return err
 		}
 		profile.Grade = grade
+		if timezone != "" {
+			if err := s.setTimezone(ctx, profile, timezone); err != nil {
+				return err
+			}
+		}
 		if err := s.profileRepo.Update(ctx, profile); err != nil {
 			return err
 		}
@@ -312,9 +550,17 @@ func (s *StudentService) CompleteOnboarding(ctx context.Context, userID string,
 		if err != nil {
 			return err
 		}
+		if timezone != "" {
+			if err := s.setTimezone(ctx, profile, timezone); err != nil {
+				return err
+			}
+		}
 		if err := s.profileRepo.Create(ctx, profile); err != nil {
 			return err
 		}
+		if _, err := s.cohorts.JoinCohort(ctx, userID); err != nil {
+			return fmt.Errorf("failed to join league cohort: %w", err)
+		}
 	}
 
 	if err := s.subjectRepo.SetInterests(ctx, userID, subjectIDs); err != nil {
@@ -328,6 +574,9 @@ type DashboardData struct {
 	Profile       *entities.StudentProfile
 	Interests     []entities.Subject
 	ActiveCourses []ActiveCourseData
+	// LastWeek — итог прошедшей недели в лиге; nil, если ученик в ней
+	// не участвовал.
+	LastWeek *entities.LeaderboardHistory
 }
 
 type ActiveCourseData struct {
@@ -345,8 +594,6 @@ func (s *StudentService) GetDashboardData(ctx context.Context, userID string) (*
 		return nil, nil
 	}
 
-	profile.CurrentStreak = calculateDisplayStreak(profile)
-
 	interests, err := s.subjectRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -372,10 +619,16 @@ func (s *StudentService) GetDashboardData(ctx context.Context, userID string) (*
 		}
 	}
 
+	lastWeek, err := s.lastWeekResult(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	return &DashboardData{
 		Profile:       profile,
 		Interests:     interests,
 		ActiveCourses: activeCourses,
+		LastWeek:      lastWeek,
 	}, nil
 }
 
@@ -390,13 +643,17 @@ type LeaderboardEntry struct {
 	LeagueID  int
 }
 
+// GetWeeklyLeaderboard возвращает недельную таблицу когорты ученика и
+// ничего не меняет: когорты назначают только сброс и онбординг. Пока сброс
+// не выполнен, ученик видит когорту прошлой недели, а ученик без когорты —
+// таблицу учеников лиги без когорты, с которыми его и ранжирует сброс.
 func (s *StudentService) GetWeeklyLeaderboard(ctx context.Context, userID string, limit int) ([]LeaderboardEntry, *int, error) {
 	profile, err := s.profileRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get user profile: %w", err)
 	}
 
-	profiles, err := s.profileRepo.GetLeagueLeaderboard(ctx, profile.CurrentLeagueID, limit)
+	profiles, err := s.profileRepo.GetLeagueLeaderboard(ctx, profile.CurrentLeagueID, profile.CohortID, limit)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get league leaderboard: %w", err)
 	}
@@ -521,7 +778,7 @@ func (s *StudentService) GetStudentHeaderInfo(ctx context.Context, userID string
 	streak := 0
 	xp := int64(0)
 	if err == nil {
-		streak = calculateDisplayStreak(profile)
+		streak = profile.CurrentStreak
 		xp = profile.XP
 	}
 
@@ -534,27 +791,3 @@ func (s *StudentService) GetStudentHeaderInfo(ctx context.Context, userID string
 		XP:            xp,
 	}, nil
 }
-
-func calculateDisplayStreak(profile *entities.StudentProfile) int {
-	if profile.LastActivityDate == nil {
-		return 0
-	}
-
-	now := time.Now().UTC()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-
-	lastActivity := time.Date(
-		profile.LastActivityDate.Year(),
-		profile.LastActivityDate.Month(),
-		profile.LastActivityDate.Day(),
-		0, 0, 0, 0, time.UTC,
-	)
-
-	daysDiff := int(today.Sub(lastActivity).Hours() / 24)
-
-	if daysDiff > 1 {
-		return 0
-	}
-
-	return profile.CurrentStreak
-}
@@ -0,0 +1,67 @@
+package student
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/entities"
+)
+
+// leaderboardRepo — когорта в памяти; запоминает, какую таблицу запросили.
+type leaderboardRepo struct {
+	ProfileRepository
+
+	profile  *entities.StudentProfile
+	members  []*entities.StudentProfile
+	leagueID int
+	cohortID string
+}
+
+func (r *leaderboardRepo) GetByUserID(context.Context, string) (*entities.StudentProfile, error) {
+	return r.profile, nil
+}
+
+func (r *leaderboardRepo) GetLeagueLeaderboard(_ context.Context, leagueID int, cohortID string, _ int) ([]*entities.StudentProfile, error) {
+	r.leagueID, r.cohortID = leagueID, cohortID
+	return r.members, nil
+}
+
+func (r *leaderboardRepo) GetUserLeagueRank(context.Context, string) (int, error) {
+	return 0, entities.ErrNotFound
+}
+
+func TestGetWeeklyLeaderboardOnlyReads(t *testing.T) {
+	tests := []struct {
+		name     string
+		cohortID string
+	}{
+		// До сброса у ученика всё ещё когорта прошлой недели
+		{"current cohort", "c-last-week"},
+		{"no cohort yet", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			me := &entities.StudentProfile{UserID: "u1", CurrentLeagueID: 2, CohortID: tt.cohortID, WeeklyXP: 40}
+			repo := &leaderboardRepo{
+				profile: me,
+				members: []*entities.StudentProfile{{UserID: "u2", CurrentLeagueID: 2, WeeklyXP: 90}, me},
+			}
+			// cohorts == nil: любое обращение к когортам уронит тест
+			svc := &StudentService{profileRepo: repo}
+
+			entries, rank, err := svc.GetWeeklyLeaderboard(context.Background(), "u1", 50)
+			if err != nil {
+				t.Fatalf("GetWeeklyLeaderboard: %v", err)
+			}
+			if repo.leagueID != 2 || repo.cohortID != tt.cohortID {
+				t.Errorf("read league %d cohort %q, want 2 %q", repo.leagueID, repo.cohortID, tt.cohortID)
+			}
+			if len(entries) != 2 || entries[0].UserID != "u2" {
+				t.Fatalf("entries = %+v", entries)
+			}
+			if rank == nil || *rank != 2 {
+				t.Errorf("rank = %v, want 2", rank)
+			}
+		})
+	}
+}
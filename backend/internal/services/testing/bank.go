@@ -0,0 +1,59 @@
+package testing
+
+import (
+	"context"
+
+	"backend/internal/entities"
+	"backend/internal/services/authz"
+)
+
+// Банк вопросов курса: вопросы без теста, из которых тесты добирают
+// случайные вопросы по PoolRule. Менять банк может только автор курса.
+
+func (s *TestService) GetBankQuestions(ctx context.Context, actor authz.Actor, courseID string) ([]entities.Question, error) {
+	if err := s.policy.CanManageCourse(ctx, actor, courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBankQuestions(ctx, courseID)
+}
+
+func (s *TestService) CreateBankQuestion(ctx context.Context, actor authz.Actor, q *entities.Question) error {
+	if err := s.policy.CanManageCourse(ctx, actor, q.CourseID); err != nil {
+		return err
+	}
+
+	return s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.addQuestion(ctx, q)
+	})
+}
+
+// UpdateBankQuestion обновляет вопрос банка; варианты сравниваются по ID,
+// как и в UpdateFullTest.
+func (s *TestService) UpdateBankQuestion(ctx context.Context, actor authz.Actor, q *entities.Question) error {
+	return s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := s.repo.GetBankQuestion(ctx, q.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanManageCourse(ctx, actor, old.CourseID); err != nil {
+			return err
+		}
+		q.CourseID = old.CourseID
+
+		if err := s.repo.UpdateBankQuestion(ctx, q); err != nil {
+			return err
+		}
+		return s.syncAnswers(ctx, q, old)
+	})
+}
+
+func (s *TestService) DeleteBankQuestion(ctx context.Context, actor authz.Actor, questionID string) error {
+	q, err := s.repo.GetBankQuestion(ctx, questionID)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.CanManageCourse(ctx, actor, q.CourseID); err != nil {
+		return err
+	}
+	return s.repo.DeleteBankQuestion(ctx, questionID)
+}
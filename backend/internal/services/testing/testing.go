@@ -2,50 +2,82 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"backend/internal/entities"
+	"backend/internal/services/authz"
+
+	"github.com/google/uuid"
 )
 
 type TestRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
 	CreateTest(ctx context.Context, test *entities.Test) error
 	AddQuestion(ctx context.Context, q *entities.Question) error
 	AddAnswer(ctx context.Context, a *entities.Answer) error
 	GetTestByModuleID(ctx context.Context, moduleID string) (*entities.Test, error)
+	GetTestFullByID(ctx context.Context, testID string) (*entities.Test, error)
 
 	UpdateTest(ctx context.Context, test *entities.Test) error
+	UpdateQuestion(ctx context.Context, q *entities.Question) error
+	UpdateAnswer(ctx context.Context, a *entities.Answer) error
 	DeleteTest(ctx context.Context, testID string) error
-	DeleteQuestionsByTestID(ctx context.Context, testID string) error
+	DeleteQuestionsExcept(ctx context.Context, testID string, keepIDs []string) error
+	DeleteAnswersExcept(ctx context.Context, questionID string, keepIDs []string) error
+
+	ReplacePoolRules(ctx context.Context, testID string, rules []entities.PoolRule) error
+
+	GetAttempts(ctx context.Context, testID, userID string, limit int) ([]entities.TestAttempt, error)
+
+	GetBankQuestions(ctx context.Context, courseID string) ([]entities.Question, error)
+	GetBankQuestion(ctx context.Context, id string) (*entities.Question, error)
+	UpdateBankQuestion(ctx context.Context, q *entities.Question) error
+	DeleteBankQuestion(ctx context.Context, id string) error
+}
+
+// teacherAttemptsLimit ограничивает выдачу, когда учитель смотрит попытки всех студентов.
+const teacherAttemptsLimit = 200
+
+// Policy проверяет, что пользователь — автор курса (или администратор).
+type Policy interface {
+	CanManageCourse(ctx context.Context, actor authz.Actor, courseID string) error
+	CanManageModule(ctx context.Context, actor authz.Actor, moduleID string) error
+	CanManageTest(ctx context.Context, actor authz.Actor, testID string) error
 }
 
 type TestService struct {
-	repo TestRepository
+	repo   TestRepository
+	policy Policy
 }
 
-func NewTestService(repo TestRepository) *TestService {
-	return &TestService{repo: repo}
+func NewTestService(repo TestRepository, policy Policy) *TestService {
+	return &TestService{repo: repo, policy: policy}
 }
 
-// TO DO: Transaction
-func (s *TestService) CreateFullTest(ctx context.Context, test *entities.Test) error {
-	if err := s.repo.CreateTest(ctx, test); err != nil {
-		return fmt.Errorf("create test: %w", err)
+func (s *TestService) CreateFullTest(ctx context.Context, actor authz.Actor, test *entities.Test) error {
+	if err := s.policy.CanManageModule(ctx, actor, test.ModuleID); err != nil {
+		return err
 	}
 
-	for _, q := range test.Questions {
-		q.TestID = test.ID
-		if err := s.repo.AddQuestion(ctx, &q); err != nil {
-			return fmt.Errorf("add question: %w", err)
+	return s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateTest(ctx, test); err != nil {
+			return fmt.Errorf("create test: %w", err)
+		}
+		if err := s.repo.ReplacePoolRules(ctx, test.ID, test.PoolRules); err != nil {
+			return err
 		}
 
-		for _, a := range q.Answers {
-			a.QuestionID = q.ID
-			if err := s.repo.AddAnswer(ctx, &a); err != nil {
-				return fmt.Errorf("add answer: %w", err)
+		for i := range test.Questions {
+			q := &test.Questions[i]
+			q.TestID = test.ID
+			if err := s.addQuestion(ctx, q); err != nil {
+				return err
 			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (s *TestService) GetTestByModule(ctx context.Context, moduleID string) (*entities.Test, error) {
@@ -56,31 +88,165 @@ func (s *TestService) GetTestByModule(ctx context.Context, moduleID string) (*en
 	return test, nil
 }
 
-func (s *TestService) UpdateFullTest(ctx context.Context, test *entities.Test) error {
-	if err := s.repo.UpdateTest(ctx, test); err != nil {
-		return err
+// GetTestView отдаёт настройки теста любому пользователю, а вопросы — только
+// автору курса или администратору. Остальные получают вопросы в попытке
+// (StudentService.StartTest), иначе лимиты попыток и времени обходились бы.
+func (s *TestService) GetTestView(ctx context.Context, actor authz.Actor, moduleID string) (*entities.Test, error) {
+	test, err := s.GetTestByModule(ctx, moduleID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.DeleteQuestionsByTestID(ctx, test.ID); err != nil {
-		return fmt.Errorf("failed to clear old questions: %w", err)
+	err = s.policy.CanManageModule(ctx, actor, moduleID)
+	if errors.Is(err, entities.ErrForbidden) {
+		view := *test
+		view.Questions = []entities.Question{}
+		return &view, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return test, nil
+}
+
+// GetTestWithAnswers отдаёт тест с правильными ответами только автору курса.
+func (s *TestService) GetTestWithAnswers(ctx context.Context, actor authz.Actor, moduleID string) (*entities.Test, error) {
+	if err := s.policy.CanManageModule(ctx, actor, moduleID); err != nil {
+		return nil, err
+	}
+	return s.GetTestByModule(ctx, moduleID)
+}
+
+// UpdateFullTest сравнивает вопросы и варианты с сохранёнными по ID:
+// совпавшие обновляются на месте, новые добавляются, пропавшие удаляются.
+// ID, которые не принадлежат этому тесту, заменяются на новые.
+func (s *TestService) UpdateFullTest(ctx context.Context, actor authz.Actor, test *entities.Test) error {
+	if err := s.policy.CanManageTest(ctx, actor, test.ID); err != nil {
+		return err
 	}
 
-	for _, q := range test.Questions {
-		q.TestID = test.ID
-		if err := s.repo.AddQuestion(ctx, &q); err != nil {
-			return fmt.Errorf("add question: %w", err)
+	return s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetTestFullByID(ctx, test.ID)
+		if err != nil {
+			return err
 		}
 
-		for _, a := range q.Answers {
-			a.QuestionID = q.ID
-			if err := s.repo.AddAnswer(ctx, &a); err != nil {
-				return fmt.Errorf("add answer: %w", err)
+		if err := s.repo.UpdateTest(ctx, test); err != nil {
+			return err
+		}
+		if err := s.repo.ReplacePoolRules(ctx, test.ID, test.PoolRules); err != nil {
+			return err
+		}
+
+		existing := make(map[string]*entities.Question, len(current.Questions))
+		for i := range current.Questions {
+			existing[current.Questions[i].ID] = &current.Questions[i]
+		}
+
+		keepIDs := make([]string, 0, len(test.Questions))
+		for i := range test.Questions {
+			q := &test.Questions[i]
+			q.TestID = test.ID
+
+			old, ok := existing[q.ID]
+			if !ok {
+				q.ID = uuid.NewString()
+				if err := s.addQuestion(ctx, q); err != nil {
+					return err
+				}
+				keepIDs = append(keepIDs, q.ID)
+				continue
 			}
+
+			delete(existing, q.ID) // защита от дублей ID в запросе
+			if err := s.updateQuestion(ctx, q, old); err != nil {
+				return err
+			}
+			keepIDs = append(keepIDs, q.ID)
+		}
+
+		if err := s.repo.DeleteQuestionsExcept(ctx, test.ID, keepIDs); err != nil {
+			return fmt.Errorf("failed to remove old questions: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *TestService) DeleteTest(ctx context.Context, actor authz.Actor, testID string) error {
+	if err := s.policy.CanManageTest(ctx, actor, testID); err != nil {
+		return err
+	}
+	return s.repo.DeleteTest(ctx, testID)
+}
+
+// GetAttempts отдаёт попытки по тесту автору курса или администратору.
+// Пустой studentID — попытки всех студентов.
+func (s *TestService) GetAttempts(
+	ctx context.Context,
+	actor authz.Actor,
+	testID, studentID string,
+) ([]entities.TestAttempt, error) {
+	if err := s.policy.CanManageTest(ctx, actor, testID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAttempts(ctx, testID, studentID, teacherAttemptsLimit)
+}
+
+func (s *TestService) addQuestion(ctx context.Context, q *entities.Question) error {
+	if err := s.repo.AddQuestion(ctx, q); err != nil {
+		return fmt.Errorf("add question: %w", err)
+	}
+
+	for i := range q.Answers {
+		a := &q.Answers[i]
+		a.QuestionID = q.ID
+		// У нового вопроса ещё нет вариантов: присланный ID мог бы
+		// совпасть с чужим вариантом, поэтому он всегда генерируется заново.
+		a.ID = uuid.NewString()
+		if err := s.repo.AddAnswer(ctx, a); err != nil {
+			return fmt.Errorf("add answer: %w", err)
 		}
 	}
 	return nil
 }
 
-func (s *TestService) DeleteTest(ctx context.Context, testID string) error {
-	return s.repo.DeleteTest(ctx, testID)
+func (s *TestService) updateQuestion(ctx context.Context, q, old *entities.Question) error {
+	if err := s.repo.UpdateQuestion(ctx, q); err != nil {
+		return fmt.Errorf("update question: %w", err)
+	}
+	return s.syncAnswers(ctx, q, old)
+}
+
+// syncAnswers приводит варианты вопроса к q.Answers: совпавшие по ID
+// обновляются, новые добавляются, остальные удаляются.
+func (s *TestService) syncAnswers(ctx context.Context, q, old *entities.Question) error {
+	existing := make(map[string]bool, len(old.Answers))
+	for _, a := range old.Answers {
+		existing[a.ID] = true
+	}
+
+	keepIDs := make([]string, 0, len(q.Answers))
+	for i := range q.Answers {
+		a := &q.Answers[i]
+		a.QuestionID = q.ID
+
+		if !existing[a.ID] {
+			a.ID = uuid.NewString()
+			if err := s.repo.AddAnswer(ctx, a); err != nil {
+				return fmt.Errorf("add answer: %w", err)
+			}
+		} else {
+			delete(existing, a.ID)
+			if err := s.repo.UpdateAnswer(ctx, a); err != nil {
+				return fmt.Errorf("update answer: %w", err)
+			}
+		}
+		keepIDs = append(keepIDs, a.ID)
+	}
+
+	if err := s.repo.DeleteAnswersExcept(ctx, q.ID, keepIDs); err != nil {
+		return fmt.Errorf("failed to remove old answers: %w", err)
+	}
+	return nil
 }
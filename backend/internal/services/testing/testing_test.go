@@ -0,0 +1,172 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/entities"
+	"backend/internal/services/authz"
+)
+
+type allowAll struct{}
+
+func (allowAll) CanManageCourse(context.Context, authz.Actor, string) error { return nil }
+func (allowAll) CanManageModule(context.Context, authz.Actor, string) error { return nil }
+func (allowAll) CanManageTest(context.Context, authz.Actor, string) error   { return nil }
+
+// modulePolicy пускает к модулю только его автора.
+type modulePolicy struct {
+	allowAll
+	authorID string
+}
+
+func (p modulePolicy) CanManageModule(_ context.Context, actor authz.Actor, _ string) error {
+	return authz.Allow(actor, p.authorID)
+}
+
+// fakeRepo запоминает записанные строки; остальные методы интерфейса не нужны.
+type fakeRepo struct {
+	TestRepository
+
+	current *entities.Test
+
+	addedQuestions   []string
+	updatedQuestions []string
+	addedAnswers     []string
+	updatedAnswers   []string
+}
+
+func (r *fakeRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *fakeRepo) GetTestFullByID(context.Context, string) (*entities.Test, error) {
+	return r.current, nil
+}
+
+func (r *fakeRepo) GetTestByModuleID(context.Context, string) (*entities.Test, error) {
+	if r.current == nil {
+		return nil, entities.ErrNotFound
+	}
+	return r.current, nil
+}
+
+func (r *fakeRepo) UpdateTest(context.Context, *entities.Test) error { return nil }
+
+func (r *fakeRepo) ReplacePoolRules(context.Context, string, []entities.PoolRule) error { return nil }
+
+func (r *fakeRepo) AddQuestion(_ context.Context, q *entities.Question) error {
+	r.addedQuestions = append(r.addedQuestions, q.ID)
+	return nil
+}
+
+func (r *fakeRepo) UpdateQuestion(_ context.Context, q *entities.Question) error {
+	r.updatedQuestions = append(r.updatedQuestions, q.ID)
+	return nil
+}
+
+func (r *fakeRepo) AddAnswer(_ context.Context, a *entities.Answer) error {
+	r.addedAnswers = append(r.addedAnswers, a.ID)
+	return nil
+}
+
+func (r *fakeRepo) UpdateAnswer(_ context.Context, a *entities.Answer) error {
+	r.updatedAnswers = append(r.updatedAnswers, a.ID)
+	return nil
+}
+
+func (r *fakeRepo) DeleteQuestionsExcept(context.Context, string, []string) error { return nil }
+
+func (r *fakeRepo) DeleteAnswersExcept(context.Context, string, []string) error { return nil }
+
+func TestUpdateFullTestRegeneratesForeignIDs(t *testing.T) {
+	repo := &fakeRepo{current: &entities.Test{
+		ID: "t1",
+		Questions: []entities.Question{{
+			ID:      "q1",
+			TestID:  "t1",
+			Answers: []entities.Answer{{ID: "a1", QuestionID: "q1"}},
+		}},
+	}}
+	svc := NewTestService(repo, allowAll{})
+
+	test := &entities.Test{
+		ID: "t1",
+		Questions: []entities.Question{
+			// свой вопрос со своим вариантом, дублем и чужим вариантом
+			{ID: "q1", Answers: []entities.Answer{{ID: "a1"}, {ID: "a1"}, {ID: "foreign-a"}}},
+			// дубль своего вопроса
+			{ID: "q1", Answers: []entities.Answer{{ID: "a1"}}},
+			// чужой вопрос с чужим вариантом
+			{ID: "foreign-q", Answers: []entities.Answer{{ID: "foreign-a"}}},
+		},
+	}
+	if err := svc.UpdateFullTest(context.Background(), authz.Actor{}, test); err != nil {
+		t.Fatalf("UpdateFullTest: %v", err)
+	}
+
+	if len(repo.updatedQuestions) != 1 || repo.updatedQuestions[0] != "q1" {
+		t.Errorf("updated questions = %v, want [q1]", repo.updatedQuestions)
+	}
+	if len(repo.updatedAnswers) != 1 || repo.updatedAnswers[0] != "a1" {
+		t.Errorf("updated answers = %v, want [a1]", repo.updatedAnswers)
+	}
+
+	foreign := map[string]bool{"q1": true, "a1": true, "foreign-q": true, "foreign-a": true}
+	seen := map[string]bool{}
+	for _, id := range append(repo.addedQuestions, repo.addedAnswers...) {
+		if foreign[id] {
+			t.Errorf("inserted row reuses client ID %q", id)
+		}
+		if seen[id] {
+			t.Errorf("inserted ID %q twice", id)
+		}
+		seen[id] = true
+	}
+	if len(repo.addedQuestions) != 2 {
+		t.Errorf("added %d questions, want 2", len(repo.addedQuestions))
+	}
+	if len(repo.addedAnswers) != 4 {
+		t.Errorf("added %d answers, want 4", len(repo.addedAnswers))
+	}
+}
+
+func TestGetTestViewShowsQuestionsOnlyToCourseAuthor(t *testing.T) {
+	repo := &fakeRepo{current: &entities.Test{
+		ID:        "t1",
+		Title:     "Quiz",
+		Questions: []entities.Question{{ID: "q1"}, {ID: "q2"}},
+	}}
+	svc := NewTestService(repo, modulePolicy{authorID: "author"})
+
+	tests := []struct {
+		name      string
+		actor     authz.Actor
+		questions int
+	}{
+		{"course author", authz.NewActor("author", "teacher"), 2},
+		{"admin", authz.NewActor("admin", "admin"), 2},
+		{"another teacher", authz.NewActor("other", "teacher"), 0},
+		{"student", authz.NewActor("student", "student"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test, err := svc.GetTestView(context.Background(), tt.actor, "m1")
+			if err != nil {
+				t.Fatalf("GetTestView: %v", err)
+			}
+			if test.Title != "Quiz" || len(test.Questions) != tt.questions {
+				t.Errorf("got title %q with %d questions, want %q with %d", test.Title, len(test.Questions), "Quiz", tt.questions)
+			}
+		})
+	}
+
+	if len(repo.current.Questions) != 2 {
+		t.Error("hiding questions modified the stored test")
+	}
+
+	if _, err := NewTestService(&fakeRepo{}, allowAll{}).GetTestView(context.Background(), authz.Actor{}, "m1"); !errors.Is(err, entities.ErrNotFound) {
+		t.Errorf("missing test: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,142 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"-1 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"* * * * mon-",
+		"@every 5m",
+	}
+	for _, spec := range tests {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidSpec", spec, err)
+		}
+	}
+}
+
+// bitsOf собирает маску поля из значений.
+func bitsOf(values ...int) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func rangeOf(lo, hi, step int) uint64 {
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		spec                          string
+		minute, hour, dom, month, dow uint64
+	}{
+		{"* * * * *", rangeOf(0, 59, 1), rangeOf(0, 23, 1), rangeOf(1, 31, 1), rangeOf(1, 12, 1), rangeOf(0, 6, 1)},
+		{"0 0 1 1 0", bitsOf(0), bitsOf(0), bitsOf(1), bitsOf(1), bitsOf(0)},
+		{"59 23 31 12 6", bitsOf(59), bitsOf(23), bitsOf(31), bitsOf(12), bitsOf(6)},
+		{"*/15 */6 */10 */3 */2", bitsOf(0, 15, 30, 45), bitsOf(0, 6, 12, 18), bitsOf(1, 11, 21, 31), bitsOf(1, 4, 7, 10), bitsOf(0, 2, 4, 6)},
+		{"10-20/5 9-17 1-7 6-8 1-5", bitsOf(10, 15, 20), rangeOf(9, 17, 1), rangeOf(1, 7, 1), bitsOf(6, 7, 8), rangeOf(1, 5, 1)},
+		{"5/20 3/10 * * *", bitsOf(5, 25, 45), bitsOf(3, 13, 23), rangeOf(1, 31, 1), rangeOf(1, 12, 1), rangeOf(0, 6, 1)},
+		{"0,30 8,12-14 1,15 jan,JUL mon-fri", bitsOf(0, 30), bitsOf(8, 12, 13, 14), bitsOf(1, 15), bitsOf(1, 7), rangeOf(1, 5, 1)},
+		{"0 0 * * 7", bitsOf(0), bitsOf(0), rangeOf(1, 31, 1), rangeOf(1, 12, 1), bitsOf(0)},
+		{"0 0 * * 5-7", bitsOf(0), bitsOf(0), rangeOf(1, 31, 1), rangeOf(1, 12, 1), bitsOf(0, 5, 6)},
+		{"@hourly", bitsOf(0), rangeOf(0, 23, 1), rangeOf(1, 31, 1), rangeOf(1, 12, 1), rangeOf(0, 6, 1)},
+		{"@weekly", bitsOf(0), bitsOf(0), rangeOf(1, 31, 1), rangeOf(1, 12, 1), bitsOf(0)},
+		{"@yearly", bitsOf(0), bitsOf(0), bitsOf(1), bitsOf(1), rangeOf(0, 6, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got := [5]uint64{s.minute, s.hour, s.dom, s.month, s.dow}
+			want := [5]uint64{tt.minute, tt.hour, tt.dom, tt.month, tt.dow}
+			for i, name := range []string{"minute", "hour", "day of month", "month", "day of week"} {
+				if got[i] != want[i] {
+					t.Errorf("%s = %b, want %b", name, got[i], want[i])
+				}
+			}
+			if s.String() != tt.spec {
+				t.Errorf("String() = %q", s.String())
+			}
+		})
+	}
+}
+
+func at(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", at(2026, 10, 17, 10, 0), at(2026, 10, 17, 10, 1)},
+		{"strictly after", "30 * * * *", at(2026, 10, 17, 10, 30), at(2026, 10, 17, 11, 30)},
+		{"seconds are dropped", "* * * * *", time.Date(2026, 10, 17, 10, 0, 59, 0, time.UTC), at(2026, 10, 17, 10, 1)},
+		{"step", "*/15 * * * *", at(2026, 10, 17, 10, 16), at(2026, 10, 17, 10, 30)},
+		{"next hour", "*/15 * * * *", at(2026, 10, 17, 10, 50), at(2026, 10, 17, 11, 0)},
+		{"next day", "30 3 * * *", at(2026, 10, 17, 4, 0), at(2026, 10, 18, 3, 30)},
+		{"across month end", "0 0 * * *", at(2026, 10, 31, 12, 0), at(2026, 11, 1, 0, 0)},
+		{"across year end", "0 * * * *", at(2026, 12, 31, 23, 5), at(2027, 1, 1, 0, 0)},
+		{"yearly", "@yearly", at(2026, 1, 1, 0, 0), at(2027, 1, 1, 0, 0)},
+		{"31st skips short months", "0 12 31 * *", at(2026, 4, 1, 0, 0), at(2026, 5, 31, 12, 0)},
+		{"leap day", "0 0 29 2 *", at(2026, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"weekday", "0 9 * * mon", at(2026, 10, 17, 10, 0), at(2026, 10, 19, 9, 0)},
+		{"sunday as 7", "0 0 * * 7", at(2026, 10, 17, 10, 0), at(2026, 10, 18, 0, 0)},
+		{"weekly across year end", "@weekly", at(2026, 12, 28, 0, 0), at(2027, 1, 3, 0, 0)},
+		{"month list", "0 0 1 jan,jul *", at(2026, 7, 1, 0, 0), at(2027, 1, 1, 0, 0)},
+		// День месяца и день недели ограничены: подходит любой
+		{"dom or dow: dow first", "0 0 13 * fri", at(2026, 10, 17, 0, 0), at(2026, 10, 23, 0, 0)},
+		{"dom or dow: dom first", "0 0 20 * fri", at(2026, 10, 17, 0, 0), at(2026, 10, 20, 0, 0)},
+		// Один из них *: должны совпасть оба
+		{"dom with star dow", "0 0 13 * *", at(2026, 10, 17, 0, 0), at(2026, 11, 13, 0, 0)},
+		{"dow with star dom", "0 0 * * fri", at(2026, 10, 17, 0, 0), at(2026, 10, 23, 0, 0)},
+		{"stepped star dom still needs both", "0 0 */10 * fri", at(2026, 10, 1, 0, 0), at(2026, 12, 11, 0, 0)},
+		{"never", "0 0 30 2 *", at(2026, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MustParse(tt.spec).Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	almaty := time.FixedZone("UTC+5", 5*60*60)
+	got := MustParse("0 0 * * *").Next(time.Date(2026, 10, 17, 10, 0, 0, 0, almaty))
+	want := time.Date(2026, 10, 18, 0, 0, 0, 0, almaty)
+	if !got.Equal(want) || got.Location() != almaty {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
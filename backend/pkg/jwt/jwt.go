@@ -9,33 +9,58 @@ import (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	// EmailVerified фиксируется при выпуске; после подтверждения email
+	// новое значение придёт с ближайшим /auth/refresh
+	EmailVerified bool `json:"email_verified"`
+	// Locale — язык пользователя для контента; как и EmailVerified,
+	// обновляется при следующем /auth/refresh
+	Locale string `json:"locale,omitempty"`
 	jwt.RegisteredClaims
 }
 
+const DefaultAccessTTL = 15 * time.Minute
+
 type JWTManager struct {
 	secretKey string
+	accessTTL time.Duration
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
-	return &JWTManager{secretKey: secretKey}
+func NewJWTManager(secretKey string, accessTTL time.Duration) *JWTManager {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTTL
+	}
+	return &JWTManager{secretKey: secretKey, accessTTL: accessTTL}
 }
 
-func (m *JWTManager) Generate(userID string, email, role string) (string, error) {
+// Generate выпускает короткоживущий access-токен для сессии sessionID
+// и возвращает его вместе со временем истечения.
+func (m *JWTManager) Generate(userID, email, role, sessionID string, emailVerified bool, locale string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.accessTTL)
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+
+		EmailVerified: emailVerified,
+		Locale:        locale,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	signed, err := token.SignedString([]byte(m.secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
 }
 
 func (m *JWTManager) Verify(tokenString string) (*Claims, error) {
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Режим оценивания по типу вопроса: {"multiple": "negative"}
+ALTER TABLE tests
+ADD COLUMN scoring_rules JSONB NOT NULL DEFAULT '{}'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tests DROP COLUMN IF EXISTS scoring_rules;
+-- +goose StatementEnd
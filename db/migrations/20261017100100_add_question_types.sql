@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- numeric: правильное значение и допустимое отклонение
+ALTER TABLE questions
+ADD COLUMN numeric_value DOUBLE PRECISION,
+ADD COLUMN numeric_tolerance DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- ordering: позиция в правильном порядке; matching: правая часть пары
+ALTER TABLE answers
+ADD COLUMN order_index INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN match_text TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE answers DROP COLUMN IF EXISTS match_text;
+ALTER TABLE answers DROP COLUMN IF EXISTS order_index;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS numeric_tolerance;
+ALTER TABLE questions DROP COLUMN IF EXISTS numeric_value;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ответы студента по каждому вопросу попытки. Текст вопроса и выбранных
+-- вариантов копируется, чтобы история не менялась после правки теста.
+CREATE TABLE test_attempt_answers (
+    id TEXT PRIMARY KEY,
+    result_id TEXT NOT NULL REFERENCES test_results (id) ON DELETE CASCADE,
+    question_id TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    question_text TEXT NOT NULL,
+    question_type VARCHAR(50) NOT NULL,
+    answer_ids TEXT[] NOT NULL DEFAULT '{}',
+    answer_texts TEXT[] NOT NULL DEFAULT '{}',
+    text_answer TEXT NOT NULL DEFAULT '',
+    pairs JSONB NOT NULL DEFAULT '{}'::jsonb,
+    is_correct BOOLEAN NOT NULL,
+    points DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_test_attempt_answers_result ON test_attempt_answers (result_id);
+CREATE INDEX idx_test_results_user_test ON test_results (user_id, test_id, attempt_date DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_test_results_user_test;
+DROP TABLE IF EXISTS test_attempt_answers;
+-- +goose StatementEnd
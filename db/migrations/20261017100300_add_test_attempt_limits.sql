@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 0 означает "без ограничения"
+ALTER TABLE tests
+ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN time_limit_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- Выданные студентам попытки; id используется как токен при отправке ответов
+CREATE TABLE test_attempts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    test_id TEXT NOT NULL REFERENCES tests (id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deadline_at TIMESTAMPTZ,
+    submitted_at TIMESTAMPTZ,
+    result_id TEXT REFERENCES test_results (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_test_attempts_user_test ON test_attempts (user_id, test_id, started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS test_attempts;
+
+ALTER TABLE tests
+DROP COLUMN IF EXISTS time_limit_seconds,
+DROP COLUMN IF EXISTS cooldown_seconds,
+DROP COLUMN IF EXISTS max_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Банк вопросов курса: вопрос без test_id, но с course_id
+ALTER TABLE questions
+ADD COLUMN course_id TEXT REFERENCES courses (id) ON DELETE CASCADE,
+ADD COLUMN difficulty INTEGER CHECK (
+    difficulty >= 1
+    AND difficulty <= 5
+),
+ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE questions
+ADD CONSTRAINT questions_test_or_bank CHECK (
+    test_id IS NOT NULL
+    OR course_id IS NOT NULL
+);
+
+CREATE INDEX idx_questions_bank ON questions (course_id) WHERE test_id IS NULL;
+
+-- "N вопросов из банка курса": фильтр по тегам (любой из) и сложности
+CREATE TABLE test_pool_rules (
+    id SERIAL PRIMARY KEY,
+    test_id TEXT NOT NULL REFERENCES tests (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    question_count INTEGER NOT NULL CHECK (question_count > 0),
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    difficulty INTEGER
+);
+
+CREATE INDEX idx_test_pool_rules_test ON test_pool_rules (test_id);
+
+ALTER TABLE tests ADD COLUMN shuffle_answers BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Набор вопросов и порядок вариантов, показанные в попытке
+ALTER TABLE test_attempts ADD COLUMN served JSONB NOT NULL DEFAULT '[]'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE test_attempts DROP COLUMN IF EXISTS served;
+ALTER TABLE tests DROP COLUMN IF EXISTS shuffle_answers;
+
+DROP TABLE IF EXISTS test_pool_rules;
+
+DELETE FROM questions WHERE test_id IS NULL;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_test_or_bank;
+DROP INDEX IF EXISTS idx_questions_bank;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS tags,
+DROP COLUMN IF EXISTS difficulty,
+DROP COLUMN IF EXISTS course_id;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Коды раньше хранились открытым текстом; после миграции их нельзя проверить
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens
+DROP COLUMN token,
+ADD COLUMN code_hash TEXT NOT NULL,
+ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+
+-- Запросы кода и попытки сброса для ограничения частоты по email и по IP
+CREATE TABLE password_reset_events (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('forgot', 'reset')),
+    email TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_reset_events_email ON password_reset_events (kind, email, created_at);
+CREATE INDEX idx_password_reset_events_ip ON password_reset_events (kind, ip, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS password_reset_events;
+
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens
+DROP COLUMN attempts,
+DROP COLUMN code_hash,
+ADD COLUMN token VARCHAR(6) NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN locale TEXT NOT NULL DEFAULT 'ru' CHECK (locale IN ('ru', 'kk', 'en'));
+
+-- Исходящие письма. Отправщик забирает строки через FOR UPDATE SKIP LOCKED
+-- и продлевает next_attempt_at на время отправки, поэтому письмо, взятое
+-- упавшим процессом, будет отправлено повторно. Письмо с кодом или ссылкой
+-- живёт не дольше них: после expires_at оно не отправляется, а тело стирается.
+CREATE TABLE email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    template TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    to_email TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT, -- очищается после отправки: в письмах бывают коды и ссылки
+    text_body TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed', 'expired')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_outbox;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+-- +goose StatementEnd
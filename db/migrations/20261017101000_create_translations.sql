@@ -0,0 +1,139 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Переводы хранятся отдельно от исходного текста. Исходные колонки
+-- (courses.title, leagues.name и т.д.) остаются текстом по умолчанию:
+-- если перевода на язык запроса нет, отдаётся он.
+
+CREATE TABLE subject_translations (
+    subject_id TEXT NOT NULL REFERENCES subjects (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    name VARCHAR(100) NOT NULL,
+    PRIMARY KEY (subject_id, locale)
+);
+
+INSERT INTO subject_translations (subject_id, locale, name)
+SELECT id, 'kk', name_kz FROM subjects;
+
+ALTER TABLE subjects RENAME COLUMN name_ru TO name;
+ALTER TABLE subjects DROP COLUMN name_kz;
+
+INSERT INTO subject_translations (subject_id, locale, name)
+SELECT s.id, 'en', v.name
+FROM subjects s
+JOIN (
+    VALUES
+        ('math', 'Mathematics'),
+        ('kaz_lang', 'Kazakh language'),
+        ('history_kz', 'History of Kazakhstan'),
+        ('physics', 'Physics'),
+        ('informatics', 'Informatics'),
+        ('english', 'English language'),
+        ('biology', 'Biology'),
+        ('chemistry', 'Chemistry'),
+        ('geography', 'Geography'),
+        ('literature', 'Russian literature'),
+        ('world_history', 'World history'),
+        ('computer_science', 'Computer science'),
+        ('logic', 'Logic'),
+        ('economics', 'Economics'),
+        ('finance', 'Financial literacy'),
+        ('law', 'Law'),
+        ('art', 'Art'),
+        ('music', 'Music'),
+        ('physical_education', 'Physical education'),
+        ('philosophy', 'Philosophy'),
+        ('sociology', 'Sociology'),
+        ('psychology', 'Psychology'),
+        ('environment', 'Ecology'),
+        ('astronomy', 'Astronomy'),
+        ('statistics', 'Statistics')
+) AS v (slug, name) ON v.slug = s.slug;
+
+CREATE TABLE course_translations (
+    course_id TEXT NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    title TEXT NOT NULL,
+    description TEXT,
+    PRIMARY KEY (course_id, locale)
+);
+
+CREATE TABLE module_translations (
+    module_id TEXT NOT NULL REFERENCES modules (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    title TEXT NOT NULL,
+    PRIMARY KEY (module_id, locale)
+);
+
+CREATE TABLE lesson_translations (
+    lesson_id TEXT NOT NULL REFERENCES lessons (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    title TEXT NOT NULL,
+    content_text TEXT,
+    PRIMARY KEY (lesson_id, locale)
+);
+
+CREATE TABLE league_translations (
+    league_id INTEGER NOT NULL REFERENCES leagues (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (league_id, locale)
+);
+
+INSERT INTO league_translations (league_id, locale, name)
+SELECT l.id, v.locale, v.name
+FROM leagues l
+JOIN (
+    VALUES
+        ('bronze', 'kk', 'Қола лигасы'),
+        ('silver', 'kk', 'Күміс лигасы'),
+        ('gold', 'kk', 'Алтын лигасы'),
+        ('sapphire', 'kk', 'Сапфир лигасы'),
+        ('ruby', 'kk', 'Рубин лигасы'),
+        ('emerald', 'kk', 'Изумруд лигасы'),
+        ('amethyst', 'kk', 'Аметист лигасы'),
+        ('diamond', 'kk', 'Алмаз лигасы'),
+        ('bronze', 'en', 'Bronze League'),
+        ('silver', 'en', 'Silver League'),
+        ('gold', 'en', 'Gold League'),
+        ('sapphire', 'en', 'Sapphire League'),
+        ('ruby', 'en', 'Ruby League'),
+        ('emerald', 'en', 'Emerald League'),
+        ('amethyst', 'en', 'Amethyst League'),
+        ('diamond', 'en', 'Diamond League')
+) AS v (slug, locale, name) ON v.slug = l.slug;
+
+CREATE TABLE achievement_translations (
+    achievement_id TEXT NOT NULL REFERENCES achievements (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL CHECK (locale IN ('ru', 'kk', 'en')),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    PRIMARY KEY (achievement_id, locale)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS achievement_translations;
+
+DROP TABLE IF EXISTS league_translations;
+
+DROP TABLE IF EXISTS lesson_translations;
+
+DROP TABLE IF EXISTS module_translations;
+
+DROP TABLE IF EXISTS course_translations;
+
+ALTER TABLE subjects ADD COLUMN name_kz VARCHAR(100);
+
+UPDATE subjects s
+SET name_kz = COALESCE(
+    (SELECT t.name FROM subject_translations t WHERE t.subject_id = s.id AND t.locale = 'kk'),
+    s.name
+);
+
+ALTER TABLE subjects ALTER COLUMN name_kz SET NOT NULL;
+ALTER TABLE subjects RENAME COLUMN name TO name_ru;
+
+DROP TABLE IF EXISTS subject_translations;
+-- +goose StatementEnd
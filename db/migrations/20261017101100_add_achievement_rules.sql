@@ -0,0 +1,96 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Правило выдачи: при событии rule_event считается метрика rule_metric,
+-- и достижение выдаётся, когда она достигает rule_threshold.
+-- Достижения без правила выдаются только вручную (GiveAchievement).
+ALTER TABLE achievements
+ADD COLUMN rule_event TEXT CHECK (
+    rule_event IN (
+        'lesson_completed',
+        'test_passed',
+        'streak_updated',
+        'course_completed',
+        'league_promoted'
+    )
+),
+ADD COLUMN rule_metric TEXT CHECK (
+    rule_metric IN (
+        'lessons_completed',
+        'tests_passed',
+        'perfect_tests',
+        'test_score',
+        'streak_days',
+        'courses_completed',
+        'league_order'
+    )
+),
+ADD COLUMN rule_threshold INTEGER NOT NULL DEFAULT 1 CHECK (rule_threshold > 0),
+ADD CONSTRAINT achievements_rule_complete CHECK ((rule_event IS NULL) = (rule_metric IS NULL));
+
+CREATE INDEX idx_achievements_rule_event ON achievements (rule_event) WHERE rule_event IS NOT NULL;
+
+INSERT INTO achievements (id, slug, name, description, icon_url, xp_reward, rule_event, rule_metric, rule_threshold)
+VALUES
+    ('ach-first-lesson', 'first_lesson', 'Первый шаг', 'Пройдите первый урок', NULL, 10, 'lesson_completed', 'lessons_completed', 1),
+    ('ach-lessons-10', 'lessons_10', 'Любознательный', 'Пройдите 10 уроков', NULL, 30, 'lesson_completed', 'lessons_completed', 10),
+    ('ach-lessons-50', 'lessons_50', 'Книжный червь', 'Пройдите 50 уроков', NULL, 100, 'lesson_completed', 'lessons_completed', 50),
+    ('ach-perfect-test', 'perfect_test', 'Отличник', 'Сдайте тест на 100%', NULL, 50, 'test_passed', 'test_score', 100),
+    ('ach-perfect-tests-5', 'perfect_tests_5', 'Без единой ошибки', 'Сдайте 5 тестов на 100%', NULL, 100, 'test_passed', 'perfect_tests', 5),
+    ('ach-tests-10', 'tests_10', 'Экзаменатор', 'Сдайте 10 тестов', NULL, 50, 'test_passed', 'tests_passed', 10),
+    ('ach-streak-3', 'streak_3', 'Разогрев', 'Занимайтесь 3 дня подряд', NULL, 15, 'streak_updated', 'streak_days', 3),
+    ('ach-streak-7', 'streak_7', 'Неделя в строю', 'Занимайтесь 7 дней подряд', NULL, 50, 'streak_updated', 'streak_days', 7),
+    ('ach-streak-30', 'streak_30', 'Железная воля', 'Занимайтесь 30 дней подряд', NULL, 200, 'streak_updated', 'streak_days', 30),
+    ('ach-first-course', 'first_course', 'Выпускник', 'Завершите первый курс', NULL, 100, 'course_completed', 'courses_completed', 1),
+    ('ach-courses-5', 'courses_5', 'Эрудит', 'Завершите 5 курсов', NULL, 300, 'course_completed', 'courses_completed', 5),
+    ('ach-league-gold', 'league_gold', 'Золотой призёр', 'Поднимитесь в золотую лигу', NULL, 100, 'league_promoted', 'league_order', 3),
+    ('ach-league-diamond', 'league_diamond', 'Бриллиант', 'Поднимитесь в алмазную лигу', NULL, 500, 'league_promoted', 'league_order', 8)
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO achievement_translations (achievement_id, locale, name, description)
+SELECT a.id, v.locale, v.name, v.description
+FROM achievements a
+JOIN (
+    VALUES
+        ('first_lesson', 'kk', 'Алғашқы қадам', 'Алғашқы сабақты өтіңіз'),
+        ('lessons_10', 'kk', 'Білімқұмар', '10 сабақ өтіңіз'),
+        ('lessons_50', 'kk', 'Кітапқұмар', '50 сабақ өтіңіз'),
+        ('perfect_test', 'kk', 'Үздік оқушы', 'Тестті 100% тапсырыңыз'),
+        ('perfect_tests_5', 'kk', 'Қатесіз', '5 тестті 100% тапсырыңыз'),
+        ('tests_10', 'kk', 'Емтихан шебері', '10 тест тапсырыңыз'),
+        ('streak_3', 'kk', 'Қыздыру', '3 күн қатарынан оқыңыз'),
+        ('streak_7', 'kk', 'Бір апта сапта', '7 күн қатарынан оқыңыз'),
+        ('streak_30', 'kk', 'Темір ерік', '30 күн қатарынан оқыңыз'),
+        ('first_course', 'kk', 'Түлек', 'Алғашқы курсты аяқтаңыз'),
+        ('courses_5', 'kk', 'Білгір', '5 курсты аяқтаңыз'),
+        ('league_gold', 'kk', 'Алтын жүлдегер', 'Алтын лигаға көтеріліңіз'),
+        ('league_diamond', 'kk', 'Гауһар', 'Алмаз лигасына көтеріліңіз'),
+        ('first_lesson', 'en', 'First step', 'Complete your first lesson'),
+        ('lessons_10', 'en', 'Curious mind', 'Complete 10 lessons'),
+        ('lessons_50', 'en', 'Bookworm', 'Complete 50 lessons'),
+        ('perfect_test', 'en', 'Top of the class', 'Pass a test with 100%'),
+        ('perfect_tests_5', 'en', 'Flawless', 'Pass 5 tests with 100%'),
+        ('tests_10', 'en', 'Examiner', 'Pass 10 tests'),
+        ('streak_3', 'en', 'Warming up', 'Study 3 days in a row'),
+        ('streak_7', 'en', 'Week strong', 'Study 7 days in a row'),
+        ('streak_30', 'en', 'Iron will', 'Study 30 days in a row'),
+        ('first_course', 'en', 'Graduate', 'Finish your first course'),
+        ('courses_5', 'en', 'Scholar', 'Finish 5 courses'),
+        ('league_gold', 'en', 'Gold medalist', 'Get promoted to the Gold League'),
+        ('league_diamond', 'en', 'Diamond', 'Get promoted to the Diamond League')
+) AS v (slug, locale, name, description) ON v.slug = a.slug
+ON CONFLICT (achievement_id, locale) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM achievements WHERE id LIKE 'ach-%';
+
+DROP INDEX IF EXISTS idx_achievements_rule_event;
+
+ALTER TABLE achievements
+DROP CONSTRAINT IF EXISTS achievements_rule_complete,
+DROP COLUMN IF EXISTS rule_threshold,
+DROP COLUMN IF EXISTS rule_metric,
+DROP COLUMN IF EXISTS rule_event;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал начислений XP. Каждый источник (урок, тест, достижение) даёт XP
+-- ученику не больше одного раза, что гарантирует уникальный ключ.
+-- xp, weekly_xp и level в student_profiles меняются только вместе
+-- с записью в этот журнал.
+CREATE TABLE xp_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    source_type TEXT NOT NULL CHECK (source_type IN ('lesson', 'test', 'achievement')),
+    source_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, source_type, source_id)
+);
+
+CREATE INDEX idx_xp_transactions_user ON xp_transactions (user_id, created_at DESC, id DESC);
+
+-- История по уже выданному XP; суммы в профилях не пересчитываются
+INSERT INTO xp_transactions (user_id, source_type, source_id, amount, created_at)
+SELECT lp.user_id, 'lesson', lp.lesson_id, l.xp_reward, COALESCE(lp.last_accessed_at, NOW())
+FROM lesson_progress lp
+JOIN lessons l ON l.id = lp.lesson_id
+JOIN student_profiles sp ON sp.user_id = lp.user_id
+WHERE lp.is_completed AND l.xp_reward > 0
+ON CONFLICT DO NOTHING;
+
+INSERT INTO xp_transactions (user_id, source_type, source_id, amount, created_at)
+SELECT DISTINCT ON (tr.user_id, tr.test_id)
+       tr.user_id, 'test', tr.test_id, 50, COALESCE(tr.attempt_date AT TIME ZONE 'UTC', NOW())
+FROM test_results tr
+JOIN student_profiles sp ON sp.user_id = tr.user_id
+WHERE tr.is_passed
+ORDER BY tr.user_id, tr.test_id, tr.attempt_date
+ON CONFLICT DO NOTHING;
+
+INSERT INTO xp_transactions (user_id, source_type, source_id, amount, created_at)
+SELECT ua.user_id, 'achievement', ua.achievement_id, a.xp_reward, COALESCE(ua.earned_at, NOW())
+FROM user_achievements ua
+JOIN achievements a ON a.id = ua.achievement_id
+WHERE a.xp_reward > 0
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS xp_transactions;
+-- +goose StatementEnd
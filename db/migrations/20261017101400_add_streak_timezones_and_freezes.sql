@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Границы дня для серии считаются в часовом поясе ученика.
+-- last_activity_date — последний засчитанный в серию локальный день:
+-- день с активностью или день, покрытый заморозкой.
+ALTER TABLE student_profiles
+    ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Asia/Almaty',
+    ADD COLUMN streak_freezes INTEGER NOT NULL DEFAULT 0 CHECK (streak_freezes >= 0);
+
+CREATE TABLE streak_freeze_usages (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE INDEX idx_student_profiles_streak ON student_profiles (last_activity_date)
+WHERE current_streak > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_student_profiles_streak;
+
+DROP TABLE IF EXISTS streak_freeze_usages;
+
+ALTER TABLE student_profiles
+    DROP COLUMN IF EXISTS streak_freezes,
+    DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Зоны повышения и понижения задаются для каждой лиги в процентах от всех
+-- её учеников. Неактивные (weekly_xp = 0) стоят ниже активных и первыми
+-- попадают в зону понижения; demote_inactive понижает их всех.
+ALTER TABLE leagues
+    ADD COLUMN promote_percent INTEGER NOT NULL DEFAULT 20 CHECK (promote_percent BETWEEN 0 AND 50),
+    ADD COLUMN demote_percent INTEGER NOT NULL DEFAULT 20 CHECK (demote_percent BETWEEN 0 AND 50),
+    ADD COLUMN demote_inactive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- При равном weekly_xp выше тот, кто набрал его раньше.
+ALTER TABLE student_profiles
+    ADD COLUMN weekly_xp_reached_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE student_profiles
+SET weekly_xp_reached_at = COALESCE(updated_at, NOW());
+
+DROP INDEX IF EXISTS idx_league_ranking;
+
+CREATE INDEX idx_league_ranking ON student_profiles (
+    current_league_id,
+    weekly_xp DESC,
+    weekly_xp_reached_at ASC,
+    user_id ASC
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_league_ranking;
+
+CREATE INDEX idx_league_ranking ON student_profiles (
+    current_league_id,
+    weekly_xp DESC
+);
+
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS weekly_xp_reached_at;
+
+ALTER TABLE leagues
+    DROP COLUMN IF EXISTS demote_inactive,
+    DROP COLUMN IF EXISTS demote_percent,
+    DROP COLUMN IF EXISTS promote_percent;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Недельные группы внутри лиги: ученики соревнуются только со своей
+-- когортой. Когорты формируются при еженедельном сбросе; ученик без
+-- когорты на текущую неделю попадает в неполную когорту своей лиги.
+CREATE TABLE league_cohorts (
+    id TEXT PRIMARY KEY,
+    league_id INTEGER NOT NULL REFERENCES leagues (id),
+    week_start DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_league_cohorts_week ON league_cohorts (league_id, week_start);
+
+ALTER TABLE student_profiles
+    ADD COLUMN current_cohort_id TEXT REFERENCES league_cohorts (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_cohort_ranking ON student_profiles (
+    current_cohort_id,
+    weekly_xp DESC,
+    weekly_xp_reached_at ASC,
+    user_id ASC
+);
+
+ALTER TABLE leaderboard_history
+    ADD COLUMN cohort_id TEXT REFERENCES league_cohorts (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE leaderboard_history DROP COLUMN IF EXISTS cohort_id;
+
+DROP INDEX IF EXISTS idx_cohort_ranking;
+
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS current_cohort_id;
+
+DROP TABLE IF EXISTS league_cohorts;
+-- +goose StatementEnd
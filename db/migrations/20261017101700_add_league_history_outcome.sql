@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Итог недели для ученика: в какую лигу он перешёл. Для записей до этой
+-- миграции итог восстанавливается по лиге следующей недели. Если записи
+-- за следующую неделю нет (последняя неделя или пропуск), итог остаётся
+-- неизвестным (NULL).
+ALTER TABLE leaderboard_history
+    ADD COLUMN outcome TEXT CHECK (outcome IN ('promoted', 'demoted', 'stayed')),
+    ADD COLUMN to_league_id INTEGER REFERENCES leagues (id);
+
+UPDATE leaderboard_history h
+SET to_league_id = n.next_league_id
+FROM (
+    SELECT id, period_end,
+           LEAD(league_id) OVER w AS next_league_id,
+           LEAD(period_start) OVER w AS next_period_start
+    FROM leaderboard_history
+    WINDOW w AS (PARTITION BY user_id ORDER BY period_start)
+) n
+WHERE h.id = n.id AND n.next_period_start = n.period_end;
+
+UPDATE leaderboard_history h
+SET outcome = CASE
+        WHEN t.order_index > f.order_index THEN 'promoted'
+        WHEN t.order_index < f.order_index THEN 'demoted'
+        ELSE 'stayed'
+    END
+FROM leagues f, leagues t
+WHERE f.id = h.league_id AND t.id = h.to_league_id;
+
+CREATE INDEX idx_leaderboard_history_user_period ON leaderboard_history (user_id, period_start DESC);
+
+CREATE INDEX idx_leaderboard_history_table ON leaderboard_history (period_start, league_id, cohort_id, rank);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_leaderboard_history_table;
+
+DROP INDEX IF EXISTS idx_leaderboard_history_user_period;
+
+ALTER TABLE leaderboard_history
+    DROP COLUMN IF EXISTS to_league_id,
+    DROP COLUMN IF EXISTS outcome;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- История запусков периодических задач. Строка на (задача, слот
+-- расписания) служит и распределённой блокировкой: слот выполняет тот
+-- экземпляр backend, который первым вставил строку. lease_until — до
+-- какого момента запуск считается живым; после него зависший запуск
+-- помечается failed и не мешает следующим. skipped — задача ничего не
+-- сделала, потому что ту же работу уже выполнял другой экземпляр.
+CREATE TABLE job_runs (
+    id TEXT PRIMARY KEY,
+    job_name TEXT NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 1,
+    instance TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    lease_until TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    error TEXT,
+    UNIQUE (job_name, scheduled_at)
+);
+
+CREATE INDEX idx_job_runs_running ON job_runs (job_name) WHERE status = 'running';
+
+CREATE INDEX idx_job_runs_started ON job_runs (started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS job_runs;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL — автор не задал награду, и за урок даётся lesson_default_xp из
+-- политики геймификации; 0 — урок не даёт XP. Раньше 0 означал награду
+-- по умолчанию, поэтому такие уроки переводятся на NULL.
+ALTER TABLE lessons ALTER COLUMN xp_reward DROP DEFAULT;
+
+UPDATE lessons SET xp_reward = NULL WHERE xp_reward <= 0;
+
+ALTER TABLE lessons ADD CONSTRAINT lessons_xp_reward_check CHECK (xp_reward >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE lessons DROP CONSTRAINT IF EXISTS lessons_xp_reward_check;
+
+UPDATE lessons SET xp_reward = 0 WHERE xp_reward IS NULL;
+
+ALTER TABLE lessons ALTER COLUMN xp_reward SET DEFAULT 10;
+-- +goose StatementEnd
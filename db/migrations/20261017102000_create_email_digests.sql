@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Разосланные недельные дайджесты. Отметка пишется в одной транзакции
+-- с письмом в email_outbox, поэтому повтор или догоняющий запуск задачи
+-- не отправит ученику дайджест за ту же неделю второй раз.
+CREATE TABLE email_digests (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    period_start TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, period_start)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_digests;
+-- +goose StatementEnd
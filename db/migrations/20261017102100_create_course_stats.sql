@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сводка по курсам для авторов. Пересчитывается задачей course_stats
+-- целиком, поэтому отстаёт от живых данных не больше чем на интервал
+-- её запуска. Средний балл и доля сдавших — NULL, пока тесты курса
+-- никто не сдавал.
+CREATE TABLE course_stats (
+    course_id TEXT PRIMARY KEY REFERENCES courses (id) ON DELETE CASCADE,
+    students INTEGER NOT NULL DEFAULT 0,
+    completions INTEGER NOT NULL DEFAULT 0,
+    avg_progress INTEGER NOT NULL DEFAULT 0,
+    active_students_7d INTEGER NOT NULL DEFAULT 0,
+    views_30d INTEGER NOT NULL DEFAULT 0,
+    favorites INTEGER NOT NULL DEFAULT 0,
+    test_attempts INTEGER NOT NULL DEFAULT 0,
+    avg_test_score INTEGER,
+    test_pass_rate INTEGER,
+    computed_at TIMESTAMPTZ NOT NULL
+);
+
+-- Просмотры и активность за последние дни выбираются по времени
+CREATE INDEX idx_logs_created ON user_activity_logs (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_created;
+
+DROP TABLE IF EXISTS course_stats;
+-- +goose StatementEnd
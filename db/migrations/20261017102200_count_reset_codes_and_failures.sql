@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Выданные коды и неверные попытки сброса считаются по адресу с любых IP
+ALTER TABLE password_reset_events
+DROP CONSTRAINT password_reset_events_kind_check,
+ADD CONSTRAINT password_reset_events_kind_check CHECK (kind IN ('forgot', 'reset', 'issued', 'failed'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM password_reset_events WHERE kind IN ('issued', 'failed');
+
+ALTER TABLE password_reset_events
+DROP CONSTRAINT password_reset_events_kind_check,
+ADD CONSTRAINT password_reset_events_kind_check CHECK (kind IN ('forgot', 'reset'));
+-- +goose StatementEnd
@@ -1,4 +1,4 @@
-import api from "./axios";
+import api, { clearTokens } from "./axios";
 import type {
   RegisterResponse,
   ResetPasswordRequest,
@@ -30,4 +30,12 @@ export const authApi = {
     const response = await api.post<LoginResponse>("/auth/login", data);
     return response.data;
   },
+  // Отзывает сессию на сервере; токены удаляются, даже если запрос не прошёл
+  logout: async () => {
+    try {
+      await api.post("/auth/logout");
+    } finally {
+      clearTokens();
+    }
+  },
 };
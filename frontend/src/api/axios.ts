@@ -1,4 +1,5 @@
-import axios from 'axios';
+import axios, { type AxiosError, type InternalAxiosRequestConfig } from 'axios';
+import type { LoginResponse } from '../types/auth';
 
 const api = axios.create({
     baseURL: '/v1',
@@ -7,6 +8,41 @@ const api = axios.create({
     },
 });
 
+export const saveTokens = (tokens: LoginResponse) => {
+    localStorage.setItem('token', tokens.token);
+    localStorage.setItem('refresh_token', tokens.refresh_token);
+};
+
+export const clearTokens = () => {
+    localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
+    localStorage.removeItem('user');
+};
+
+// Один refresh на все запросы, получившие 401 одновременно: refresh-токен
+// одноразовый, и второй обмен того же токена отозвал бы всю сессию
+let refreshing: Promise<string> | null = null;
+
+const refreshAccessToken = (): Promise<string> => {
+    if (!refreshing) {
+        const refreshToken = localStorage.getItem('refresh_token');
+        refreshing = (async () => {
+            if (!refreshToken) {
+                throw new Error('no refresh token');
+            }
+            // Голый axios: ответ 401 от /auth/refresh не должен попасть в интерцептор
+            const response = await axios.post<LoginResponse>('/v1/auth/refresh', {
+                refresh_token: refreshToken,
+            });
+            saveTokens(response.data);
+            return response.data.token;
+        })().finally(() => {
+            refreshing = null;
+        });
+    }
+    return refreshing;
+};
+
 api.interceptors.request.use((config) => {
     const token = localStorage.getItem('token');
     if (token) {
@@ -15,16 +51,40 @@ api.interceptors.request.use((config) => {
     return config;
 });
 
+type RetriableConfig = InternalAxiosRequestConfig & { _retried?: boolean };
+
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
-        if (error.response && error.response.status === 401) {
-            localStorage.removeItem('token');
-            localStorage.removeItem('user');
-            // window.location.href = '/login'; // Пока закомментируем, чтобы не циклило
+    async (error: AxiosError) => {
+        const config = error.config as RetriableConfig | undefined;
+
+        if (error.response?.status !== 401 || !config) {
+            return Promise.reject(error);
         }
+
+        // Повторяем запрос один раз; 401 после обновления — значит, сессия закончилась.
+        // 401 от /auth/login — неверный пароль, а не истёкший токен
+        if (!config._retried && config.url !== '/auth/login' && localStorage.getItem('refresh_token')) {
+            config._retried = true;
+            let token: string;
+            try {
+                token = await refreshAccessToken();
+            } catch (refreshError) {
+                // Сетевая ошибка не повод разлогинивать: токены ещё могут быть живы
+                if (axios.isAxiosError(refreshError) && !refreshError.response) {
+                    return Promise.reject(error);
+                }
+                clearTokens();
+                return Promise.reject(error);
+            }
+            config.headers.Authorization = `Bearer ${token}`;
+            return api(config);
+        }
+
+        clearTokens();
+        // window.location.href = '/login'; // Пока закомментируем, чтобы не циклило
         return Promise.reject(error);
     }
 );
 
-export default api;
\ No newline at end of file
+export default api;
@@ -22,8 +22,10 @@ export const coursesApi = {
     return response.data;
   },
 
-  getCatalog: async (): Promise<Course[]> => {
-    const response = await api.get<{ courses: Course[] }>("/catalog");
+  getCatalog: async (query?: string): Promise<Course[]> => {
+    const response = await api.get<{ courses: Course[] }>("/catalog", {
+      params: query ? { q: query } : undefined,
+    });
     return response.data.courses || [];
   },
   create: async (data: CreateCourseRequest): Promise<CreateCourseResponse> => {
@@ -19,6 +19,21 @@ export interface LeaderboardEntry {
   league_id?: number;
 }
 
+export type LeagueOutcome = "promoted" | "demoted" | "stayed";
+
+// Итог одной недели в лиге; outcome нет у старых записей с неизвестным итогом
+export interface LeagueHistory {
+  period_start: string;
+  period_end: string;
+  league_id: number;
+  league_name: string;
+  league_icon_url: string;
+  rank: number;
+  xp: number;
+  outcome?: LeagueOutcome;
+  to_league_id?: number;
+}
+
 export interface LeaderboardResponse {
   leaderboard: LeaderboardEntry[];
   user_rank?: number;
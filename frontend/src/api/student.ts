@@ -1,5 +1,6 @@
 import api from "./axios";
 import type { Subject } from "../types/subject";
+import type { LeagueHistory } from "./gamification";
 
 export interface StudentProfile {
   id: string;
@@ -24,6 +25,7 @@ export interface DashboardData {
   profile: StudentProfile;
   interests: Subject[];
   active_courses: ActiveCourse[];
+  last_week: LeagueHistory | null;
 }
 
 export interface ActiveCourse {
@@ -34,6 +34,16 @@ export interface SubmitTestResponse {
   xp_gained: number;
 }
 
+export interface StartTestResponse {
+  attempt_id: string;
+  started_at: string;
+  deadline?: string;
+  time_limit_seconds: number;
+  attempts_used: number;
+  max_attempts: number;
+  test: Test;
+}
+
 export interface StudentAnswer {
   question_id: string;
   answer_id: string;
@@ -71,15 +81,25 @@ export const testsApi = {
     return response.data;
   },
 
+  // Вопросы студент получает только в попытке
+  startTest: async (testId: string): Promise<StartTestResponse> => {
+    const response = await api.post<StartTestResponse>(
+      `/student/tests/${testId}/start`
+    );
+    return response.data;
+  },
+
   // Метод для отправки теста
   submitTest: async (
     testId: string,
+    attemptId: string,
     answers: StudentAnswer[]
   ): Promise<SubmitTestResponse> => {
     const response = await api.post<SubmitTestResponse>(
       "/student/tests/submit",
       {
         test_id: testId,
+        attempt_id: attemptId,
         answers: answers,
       }
     );
@@ -26,7 +26,8 @@ export const ProtectedRoute = ({ children, roles }: ProtectedRouteProps) => {
     // eslint-disable-next-line
     const currentTime = Date.now() / 1000;
 
-    if (decoded.exp < currentTime) {
+    // Истёкший access-токен обновит первый же запрос к API, если есть refresh-токен
+    if (decoded.exp < currentTime && !localStorage.getItem("refresh_token")) {
       return <Navigate to="/login" replace />;
     }
 
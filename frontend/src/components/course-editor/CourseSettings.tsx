@@ -91,7 +91,7 @@ export const CourseSettings: React.FC<CourseSettingsProps> = ({
               >
                 {subjects.map((s) => (
                   <option key={s.id} value={s.id}>
-                    {s.name_ru}
+                    {s.name}
                   </option>
                 ))}
               </select>
@@ -81,9 +81,14 @@ export const LessonEditor: React.FC<LessonEditorProps> = ({
           <Input
             label="Награда (XP)"
             type="number"
-            value={lesson.xp_reward}
+            min={0}
+            value={lesson.xp_reward ?? ""}
+            placeholder="По умолчанию"
             onChange={(e) =>
-              onChange({ ...lesson, xp_reward: Number(e.target.value) })
+              onChange({
+                ...lesson,
+                xp_reward: e.target.value === "" ? null : Number(e.target.value),
+              })
             }
           />
         </div>
@@ -0,0 +1,85 @@
+import React from "react";
+import { TrendingUp, TrendingDown, Minus, Trophy } from "lucide-react";
+import type { League, LeagueHistory } from "../../api/gamification";
+
+interface Props {
+  week: LeagueHistory;
+  // Лига, в которую ученик перешёл по итогам недели
+  toLeague?: League;
+}
+
+// period_end — начало следующей недели, поэтому последний день недели
+// на день раньше
+const formatDay = (day: string, shiftDays = 0) => {
+  const date = new Date(`${day}T00:00:00`);
+  date.setDate(date.getDate() + shiftDays);
+  return date.toLocaleDateString("ru-RU", { day: "numeric", month: "long" });
+};
+
+export const LastWeekCard: React.FC<Props> = ({ week, toLeague }) => {
+  const outcome = (() => {
+    switch (week.outcome) {
+      case "promoted":
+        return {
+          icon: <TrendingUp size={20} />,
+          className: "bg-green-100 text-green-600",
+          text: `Повышение в лигу «${toLeague?.name ?? "выше"}»`,
+        };
+      case "demoted":
+        return {
+          icon: <TrendingDown size={20} />,
+          className: "bg-red-100 text-red-500",
+          text: `Понижение в лигу «${toLeague?.name ?? "ниже"}»`,
+        };
+      case "stayed":
+        return {
+          icon: <Minus size={20} />,
+          className: "bg-gray-100 text-gray-500",
+          text: `Остаётесь в лиге «${week.league_name}»`,
+        };
+      default:
+        return null;
+    }
+  })();
+
+  return (
+    <div className="bg-white p-6 rounded-2xl shadow-sm border border-gray-100 flex flex-col md:flex-row justify-between items-center gap-6">
+      {/* Лига и место */}
+      <div className="flex items-center gap-4 w-full md:w-auto">
+        {week.league_icon_url ? (
+          <img
+            src={week.league_icon_url}
+            alt={week.league_name}
+            className="w-12 h-12 object-contain"
+          />
+        ) : (
+          <div className="flex items-center justify-center w-12 h-12 bg-yellow-100 text-yellow-600 rounded-full">
+            <Trophy size={24} />
+          </div>
+        )}
+        <div>
+          <div className="text-sm text-gray-500 font-medium">
+            Прошлая неделя · {formatDay(week.period_start)} —{" "}
+            {formatDay(week.period_end, -1)}
+          </div>
+          <div className="text-xl font-bold text-gray-800">
+            {week.rank}-е место в лиге «{week.league_name}»
+          </div>
+          <div className="text-sm text-gray-500">{week.xp} XP за неделю</div>
+        </div>
+      </div>
+
+      {/* Итог недели */}
+      {outcome && (
+        <div className="flex items-center gap-3">
+          <div
+            className={`flex items-center justify-center w-10 h-10 rounded-full ${outcome.className}`}
+          >
+            {outcome.icon}
+          </div>
+          <div className="font-medium text-gray-800">{outcome.text}</div>
+        </div>
+      )}
+    </div>
+  );
+};
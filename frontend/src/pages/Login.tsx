@@ -1,5 +1,6 @@
 import React, { useState } from "react";
 import { authApi } from "../api/auth";
+import { saveTokens } from "../api/axios";
 import { Button } from "../components/ui/Button";
 import { Input } from "../components/ui/Input";
 import { Link, useNavigate } from "react-router-dom";
@@ -30,7 +31,7 @@ export const LoginPage = () => {
     try {
       const response = await authApi.login(formData);
 
-      localStorage.setItem("token", response.token);
+      saveTokens(response);
 
       const decoded = jwtDecode<JwtPayload>(response.token);
 
@@ -19,6 +19,7 @@ export const CatalogPage = () => {
 
   // Состояние фильтров
   const [searchQuery, setSearchQuery] = useState("");
+  const [debouncedQuery, setDebouncedQuery] = useState("");
   const [selectedSubject, setSelectedSubject] = useState<string>("");
   const [selectedDifficulty, setSelectedDifficulty] = useState<number | "">("");
   const [selectedTags, setSelectedTags] = useState<number[]>([]);
@@ -26,37 +27,53 @@ export const CatalogPage = () => {
   // Мобильное меню фильтров
   const [showMobileFilters, setShowMobileFilters] = useState(false);
 
-  // 1. Загрузка справочников и курсов
+  // 1. Загрузка справочников
   useEffect(() => {
-    const loadData = async () => {
+    const loadDictionaries = async () => {
       try {
-        setIsLoading(true);
-        const [coursesData, subjectsData, tagsData] = await Promise.all([
-          coursesApi.getCatalog(),
+        const [subjectsData, tagsData] = await Promise.all([
           subjectsApi.getAll(),
           coursesApi.getAllTags(),
         ]);
-        setCourses(coursesData);
         setSubjects(subjectsData);
         setAllTags(tagsData);
-        setFilteredCourses(coursesData);
       } catch (error) {
         console.error("Ошибка загрузки данных", error);
-      } finally {
-        setIsLoading(false);
       }
     };
-    loadData();
+    loadDictionaries();
   }, []);
 
-  // 2. Логика фильтрации (Client-Side)
+  // Поиск уходит на сервер (он же записывает запрос в аналитику) только
+  // после паузы в наборе, а не на каждую букву
   useEffect(() => {
-    const filtered = courses.filter((c) => {
-      // Поиск по названию
-      const matchesSearch = c.title
-        .toLowerCase()
-        .includes(searchQuery.toLowerCase());
+    const timer = setTimeout(() => setDebouncedQuery(searchQuery.trim()), 400);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
+
+  // 2. Загрузка курсов с учётом поиска
+  useEffect(() => {
+    let cancelled = false;
+    const loadCourses = async () => {
+      try {
+        setIsLoading(true);
+        const coursesData = await coursesApi.getCatalog(debouncedQuery);
+        if (!cancelled) setCourses(coursesData);
+      } catch (error) {
+        console.error("Ошибка загрузки курсов", error);
+      } finally {
+        if (!cancelled) setIsLoading(false);
+      }
+    };
+    loadCourses();
+    return () => {
+      cancelled = true;
+    };
+  }, [debouncedQuery]);
 
+  // 3. Логика фильтрации (Client-Side)
+  useEffect(() => {
+    const filtered = courses.filter((c) => {
       // Фильтр по предмету
       const matchesSubject = selectedSubject
         ? c.subject_id === selectedSubject
@@ -75,13 +92,11 @@ export const CatalogPage = () => {
           ? c.tags?.some((tag) => selectedTags.includes(tag.id))
           : true;
 
-      return (
-        matchesSearch && matchesSubject && matchesDifficulty && matchesTags
-      );
+      return matchesSubject && matchesDifficulty && matchesTags;
     });
 
     setFilteredCourses(filtered);
-  }, [searchQuery, selectedSubject, selectedDifficulty, selectedTags, courses]);
+  }, [selectedSubject, selectedDifficulty, selectedTags, courses]);
 
   // Хендлер для тегов (toggle)
   const toggleTag = (tagId: number) => {
@@ -164,7 +179,7 @@ export const CatalogPage = () => {
                 <option value="">Все предметы</option>
                 {subjects.map((s) => (
                   <option key={s.id} value={s.id}>
-                    {s.name_ru}
+                    {s.name}
                   </option>
                 ))}
               </select>
@@ -137,7 +137,7 @@ export const OnboardingPage = () => {
                       : "border-gray-200 text-gray-700 hover:border-indigo-300 hover:bg-gray-50"
                   }`}
                 >
-                  <span className="font-semibold block">{subject.name_ru}</span>
+                  <span className="font-semibold block">{subject.name}</span>
                   {isSelected && (
                     <div className="absolute top-2 right-2 bg-white text-indigo-600 rounded-full p-0.5">
                       <Check size={12} strokeWidth={4} />
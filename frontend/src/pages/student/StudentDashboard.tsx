@@ -6,6 +6,7 @@ import type { League } from "../../api/gamification";
 import type { DashboardData } from "../../api/student";
 import { GamificationStats } from "../../components/student/GamificationStats";
 import { ActiveCourseCard } from "../../components/student/ActiveCourseCard";
+import { LastWeekCard } from "../../components/student/LastWeekCard";
 import { Button } from "../../components/ui/Button";
 import { Compass, BookOpen } from "lucide-react";
 import type { Course } from "../../types/course";
@@ -90,6 +91,16 @@ export const StudentDashboard = () => {
               leagueIcon={currentLeague?.icon_url}
             />
           )}
+          {data.last_week && (
+            <div className="mt-4">
+              <LastWeekCard
+                week={data.last_week}
+                toLeague={leagues.find(
+                  (l) => l.id === data.last_week?.to_league_id
+                )}
+              />
+            </div>
+          )}
         </header>
 
         {/* 2. Активные курсы */}
@@ -11,6 +11,7 @@ import {
 } from "lucide-react";
 import { Button } from "../../components/ui/Button";
 import { studentApi, type HeaderInfo } from "../../api/student";
+import { authApi } from "../../api/auth";
 
 export const StudentHeader = () => {
   const navigate = useNavigate();
@@ -35,8 +36,12 @@ export const StudentHeader = () => {
     loadData();
   }, []);
 
-  const handleLogout = () => {
-    localStorage.removeItem("token");
+  const handleLogout = async () => {
+    try {
+      await authApi.logout();
+    } catch (e) {
+      console.error("Ошибка выхода", e);
+    }
     navigate("/login");
   };
 
@@ -1,5 +1,6 @@
 import { useState, useEffect } from "react";
 import { useParams, useNavigate } from "react-router-dom";
+import { AxiosError } from "axios";
 import { testsApi, type Test, type StudentAnswer } from "../../api/tests";
 import { Button } from "../../components/ui/Button";
 import { CheckCircle, XCircle, AlertCircle } from "lucide-react";
@@ -9,6 +10,8 @@ export const TestPlayer = () => {
   const navigate = useNavigate();
 
   const [test, setTest] = useState<Test | null>(null);
+  const [attemptId, setAttemptId] = useState<string | null>(null);
+  const [startError, setStartError] = useState<string | null>(null);
   const [answers, setAnswers] = useState<Record<string, string>>({}); // questionId -> answerId
   const [result, setResult] = useState<{
     is_passed: boolean;
@@ -22,10 +25,18 @@ export const TestPlayer = () => {
     if (!moduleId) return;
     const loadTest = async () => {
       try {
-        const data = await testsApi.getByModuleIdWithAnswers(moduleId);
-        setTest(data);
+        const { test_id } = await testsApi.getByModuleId(moduleId);
+        const start = await testsApi.startTest(test_id);
+        setTest(start.test);
+        setAttemptId(start.attempt_id);
       } catch (error) {
-        console.error("Test not found", error);
+        console.error("Failed to start test", error);
+        const err = error as AxiosError<{ message: string }>;
+        if (err.response?.status !== 404) {
+          setStartError(
+            err.response?.data?.message || "Не удалось начать попытку"
+          );
+        }
       } finally {
         setIsLoading(false);
       }
@@ -38,7 +49,7 @@ export const TestPlayer = () => {
   };
 
   const handleSubmit = async () => {
-    if (!test) return;
+    if (!test || !attemptId) return;
     setIsSubmitting(true);
 
     const submitData: StudentAnswer[] = Object.entries(answers).map(
@@ -49,7 +60,11 @@ export const TestPlayer = () => {
     );
 
     try {
-      const res = await testsApi.submitTest(test.test_id, submitData);
+      const res = await testsApi.submitTest(
+        test.test_id,
+        attemptId,
+        submitData
+      );
       setResult(res);
     } catch (error) {
       alert("Ошибка отправки теста");
@@ -61,6 +76,8 @@ export const TestPlayer = () => {
 
   if (isLoading)
     return <div className="p-10 text-center">Загрузка теста...</div>;
+  if (startError)
+    return <div className="p-10 text-center">{startError}</div>;
   if (!test) return <div className="p-10 text-center">Тест не найден</div>;
 
   // ЭКРАН РЕЗУЛЬТАТА
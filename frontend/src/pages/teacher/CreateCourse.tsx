@@ -162,7 +162,7 @@ export const CreateCoursePage = () => {
               {subjects.length === 0 && <option>Загрузка...</option>}
               {subjects.map((s) => (
                 <option key={s.id} value={s.id}>
-                  {s.name_ru}
+                  {s.name}
                 </option>
               ))}
             </select>
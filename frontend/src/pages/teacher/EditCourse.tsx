@@ -146,7 +146,7 @@ export const EditCoursePage = () => {
       content_text: "",
       video_url: "",
       file_attachment_url: "",
-      xp_reward: 0,
+      xp_reward: null,
     };
 
     try {
@@ -11,6 +11,9 @@ export interface User {
 
 export interface LoginResponse {
   token: string;
+  expires_at: string;
+  refresh_token: string;
+  refresh_expires_at: string;
 }
 
 export interface RegisterResponse {
@@ -5,7 +5,8 @@ export interface Lesson {
   content_text?: string;
   video_url?: string;
   file_attachment_url?: string;
-  xp_reward: number;
+  // null — награда по умолчанию из политики геймификации
+  xp_reward: number | null;
   order_index: number;
 }
 
@@ -79,7 +80,7 @@ export interface CreateLessonRequest {
   content_text: string;
   video_url: string;
   file_attachment_url: string;
-  xp_reward: number;
+  xp_reward: number | null;
 }
 
 export interface UpdateLessonRequest {
@@ -88,5 +89,5 @@ export interface UpdateLessonRequest {
   content_text: string;
   video_url: string;
   file_attachment_url: string;
-  xp_reward: number;
+  xp_reward: number | null;
 }
@@ -1,6 +1,5 @@
 export interface Subject {
     id: string;
     slug: string;
-    name_ru: string;
-    name_kz: string;
+    name: string;
 }
\ No newline at end of file
@@ -83,6 +83,10 @@ def _get_all_courses_with_stats(user_grade: int) -> pd.DataFrame:
     - popularity: сколько раз курс смотрели вообще
     - similar_popularity: сколько раз курс смотрели ученики того же класса (grade)
     Это даёт нам лёгкий "коллаборативный фильтр".
+
+    Учитываем только просмотры и прохождения: открытия уроков, отправки
+    тестов и переключения избранного тоже пишутся с course_id, но
+    популярность курса не отражают (снятие из избранного её бы поднимало).
     """
     query = """
     SELECT
@@ -97,7 +101,9 @@ def _get_all_courses_with_stats(user_grade: int) -> pd.DataFrame:
             0
         ) AS similar_popularity
     FROM courses c
-    LEFT JOIN user_activity_logs ual ON ual.course_id = c.id
+    LEFT JOIN user_activity_logs ual
+        ON ual.course_id = c.id
+       AND ual.action_type IN ('view', 'complete')
     LEFT JOIN student_profiles sp ON sp.user_id = ual.user_id
     WHERE c.is_published = TRUE
     GROUP BY c.id, c.subject_id, c.difficulty_level, c.title, c.created_at